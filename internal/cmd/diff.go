@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// outputDiff is the difference between generated files and an output directory
+type outputDiff struct {
+	// added are generated files that don't exist yet
+	added []string
+	// changed are generated files whose content differs from the existing file
+	changed []string
+	// removed are existing .tf files that are no longer generated
+	removed []string
+	// diffs holds the unified diff of each changed file
+	diffs map[string]string
+}
+
+func (d *outputDiff) empty() bool {
+	return len(d.added) == 0 && len(d.changed) == 0 && len(d.removed) == 0
+}
+
+// diffOutput compares generated files, keyed by path relative to outputDir,
+// against the files in outputDir. Existing .tf files in the directories the
+// files are generated into count as removed when they are not generated;
+// other files, such as Terraform state, are not compared.
+func diffOutput(files map[string]string, outputDir string) (*outputDiff, error) {
+	d := &outputDiff{diffs: make(map[string]string)}
+
+	dirs := map[string]bool{".": true}
+	for filename, content := range files {
+		dirs[filepath.Dir(filename)] = true
+
+		existing, err := readFile(filepath.Join(outputDir, filename))
+		if errors.Is(err, fs.ErrNotExist) {
+			d.added = append(d.added, filename)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filepath.Join(outputDir, filename), err)
+		}
+		if string(existing) == content {
+			continue
+		}
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(existing)),
+			B:        difflib.SplitLines(content),
+			FromFile: "a/" + filepath.ToSlash(filename),
+			ToFile:   "b/" + filepath.ToSlash(filename),
+			Context:  3,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff %s: %w", filename, err)
+		}
+		d.changed = append(d.changed, filename)
+		d.diffs[filename] = diff
+	}
+
+	for dir := range dirs {
+		matches, err := filepath.Glob(filepath.Join(outputDir, dir, "*.tf"))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			filename, err := filepath.Rel(outputDir, match)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := files[filename]; !ok {
+				d.removed = append(d.removed, filename)
+			}
+		}
+	}
+
+	sort.Strings(d.added)
+	sort.Strings(d.changed)
+	sort.Strings(d.removed)
+	return d, nil
+}
+
+// printOutputDiff prints the unified diff of each changed file, followed by
+// the added, changed, and removed files
+func printOutputDiff(d *outputDiff, outputDir string) {
+	for _, filename := range d.changed {
+		fmt.Print(d.diffs[filename])
+	}
+
+	for _, group := range []struct {
+		title string
+		files []string
+	}{
+		{"Added", d.added},
+		{"Changed", d.changed},
+		{"Removed", d.removed},
+	} {
+		if len(group.files) == 0 {
+			continue
+		}
+		fmt.Printf("%s files:\n", group.title)
+		for _, filename := range group.files {
+			fmt.Printf("  %s\n", filepath.Join(outputDir, filename))
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// captureStdout returns what run prints to standard output
+func captureStdout(t *testing.T, run func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	run()
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestDiffOutput(t *testing.T) {
+	outputDir := t.TempDir()
+	existing := map[string]string{
+		"same.tf":           "locals {}\n",
+		"changed.tf":        "locals {\n  a = 1\n}\n",
+		"old.tf":            "locals {}\n",
+		"terraform.tfstate": "{}\n",
+	}
+	for name, content := range existing {
+		if err := os.WriteFile(filepath.Join(outputDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := map[string]string{
+		"same.tf":    "locals {}\n",
+		"changed.tf": "locals {\n  a = 2\n}\n",
+		"added.tf":   "locals {}\n",
+	}
+	d, err := diffOutput(files, outputDir)
+	if err != nil {
+		t.Fatalf("Expected no error diffing, got: %v", err)
+	}
+	if !reflect.DeepEqual(d.added, []string{"added.tf"}) || !reflect.DeepEqual(d.changed, []string{"changed.tf"}) || !reflect.DeepEqual(d.removed, []string{"old.tf"}) {
+		t.Errorf("Expected added.tf added, changed.tf changed, and old.tf removed, got %v, %v, and %v", d.added, d.changed, d.removed)
+	}
+	for _, want := range []string{"--- a/changed.tf", "+++ b/changed.tf", "-  a = 1", "+  a = 2"} {
+		if !strings.Contains(d.diffs["changed.tf"], want) {
+			t.Errorf("Expected the diff of changed.tf to contain %q, got:\n%s", want, d.diffs["changed.tf"])
+		}
+	}
+
+	out := captureStdout(t, func() { printOutputDiff(d, outputDir) })
+	for _, want := range []string{
+		"+  a = 2",
+		"Added files:\n  " + filepath.Join(outputDir, "added.tf"),
+		"Changed files:\n  " + filepath.Join(outputDir, "changed.tf"),
+		"Removed files:\n  " + filepath.Join(outputDir, "old.tf"),
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "same.tf") {
+		t.Errorf("Expected unchanged files not to be listed, got:\n%s", out)
+	}
+
+	// Nothing is written
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(existing) {
+		t.Errorf("Expected only the %d existing files in the output directory, got %d", len(existing), len(entries))
+	}
+	for name, content := range existing {
+		if got, err := os.ReadFile(filepath.Join(outputDir, name)); err != nil || string(got) != content {
+			t.Errorf("Expected %s to be left unchanged, got %q (%v)", name, got, err)
+		}
+	}
+
+	d, err = diffOutput(map[string]string{"same.tf": "locals {}\n", "changed.tf": existing["changed.tf"], "old.tf": "locals {}\n"}, outputDir)
+	if err != nil || !d.empty() {
+		t.Errorf("Expected no differences, got %+v (%v)", d, err)
+	}
+}
@@ -0,0 +1,333 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"custoodian/pkg/config"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+type docOptions struct {
+	configFile string
+	output     string
+}
+
+func newDocCmd() *cobra.Command {
+	opts := &docOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "doc [config-file]",
+		Short: "Generate markdown documentation for a configuration",
+		Long: `Generate markdown documentation describing the infrastructure in a configuration file.
+
+Unlike 'schema', which documents the configuration format, this documents the
+resources actually configured: each resource, its settings, and the other
+declared resources it references. The output is intended to be attached to
+change requests so reviewers can understand a deployment without reading HCL.
+
+Examples:
+  custodian doc config.textproto
+  custodian doc config.textproto --output infra.md`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.configFile = args[0]
+			return runDoc(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Output file (default: stdout)")
+
+	return cmd
+}
+
+func runDoc(opts *docOptions) error {
+	cfg, err := loadConfig(opts.configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	hash, err := configHash(cfg)
+	if err != nil {
+		return err
+	}
+
+	content := renderConfigDoc(cfg, opts.configFile, hash)
+
+	if opts.output == "" {
+		fmt.Print(content)
+		return nil
+	}
+
+	if err := writeFile(opts.output, content); err != nil {
+		return fmt.Errorf("failed to write documentation: %w", err)
+	}
+
+	fmt.Printf("✓ Documentation written to %s\n", opts.output)
+	return nil
+}
+
+// docResource is a named resource found in the configuration
+type docResource struct {
+	kind string
+	name string
+}
+
+// configDoc renders a configuration as markdown. Resources are discovered by
+// reflection so new configuration sections are documented without changes here.
+type configDoc struct {
+	b strings.Builder
+	// declared maps resource names to the resources declaring them, used to
+	// detect cross-references
+	declared map[string][]docResource
+}
+
+// renderConfigDoc returns markdown documentation for cfg
+func renderConfigDoc(cfg *config.Config, source, hash string) string {
+	d := &configDoc{declared: make(map[string][]docResource)}
+	d.collect(cfg.ProtoReflect())
+
+	title := cfg.GetProject().GetName()
+	if title == "" {
+		title = cfg.GetProject().GetId()
+	}
+	fmt.Fprintf(&d.b, "# Infrastructure: %s\n\n", escapeMarkdown(title))
+	fmt.Fprintf(&d.b, "Generated by custoodian from `%s` (config hash `%s`).\n", source, hash)
+
+	m := cfg.ProtoReflect()
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if !m.Has(fd) {
+			continue
+		}
+
+		fmt.Fprintf(&d.b, "\n## %s\n", humanize(string(fd.Name())))
+		if fd.IsList() {
+			list := m.Get(fd).List()
+			for j := 0; j < list.Len(); j++ {
+				d.renderResource(list.Get(j).Message(), 3)
+			}
+		} else {
+			d.renderSection(m.Get(fd).Message(), 3)
+		}
+	}
+
+	return d.b.String()
+}
+
+// collect records the names of all resources in m and its descendants
+func (d *configDoc) collect(m protoreflect.Message) {
+	if name := resourceName(m); name != "" {
+		d.declared[name] = append(d.declared[name], docResource{kind: messageKind(m), name: name})
+	}
+
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Kind() != protoreflect.MessageKind || fd.IsMap() {
+			return true
+		}
+		if fd.IsList() {
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				d.collect(list.Get(i).Message())
+			}
+		} else {
+			d.collect(v.Message())
+		}
+		return true
+	})
+}
+
+// renderSection writes the settings and resources of a configuration section
+func (d *configDoc) renderSection(m protoreflect.Message, level int) {
+	rows, children := d.flatten(m, "")
+	d.renderTable(rows)
+	for _, child := range children {
+		d.renderResource(child, level)
+	}
+}
+
+// renderResource writes a heading, settings table, and references for a resource
+func (d *configDoc) renderResource(m protoreflect.Message, level int) {
+	name := resourceName(m)
+	heading := messageKind(m)
+	if name != "" {
+		heading = fmt.Sprintf("%s `%s`", heading, name)
+	}
+	fmt.Fprintf(&d.b, "\n%s %s\n", strings.Repeat("#", level), heading)
+
+	rows, children := d.flatten(m, "")
+	d.renderTable(rows)
+
+	var refs []string
+	for _, row := range rows {
+		for _, value := range row.refValues {
+			for _, target := range d.declared[value] {
+				if target.name == name && target.kind == messageKind(m) {
+					continue
+				}
+				refs = append(refs, fmt.Sprintf("- `%s` → %s `%s`", row.key, target.kind, target.name))
+			}
+		}
+	}
+	if len(refs) > 0 {
+		d.b.WriteString("\nReferences:\n\n")
+		d.b.WriteString(strings.Join(refs, "\n"))
+		d.b.WriteString("\n")
+	}
+
+	// Nested resources (e.g. subnets of a VPC) are rendered one level deeper
+	childLevel := level + 1
+	if childLevel > 6 {
+		childLevel = 6
+	}
+	for _, child := range children {
+		d.renderResource(child, childLevel)
+	}
+}
+
+// docRow is a single setting in a resource's table
+type docRow struct {
+	key   string
+	value string
+	// refValues holds the raw string values that may name other resources
+	refValues []string
+}
+
+// flatten returns the settings of m as table rows, with singular nested
+// messages flattened into dotted keys, and the repeated nested messages that
+// should be rendered as separate resources
+func (d *configDoc) flatten(m protoreflect.Message, prefix string) ([]docRow, []protoreflect.Message) {
+	var rows []docRow
+	var children []protoreflect.Message
+
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if !m.Has(fd) {
+			continue
+		}
+		key := prefix + string(fd.Name())
+		v := m.Get(fd)
+
+		switch {
+		case fd.IsMap():
+			var entries []string
+			v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				entries = append(entries, fmt.Sprintf("%s=%s", k.String(), formatValue(fd.MapValue(), mv)))
+				return true
+			})
+			sort.Strings(entries)
+			rows = append(rows, docRow{key: key, value: "`" + strings.Join(entries, "`, `") + "`"})
+		case fd.IsList() && fd.Kind() == protoreflect.MessageKind:
+			list := v.List()
+			for j := 0; j < list.Len(); j++ {
+				children = append(children, list.Get(j).Message())
+			}
+		case fd.IsList():
+			list := v.List()
+			values := make([]string, list.Len())
+			var refValues []string
+			for j := 0; j < list.Len(); j++ {
+				values[j] = formatValue(fd, list.Get(j))
+				if fd.Kind() == protoreflect.StringKind {
+					refValues = append(refValues, list.Get(j).String())
+				}
+			}
+			rows = append(rows, docRow{key: key, value: "`" + strings.Join(values, "`, `") + "`", refValues: refValues})
+		case fd.Kind() == protoreflect.MessageKind:
+			nestedRows, nestedChildren := d.flatten(v.Message(), key+".")
+			rows = append(rows, nestedRows...)
+			children = append(children, nestedChildren...)
+		default:
+			row := docRow{key: key, value: "`" + formatValue(fd, v) + "`"}
+			if fd.Kind() == protoreflect.StringKind && !isNameField(fd) {
+				row.refValues = []string{v.String()}
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	return rows, children
+}
+
+// renderTable writes rows as a markdown settings table
+func (d *configDoc) renderTable(rows []docRow) {
+	if len(rows) == 0 {
+		return
+	}
+	d.b.WriteString("\n| Setting | Value |\n|---------|-------|\n")
+	for _, row := range rows {
+		fmt.Fprintf(&d.b, "| %s | %s |\n", row.key, escapeMarkdown(row.value))
+	}
+}
+
+// formatValue formats a scalar field value for display
+func formatValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	if fd.Kind() == protoreflect.EnumKind {
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return fmt.Sprintf("%d", v.Enum())
+	}
+	return strings.ReplaceAll(v.String(), "\n", " ")
+}
+
+// isNameField reports whether fd holds the identifying name of its resource
+func isNameField(fd protoreflect.FieldDescriptor) bool {
+	switch fd.Name() {
+	case "name", "account_id", "role_id", "dataset_id", "table_id", "repository_id", "range_name":
+		return true
+	}
+	return false
+}
+
+// resourceName returns the identifying name of a resource, or "" if m has none
+func resourceName(m protoreflect.Message) string {
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if isNameField(fd) && fd.Kind() == protoreflect.StringKind && !fd.IsList() {
+			return m.Get(fd).String()
+		}
+	}
+	return ""
+}
+
+// messageKind returns a readable resource kind from a message name
+// (e.g. "CloudSqlInstance" becomes "Cloud Sql Instance")
+func messageKind(m protoreflect.Message) string {
+	name := string(m.Descriptor().Name())
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// humanize converts a snake_case field name into a title (e.g. "load_balancers" becomes "Load Balancers")
+func humanize(field string) string {
+	words := strings.Split(field, "_")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// escapeMarkdown escapes characters that would break a markdown table cell
+func escapeMarkdown(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+func init() {
+	rootCmd.AddCommand(newDocCmd())
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"custoodian/pkg/config"
+)
+
+func TestRenderConfigDoc(t *testing.T) {
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test | Project", Labels: map[string]string{"team": "web", "env": "prod"}},
+		Networking: &config.Networking{
+			Vpcs: []*config.Vpc{{
+				Name:    "main-vpc",
+				Subnets: []*config.Subnet{{Name: "main-subnet", Cidr: "10.0.0.0/24", Region: config.Region_REGION_US_CENTRAL1}},
+			}},
+			FirewallRules: []*config.FirewallRule{{
+				Name:        "allow-web",
+				Network:     "main-vpc",
+				Description: "HTTP | HTTPS",
+				Direction:   "INGRESS",
+			}},
+		},
+	}
+
+	doc := renderConfigDoc(cfg, "config.textproto", "4a5e1e4b")
+	for _, want := range []string{
+		"# Infrastructure: Test \\| Project\n",
+		"Generated by custoodian from `config.textproto` (config hash `4a5e1e4b`).\n",
+		"\n## Project\n",
+		"| Setting | Value |\n|---------|-------|\n| id | `test-project-123` |\n",
+		"| labels | `env=prod`, `team=web` |\n",
+		"\n## Networking\n",
+		"\n### Vpc `main-vpc`\n",
+		"\n#### Subnet `main-subnet`\n",
+		"| cidr | `10.0.0.0/24` |\n",
+		"| region | `REGION_US_CENTRAL1` |\n",
+		"\n### Firewall Rule `allow-web`\n",
+		"| description | `HTTP \\| HTTPS` |\n",
+		"- `network` → Vpc `main-vpc`",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("Expected the documentation to contain %q, got:\n%s", want, doc)
+		}
+	}
+}
+
+func TestHumanize(t *testing.T) {
+	for field, want := range map[string]string{
+		"project":        "Project",
+		"load_balancers": "Load Balancers",
+		"cloud_run":      "Cloud Run",
+	} {
+		if got := humanize(field); got != want {
+			t.Errorf("humanize(%q) = %q, want %q", field, got, want)
+		}
+	}
+}
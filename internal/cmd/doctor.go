@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"custoodian/internal/generator"
+
+	"github.com/spf13/cobra"
+)
+
+type doctorOptions struct {
+	outputDir string
+	// lookPath finds executables; it defaults to exec.LookPath
+	lookPath func(file string) (string, error)
+}
+
+// doctorCheck is a single environment check reported by doctor
+type doctorCheck struct {
+	name string
+	run  func() (string, error)
+}
+
+func newDoctorCmd() *cobra.Command {
+	opts := &doctorOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the environment for common problems",
+		Long: `Check the environment custoodian runs in and print a pass/fail checklist.
+
+This checks the Go runtime, that the built-in templates parse, that terraform or
+tofu (used by generate --tf-validate) is installed, and that the output
+directory is writable.
+Include the output when reporting issues.
+
+Examples:
+  custodian doctor
+  custodian doctor --output ./infrastructure`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDoctor(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.outputDir, "output", "o", ".", "Output directory to check for write permission")
+
+	return cmd
+}
+
+func runDoctor(opts *doctorOptions) error {
+	lookPath := opts.lookPath
+	if lookPath == nil {
+		lookPath = exec.LookPath
+	}
+
+	checks := []doctorCheck{
+		{"custoodian version", func() (string, error) {
+			return fmt.Sprintf("%s (commit: %s, built: %s)", version, commit, date), nil
+		}},
+		{"Go runtime", func() (string, error) {
+			return fmt.Sprintf("%s %s/%s", runtime.Version(), runtime.GOOS, runtime.GOARCH), nil
+		}},
+		{"built-in templates", checkBuiltinTemplates},
+		{"terraform", func() (string, error) { return checkTerraform(lookPath) }},
+		{"output directory", func() (string, error) { return checkWritable(opts.outputDir) }},
+	}
+
+	failed := 0
+	for _, check := range checks {
+		detail, err := check.run()
+		if err != nil {
+			failed++
+			fmt.Printf("✗ %s: %v\n", check.name, err)
+			continue
+		}
+		fmt.Printf("✓ %s: %s\n", check.name, detail)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
+
+// checkBuiltinTemplates parses the built-in templates without the template cache
+func checkBuiltinTemplates() (string, error) {
+	_, err := generator.NewWithOptions("builtin", &generator.NewOptions{
+		Logger:       log.New(io.Discard, "", 0),
+		DisableCache: true,
+	})
+	if err != nil {
+		return "", err
+	}
+	return "parsed successfully", nil
+}
+
+// checkTerraform looks up the terraform binary, falling back to tofu
+func checkTerraform(lookPath func(string) (string, error)) (string, error) {
+	for _, binary := range []string{"terraform", "tofu"} {
+		if path, err := lookPath(binary); err == nil {
+			return fmt.Sprintf("%s found at %s", binary, path), nil
+		}
+	}
+	return "", fmt.Errorf("neither terraform nor tofu found in PATH (needed by generate --tf-validate)")
+}
+
+// checkWritable checks that files can be created in dir. A directory that does
+// not exist yet is checked through its nearest existing parent, since generate
+// creates it.
+func checkWritable(dir string) (string, error) {
+	dir = filepath.Clean(dir)
+	existing := dir
+	for {
+		info, err := os.Stat(existing)
+		if err == nil {
+			if !info.IsDir() {
+				return "", fmt.Errorf("%s is not a directory", existing)
+			}
+			break
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return "", err
+		}
+		existing = parent
+	}
+
+	f, err := os.CreateTemp(existing, ".custoodian-doctor-*")
+	if err != nil {
+		return "", fmt.Errorf("%s is not writable: %w", existing, err)
+	}
+	name := f.Name()
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Remove(name); err != nil {
+		return "", err
+	}
+
+	if existing != dir {
+		return fmt.Sprintf("%s does not exist yet; %s is writable", dir, existing), nil
+	}
+	return fmt.Sprintf("%s is writable", dir), nil
+}
+
+func init() {
+	rootCmd.AddCommand(newDoctorCmd())
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestRunDoctor(t *testing.T) {
+	tests := []struct {
+		name     string
+		lookPath func(string) (string, error)
+		wantErr  string
+		want     string
+	}{
+		{
+			name: "terraform found",
+			lookPath: func(file string) (string, error) {
+				if file == "terraform" {
+					return "/usr/local/bin/terraform", nil
+				}
+				return "", exec.ErrNotFound
+			},
+			want: "✓ terraform: terraform found at /usr/local/bin/terraform",
+		},
+		{
+			name: "tofu found",
+			lookPath: func(file string) (string, error) {
+				if file == "tofu" {
+					return "/usr/bin/tofu", nil
+				}
+				return "", exec.ErrNotFound
+			},
+			want: "✓ terraform: tofu found at /usr/bin/tofu",
+		},
+		{
+			name: "terraform missing",
+			lookPath: func(string) (string, error) {
+				return "", exec.ErrNotFound
+			},
+			wantErr: "1 of 5 checks failed",
+			want:    "✗ terraform: neither terraform nor tofu found in PATH",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &doctorOptions{outputDir: t.TempDir(), lookPath: tt.lookPath}
+
+			var err error
+			output := captureStdout(t, func() { err = runDoctor(opts) })
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("runDoctor() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("runDoctor() error = %v, want %q", err, tt.wantErr)
+			}
+			if !strings.Contains(output, tt.want) {
+				t.Errorf("output does not contain %q:\n%s", tt.want, output)
+			}
+			if !strings.Contains(output, "✓ output directory") {
+				t.Errorf("output directory check failed:\n%s", output)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+
+	"custoodian/internal/generator"
+	"custoodian/internal/validator"
+
+	"github.com/spf13/cobra"
+)
+
+type explainOptions struct {
+	configFile string
+	validate   bool
+}
+
+func newExplainCmd() *cobra.Command {
+	opts := &explainOptions{
+		validate: true,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "explain [config-file]",
+		Short: "Summarize the resources a configuration generates",
+		Long: `Summarize the resources generated for a configuration without writing any
+Terraform.
+
+The configuration is validated first, then each project is printed as a tree
+with the names of its resources grouped by Terraform resource type, followed by
+the APIs the project enables and any APIs its resources need that it doesn't
+enable. Disabled resources are left out.
+
+Examples:
+  custodian explain config.textproto
+  custodian explain --validate=false config.textproto`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.configFile = args[0]
+			return runExplain(opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.validate, "validate", true, "Validate configuration before summarizing")
+
+	return cmd
+}
+
+func runExplain(opts *explainOptions) error {
+	cfg, err := loadConfig(opts.configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if opts.validate {
+		if err := validator.ValidateConfig(cfg); err != nil {
+			return fmt.Errorf("configuration validation failed: %w%s", err, explainHint(err))
+		}
+		printWarnings(validator.Warnings(cfg))
+	}
+
+	summary, err := generator.Summarize(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to summarize configuration: %w", err)
+	}
+
+	for i, project := range summary.Projects {
+		if i > 0 {
+			fmt.Println()
+		}
+		printProjectSummary(project)
+	}
+	return nil
+}
+
+// treeNode is an entry of the tree printed by explain
+type treeNode struct {
+	label    string
+	children []treeNode
+}
+
+// printProjectSummary prints a project's resources and APIs as a tree
+func printProjectSummary(project generator.ProjectSummary) {
+	count := 0
+	var nodes []treeNode
+	for _, group := range project.Resources {
+		count += len(group.Names)
+		node := treeNode{label: fmt.Sprintf("%s (%d)", group.Type, len(group.Names))}
+		for _, name := range group.Names {
+			node.children = append(node.children, treeNode{label: name})
+		}
+		nodes = append(nodes, node)
+	}
+
+	title := "Project " + project.Id
+	if project.Name != "" {
+		title += fmt.Sprintf(" (%s)", project.Name)
+	}
+	fmt.Printf("%s: %d resources\n", title, count)
+
+	apis := treeNode{label: fmt.Sprintf("APIs enabled (%d)", len(project.APIs))}
+	for _, api := range project.APIs {
+		apis.children = append(apis.children, treeNode{label: api})
+	}
+	nodes = append(nodes, apis)
+	if len(project.MissingAPIs) > 0 {
+		missing := treeNode{label: fmt.Sprintf("APIs needed but not enabled (%d)", len(project.MissingAPIs))}
+		for _, api := range project.MissingAPIs {
+			missing.children = append(missing.children, treeNode{label: api})
+		}
+		nodes = append(nodes, missing)
+	}
+
+	printTree(nodes, "")
+}
+
+// printTree prints nodes below a parent whose children are indented by prefix
+func printTree(nodes []treeNode, prefix string) {
+	for i, node := range nodes {
+		branch, indent := "├── ", "│   "
+		if i == len(nodes)-1 {
+			branch, indent = "└── ", "    "
+		}
+		fmt.Println(prefix + branch + node.label)
+		printTree(node.children, prefix+indent)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(newExplainCmd())
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"custoodian/internal/validator"
+
+	"github.com/spf13/cobra"
+)
+
+func newExplainErrorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "explain-error [code]",
+		Short: "Explain a validation error code",
+		Long: `Print a detailed description and remediation for a validation error code.
+
+Validation errors include a stable code in brackets, e.g. "[NET002] CIDR range
+10.0.1.0/24 (subnet b in VPC main) overlaps with 10.0.0.0/16 (subnet a in VPC
+main)". Without a code, all codes are listed.
+
+Examples:
+  custodian explain-error NET002
+  custodian explain-error`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				for _, code := range validator.Codes() {
+					explanation, _ := validator.Explain(code)
+					fmt.Printf("%s  %s\n", code, explanation.Title)
+				}
+				return nil
+			}
+			return runExplainError(args[0])
+		},
+	}
+
+	return cmd
+}
+
+func runExplainError(arg string) error {
+	code := validator.Code(strings.ToUpper(strings.Trim(arg, "[]")))
+	explanation, ok := validator.Explain(code)
+	if !ok {
+		return fmt.Errorf("unknown error code: %s (run 'custodian explain-error' to list codes)", arg)
+	}
+
+	fmt.Printf("%s: %s\n\n%s\n\nRemediation: %s\n", explanation.Code, explanation.Title, explanation.Description, explanation.Remediation)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(newExplainErrorCmd())
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"custoodian/pkg/config"
+
+	"github.com/spf13/cobra"
+)
+
+type fmtOptions struct {
+	write bool
+	check bool
+}
+
+func newFmtCmd() *cobra.Command {
+	opts := &fmtOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "fmt [config-file...]",
+		Short: "Rewrite configuration files in canonical format",
+		Long: `Rewrite Protocol Buffer configuration files (textproto or JSON) in canonical
+format, similar to terraform fmt.
+
+Fields are written in schema order, one per line, indented by two spaces. The
+configuration is parsed but not resolved, so references such as @env or
+@project.id are kept as written. By default the formatted configuration is printed to stdout.
+
+Comments can't be carried over, so --write refuses to rewrite a file that has
+them, and --check ignores them when comparing. YAML files are not supported.
+
+Examples:
+  custodian fmt config.textproto
+  custodian fmt --write config.textproto
+  custodian fmt --check examples/*.textproto`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFmt(opts, args)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&opts.write, "write", "w", false, "Write the formatted configuration back to the file")
+	cmd.Flags().BoolVar(&opts.check, "check", false, "Exit non-zero if any file is not formatted, without changing it")
+	cmd.MarkFlagsMutuallyExclusive("write", "check")
+
+	return cmd
+}
+
+func runFmt(opts *fmtOptions, files []string) error {
+	unformatted := 0
+	for _, file := range files {
+		content, err := readFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		format := config.InputFormatForFile(file)
+		cfg := &config.Config{}
+		if err := config.Unmarshal(content, format, cfg); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+
+		formatted, err := config.Format(cfg, format)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+
+		// JSON has no comments, and protojson rejects any that slipped in
+		stripped, hasComments := content, false
+		if format == config.InputTextproto {
+			stripped, hasComments = config.StripComments(content)
+		}
+
+		switch {
+		case opts.check:
+			if !bytes.Equal(stripped, formatted) {
+				fmt.Println(file)
+				unformatted++
+			}
+		case opts.write:
+			// Comments are all a formatted file can differ by
+			if bytes.Equal(stripped, formatted) {
+				continue
+			}
+			if hasComments {
+				return fmt.Errorf("%s has comments, which formatting would remove; remove them or run without --write", file)
+			}
+			// Keep the file's permissions rather than writeFile's 0600
+			info, err := os.Stat(file)
+			if err != nil {
+				return fmt.Errorf("failed to stat %s: %w", file, err)
+			}
+			if err := os.WriteFile(filepath.Clean(file), formatted, info.Mode().Perm()); err != nil {
+				return fmt.Errorf("failed to write %s: %w", file, err)
+			}
+			fmt.Println(file)
+		default:
+			if hasComments {
+				fmt.Fprintf(os.Stderr, "⚠ Warning: %s: comments are not preserved\n", file)
+			}
+			os.Stdout.Write(formatted)
+		}
+	}
+
+	if unformatted > 0 {
+		return fmt.Errorf("%d of %d files are not formatted; run custodian fmt --write", unformatted, len(files))
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(newFmtCmd())
+}
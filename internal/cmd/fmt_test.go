@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunFmt(t *testing.T) {
+	const formatted = `project: {
+  id: "test-project-123"
+  name: "Test Project"
+}
+`
+	tests := []struct {
+		name    string
+		content string
+		// checkErr and writeErr are the expected errors of --check and --write
+		checkErr string
+		writeErr string
+		// written is the file content after --write
+		written string
+	}{
+		{"formatted", formatted, "", "", formatted},
+		{"formatted with comments", "# Production project\n" + formatted, "", "", "# Production project\n" + formatted},
+		{"unformatted", `project { name: "Test Project" id: "test-project-123" }`, "1 of 1 files are not formatted", "", formatted},
+		{"unformatted with comments", "# Production project\n" + `project { id: "test-project-123" name: "Test Project" }`, "1 of 1 files are not formatted", "has comments, which formatting would remove", ""},
+	}
+
+	for _, test := range tests {
+		file := filepath.Join(t.TempDir(), "config.textproto")
+		if err := os.WriteFile(file, []byte(test.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		err := runFmt(&fmtOptions{check: true}, []string{file})
+		if (test.checkErr == "") != (err == nil) || (err != nil && !strings.Contains(err.Error(), test.checkErr)) {
+			t.Errorf("%s: expected --check error %q, got: %v", test.name, test.checkErr, err)
+		}
+
+		err = runFmt(&fmtOptions{write: true}, []string{file})
+		if (test.writeErr == "") != (err == nil) || (err != nil && !strings.Contains(err.Error(), test.writeErr)) {
+			t.Errorf("%s: expected --write error %q, got: %v", test.name, test.writeErr, err)
+		}
+		if test.written == "" {
+			test.written = test.content
+		}
+		if content, err := os.ReadFile(file); err != nil || string(content) != test.written {
+			t.Errorf("%s: expected the file to contain %q after --write, got %q (%v)", test.name, test.written, content, err)
+		}
+	}
+}
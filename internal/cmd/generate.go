@@ -1,44 +1,103 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"os"
+	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"custoodian/internal/generator"
 	"custoodian/internal/validator"
 	"custoodian/pkg/config"
 
 	"github.com/spf13/cobra"
-	"google.golang.org/protobuf/encoding/prototext"
 )
 
+// terraformGitignore is written to the output directory by --write-gitignore
+// so that local Terraform state and provider caches are not committed
+const terraformGitignore = `# Terraform working directory
+.terraform/
+
+# Terraform state files
+*.tfstate
+*.tfstate.*
+
+# Crash logs
+crash.log
+crash.*.log
+
+# Plan files
+*.tfplan
+
+# Uncomment to ignore the dependency lock file (committing it is recommended)
+# .terraform.lock.hcl
+`
+
 type generateOptions struct {
-	configFile   string
-	outputDir    string
-	templateDir  string
-	templateRepo string
-	validate     bool
-	dryRun       bool
+	configFile      string
+	outputDir       string
+	templateDir     string
+	templateRepo    string
+	validate        bool
+	dryRun          bool
+	diff            bool
+	outputFormat    string
+	writeGitignore  bool
+	writeMakefile   bool
+	varFile         bool
+	stamp           bool
+	strictTemplates bool
+	noCache         bool
+	sortOutput      string
+	format          bool
+	tfValidate      bool
+	singleFile      bool
+	asModule        bool
+	strict          bool
+	importBlocks    bool
+	summary         bool
 }
 
 func newGenerateCmd() *cobra.Command {
 	opts := &generateOptions{
 		validate: true,
+		format:   true,
 	}
 
 	cmd := &cobra.Command{
 		Use:   "generate [config-file]",
 		Short: "Generate Terraform code from Protocol Buffer configuration",
-		Long: `Generate Terraform code from a Protocol Buffer text configuration file.
+		Long: `Generate Terraform code from a Protocol Buffer configuration file.
 
-The configuration file should be in Protocol Buffer text format (.textproto).
+The configuration file can be in Protocol Buffer text format (.textproto), or
+use the Protocol Buffer JSON mapping as JSON (.json) or YAML (.yaml, .yml).
 Templates can be loaded from a local directory or a Git repository.
 
 Examples:
   custodian generate config.textproto
   custodian generate --template-dir ./templates config.textproto
   custodian generate --template-repo github.com/org/templates config.textproto
-  custodian generate --output ./output --dry-run config.textproto`,
+  custodian generate --output ./output --dry-run config.textproto
+  custodian generate --summary config.textproto
+  custodian generate --output ./output --diff config.textproto
+  custodian generate --output-format opentofu config.textproto
+  custodian generate --output ./output --write-makefile config.textproto
+  custodian generate --output ./output --var-file config.textproto
+  custodian generate --output ./output --stamp config.textproto
+  custodian generate --template-dir ./templates --strict-templates config.textproto
+  custodian generate --template-dir ./templates --no-cache config.textproto
+  custodian generate --sort-output as-declared config.textproto
+  custodian generate --dry-run --tf-validate config.textproto
+  custodian generate --output ./output --single-file config.textproto
+  custodian generate --output ./module --as-module config.textproto
+  custodian generate --strict config.textproto
+  custodian generate --output ./output --import config.textproto`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.configFile = args[0]
@@ -51,11 +110,36 @@ Examples:
 	cmd.Flags().StringVar(&opts.templateRepo, "template-repo", "", "Git repository URL containing Terraform templates")
 	cmd.Flags().BoolVar(&opts.validate, "validate", true, "Validate configuration before generating")
 	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Show what would be generated without writing files")
+	cmd.Flags().BoolVar(&opts.diff, "diff", false, "Show how the generated files differ from those in the output directory without writing files; exits non-zero if they differ")
+	cmd.Flags().StringVar(&opts.outputFormat, "output-format", generator.OutputFormatTerraform, "Target tool for generated code (terraform, opentofu)")
+	cmd.Flags().BoolVar(&opts.writeGitignore, "write-gitignore", false, "Write a .gitignore for Terraform state into the output directory")
+	cmd.Flags().BoolVar(&opts.writeMakefile, "write-makefile", false, "Write a Makefile with init, plan, apply, fmt, and validate targets into the output directory")
+	cmd.Flags().BoolVar(&opts.varFile, "var-file", false, "Write a terraform.tfvars with the project ID, region, and zone of the configuration into the output directory")
+	cmd.Flags().BoolVar(&opts.stamp, "stamp", false, "Write metadata.tf with generated_at, custoodian_version, and config_hash locals and outputs")
+	cmd.Flags().BoolVar(&opts.strictTemplates, "strict-templates", false, "Fail when a template references missing data instead of rendering <no value>")
+	cmd.Flags().BoolVar(&opts.noCache, "no-cache", false, "Load and parse templates again instead of using the in-memory template cache")
+	cmd.Flags().BoolVar(&opts.format, "format", true, "Format generated files like terraform fmt")
+	cmd.Flags().BoolVar(&opts.tfValidate, "tf-validate", false, "Run terraform init and validate on the generated files before writing them (requires terraform, or tofu for opentofu)")
+	cmd.Flags().BoolVar(&opts.singleFile, "single-file", false, "Combine all generated .tf files into a single main.tf")
+	cmd.Flags().StringVar(&opts.sortOutput, "sort-output", string(config.OrderByName), "Order of resources within each file (by-name, by-type, as-declared)")
+	cmd.Flags().BoolVar(&opts.asModule, "as-module", false, "Generate a reusable module whose resource names, regions, and sizes are input variables, with their values in terraform.tfvars")
+	cmd.Flags().BoolVar(&opts.strict, "strict", false, "Reject unspecified regions, zones, and machine types that would otherwise be defaulted")
+	cmd.Flags().BoolVar(&opts.importBlocks, "import", false, "Write import blocks for resources with an import_id into imports.tf (requires Terraform 1.5 or later)")
+	cmd.Flags().BoolVar(&opts.summary, "summary", false, "Show the lines, bytes, and resources of each file that would be generated without writing files")
+	cmd.MarkFlagsMutuallyExclusive("dry-run", "diff")
+	cmd.MarkFlagsMutuallyExclusive("summary", "dry-run")
+	cmd.MarkFlagsMutuallyExclusive("summary", "diff")
+	cmd.MarkFlagsMutuallyExclusive("as-module", "var-file")
 
 	return cmd
 }
 
 func runGenerate(opts *generateOptions) error {
+	// Strict checks are part of validation, which is on by default
+	if opts.strict && !opts.validate {
+		return fmt.Errorf("--strict requires validation; remove --validate=false")
+	}
+
 	// Read and parse the configuration file
 	cfg, err := loadConfig(opts.configFile)
 	if err != nil {
@@ -64,9 +148,11 @@ func runGenerate(opts *generateOptions) error {
 
 	// Validate configuration if requested
 	if opts.validate {
-		if err := validator.ValidateConfig(cfg); err != nil {
-			return fmt.Errorf("configuration validation failed: %w", err)
+		result := validator.ValidateWithOptions(cfg, &validator.ValidateOptions{Strict: opts.strict})
+		if err := result.Err(); err != nil {
+			return fmt.Errorf("configuration validation failed: %w%s", err, explainHint(err))
 		}
+		printWarnings(validator.Warnings(cfg))
 		fmt.Println("✓ Configuration validation passed")
 	}
 
@@ -82,7 +168,22 @@ func runGenerate(opts *generateOptions) error {
 	}
 
 	// Create generator
-	gen, err := generator.New(templateSource)
+	genOpts := &generator.NewOptions{
+		OutputFormat:    opts.outputFormat,
+		StrictTemplates: opts.strictTemplates,
+		ResourceOrder:   config.ResourceOrder(opts.sortOutput),
+		DisableFormat:   !opts.format,
+		DisableCache:    opts.noCache,
+		AsModule:        opts.asModule,
+	}
+	if opts.stamp {
+		hash, err := configHash(cfg)
+		if err != nil {
+			return err
+		}
+		genOpts.Stamp = &generator.Stamp{GeneratedAt: time.Now(), Version: version, ConfigHash: hash}
+	}
+	gen, err := generator.NewWithOptions(templateSource, genOpts)
 	if err != nil {
 		return fmt.Errorf("failed to create generator: %w", err)
 	}
@@ -93,7 +194,71 @@ func runGenerate(opts *generateOptions) error {
 		return fmt.Errorf("failed to generate Terraform code: %w", err)
 	}
 
+	// Render the Makefile alongside the Terraform files if requested
+	if opts.writeMakefile {
+		makefile, err := gen.GenerateMakefile()
+		if err != nil {
+			return fmt.Errorf("failed to generate Makefile: %w", err)
+		}
+		// Each project of a multi-project configuration is its own Terraform root
+		if len(cfg.Projects) > 0 {
+			for _, project := range cfg.Projects {
+				files[filepath.Join(project.Id, "Makefile")] = makefile
+			}
+		} else {
+			files["Makefile"] = makefile
+		}
+	}
+
+	// Write the variable values matching the configuration if requested
+	if opts.varFile {
+		tfvars, err := gen.GenerateTfvars(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to generate %s: %w", generator.TfvarsFileName, err)
+		}
+		for filename, content := range tfvars {
+			files[filename] = content
+		}
+	}
+
+	// Adopt existing resources with import blocks if requested
+	if opts.importBlocks {
+		imports, err := gen.GenerateImports(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to generate %s: %w", generator.ImportsFileName, err)
+		}
+		if len(imports) == 0 {
+			printWarnings([]string{"--import was given but no resource sets import_id"})
+		}
+		for filename, content := range imports {
+			files[filename] = content
+		}
+	}
+
+	// Combine the .tf files of each Terraform root into main.tf if requested
+	if opts.singleFile {
+		files = generator.CombineFiles(files)
+	}
+
+	// Check the generated code with Terraform itself before writing anything
+	if opts.tfValidate {
+		roots := []string{"."}
+		if len(cfg.Projects) > 0 {
+			roots = roots[:0]
+			for _, project := range cfg.Projects {
+				roots = append(roots, project.Id)
+			}
+		}
+		if err := terraformValidate(files, roots, opts.outputFormat); err != nil {
+			return err
+		}
+	}
+
 	// Output results
+	if opts.summary {
+		printFileSummary(os.Stdout, files)
+		return nil
+	}
 	if opts.dryRun {
 		fmt.Println("Files that would be generated:")
 		for filename, content := range files {
@@ -104,6 +269,20 @@ func runGenerate(opts *generateOptions) error {
 		return nil
 	}
 
+	// Compare against the output directory instead of writing to it
+	if opts.diff {
+		d, err := diffOutput(files, opts.outputDir)
+		if err != nil {
+			return err
+		}
+		if d.empty() {
+			fmt.Printf("✓ No changes to the %d Terraform files in %s\n", len(files), opts.outputDir)
+			return nil
+		}
+		printOutputDiff(d, opts.outputDir)
+		return fmt.Errorf("%d added, %d changed, %d removed files in %s", len(d.added), len(d.changed), len(d.removed), opts.outputDir)
+	}
+
 	// Write files to output directory
 	for filename, content := range files {
 		outputPath := filepath.Join(opts.outputDir, filename)
@@ -113,10 +292,68 @@ func runGenerate(opts *generateOptions) error {
 		fmt.Printf("Generated: %s\n", outputPath)
 	}
 
+	// Write .gitignore if requested, never clobbering an existing one
+	if opts.writeGitignore {
+		gitignorePath := filepath.Join(opts.outputDir, ".gitignore")
+		if _, err := os.Stat(gitignorePath); err == nil {
+			fmt.Printf("Skipped: %s already exists\n", gitignorePath)
+		} else {
+			if err := writeFile(gitignorePath, terraformGitignore); err != nil {
+				return fmt.Errorf("failed to write %s: %w", gitignorePath, err)
+			}
+			fmt.Printf("Generated: %s\n", gitignorePath)
+		}
+	}
+
 	fmt.Printf("✓ Generated %d Terraform files in %s\n", len(files), opts.outputDir)
 	return nil
 }
 
+// terraformValidate writes files to a temporary directory and runs
+// "init -backend=false" and "validate" in each root module directory with the
+// binary of the output format (terraform or tofu)
+func terraformValidate(files map[string]string, roots []string, outputFormat string) error {
+	binary := "terraform"
+	if outputFormat == generator.OutputFormatOpenTofu {
+		binary = "tofu"
+	}
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return fmt.Errorf("--tf-validate requires %s, which was not found in PATH", binary)
+	}
+
+	dir, err := os.MkdirTemp("", "custoodian-validate-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for filename, content := range files {
+		if err := writeFile(filepath.Join(dir, filename), content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", filename, err)
+		}
+	}
+
+	sort.Strings(roots)
+	for _, root := range roots {
+		for _, args := range [][]string{
+			{"init", "-backend=false", "-input=false", "-no-color"},
+			{"validate", "-no-color"},
+		} {
+			var out bytes.Buffer
+			command := exec.Command(path, args...)
+			command.Dir = filepath.Join(dir, root)
+			command.Stdout = &out
+			command.Stderr = &out
+			if err := command.Run(); err != nil {
+				return fmt.Errorf("%s %s failed in %s: %w\n%s", binary, args[0], root, err, strings.TrimSpace(out.String()))
+			}
+		}
+		fmt.Printf("✓ %s validate passed for %s\n", binary, root)
+	}
+	return nil
+}
+
 func loadConfig(filename string) (*config.Config, error) {
 	content, err := readFile(filename)
 	if err != nil {
@@ -124,13 +361,46 @@ func loadConfig(filename string) (*config.Config, error) {
 	}
 
 	cfg := &config.Config{}
-	if err := prototext.Unmarshal(content, cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse Protocol Buffer text format: %w", err)
+	if err := config.Unmarshal(content, config.InputFormatForFile(filename), cfg); err != nil {
+		return nil, err
+	}
+
+	if err := config.ResolveReferences(cfg); err != nil {
+		return nil, fmt.Errorf("failed to resolve references: %w", err)
 	}
 
 	return cfg, nil
 }
 
+// printFileSummary writes a table of the files that would be generated, sorted
+// by name, with their line count, size in bytes, and number of resource
+// blocks, followed by the totals
+func printFileSummary(w io.Writer, files map[string]string) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "FILE\tLINES\tBYTES\tRESOURCES")
+	totalLines, totalBytes, totalResources := 0, 0, 0
+	for _, name := range names {
+		content := files[name]
+		lines := strings.Count(content, "\n")
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			lines++
+		}
+		resources := generator.CountResources(content)
+		fmt.Fprintf(table, "%s\t%d\t%d\t%d\n", name, lines, len(content), resources)
+		totalLines += lines
+		totalBytes += len(content)
+		totalResources += resources
+	}
+	fmt.Fprintf(table, "%d files\t%d\t%d\t%d\n", len(files), totalLines, totalBytes, totalResources)
+	table.Flush()
+}
+
 func init() {
 	rootCmd.AddCommand(newGenerateCmd())
 }
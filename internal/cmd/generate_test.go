@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"custoodian/internal/generator"
+	"custoodian/internal/validator"
+)
+
+func TestLoadConfigApexRecord(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "dns.textproto")
+	content := `
+project {
+  id: "test-project-123"
+  name: "Test Project"
+}
+dns {
+  managed_zones {
+    name: "public"
+    dns_name: "example.com."
+    record_sets {
+      name: "@"
+      type: "A"
+      rrdatas: "203.0.113.10"
+    }
+  }
+}
+`
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig(file)
+	if err != nil {
+		t.Fatalf("Expected the apex record to load, got: %v", err)
+	}
+	if err := validator.ValidateConfig(cfg); err != nil {
+		t.Fatalf("Expected the configuration to be valid, got: %v", err)
+	}
+
+	gen, err := generator.New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+	if !strings.Contains(files["dns.tf"], `name         = "example.com."`) {
+		t.Errorf("Expected a record set at the zone apex, got:\n%s", files["dns.tf"])
+	}
+}
+
+func TestGenerateStrictValidation(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.textproto")
+	content := `
+project {
+  id: "test-project-123"
+  name: "Test Project"
+}
+networking {
+  vpcs {
+    name: "main-vpc"
+    subnets {
+      name: "main-subnet"
+      cidr: "10.0.0.0/24"
+    }
+  }
+}
+`
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"strict", []string{"--strict"}, "[CFG012]"},
+		{"strict with validation", []string{"--strict", "--validate=true"}, "[CFG012]"},
+		{"strict without validation", []string{"--strict", "--validate=false"}, "--strict requires validation"},
+	}
+	for _, test := range tests {
+		cmd := newGenerateCmd()
+		cmd.SetArgs(append(test.args, "--dry-run", file))
+		cmd.SilenceUsage, cmd.SilenceErrors = true, true
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: expected error containing %q, got: %v", test.name, test.want, err)
+		}
+	}
+}
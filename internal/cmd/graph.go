@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+
+	"custoodian/internal/generator"
+
+	"github.com/spf13/cobra"
+)
+
+type graphOptions struct {
+	configFile string
+	output     string
+}
+
+func newGraphCmd() *cobra.Command {
+	opts := &graphOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "graph [config-file]",
+		Short: "Print the resource dependency graph of a configuration",
+		Long: `Print the dependency graph of the resources generated for a configuration in
+the Graphviz DOT language.
+
+Nodes are Terraform resources and edges point from a resource to the resources
+it depends on, e.g. subnet → VPC, instance group → instance template, and load
+balancer backend → instance group, as well as resources to the project API they
+wait for. Render the graph with Graphviz, e.g. dot -Tsvg.
+
+Examples:
+  custodian graph config.textproto
+  custodian graph config.textproto --output graph.dot
+  custodian graph config.textproto | dot -Tsvg > graph.svg`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.configFile = args[0]
+			return runGraph(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Output file (default: stdout)")
+
+	return cmd
+}
+
+func runGraph(opts *graphOptions) error {
+	cfg, err := loadConfig(opts.configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	graph, err := generator.DependencyGraph(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to build dependency graph: %w", err)
+	}
+
+	content := graph.DOT()
+
+	if opts.output == "" {
+		fmt.Print(content)
+		return nil
+	}
+
+	if err := writeFile(opts.output, content); err != nil {
+		return fmt.Errorf("failed to write graph: %w", err)
+	}
+
+	fmt.Printf("✓ Dependency graph written to %s\n", opts.output)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(newGraphCmd())
+}
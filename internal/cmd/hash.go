@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"custoodian/pkg/config"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
+)
+
+type hashOptions struct {
+	configFile string
+}
+
+func newHashCmd() *cobra.Command {
+	opts := &hashOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "hash [config-file]",
+		Short: "Print a stable fingerprint of a configuration",
+		Long: `Print a stable SHA-256 fingerprint of a Protocol Buffer configuration file.
+
+The configuration is parsed and re-marshaled in deterministic binary form before
+hashing, so formatting, comments, and field ordering in the source file do not
+affect the result. Pipelines can use the fingerprint to cache generation results
+and skip unchanged configurations.
+
+Examples:
+  custodian hash config.textproto`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.configFile = args[0]
+			return runHash(opts)
+		},
+	}
+
+	return cmd
+}
+
+func runHash(opts *hashOptions) error {
+	cfg, err := loadConfig(opts.configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	hash, err := configHash(cfg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(hash)
+	return nil
+}
+
+// configHash returns the hex-encoded SHA-256 of the deterministic binary encoding of cfg
+func configHash(cfg *config.Config) (string, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to canonicalize configuration: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func init() {
+	rootCmd.AddCommand(newHashCmd())
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigHash(t *testing.T) {
+	hashOf := func(content string) string {
+		file := filepath.Join(t.TempDir(), "config.textproto")
+		if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := loadConfig(file)
+		if err != nil {
+			t.Fatalf("Failed to load configuration: %v", err)
+		}
+		hash, err := configHash(cfg)
+		if err != nil {
+			t.Fatalf("Failed to hash configuration: %v", err)
+		}
+		return hash
+	}
+
+	original := hashOf(`
+project {
+  id: "test-project-123"
+  name: "Test Project"
+}
+networking {
+  vpcs { name: "main-vpc" }
+}
+`)
+	// Same configuration with comments, other whitespace, and fields in another order
+	reordered := hashOf(`# Networking first
+networking { vpcs {
+    name: "main-vpc"
+} }
+project { name: "Test Project"   id: "test-project-123" }
+`)
+	if original != reordered {
+		t.Errorf("Expected formatting and field order not to change the hash, got %s and %s", original, reordered)
+	}
+
+	changed := hashOf(`
+project {
+  id: "test-project-123"
+  name: "Test Project"
+}
+networking {
+  vpcs { name: "other-vpc" }
+}
+`)
+	if original == changed {
+		t.Errorf("Expected a changed configuration to change the hash, got %s for both", original)
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"custoodian/internal/generator"
+
+	"github.com/spf13/cobra"
+)
+
+type importsOptions struct {
+	configFile string
+	output     string
+}
+
+func newImportsCmd() *cobra.Command {
+	opts := &importsOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "imports [config-file]",
+		Short: "Generate a terraform import script for existing resources",
+		Long: `Generate a shell script of 'terraform import' commands for resources with an import_id.
+
+This lets teams on Terraform versions before 1.5, which lack import blocks,
+adopt existing infrastructure; with 1.5 or later, 'generate --import' writes
+import blocks instead. Run the script from the directory containing
+the generated Terraform code after 'terraform init'. Set TF=tofu to use OpenTofu.
+
+Examples:
+  custodian imports config.textproto
+  custodian imports config.textproto --output import.sh`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.configFile = args[0]
+			return runImports(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Output script file (default: stdout)")
+
+	return cmd
+}
+
+func runImports(opts *importsOptions) error {
+	cfg, err := loadConfig(opts.configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	targets, err := generator.ImportTargets(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to collect import targets: %w", err)
+	}
+
+	var script strings.Builder
+	script.WriteString("#!/bin/sh\n")
+	fmt.Fprintf(&script, "# Terraform import commands generated by custoodian from %s\n", filepath.Base(opts.configFile))
+	script.WriteString("# Run from the directory containing the generated Terraform code after 'terraform init'.\n")
+	script.WriteString("set -e\n\n")
+	for _, target := range targets {
+		command := fmt.Sprintf("${TF:-terraform} import %s %s", shellQuote(target.Address), shellQuote(target.ID))
+		if target.Dir != "" {
+			// Each project of a multi-project configuration is its own Terraform root
+			command = fmt.Sprintf("(cd %s && %s)", shellQuote(target.Dir), command)
+		}
+		script.WriteString(command + "\n")
+	}
+
+	if opts.output == "" {
+		fmt.Print(script.String())
+		return nil
+	}
+
+	if err := writeFile(opts.output, script.String()); err != nil {
+		return fmt.Errorf("failed to write import script: %w", err)
+	}
+	// The script is meant to be executed directly
+	if err := os.Chmod(filepath.Clean(opts.output), 0700); err != nil {
+		return fmt.Errorf("failed to make import script executable: %w", err)
+	}
+
+	fmt.Printf("✓ Wrote %d import commands to %s\n", len(targets), opts.output)
+	return nil
+}
+
+// shellQuote quotes s for safe use as a single POSIX shell word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func init() {
+	rootCmd.AddCommand(newImportsCmd())
+}
@@ -0,0 +1,384 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// initConfigFile is the name of the configuration written by init
+const initConfigFile = "config.textproto"
+
+type initOptions struct {
+	resources []string
+	force     bool
+}
+
+// initSection is a top-level section of the starter configuration
+type initSection struct {
+	// name is the configuration field of the section, as --resources takes it
+	name string
+	// apis are the GcpApi values the project enables for the section
+	apis []string
+	// content returns the section, given the sections being written
+	content func(selected map[string]bool) string
+}
+
+// initSections are the sections init can write, in configuration order
+var initSections = []initSection{
+	{
+		name: "networking",
+		apis: []string{"GCP_API_COMPUTE"},
+		content: func(map[string]bool) string {
+			return `# Networking: VPCs with their subnets, and firewall rules
+networking {
+  vpcs {
+    name: "main-vpc"
+    description: "Main VPC network"
+    auto_create_subnetworks: false
+    routing_mode: "REGIONAL"
+
+    subnets {
+      name: "main-subnet"
+      cidr: "10.0.1.0/24"
+      region: REGION_US_CENTRAL1
+      private_ip_google_access: true
+    }
+  }
+
+  # Allow SSH from Identity-Aware Proxy to instances tagged ssh-allowed
+  firewall_rules {
+    name: "allow-ssh-iap"
+    direction: "INGRESS"
+    network: "main-vpc"
+    source_ranges: ["35.235.240.0/20"]
+    target_tags: ["ssh-allowed"]
+
+    allow {
+      protocol: "tcp"
+      ports: ["22"]
+    }
+  }
+}
+`
+		},
+	},
+	{
+		name: "compute",
+		apis: []string{"GCP_API_COMPUTE"},
+		content: func(selected map[string]bool) string {
+			// Attach to the starter subnet when networking is written too
+			iface := `network: "default"`
+			if selected["networking"] {
+				iface = `subnetwork: "main-subnet"`
+			}
+			return `# Compute: VM instances, instance templates, and managed instance groups
+compute {
+  instances {
+    name: "app-vm"
+    zone: ZONE_US_CENTRAL1_A
+    machine_type: MACHINE_TYPE_E2_MEDIUM
+    image: "projects/debian-cloud/global/images/family/debian-12"
+
+    network_interfaces {
+      ` + iface + `
+    }
+
+    tags: ["ssh-allowed"]
+  }
+}
+`
+		},
+	},
+	{
+		name: "iam",
+		apis: []string{"GCP_API_IAM"},
+		content: func(map[string]bool) string {
+			return `# IAM: service accounts, project role bindings, and custom roles
+iam {
+  service_accounts {
+    account_id: "app-sa"
+    display_name: "Application Service Account"
+    roles: ["roles/logging.logWriter"]
+  }
+}
+`
+		},
+	},
+	{
+		name: "storage",
+		apis: []string{"GCP_API_STORAGE"},
+		content: func(map[string]bool) string {
+			return `# Storage: Cloud Storage buckets (names are globally unique)
+storage {
+  buckets {
+    name: "my-project-123-assets"
+    location: "US"
+    storage_class: "STANDARD"
+    uniform_bucket_level_access: true
+  }
+}
+`
+		},
+	},
+	{
+		name: "cloud_run",
+		apis: []string{"GCP_API_CLOUD_RUN"},
+		content: func(map[string]bool) string {
+			return `# Cloud Run: containerized services
+cloud_run {
+  services {
+    name: "app-service"
+    location: REGION_US_CENTRAL1
+    image: "us-docker.pkg.dev/cloudrun/container/hello"
+
+    config {
+      port: 8080
+      max_instances: 10
+    }
+
+    traffic {
+      percent: 100
+    }
+  }
+}
+`
+		},
+	},
+	{
+		name: "databases",
+		apis: []string{"GCP_API_SQL_ADMIN"},
+		content: func(map[string]bool) string {
+			return `# Databases: Cloud SQL and Spanner instances
+databases {
+  cloud_sql_instances {
+    name: "app-db"
+    database_version: "POSTGRES_15"
+    region: REGION_US_CENTRAL1
+    tier: "db-f1-micro"
+    deletion_protection: true
+  }
+}
+`
+		},
+	},
+	{
+		name: "pubsub",
+		apis: []string{"GCP_API_PUBSUB"},
+		content: func(map[string]bool) string {
+			return `# Pub/Sub: topics
+pubsub {
+  topics {
+    name: "app-events"
+  }
+}
+`
+		},
+	},
+	{
+		name: "dns",
+		apis: []string{"GCP_API_CLOUD_DNS"},
+		content: func(map[string]bool) string {
+			return `# Cloud DNS: managed zones and their record sets
+dns {
+  managed_zones {
+    name: "main-zone"
+    dns_name: "example.com."
+
+    record_sets {
+      name: "www"
+      type: "A"
+      rrdatas: ["203.0.113.10"]
+    }
+  }
+}
+`
+		},
+	},
+	{
+		name: "bigquery",
+		apis: []string{"GCP_API_BIGQUERY"},
+		content: func(map[string]bool) string {
+			return `# BigQuery: datasets and tables
+bigquery {
+  datasets {
+    dataset_id: "analytics"
+    location: "US"
+  }
+
+  tables {
+    table_id: "events"
+    dataset: "analytics"
+    fields { name: "id" type: "STRING" mode: "REQUIRED" }
+    fields { name: "created_at" type: "TIMESTAMP" }
+    time_partitioning { type: "DAY" field: "created_at" }
+  }
+}
+`
+		},
+	},
+}
+
+func newInitCmd() *cobra.Command {
+	opts := &initOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "init [directory]",
+		Short: "Write a starter configuration",
+		Long: `Write a commented config.textproto with a project block and placeholder
+sections to start a new configuration from, plus a .gitignore for Terraform
+state. Files are written to the given directory, or the current one.
+
+By default the networking and compute sections are included; --resources
+chooses the sections instead. An existing config.textproto is only replaced
+with --force, and an existing .gitignore is never replaced.
+
+Examples:
+  custodian init
+  custodian init ./infrastructure
+  custodian init --resources networking,compute,storage,iam`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			return runInit(opts, dir)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&opts.resources, "resources", []string{"networking", "compute"}, "Comma-separated sections to include ("+strings.Join(initSectionNames(), ", ")+")")
+	cmd.Flags().BoolVarP(&opts.force, "force", "f", false, "Replace an existing config.textproto")
+
+	return cmd
+}
+
+func runInit(opts *initOptions, dir string) error {
+	content, err := initConfig(opts.resources)
+	if err != nil {
+		return err
+	}
+
+	configPath := filepath.Join(dir, initConfigFile)
+	if _, err := os.Stat(configPath); err == nil && !opts.force {
+		return fmt.Errorf("%s already exists; use --force to replace it", configPath)
+	}
+	if err := writeFile(configPath, content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", configPath, err)
+	}
+	fmt.Printf("Created: %s\n", configPath)
+
+	// Never clobber an existing .gitignore, as with generate --write-gitignore
+	gitignorePath := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(gitignorePath); err == nil {
+		fmt.Printf("Skipped: %s already exists\n", gitignorePath)
+	} else {
+		if err := writeFile(gitignorePath, terraformGitignore); err != nil {
+			return fmt.Errorf("failed to write %s: %w", gitignorePath, err)
+		}
+		fmt.Printf("Created: %s\n", gitignorePath)
+	}
+
+	fmt.Printf("\nNext, replace the placeholder values in %s, then run:\n", configPath)
+	fmt.Printf("  custodian validate %s\n", configPath)
+	fmt.Printf("  custodian generate %s --output ./terraform\n", configPath)
+	return nil
+}
+
+// initConfig returns the starter configuration with the named sections
+func initConfig(resources []string) (string, error) {
+	selected := make(map[string]bool)
+	for _, resource := range resources {
+		resource = strings.TrimSpace(resource)
+		if resource == "" {
+			continue
+		}
+		if !isInitSection(resource) {
+			return "", fmt.Errorf("unknown resource section %q (must be one of %s)", resource, strings.Join(initSectionNames(), ", "))
+		}
+		selected[resource] = true
+	}
+
+	var apis []string
+	seen := make(map[string]bool)
+	for _, section := range initSections {
+		if !selected[section.name] {
+			continue
+		}
+		for _, api := range section.apis {
+			if !seen[api] {
+				seen[api] = true
+				apis = append(apis, api)
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(`# Custoodian configuration
+# Generated by custodian init. Replace the placeholder values, then validate
+# and generate Terraform from this file. "custodian schema --format markdown"
+# describes every message and field.
+
+project {
+  # Globally unique project ID: 6-30 lowercase letters, digits, or hyphens
+  id: "my-project-123"
+  name: "My Project"
+
+  # Billing account to link, in the form XXXXXX-XXXXXX-XXXXXX
+  # billing_account: "000000-000000-000000"
+
+  # Organization or folder to create the project in (set at most one)
+  # organization_id: "123456789012"
+  # folder_id: "123456789012"
+`)
+	if len(apis) > 0 {
+		b.WriteString("\n  # APIs to enable\n  apis: [\n")
+		for i, api := range apis {
+			b.WriteString("    " + api)
+			if i < len(apis)-1 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("  ]\n")
+	}
+	b.WriteString(`
+  labels {
+    key: "environment"
+    value: "dev"
+  }
+}
+`)
+
+	for _, section := range initSections {
+		if selected[section.name] {
+			b.WriteString("\n" + section.content(selected))
+		}
+	}
+	return b.String(), nil
+}
+
+// initSectionNames returns the names of the sections init can write
+func initSectionNames() []string {
+	names := make([]string, len(initSections))
+	for i, section := range initSections {
+		names[i] = section.name
+	}
+	return names
+}
+
+func isInitSection(name string) bool {
+	for _, section := range initSections {
+		if section.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(newInitCmd())
+}
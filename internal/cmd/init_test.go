@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"custoodian/internal/validator"
+)
+
+func TestInitConfig(t *testing.T) {
+	// Each section on its own, and all of them together
+	selections := [][]string{initSectionNames()}
+	for _, name := range initSectionNames() {
+		selections = append(selections, []string{name})
+	}
+
+	for _, resources := range selections {
+		content, err := initConfig(resources)
+		if err != nil {
+			t.Fatalf("%v: expected no error, got: %v", resources, err)
+		}
+
+		file := filepath.Join(t.TempDir(), initConfigFile)
+		if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := loadConfig(file)
+		if err != nil {
+			t.Errorf("%v: expected the configuration to parse, got: %v", resources, err)
+			continue
+		}
+		if err := validator.ValidateConfig(cfg); err != nil {
+			t.Errorf("%v: expected the configuration to be valid, got: %v", resources, err)
+		}
+	}
+
+	if _, err := initConfig([]string{"networking", "network"}); err == nil || !strings.Contains(err.Error(), `unknown resource section "network"`) {
+		t.Errorf("Expected an unknown section error, got: %v", err)
+	}
+}
+
+func TestRunInit(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, initConfigFile)
+	gitignorePath := filepath.Join(dir, ".gitignore")
+	for path, content := range map[string]string{configPath: "# existing\n", gitignorePath: "*.tfstate\n"} {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// An existing configuration is only replaced with --force
+	opts := &initOptions{resources: []string{"networking"}}
+	if err := runInit(opts, dir); err == nil || !strings.Contains(err.Error(), "already exists; use --force to replace it") {
+		t.Errorf("Expected an existing configuration to be refused, got: %v", err)
+	}
+	if content, _ := os.ReadFile(configPath); string(content) != "# existing\n" {
+		t.Errorf("Expected the existing configuration to be kept, got:\n%s", content)
+	}
+
+	opts.force = true
+	if err := runInit(opts, dir); err != nil {
+		t.Fatalf("Expected --force to replace the configuration, got: %v", err)
+	}
+	if content, _ := os.ReadFile(configPath); !strings.Contains(string(content), "networking {") {
+		t.Errorf("Expected the starter configuration, got:\n%s", content)
+	}
+
+	// An existing .gitignore is never replaced
+	if content, _ := os.ReadFile(gitignorePath); string(content) != "*.tfstate\n" {
+		t.Errorf("Expected the existing .gitignore to be kept, got:\n%s", content)
+	}
+}
@@ -15,7 +15,7 @@ var (
 var rootCmd = &cobra.Command{
 	Use:   "custoodian",
 	Short: "Generate Terraform code from Protocol Buffer configurations for GCP",
-	Long: `Custoodian is a tool that generates Terraform code from Protocol Buffer text configurations
+	Long: `Custoodian is a tool that generates Terraform code from Protocol Buffer configurations
 for Google Cloud Platform resources. It provides type-safe infrastructure configuration
 with comprehensive validation and supports custom template systems.
 
@@ -3,6 +3,9 @@ package cmd
 import (
 	"fmt"
 
+	"custoodian/internal/generator"
+	"custoodian/pkg/config"
+
 	"github.com/spf13/cobra"
 )
 
@@ -26,7 +29,8 @@ or IDE integration purposes.
 
 Examples:
   custodian schema                    # Display proto schema
-  custodian schema --format json     # Display JSON schema
+  custodian schema --format json     # Display JSON Schema of the configuration
+  custodian schema --format markdown # Display reference documentation
   custodian schema --output schema/  # Export to directory`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runSchema(opts)
@@ -78,13 +82,43 @@ func outputProtoSchema(output string) error {
 }
 
 func outputJSONSchema(output string) error {
-	// TODO: Implement JSON schema generation
-	return fmt.Errorf("JSON schema format not yet implemented")
+	content, err := config.JSONSchema()
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		fmt.Print(string(content))
+		return nil
+	}
+
+	filename := fmt.Sprintf("%s/%s", output, "config.schema.json")
+	if err := writeFile(filename, string(content)); err != nil {
+		return err
+	}
+	fmt.Printf("Exported: %s\n", filename)
+
+	return nil
 }
 
 func outputMarkdownSchema(output string) error {
-	// TODO: Implement Markdown documentation generation
-	return fmt.Errorf("Markdown schema format not yet implemented")
+	content, err := generator.SchemaMarkdown()
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		fmt.Print(content)
+		return nil
+	}
+
+	filename := fmt.Sprintf("%s/%s", output, "schema.md")
+	if err := writeFile(filename, content); err != nil {
+		return err
+	}
+	fmt.Printf("Exported: %s\n", filename)
+
+	return nil
 }
 
 func getConfigProtoContent() string {
@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+
+	"custoodian/internal/validator"
 )
 
 // readFile reads the entire content of a file
@@ -13,6 +16,22 @@ func readFile(filename string) ([]byte, error) {
 	return os.ReadFile(cleanPath)
 }
 
+// printWarnings prints validation warnings to stderr
+func printWarnings(warnings []string) {
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "⚠ Warning: %s\n", warning)
+	}
+}
+
+// explainHint returns a hint pointing at explain-error for a validation error
+// with a code, or "" if err has none
+func explainHint(err error) string {
+	if code := validator.CodeOf(err); code != "" {
+		return fmt.Sprintf("\nRun 'custodian explain-error %s' for details", code)
+	}
+	return ""
+}
+
 // writeFile writes content to a file, creating directories as needed
 func writeFile(filename, content string) error {
 	// Clean the file path to prevent directory traversal
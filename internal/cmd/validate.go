@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"custoodian/internal/validator"
@@ -10,15 +11,20 @@ import (
 
 type validateOptions struct {
 	configFile string
+	format     string
+	strict     bool
 }
 
 func newValidateCmd() *cobra.Command {
-	opts := &validateOptions{}
+	opts := &validateOptions{
+		format: "text",
+	}
 
 	cmd := &cobra.Command{
 		Use:   "validate [config-file]",
 		Short: "Validate a Protocol Buffer configuration file",
-		Long: `Validate a Protocol Buffer text configuration file for syntax and constraints.
+		Long: `Validate a Protocol Buffer configuration file (textproto, JSON, or YAML) for
+syntax and constraints.
 
 This command checks:
 - Protocol Buffer syntax
@@ -27,9 +33,20 @@ This command checks:
 - Cross-field dependencies
 - Naming conventions
 
+With --format json, the result is printed as a JSON object with a "valid"
+boolean and an "errors" array of {path, code, message, severity} entries;
+warnings are included with severity "warning" when the configuration is valid.
+The exit status is non-zero when the configuration is invalid.
+
+With --strict, regions, zones, and machine types that resources need but
+leave unspecified are errors instead of being defaulted (e.g. a subnet
+without a region to us-central1).
+
 Examples:
   custodian validate config.textproto
-  custodian validate examples/simple.textproto`,
+  custodian validate examples/simple.textproto
+  custodian validate --format json config.textproto
+  custodian validate --strict config.textproto`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.configFile = args[0]
@@ -37,10 +54,17 @@ Examples:
 		},
 	}
 
+	cmd.Flags().StringVar(&opts.format, "format", "text", "Output format (text, json)")
+	cmd.Flags().BoolVar(&opts.strict, "strict", false, "Reject unspecified regions, zones, and machine types that would otherwise be defaulted")
+
 	return cmd
 }
 
 func runValidate(opts *validateOptions) error {
+	if opts.format != "text" && opts.format != "json" {
+		return fmt.Errorf("unsupported format: %s", opts.format)
+	}
+
 	// Load configuration
 	cfg, err := loadConfig(opts.configFile)
 	if err != nil {
@@ -48,9 +72,28 @@ func runValidate(opts *validateOptions) error {
 	}
 
 	// Validate configuration
-	if err := validator.ValidateConfig(cfg); err != nil {
-		return fmt.Errorf("validation failed: %w", err)
+	result := validator.ValidateWithOptions(cfg, &validator.ValidateOptions{Strict: opts.strict})
+	if opts.format == "json" {
+		output, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode validation result: %w", err)
+		}
+		fmt.Println(string(output))
+		if !result.Valid {
+			return fmt.Errorf("validation failed")
+		}
+		return nil
+	}
+
+	if err := result.Err(); err != nil {
+		return fmt.Errorf("validation failed: %w%s", err, explainHint(err))
+	}
+
+	var warnings []string
+	for _, warning := range result.Errors {
+		warnings = append(warnings, warning.Message)
 	}
+	printWarnings(warnings)
 
 	fmt.Println("✓ Configuration is valid")
 	return nil
@@ -3,8 +3,12 @@
 package generator
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"log"
+	"path"
+	"sort"
 	"strings"
 	"sync"
 	"text/template"
@@ -12,6 +16,9 @@ import (
 
 	"custoodian/internal/templates"
 	"custoodian/pkg/config"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"golang.org/x/sync/errgroup"
 )
 
 // templateCacheEntry represents a cached template with metadata
@@ -36,7 +43,8 @@ var (
 // The generator supports multiple template sources:
 //   - Built-in templates: Pre-defined templates for common GCP resources
 //   - Local directory: Custom templates from a local filesystem directory
-//   - Git repository: Custom templates from a remote Git repository (planned)
+//   - Git repository: Custom templates from a remote Git repository, optionally
+//     pinned to a branch, tag, or commit with @ref
 //
 // Performance Optimizations:
 //   - Template caching: Parsed templates are cached to avoid re-parsing
@@ -56,14 +64,92 @@ type Generator struct {
 
 	// logger provides structured logging for debugging and monitoring
 	logger *log.Logger
+
+	// outputFormat selects the Terraform-compatible tool the output targets
+	outputFormat string
+
+	// stamp is written to metadata.tf when set
+	stamp *Stamp
+
+	// strictTemplates fails generation when a template references missing data
+	strictTemplates bool
+
+	// resourceOrder orders the resources of each collection in the output
+	resourceOrder config.ResourceOrder
+
+	// formatOutput formats the generated .tf files like terraform fmt
+	formatOutput bool
+
+	// apiPropagationDelay is the project's api_propagation_delay for the
+	// configuration being generated, empty when no time_sleep is emitted
+	apiPropagationDelay string
+
+	// commonLabels are the common_labels of the configuration being generated
+	commonLabels map[string]string
+
+	// extraFuncs are additional template functions registered alongside
+	// the built-in ones
+	extraFuncs template.FuncMap
+
+	// asModule generates a reusable module whose names, regions, and sizes
+	// are input variables
+	asModule bool
+
+	// moduleInputs collects the input variables of the module being
+	// generated, nil unless asModule is set
+	moduleInputs *ModuleInputs
 }
 
+// Supported output formats for generated code
+const (
+	// OutputFormatTerraform targets HashiCorp Terraform (default)
+	OutputFormatTerraform = "terraform"
+	// OutputFormatOpenTofu targets OpenTofu
+	OutputFormatOpenTofu = "opentofu"
+)
+
 // NewOptions provides configuration options for creating a Generator
 type NewOptions struct {
 	// Logger provides custom logging. If nil, a default logger is used.
 	Logger *log.Logger
 	// DisableCache disables template caching for development/testing
 	DisableCache bool
+	// OutputFormat selects the tool the generated code targets
+	// ("terraform" or "opentofu"). Defaults to "terraform".
+	OutputFormat string
+	// Stamp, if set, adds metadata.tf recording when and with which version
+	// the code was generated. Nil omits it.
+	Stamp *Stamp
+	// StrictTemplates makes templates fail on missing map keys
+	// (missingkey=error) and fails generation when a file contains
+	// "<no value>", instead of writing it into the generated code
+	StrictTemplates bool
+	// ResourceOrder orders the resources of each collection within the
+	// generated files. Defaults to config.OrderByName.
+	ResourceOrder config.ResourceOrder
+	// DisableFormat writes the templates' output as is instead of
+	// formatting the generated .tf files like terraform fmt
+	DisableFormat bool
+	// ExtraFuncs are additional functions available to all templates,
+	// registered before the templates are parsed. Names returned by
+	// ReservedFuncNames are skipped with a warning, so the built-in
+	// functions always win. Templates are not cached when set.
+	ExtraFuncs template.FuncMap
+	// AsModule generates a reusable module: the names, regions, and sizes
+	// of resources reference input variables, which are declared in
+	// variables.tf and set to the configuration's values in terraform.tfvars
+	AsModule bool
+}
+
+// Stamp identifies a generation run for traceability
+type Stamp struct {
+	// GeneratedAt is when the code was generated
+	GeneratedAt time.Time
+	// Version is the custoodian version that generated the code
+	Version string
+	// ConfigHash is the fingerprint of the configuration the code was
+	// generated from, as printed by the hash command. Empty omits it.
+	ConfigHash string
 }
 
 // New creates a new Generator instance with the specified template source.
@@ -71,11 +157,12 @@ type NewOptions struct {
 // The templateSource parameter determines where templates are loaded from:
 //   - "builtin" or empty string: Uses built-in templates embedded in the binary
 //   - Local path: Loads templates from the specified directory (e.g., "./templates")
-//   - Git URL: Loads templates from a Git repository (format: "github.com/org/repo")
+//   - Git URL: Loads templates from a Git repository (format: "github.com/org/repo",
+//     optionally followed by @ref to pin a branch, tag, or commit)
 //
 // Returns an error if template loading fails, including cases where:
 //   - Local directory doesn't exist or contains no .tf files
-//   - Git repository is inaccessible (when implemented)
+//   - Git repository is inaccessible, or its host or @ref is not allowed
 //   - Template parsing fails due to syntax errors
 //
 // Example usage:
@@ -108,17 +195,49 @@ func NewWithOptions(templateSource string, opts *NewOptions) (*Generator, error)
 	if opts.Logger == nil {
 		opts.Logger = log.Default()
 	}
+	if opts.OutputFormat == "" {
+		opts.OutputFormat = OutputFormatTerraform
+	}
+	if opts.OutputFormat != OutputFormatTerraform && opts.OutputFormat != OutputFormatOpenTofu {
+		return nil, fmt.Errorf("unsupported output format: %s (must be %s or %s)", opts.OutputFormat, OutputFormatTerraform, OutputFormatOpenTofu)
+	}
+
+	resourceOrder, err := config.ParseResourceOrder(string(opts.ResourceOrder))
+	if err != nil {
+		return nil, err
+	}
 
 	g := &Generator{
-		templateSource: templateSource,
-		logger:         opts.Logger,
+		templateSource:  templateSource,
+		logger:          opts.Logger,
+		outputFormat:    opts.OutputFormat,
+		stamp:           opts.Stamp,
+		strictTemplates: opts.StrictTemplates,
+		resourceOrder:   resourceOrder,
+		formatOutput:    !opts.DisableFormat,
+		extraFuncs:      opts.ExtraFuncs,
+		asModule:        opts.AsModule,
 	}
 
+	// Functions can't be compared, so templates parsed with extra functions
+	// can't be shared through the cache
+	useCache := !opts.DisableCache && len(opts.ExtraFuncs) == 0
+
 	startTime := time.Now()
-	if err := g.loadTemplates(!opts.DisableCache); err != nil {
+	if err := g.loadTemplates(useCache); err != nil {
 		return nil, fmt.Errorf("failed to load templates from %s: %w", templateSource, err)
 	}
 
+	if g.strictTemplates {
+		// Cached templates are shared between generators, so the option is
+		// set on a copy
+		strict, err := g.templates.Clone()
+		if err != nil {
+			return nil, fmt.Errorf("failed to clone templates: %w", err)
+		}
+		g.templates = strict.Option("missingkey=error")
+	}
+
 	g.logger.Printf("Templates loaded from %s in %v", templateSource, time.Since(startTime))
 	return g, nil
 }
@@ -131,13 +250,28 @@ func NewWithOptions(templateSource string, opts *NewOptions) (*Generator, error)
 //
 // The generated file structure includes:
 //   - project.tf: GCP project configuration, provider setup, and API enablement
+//   - backend.tf: Terraform backend storing the state (only with project.backend)
 //   - networking.tf: VPCs, subnets, firewall rules, NAT gateways, reserved IPs
 //   - compute.tf: Instance templates, managed instance groups, individual instances
 //   - load_balancers.tf: HTTP/HTTPS/TCP load balancers with health checks
 //   - iam.tf: Service accounts, role bindings, custom roles
 //   - storage.tf: Cloud Storage buckets with lifecycle policies
+//   - kms.tf: Cloud KMS key rings and crypto keys for CMEK
+//   - monitoring.tf: Notification channels, alert policies, and log-based metrics
+//   - logging.tf: Log sinks and their destination IAM bindings
+//   - tags.tf: Resource Manager tag keys, values, and bindings
+//   - pubsub.tf: Pub/Sub topics
+//   - scheduler.tf: Cloud Scheduler jobs
+//   - tasks.tf: Cloud Tasks queues
+//   - filestore.tf: Filestore instances
+//   - gke.tf: GKE clusters and node pools
+//   - dns.tf: Cloud DNS managed zones and record sets
+//   - bigquery.tf: BigQuery datasets and tables
+//   - functions.tf: 2nd gen Cloud Functions
+//   - artifacts.tf: Artifact Registry repositories
 //   - variables.tf: Terraform input variables with sensible defaults
 //   - outputs.tf: Terraform outputs for important resource attributes
+//   - metadata.tf: generated_at, custoodian_version, and config_hash locals and outputs (only with a Stamp)
 //
 // Parameters:
 //   - cfg: The protobuf configuration containing all resource definitions
@@ -149,133 +283,630 @@ func NewWithOptions(templateSource string, opts *NewOptions) (*Generator, error)
 // The method will skip generating files for resource types that are not defined
 // in the configuration (e.g., if no compute resources are specified, compute.tf
 // won't be included in the result).
+// Resources with enabled set to false are skipped as if they were not declared.
+// Within each file, resources appear in the generator's ResourceOrder. Unless
+// DisableFormat is set, the .tf files are formatted like terraform fmt. The output
+// section of the configuration can rename the .tf files, e.g. numbering them
+// in apply order ("02-networking.tf"); the names above are the defaults.
+//
+// The files are rendered concurrently, so the output does not depend on the
+// order they finish in. If several fail, the first failure is returned.
+//
+// A configuration that declares projects instead of project generates the files
+// of each project under a subdirectory named after its project ID (e.g.
+// "web-prod/project.tf"), containing only the resources assigned to it.
 //
 // Security Considerations:
 //   - All string values are properly quoted to prevent injection attacks
 //   - File paths are sanitized to prevent directory traversal
 //   - Sensitive values (like service account keys) are marked as sensitive in outputs
 func (g *Generator) Generate(cfg *config.Config) (map[string]string, error) {
+	if len(cfg.Projects) > 0 {
+		return g.generateProjects(cfg)
+	}
+
 	files := make(map[string]string)
 
+	// Resources with enabled: false are excluded from generation
+	cfg = config.WithoutDisabled(cfg)
+	cfg = config.Sorted(cfg, g.resourceOrder)
+
+	// Resources that depend on project APIs wait for the time_sleep in project.tf
+	g.apiPropagationDelay = ""
+	if len(cfg.GetProject().GetApis()) > 0 {
+		g.apiPropagationDelay = cfg.GetProject().GetApiPropagationDelay()
+	}
+
+	// Templates merge these into the labels of each resource
+	g.commonLabels = cfg.CommonLabels
+
+	// Templates record the input variables of a module as they render
+	g.moduleInputs = nil
+	if g.asModule {
+		g.moduleInputs = newModuleInputs()
+	}
+
+	// Each file is rendered in its own goroutine; the templates are only read,
+	// which is safe concurrently. render adds the file to files unless it is
+	// empty and keepEmpty is false, and the first error fails generation.
+	var (
+		group errgroup.Group
+		mu    sync.Mutex
+	)
+	render := func(name, description string, keepEmpty bool, generate func() (string, error)) {
+		group.Go(func() error {
+			content, err := generate()
+			if err != nil {
+				return fmt.Errorf("failed to generate %s configuration: %w", description, err)
+			}
+			if content == "" && !keepEmpty {
+				return nil
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			files[name] = content
+			return nil
+		})
+	}
+
 	// Generate project configuration - this is required and includes provider setup
 	if cfg.Project != nil {
-		content, err := g.generateProject(cfg.Project)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate project configuration: %w", err)
-		}
-		files["project.tf"] = content
+		render("project.tf", "project", true, func() (string, error) {
+			return g.generateProject(cfg.Project, usesGeneratedPasswords(cfg))
+		})
 	}
 
 	// Generate networking resources (VPCs, subnets, firewall rules, NAT gateways)
 	if cfg.Networking != nil {
-		content, err := g.generateNetworking(cfg.Networking)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate networking configuration: %w", err)
-		}
-		// Only include the file if it has actual content
-		if content != "" {
-			files["networking.tf"] = content
-		}
+		render("networking.tf", "networking", false, func() (string, error) {
+			return g.generateNetworking(cfg.Networking)
+		})
 	}
 
 	// Generate compute resources (templates, instance groups, individual instances)
 	if cfg.Compute != nil {
-		content, err := g.generateCompute(cfg.Compute)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate compute configuration: %w", err)
-		}
-		if content != "" {
-			files["compute.tf"] = content
-		}
+		render("compute.tf", "compute", false, func() (string, error) {
+			return g.generateCompute(cfg.Compute, cryptoKeyGrants(cfg, "compute"))
+		})
 	}
 
 	// Generate load balancer configurations with health checks
 	if len(cfg.LoadBalancers) > 0 {
-		content, err := g.generateLoadBalancers(cfg.LoadBalancers)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate load balancer configuration: %w", err)
-		}
-		files["load_balancers.tf"] = content
+		render("load_balancers.tf", "load balancer", true, func() (string, error) {
+			return g.generateLoadBalancers(cfg.LoadBalancers)
+		})
 	}
 
 	// Generate IAM resources (service accounts, role bindings, custom roles)
 	if cfg.Iam != nil {
-		content, err := g.generateIAM(cfg.Iam)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate IAM configuration: %w", err)
-		}
-		if content != "" {
-			files["iam.tf"] = content
-		}
+		render("iam.tf", "IAM", false, func() (string, error) {
+			return g.generateIAM(cfg.Iam)
+		})
 	}
 
 	// Generate storage resources (Cloud Storage buckets with lifecycle policies)
 	if cfg.Storage != nil {
-		content, err := g.generateStorage(cfg.Storage)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate storage configuration: %w", err)
-		}
-		if content != "" {
-			files["storage.tf"] = content
-		}
+		render("storage.tf", "storage", false, func() (string, error) {
+			return g.generateStorage(cfg.Storage, cryptoKeyGrants(cfg, "storage"))
+		})
 	}
 
 	// Generate Cloud Run resources (services, VPC connectors)
 	if cfg.CloudRun != nil {
-		content, err := g.generateCloudRun(cfg.CloudRun)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate Cloud Run configuration: %w", err)
-		}
-		if content != "" {
-			files["cloud_run.tf"] = content
-		}
+		render("cloud_run.tf", "Cloud Run", false, func() (string, error) {
+			return g.generateCloudRun(cfg.CloudRun, cfg.SecretManager)
+		})
 	}
 
 	// Generate database resources (Cloud SQL, Cloud Spanner)
 	if cfg.Databases != nil {
-		content, err := g.generateDatabases(cfg.Databases)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate database configuration: %w", err)
-		}
-		if content != "" {
-			files["databases.tf"] = content
-		}
+		render("databases.tf", "database", false, func() (string, error) {
+			return g.generateDatabases(cfg.Databases, cryptoKeyGrants(cfg, "sql"))
+		})
 	}
 
 	// Generate Secret Manager resources (secrets and versions)
 	if cfg.SecretManager != nil {
-		content, err := g.generateSecretManager(cfg.SecretManager)
+		render("secret_manager.tf", "Secret Manager", false, func() (string, error) {
+			return g.generateSecretManager(cfg.SecretManager)
+		})
+	}
+
+	// Generate KMS resources (key rings and crypto keys)
+	if cfg.Kms != nil {
+		render("kms.tf", "KMS", false, func() (string, error) {
+			return g.generateKMS(cfg)
+		})
+	}
+
+	// Generate monitoring resources (notification channels, alert policies)
+	if cfg.Monitoring != nil {
+		render("monitoring.tf", "monitoring", false, func() (string, error) {
+			return g.generateMonitoring(cfg.Monitoring)
+		})
+	}
+
+	// Generate log sinks
+	if len(cfg.LogSinks) > 0 {
+		render("logging.tf", "logging", false, func() (string, error) {
+			return g.generateLogSinks(cfg.LogSinks)
+		})
+	}
+
+	// Generate Resource Manager tags
+	if cfg.ResourceTags != nil {
+		render("tags.tf", "resource tags", false, func() (string, error) {
+			return g.generateResourceTags(cfg.ResourceTags)
+		})
+	}
+
+	// Generate Pub/Sub topics
+	if cfg.Pubsub != nil {
+		render("pubsub.tf", "Pub/Sub", false, func() (string, error) {
+			return g.generatePubSub(cfg.Pubsub)
+		})
+	}
+
+	// Generate Cloud Scheduler jobs
+	if cfg.Scheduler != nil {
+		render("scheduler.tf", "Cloud Scheduler", false, func() (string, error) {
+			return g.generateScheduler(cfg)
+		})
+	}
+
+	// Generate Cloud Tasks queues
+	if cfg.Tasks != nil {
+		render("tasks.tf", "Cloud Tasks", false, func() (string, error) {
+			return g.generateTasks(cfg.Tasks)
+		})
+	}
+
+	// Generate Filestore instances
+	if cfg.Filestore != nil {
+		render("filestore.tf", "Filestore", false, func() (string, error) {
+			return g.generateFilestore(cfg.Filestore)
+		})
+	}
+
+	// Generate GKE clusters
+	if cfg.Gke != nil {
+		render("gke.tf", "GKE", false, func() (string, error) {
+			return g.generateGke(cfg.Gke)
+		})
+	}
+
+	// Generate Cloud DNS zones
+	if cfg.Dns != nil {
+		render("dns.tf", "Cloud DNS", false, func() (string, error) {
+			return g.generateDns(cfg.Dns)
+		})
+	}
+
+	// Generate BigQuery datasets and tables
+	if cfg.Bigquery != nil {
+		render("bigquery.tf", "BigQuery", false, func() (string, error) {
+			return g.generateBigQuery(cfg.Bigquery)
+		})
+	}
+
+	// Generate Cloud Functions
+	if cfg.CloudFunctions != nil {
+		render("functions.tf", "Cloud Functions", false, func() (string, error) {
+			return g.generateFunctions(cfg)
+		})
+	}
+
+	// Generate Artifact Registry repositories
+	if cfg.ArtifactRegistry != nil {
+		render("artifacts.tf", "Artifact Registry", false, func() (string, error) {
+			return g.generateArtifactRegistry(cfg.ArtifactRegistry)
+		})
+	}
+
+	// Generate backend configuration if the project stores state remotely
+	if backend := cfg.GetProject().GetBackend(); backend != nil {
+		render("backend.tf", "backend", true, func() (string, error) {
+			return g.generateBackend(backend)
+		})
+	}
+
+	// Generate variables file - always included with default values
+	render("variables.tf", "variables", true, func() (string, error) {
+		return g.generateVariables(cfg)
+	})
+
+	// Generate outputs file - always included to expose important resource attributes
+	render("outputs.tf", "outputs", true, func() (string, error) {
+		return g.generateOutputs(cfg)
+	})
+
+	// Record when and with which version the code was generated if requested
+	if g.stamp != nil {
+		render("metadata.tf", "metadata", true, g.generateMetadata)
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	// The input variables of a module are known once every file is rendered
+	if g.moduleInputs != nil {
+		if err := g.generateModuleInputs(cfg, files); err != nil {
+			return nil, err
+		}
+	}
+
+	if g.strictTemplates {
+		if err := checkMissingValues(files); err != nil {
+			return nil, err
+		}
+	}
+
+	if g.formatOutput {
+		g.formatFiles(files)
+	}
+
+	return renameOutputFiles(files, cfg.Output)
+}
+
+// renameOutputFiles returns files with the .tf files renamed according to
+// the output settings of the configuration
+func renameOutputFiles(files map[string]string, output *config.Output) (map[string]string, error) {
+	if output == nil {
+		return files, nil
+	}
+	names, err := config.OutputFileNames(output)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output file names: %w", err)
+	}
+
+	renamed := make(map[string]string, len(files))
+	for name, content := range files {
+		if final, ok := names[name]; ok {
+			name = final
+		}
+		renamed[name] = content
+	}
+	return renamed, nil
+}
+
+// formatFiles formats the .tf files in place with hclwrite, which applies the
+// layout rules of terraform fmt. Formatting only changes whitespace, so a
+// file with syntax errors, e.g. from a custom template, is still written and
+// terraform reports the problem with its own diagnostics.
+func (g *Generator) formatFiles(files map[string]string) {
+	for name, content := range files {
+		if strings.HasSuffix(name, ".tf") {
+			files[name] = string(hclwrite.Format([]byte(content)))
+		}
+	}
+}
+
+// checkMissingValues returns an error naming the first file, in name order,
+// where a template rendered missing data as "<no value>"
+func checkMissingValues(files map[string]string) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for i, line := range strings.Split(files[name], "\n") {
+			if strings.Contains(line, "<no value>") {
+				return fmt.Errorf("%s:%d: template rendered missing data as <no value>: %s", name, i+1, strings.TrimSpace(line))
+			}
+		}
+	}
+	return nil
+}
+
+// CombinedFileName is the file the .tf files of each directory are combined
+// into by CombineFiles
+const CombinedFileName = "main.tf"
+
+// CombineFiles returns files with the .tf files of each directory concatenated
+// into a single main.tf, each preceded by a "# === name ===" separator.
+// project.tf, which configures the providers, comes first and the other files
+// follow in name order. Files that are not .tf files, such as a Makefile, and
+// the project subdirectories of a multi-project configuration are kept apart.
+func CombineFiles(files map[string]string) map[string]string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		di, dj := path.Dir(names[i]), path.Dir(names[j])
+		if di != dj {
+			return di < dj
+		}
+		bi, bj := path.Base(names[i]), path.Base(names[j])
+		if (bi == "project.tf") != (bj == "project.tf") {
+			return bi == "project.tf"
+		}
+		return bi < bj
+	})
+
+	combined := make(map[string]string)
+	sections := make(map[string][]string)
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".tf") {
+			combined[name] = files[name]
+			continue
+		}
+		dir := path.Dir(name)
+		section := fmt.Sprintf("# === %s ===\n\n%s\n", path.Base(name), strings.Trim(files[name], "\n"))
+		sections[dir] = append(sections[dir], section)
+	}
+	for dir, parts := range sections {
+		combined[path.Join(dir, CombinedFileName)] = strings.Join(parts, "\n")
+	}
+	return combined
+}
+
+// generateProjects generates each project of a multi-project configuration
+// into its own subdirectory
+func (g *Generator) generateProjects(cfg *config.Config) (map[string]string, error) {
+	scoped, err := config.SplitByProject(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string)
+	for _, projectCfg := range scoped {
+		projectFiles, err := g.Generate(projectCfg)
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate Secret Manager configuration: %w", err)
+			return nil, fmt.Errorf("project %s: %w", projectCfg.Project.Id, err)
 		}
-		if content != "" {
-			files["secret_manager.tf"] = content
+		for filename, content := range projectFiles {
+			files[path.Join(projectCfg.Project.Id, filename)] = content
 		}
 	}
+	return files, nil
+}
+
+// lookupOrBuiltin returns the template name of the template source, or for
+// sources without it, the built-in one. The built-in template is parsed into
+// a copy so the shared template set is not modified.
+func (g *Generator) lookupOrBuiltin(name string) (*template.Template, error) {
+	if tmpl := g.templates.Lookup(name); tmpl != nil {
+		return tmpl, nil
+	}
 
-	// Generate variables file - always included with default values
-	variables, err := g.generateVariables(cfg)
+	clone, err := g.templates.Clone()
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate variables configuration: %w", err)
+		return nil, fmt.Errorf("failed to clone templates: %w", err)
 	}
-	files["variables.tf"] = variables
+	tmpl, err := clone.New(name).Parse(templates.GetBuiltinTemplates()[name])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse built-in %s template: %w", name, err)
+	}
+	return tmpl, nil
+}
 
-	// Generate outputs file - always included to expose important resource attributes
-	outputs, err := g.generateOutputs(cfg)
+// GenerateMakefile renders the Makefile template with init, plan, apply, fmt,
+// and validate targets for the generated code.
+//
+// The Makefile template is taken from the configured template source when it
+// provides one, so teams can standardize their own commands; otherwise the
+// built-in Makefile is used. Targets run the terraform or tofu binary
+// according to the output format.
+func (g *Generator) GenerateMakefile() (string, error) {
+	tmpl, err := g.lookupOrBuiltin("Makefile")
+	if err != nil {
+		return "", err
+	}
+
+	ctx := &TemplateContext{
+		Dependencies: &DependencyInfo{},
+		OutputFormat: g.outputFormat,
+	}
+
+	var output strings.Builder
+	if err := tmpl.Execute(&output, ctx); err != nil {
+		return "", fmt.Errorf("template execution failed for Makefile: %w", err)
+	}
+	return output.String(), nil
+}
+
+// TfvarsFileName is the name of the variable values file written by
+// GenerateTfvars, which Terraform loads automatically
+const TfvarsFileName = "terraform.tfvars"
+
+// moduleVariablesTemplateName is the template declaring the input variables
+// of a generated module, which are appended to variables.tf
+const moduleVariablesTemplateName = "module_variables"
+
+// TfvarsData is the template data for terraform.tfvars
+type TfvarsData struct {
+	// ProjectId is the value of the project_id variable
+	ProjectId string
+	// Region is the value of the region variable, or "" to keep its default
+	Region string
+	// Zone is the value of the zone variable, or "" to keep its default
+	Zone string
+	// Variables are the input variables of a generated module
+	Variables []*ModuleVariable
+}
+
+// GenerateTfvars renders terraform.tfvars with the values of the project_id,
+// region, and zone variables in variables.tf that match cfg. The region is
+// the first subnet's and the zone the first instance's or instance group's;
+// without a subnet the region is that of the zone, and values that can't be
+// derived are left out so their defaults apply. Sensitive variables are never
+// written.
+//
+// The result is keyed by file name like Generate's, with a file in each
+// project directory of a multi-project configuration. Template sources
+// without a terraform.tfvars template use the built-in one.
+func (g *Generator) GenerateTfvars(cfg *config.Config) (map[string]string, error) {
+	scoped, err := config.SplitByProject(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate outputs configuration: %w", err)
+		return nil, err
 	}
-	files["outputs.tf"] = outputs
 
+	files := make(map[string]string)
+	for _, projectCfg := range scoped {
+		content, err := g.renderTfvars(config.WithoutDisabled(projectCfg), nil)
+		if err != nil {
+			return nil, err
+		}
+		filename := TfvarsFileName
+		if len(cfg.Projects) > 0 {
+			filename = path.Join(projectCfg.Project.Id, filename)
+		}
+		files[filename] = content
+	}
 	return files, nil
 }
 
+// renderTfvars renders terraform.tfvars for an enabled single-project
+// configuration, with the values of the input variables of a module
+func (g *Generator) renderTfvars(cfg *config.Config, variables []*ModuleVariable) (string, error) {
+	tmpl, err := g.lookupOrBuiltin(TfvarsFileName)
+	if err != nil {
+		return "", err
+	}
+
+	data := &TfvarsData{ProjectId: cfg.GetProject().GetId(), Variables: variables}
+	data.Region, data.Zone = tfvarsLocation(cfg)
+
+	ctx := &TemplateContext{
+		Data:         data,
+		Dependencies: &DependencyInfo{},
+		OutputFormat: g.outputFormat,
+	}
+
+	var output strings.Builder
+	if err := tmpl.Execute(&output, ctx); err != nil {
+		return "", fmt.Errorf("template execution failed for %s: %w", TfvarsFileName, err)
+	}
+
+	// tfvars files use HCL syntax too, but formatFiles only formats .tf files
+	if !g.formatOutput {
+		return output.String(), nil
+	}
+	return string(hclwrite.Format([]byte(output.String()))), nil
+}
+
+// generateModuleInputs declares the input variables the templates recorded
+// while generating a module in variables.tf, and sets them to the values of
+// the configuration in terraform.tfvars. Template sources without a
+// module_variables template use the built-in one.
+func (g *Generator) generateModuleInputs(cfg *config.Config, files map[string]string) error {
+	variables := g.moduleInputs.Variables()
+
+	tmpl, err := g.lookupOrBuiltin(moduleVariablesTemplateName)
+	if err != nil {
+		return err
+	}
+
+	ctx := &TemplateContext{
+		Data:         variables,
+		Dependencies: &DependencyInfo{},
+		OutputFormat: g.outputFormat,
+	}
+
+	var output strings.Builder
+	if err := tmpl.Execute(&output, ctx); err != nil {
+		return fmt.Errorf("template execution failed for module variables: %w", err)
+	}
+	files["variables.tf"] += output.String()
+
+	tfvars, err := g.renderTfvars(cfg, variables)
+	if err != nil {
+		return err
+	}
+	files[TfvarsFileName] = tfvars
+	return nil
+}
+
+// tfvarsLocation returns the region and zone terraform.tfvars sets for cfg,
+// "" for those it has no resources to derive from
+func tfvarsLocation(cfg *config.Config) (string, string) {
+	var region, zone string
+	for _, vpc := range cfg.GetNetworking().GetVpcs() {
+		for _, subnet := range vpc.Subnets {
+			if region == "" && subnet.Region != config.Region_REGION_UNSPECIFIED {
+				region = regionToString(subnet.Region)
+			}
+		}
+	}
+
+	for _, instance := range cfg.GetCompute().GetInstances() {
+		if zone == "" && instance.Zone != config.Zone_ZONE_UNSPECIFIED {
+			zone = zoneToString(instance.Zone)
+		}
+	}
+	for _, group := range cfg.GetCompute().GetInstanceGroups() {
+		if zone == "" && len(group.Zones) > 0 && group.Zones[0] != config.Zone_ZONE_UNSPECIFIED {
+			zone = zoneToString(group.Zones[0])
+		}
+	}
+
+	// A zone's region is its name without the zone letter (us-central1-a)
+	if region == "" && zone != "" {
+		region = zone[:strings.LastIndex(zone, "-")]
+	}
+	return region, zone
+}
+
+// generateMetadata generates locals and outputs carrying the generation stamp.
+//
+// Terraform treats the values as static strings, so a plan that changes them
+// shows the code was regenerated since the last apply. Template sources without
+// a metadata.tf template use the built-in one.
+func (g *Generator) generateMetadata() (string, error) {
+	tmpl, err := g.lookupOrBuiltin("metadata.tf")
+	if err != nil {
+		return "", err
+	}
+
+	ctx := &TemplateContext{
+		Data: map[string]string{
+			"GeneratedAt": g.stamp.GeneratedAt.UTC().Format(time.RFC3339),
+			"Version":     g.stamp.Version,
+			"ConfigHash":  g.stamp.ConfigHash,
+		},
+		Dependencies: &DependencyInfo{},
+		OutputFormat: g.outputFormat,
+	}
+
+	var output strings.Builder
+	if err := tmpl.Execute(&output, ctx); err != nil {
+		return "", fmt.Errorf("template execution failed for metadata configuration: %w", err)
+	}
+	return output.String(), nil
+}
+
+// generateBackend generates the terraform backend block storing the state.
+// Template sources without a backend.tf template use the built-in one.
+func (g *Generator) generateBackend(backend *config.Backend) (string, error) {
+	tmpl, err := g.lookupOrBuiltin("backend.tf")
+	if err != nil {
+		return "", err
+	}
+
+	ctx := &TemplateContext{
+		Data:         backend,
+		Dependencies: &DependencyInfo{},
+		OutputFormat: g.outputFormat,
+	}
+
+	var output strings.Builder
+	if err := tmpl.Execute(&output, ctx); err != nil {
+		return "", fmt.Errorf("template execution failed for backend configuration: %w", err)
+	}
+	return output.String(), nil
+}
+
 // loadTemplates loads and parses templates from the specified source with optional caching.
 //
 // This method handles loading templates from three different sources:
 //  1. Built-in templates: Embedded templates for standard GCP resources
 //  2. Local directory: Templates from a filesystem directory
-//  3. Git repository: Templates from a remote Git repository (not yet implemented)
+//  3. Git repository: Templates fetched from a remote Git repository at the
+//     default branch or an @ref (see templates.LoadFromGit)
 //
 // The method also sets up custom template functions that are available in all
 // templates for converting protobuf enums to Terraform-compatible strings and
@@ -283,16 +914,22 @@ func (g *Generator) Generate(cfg *config.Config) (map[string]string, error) {
 //
 // Performance Features:
 //   - Template caching: Avoids re-parsing templates when using the same source
-//   - Cache invalidation: Templates expire after a configurable timeout
+//   - Cache invalidation: Templates expire after a configurable timeout, and
+//     local directories are cached by content so edits take effect immediately
 //   - Thread-safe: Multiple goroutines can safely access the cache
 //
 // Available template functions:
 //   - regionToString: Converts Region enum to GCP region string (e.g., "us-central1")
+//   - regionExpression: Quoted region string, or var.region when the Region is unspecified
 //   - zoneToString: Converts Zone enum to GCP zone string (e.g., "us-central1-a")
 //   - machineTypeToString: Converts MachineType enum to GCP machine type (e.g., "e2-medium")
 //   - apiToString: Converts GcpApi enum to API service name (e.g., "compute.googleapis.com")
 //   - networkTierToString: Converts NetworkTier enum to string (e.g., "PREMIUM")
-//   - indent: Adds specified number of spaces to each line of text
+//   - vpcEgressToString: Converts VpcEgress enum to annotation value (e.g., "all-traffic")
+//   - artifactFormatToString: Converts ArtifactFormat enum to repository format (e.g., "DOCKER")
+//   - providerSource: Returns the provider source address for an output format
+//   - normalizePorts: Sorts, deduplicates, and optionally collapses firewall port lists
+//   - mergeLabels: Merges a resource's labels over the common labels of the configuration
 //   - quote: Wraps string in double quotes for Terraform syntax
 //   - join: Joins string slice with separator (strings.Join wrapper)
 //   - lower/upper: String case conversion (strings.ToLower/ToUpper wrappers)
@@ -307,37 +944,46 @@ func (g *Generator) Generate(cfg *config.Config) (map[string]string, error) {
 //   - Template parsing fails due to syntax errors
 //   - No valid templates are found in the specified source
 func (g *Generator) loadTemplates(useCache bool) error {
+	var templateContent map[string]string
+	var err error
+
+	// Local directories are read on every load and cached by their content,
+	// since they may be edited between runs. Built-in templates can't change,
+	// and Git repositories are only cloned again once their entry expires.
+	cacheKey := g.templateSource
+	local := g.templateSource != "builtin" && g.templateSource != "" && !templates.IsGitURL(g.templateSource)
+	if local {
+		g.logger.Printf("Loading templates from directory: %s", g.templateSource)
+		templateContent, err = templates.LoadFromDirectory(g.templateSource)
+		if err != nil {
+			return fmt.Errorf("failed to load custom templates from %s: %w", g.templateSource, err)
+		}
+		g.logger.Printf("Loaded %d custom templates", len(templateContent))
+		cacheKey = g.templateSource + "@" + templatesDigest(templateContent)
+	}
+
 	// Check cache first if enabled
 	if useCache {
-		if cached := g.getCachedTemplate(); cached != nil {
+		if cached := g.getCachedTemplate(cacheKey); cached != nil {
 			g.templates = cached
 			g.logger.Printf("Using cached templates for source: %s", g.templateSource)
 			return nil
 		}
 	}
 
-	g.logger.Printf("Loading templates from source: %s", g.templateSource)
-
-	var templateContent map[string]string
-	var err error
-
 	// Determine template source and load content
-	switch g.templateSource {
-	case "builtin", "":
+	switch {
+	case local:
+		// Already loaded to compute the cache key
+	case g.templateSource == "builtin" || g.templateSource == "":
+		g.logger.Printf("Loading templates from source: %s", g.templateSource)
 		// Use embedded templates for standard GCP resources
 		templateContent = templates.GetBuiltinTemplates()
 		g.logger.Printf("Loaded %d built-in templates", len(templateContent))
 	default:
-		// Check if it's a local directory or a Git repository URL
-		if strings.Contains(g.templateSource, "://") || strings.Contains(g.templateSource, "@") {
-			// Git repository format detected (e.g., github.com/org/repo or git@github.com:org/repo.git)
-			g.logger.Printf("Loading templates from Git repository: %s", g.templateSource)
-			templateContent, err = templates.LoadFromGit(g.templateSource)
-		} else {
-			// Local directory path
-			g.logger.Printf("Loading templates from directory: %s", g.templateSource)
-			templateContent, err = templates.LoadFromDirectory(g.templateSource)
-		}
+		// Git repository format detected (e.g., github.com/org/repo or git@github.com:org/repo.git)
+		g.logger.Printf("Loading templates from Git repository: %s", g.templateSource)
+		templateContent, err = templates.LoadFromGit(g.templateSource)
 		if err != nil {
 			return fmt.Errorf("failed to load custom templates from %s: %w", g.templateSource, err)
 		}
@@ -348,49 +994,130 @@ func (g *Generator) loadTemplates(useCache bool) error {
 	g.templates = template.New("custodian")
 
 	// Register custom functions available to all templates
-	g.templates = g.templates.Funcs(template.FuncMap{
+	funcs := builtinFuncs()
+	for name, fn := range g.extraFuncs {
+		if isReservedFuncName(name) {
+			g.logger.Printf("Warning: ignoring extra template function %q, which is reserved", name)
+			continue
+		}
+		funcs[name] = fn
+	}
+	g.templates = g.templates.Funcs(funcs)
+
+	// Parse each template and add it to the template collection
+	templateCount := 0
+	for name, content := range templateContent {
+		if _, err := g.templates.New(name).Parse(content); err != nil {
+			return fmt.Errorf("failed to parse template %s: %w", name, err)
+		}
+		templateCount++
+	}
+
+	g.logger.Printf("Successfully parsed %d templates", templateCount)
+
+	// Cache the parsed templates if caching is enabled
+	if useCache {
+		g.cacheTemplate(cacheKey, g.templates)
+	}
+
+	return nil
+}
+
+// builtinFuncs returns the functions custoodian registers for all templates
+func builtinFuncs() template.FuncMap {
+	return template.FuncMap{
 		// GCP enum conversion functions
 		"regionToString":      regionToString,
 		"zoneToString":        zoneToString,
 		"machineTypeToString": machineTypeToString,
 		"apiToString":         apiToString,
 		"networkTierToString": networkTierToString,
+		"vpcEgressToString":   vpcEgressToString,
+		"providerSource":      providerSource,
+		"normalizePorts":      normalizePorts,
+		"durationSeconds":     durationSeconds,
+		"spannerDuration":     spannerDuration,
+		"durationMillis":      durationMillis,
+		"regionExpression":    regionExpression,
+
+		// Cloud DNS record set names
+		"dnsRecordName":         config.DnsRecordName,
+		"dnsRecordResourceName": dnsRecordResourceName,
+
+		// BigQuery table schemas
+		"bigQuerySchema": bigQuerySchema,
+
+		// Artifact Registry repository formats
+		"artifactFormatToString": artifactFormatToString,
+
+		// Resource labels with the common labels of the configuration
+		"mergeLabels": mergeLabels,
 
 		// Text manipulation functions
-		"indent":           indent,
 		"quote":            quote,
 		"join":             strings.Join,
 		"lower":            strings.ToLower,
 		"upper":            strings.ToUpper,
 		"replace":          strings.ReplaceAll,
 		"unescapeNewlines": func(s string) string { return strings.ReplaceAll(s, "\\n", "\n") },
-	})
+	}
+}
 
-	// Parse each template and add it to the template collection
-	templateCount := 0
-	for name, content := range templateContent {
-		if _, err := g.templates.New(name).Parse(content); err != nil {
-			return fmt.Errorf("failed to parse template %s: %w", name, err)
-		}
-		templateCount++
+// textTemplateFuncs are the functions predefined by text/template, which
+// template functions with the same name would replace
+var textTemplateFuncs = []string{
+	"and", "call", "eq", "ge", "gt", "html", "index", "js", "le", "len",
+	"lt", "ne", "not", "or", "print", "printf", "println", "slice", "urlquery",
+}
+
+// ReservedFuncNames returns the sorted names of the template functions that
+// NewOptions.ExtraFuncs can't replace: the functions custoodian registers
+// and those predefined by text/template
+func ReservedFuncNames() []string {
+	names := append([]string(nil), textTemplateFuncs...)
+	for name := range builtinFuncs() {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
+}
 
-	g.logger.Printf("Successfully parsed %d templates", templateCount)
+// isReservedFuncName reports whether name is one of ReservedFuncNames
+func isReservedFuncName(name string) bool {
+	if _, ok := builtinFuncs()[name]; ok {
+		return true
+	}
+	for _, reserved := range textTemplateFuncs {
+		if name == reserved {
+			return true
+		}
+	}
+	return false
+}
 
-	// Cache the parsed templates if caching is enabled
-	if useCache {
-		g.cacheTemplate(g.templates)
+// templatesDigest returns a SHA-256 of template names and contents, which
+// changes whenever a template is added, removed, renamed, or edited
+func templatesDigest(content map[string]string) string {
+	names := make([]string, 0, len(content))
+	for name := range content {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	return nil
+	hash := sha256.New()
+	for _, name := range names {
+		// Length prefixes keep name and content boundaries unambiguous
+		fmt.Fprintf(hash, "%d:%s%d:%s", len(name), name, len(content[name]), content[name])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
 }
 
 // getCachedTemplate retrieves cached templates if they exist and are still valid
-func (g *Generator) getCachedTemplate() *template.Template {
+func (g *Generator) getCachedTemplate(key string) *template.Template {
 	cacheMutex.RLock()
 	defer cacheMutex.RUnlock()
 
-	entry, exists := templateCache[g.templateSource]
+	entry, exists := templateCache[key]
 	if !exists {
 		return nil
 	}
@@ -405,12 +1132,18 @@ func (g *Generator) getCachedTemplate() *template.Template {
 	return entry.templates
 }
 
-// cacheTemplate stores parsed templates in the cache
-func (g *Generator) cacheTemplate(templates *template.Template) {
+// cacheTemplate stores parsed templates in the cache, replacing the entries
+// for earlier contents of the same source
+func (g *Generator) cacheTemplate(key string, templates *template.Template) {
 	cacheMutex.Lock()
 	defer cacheMutex.Unlock()
 
-	templateCache[g.templateSource] = &templateCacheEntry{
+	for cached, entry := range templateCache {
+		if entry.source == g.templateSource {
+			delete(templateCache, cached)
+		}
+	}
+	templateCache[key] = &templateCacheEntry{
 		templates: templates,
 		loadTime:  time.Now(),
 		source:    g.templateSource,
@@ -427,9 +1160,9 @@ func (g *Generator) cleanExpiredCache() {
 	now := time.Now()
 	expiredCount := 0
 
-	for source, entry := range templateCache {
+	for key, entry := range templateCache {
 		if now.Sub(entry.loadTime) > cacheTimeout {
-			delete(templateCache, source)
+			delete(templateCache, key)
 			expiredCount++
 		}
 	}
@@ -439,31 +1172,104 @@ func (g *Generator) cleanExpiredCache() {
 	}
 }
 
+// ClearTemplateCache removes all entries from the template cache, so the next
+// generator loads and parses its templates again
+func ClearTemplateCache() {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+
+	templateCache = make(map[string]*templateCacheEntry)
+}
+
 // generateProject generates Terraform configuration for GCP project setup.
 //
 // This includes the Terraform provider configuration, project resource creation,
 // and API service enablement. The generated project.tf file serves as the
 // foundation for all other resources.
 //
+// The provider source addresses in the required_providers block depend on
+// the configured output format (Terraform or OpenTofu).
+//
 // Generated resources:
 //   - terraform and google provider configuration
 //   - google_project resource with billing and organization setup
 //   - google_project_service resources for each enabled API
-func (g *Generator) generateProject(project *config.Project) (string, error) {
+//
+// The random provider is declared when requiresRandom is set, i.e. when a
+// secret or Cloud SQL user has a generated password.
+func (g *Generator) generateProject(project *config.Project, requiresRandom bool) (string, error) {
+	ctx := &ProjectContext{
+		Project: project,
+		TemplateContext: &TemplateContext{
+			Data:         project,
+			Dependencies: &DependencyInfo{RequiresRandomProvider: requiresRandom},
+			OutputFormat: g.outputFormat,
+			CommonLabels: g.commonLabels,
+			Module:       g.moduleInputs,
+		},
+	}
+
 	var output strings.Builder
-	err := g.templates.ExecuteTemplate(&output, "project.tf", project)
+	err := g.templates.ExecuteTemplate(&output, "project.tf", ctx)
 	if err != nil {
 		return "", fmt.Errorf("template execution failed for project configuration: %w", err)
 	}
 	return output.String(), nil
 }
 
+// ProjectContext is the template data for project.tf. project.tf templates
+// used to get the *config.Project itself, so the project is embedded for them
+// to keep referring to fields such as .Id and .Apis, alongside the
+// TemplateContext whose Data is the same project.
+type ProjectContext struct {
+	*config.Project
+	*TemplateContext
+}
+
+// usesGeneratedPasswords reports whether any secret or Cloud SQL user in cfg
+// has a password generated by a random_password resource
+func usesGeneratedPasswords(cfg *config.Config) bool {
+	for _, secret := range cfg.GetSecretManager().GetSecrets() {
+		if secret.GetGenerate() != nil {
+			return true
+		}
+	}
+	for _, instance := range cfg.GetDatabases().GetCloudSqlInstances() {
+		for _, user := range instance.Users {
+			if user.GeneratePassword != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // TemplateContext provides comprehensive context for template execution with dependency information
 type TemplateContext struct {
 	// Primary data for the template
 	Data interface{}
 	// Dependency information
 	Dependencies *DependencyInfo
+	// OutputFormat is the tool the generated code targets ("terraform" or "opentofu")
+	OutputFormat string
+	// Locations of the resources whose region or zone falls back to a variable
+	Locations *LocationInfo
+	// CommonLabels are the common_labels of the configuration, which templates
+	// merge into the labels of each resource with mergeLabels
+	CommonLabels map[string]string
+	// Module collects the input variables templates declare with Var when
+	// generating a module, nil otherwise
+	Module *ModuleInputs
+}
+
+// LocationInfo contains the resolved locations of resources as HCL
+// expressions: a quoted region or zone name, or var.region or var.zone where
+// the configuration leaves it unspecified
+type LocationInfo struct {
+	// Zone of each instance, by name
+	InstanceZones map[string]string
+	// Region of each instance template, by name
+	TemplateRegions map[string]string
 }
 
 // DependencyInfo contains information about resource dependencies
@@ -476,12 +1282,25 @@ type DependencyInfo struct {
 	RequiresNetworking bool
 	// Network names that this resource depends on
 	NetworkDependencies []string
+	// Delay after API enablement; when set, dependents also wait for
+	// time_sleep.api_propagation
+	APIPropagationDelay string
+	// Whether project.tf must declare the random provider for generated passwords
+	RequiresRandomProvider bool
+	// Service account resources each IAM role binding (by index) depends on
+	ServiceAccountDependencies map[int][]string
+	// Secret Manager secrets declared in the configuration, by name, which
+	// templates reference instead of naming them
+	DeclaredSecrets map[string]bool
+	// IAM member resources granting the service agent use of each CMEK key,
+	// by key name, which resources encrypted with the key depend on
+	CryptoKeyGrants map[string]string
 }
 
 // generateNetworking generates Terraform configuration for networking resources.
 //
-// This includes VPC networks, subnets, firewall rules, NAT gateways, and
-// reserved IP addresses. Resources are organized hierarchically with proper
+// This includes VPC networks, subnets, firewall rules, NAT gateways, VPC
+// peerings, and reserved IP addresses. Resources are organized hierarchically with proper
 // dependencies (e.g., subnets reference their parent VPC).
 //
 // Generated resources:
@@ -490,17 +1309,20 @@ type DependencyInfo struct {
 //   - google_compute_subnetwork for subnets with secondary ranges
 //   - google_compute_firewall for firewall rules
 //   - google_compute_router_nat for NAT gateways
+//   - google_compute_network_peering for VPC peerings
 func (g *Generator) generateNetworking(networking *config.Networking) (string, error) {
 	// Create template context with dependency information
 	ctx := &TemplateContext{
 		Data: networking,
 		Dependencies: &DependencyInfo{
 			RequiresProjectAPIs: true,
+			APIPropagationDelay: g.apiPropagationDelay,
 			ProjectAPIs:         []string{"compute.googleapis.com"},
 			RequiresNetworking:  false, // This IS the networking layer
 		},
+		Module: g.moduleInputs,
 	}
-	
+
 	var output strings.Builder
 	err := g.templates.ExecuteTemplate(&output, "networking.tf", ctx)
 	if err != nil {
@@ -512,53 +1334,60 @@ func (g *Generator) generateNetworking(networking *config.Networking) (string, e
 // generateCompute generates Terraform configuration for compute resources.
 //
 // This includes instance templates, managed instance groups, autoscalers,
-// and individual VM instances. Instance groups automatically reference
-// their templates and include autoscaling policies when specified.
+// individual VM instances, and sole-tenant node templates and groups.
+// Instance groups automatically reference their templates and include
+// autoscaling policies when specified.
 //
 // Generated resources:
 //   - google_compute_instance_template with disks, networking, and metadata
+//   - google_compute_node_template and google_compute_node_group for sole tenancy
 //   - google_compute_instance_group_manager for managed groups
 //   - google_compute_autoscaler for auto-scaling policies
 //   - google_compute_instance for individual VMs
-func (g *Generator) generateCompute(compute *config.Compute) (string, error) {
+func (g *Generator) generateCompute(compute *config.Compute, keyGrants map[string]string) (string, error) {
 	// Collect network dependencies from compute configuration
 	var networkDeps []string
-	
+
 	// Check instance templates for network dependencies
 	for _, template := range compute.InstanceTemplates {
-		for _, netIface := range template.NetworkInterfaces {
-			if netIface.Network != "" {
-				networkDeps = append(networkDeps, fmt.Sprintf("google_compute_network.%s", netIface.Network))
-			}
-			if netIface.Subnetwork != "" {
-				networkDeps = append(networkDeps, fmt.Sprintf("google_compute_subnetwork.%s", netIface.Subnetwork))
-			}
-		}
+		networkDeps = append(networkDeps, interfaceNetworkDeps(template.NetworkInterfaces)...)
 	}
-	
+
 	// Check individual instances for network dependencies
 	for _, instance := range compute.Instances {
-		for _, netIface := range instance.NetworkInterfaces {
-			if netIface.Network != "" {
-				networkDeps = append(networkDeps, fmt.Sprintf("google_compute_network.%s", netIface.Network))
-			}
-			if netIface.Subnetwork != "" {
-				networkDeps = append(networkDeps, fmt.Sprintf("google_compute_subnetwork.%s", netIface.Subnetwork))
-			}
-		}
+		networkDeps = append(networkDeps, interfaceNetworkDeps(instance.NetworkInterfaces)...)
 	}
-	
+
+	networkDeps = sortedUnique(networkDeps)
+
+	// Resolve locations, falling back to the region and zone variables
+	locations := &LocationInfo{
+		InstanceZones:   make(map[string]string),
+		TemplateRegions: make(map[string]string),
+	}
+	for _, instance := range compute.Instances {
+		locations.InstanceZones[instance.Name] = zoneExpression(instance.Zone)
+	}
+	for _, template := range compute.InstanceTemplates {
+		locations.TemplateRegions[template.Name] = regionExpression(template.Region)
+	}
+
 	// Create template context with dependency information
 	ctx := &TemplateContext{
 		Data: compute,
 		Dependencies: &DependencyInfo{
-			RequiresProjectAPIs:     true,
-			ProjectAPIs:            []string{"compute.googleapis.com"},
-			RequiresNetworking:     len(networkDeps) > 0,
-			NetworkDependencies:    networkDeps,
+			RequiresProjectAPIs: true,
+			APIPropagationDelay: g.apiPropagationDelay,
+			ProjectAPIs:         []string{"compute.googleapis.com"},
+			RequiresNetworking:  len(networkDeps) > 0,
+			NetworkDependencies: networkDeps,
+			CryptoKeyGrants:     keyGrants,
 		},
+		Locations:    locations,
+		CommonLabels: g.commonLabels,
+		Module:       g.moduleInputs,
 	}
-	
+
 	var output strings.Builder
 	err := g.templates.ExecuteTemplate(&output, "compute.tf", ctx)
 	if err != nil {
@@ -567,6 +1396,21 @@ func (g *Generator) generateCompute(compute *config.Compute) (string, error) {
 	return output.String(), nil
 }
 
+// interfaceNetworkDeps returns the addresses of the networks and subnetworks
+// that network interfaces attach to
+func interfaceNetworkDeps(interfaces []*config.NetworkInterface) []string {
+	var deps []string
+	for _, netIface := range interfaces {
+		if netIface.Network != "" {
+			deps = append(deps, fmt.Sprintf("google_compute_network.%s", netIface.Network))
+		}
+		if netIface.Subnetwork != "" {
+			deps = append(deps, fmt.Sprintf("google_compute_subnetwork.%s", netIface.Subnetwork))
+		}
+	}
+	return deps
+}
+
 // generateLoadBalancers generates Terraform configuration for load balancers.
 //
 // This creates complete load balancing setups including forwarding rules,
@@ -576,6 +1420,9 @@ func (g *Generator) generateCompute(compute *config.Compute) (string, error) {
 // Generated resources:
 //   - google_compute_global_forwarding_rule for traffic entry points
 //   - google_compute_target_http_proxy for HTTP load balancers
+//   - google_compute_target_https_proxy and a port 443 forwarding rule when
+//     SSL is enabled
+//   - google_compute_managed_ssl_certificate for managed domains
 //   - google_compute_url_map for routing rules
 //   - google_compute_backend_service for backend configuration
 //   - google_compute_health_check for health monitoring
@@ -602,18 +1449,38 @@ func (g *Generator) generateLoadBalancers(lbs []*config.LoadBalancer) (string, e
 //   - google_project_iam_custom_role for custom role definitions
 func (g *Generator) generateIAM(iam *config.Iam) (string, error) {
 	var output strings.Builder
-	
+
+	// Role binding members are plain strings, so bindings that grant roles to
+	// service accounts declared here need explicit dependencies on them
+	accounts := make(map[string]bool)
+	for _, account := range iam.ServiceAccounts {
+		accounts[account.AccountId] = true
+	}
+	saDeps := make(map[int][]string)
+	for i, binding := range iam.RoleBindings {
+		var deps []string
+		for _, member := range binding.Members {
+			if accountId := serviceAccountMemberId(member); accounts[accountId] {
+				deps = append(deps, "google_service_account."+accountId)
+			}
+		}
+		if len(deps) > 0 {
+			saDeps[i] = sortedUnique(deps)
+		}
+	}
+
 	// Create template context with dependencies
 	ctx := &TemplateContext{
 		Data: iam,
 		Dependencies: &DependencyInfo{
-			RequiresProjectAPIs:     false,
-			ProjectAPIs:            []string{},
-			RequiresNetworking:     false,
-			NetworkDependencies:    []string{},
+			RequiresProjectAPIs:        false,
+			ProjectAPIs:                []string{},
+			RequiresNetworking:         false,
+			NetworkDependencies:        []string{},
+			ServiceAccountDependencies: saDeps,
 		},
 	}
-	
+
 	err := g.templates.ExecuteTemplate(&output, "iam.tf", ctx)
 	if err != nil {
 		return "", fmt.Errorf("template execution failed for IAM configuration: %w", err)
@@ -630,20 +1497,23 @@ func (g *Generator) generateIAM(iam *config.Iam) (string, error) {
 //   - google_storage_bucket with location, storage class, and access settings
 //   - Lifecycle rules for automatic storage class transitions and deletion
 //   - Versioning and uniform bucket-level access configuration
-func (g *Generator) generateStorage(storage *config.Storage) (string, error) {
+func (g *Generator) generateStorage(storage *config.Storage, keyGrants map[string]string) (string, error) {
 	var output strings.Builder
-	
+
 	// Create template context with dependencies
 	ctx := &TemplateContext{
 		Data: storage,
 		Dependencies: &DependencyInfo{
-			RequiresProjectAPIs:     false,
-			ProjectAPIs:            []string{},
-			RequiresNetworking:     false,
-			NetworkDependencies:    []string{},
+			RequiresProjectAPIs: false,
+			ProjectAPIs:         []string{},
+			RequiresNetworking:  false,
+			NetworkDependencies: []string{},
+			CryptoKeyGrants:     keyGrants,
 		},
+		CommonLabels: g.commonLabels,
+		Module:       g.moduleInputs,
 	}
-	
+
 	err := g.templates.ExecuteTemplate(&output, "storage.tf", ctx)
 	if err != nil {
 		return "", fmt.Errorf("template execution failed for storage configuration: %w", err)
@@ -700,18 +1570,28 @@ func (g *Generator) generateOutputs(cfg *config.Config) (string, error) {
 //   - google_cloud_run_service for containerized applications
 //   - google_cloud_run_service_iam_member for access control
 //   - google_vpc_access_connector for VPC connectivity
-func (g *Generator) generateCloudRun(cloudRun *config.CloudRun) (string, error) {
+func (g *Generator) generateCloudRun(cloudRun *config.CloudRun, secretManager *config.SecretManager) (string, error) {
+	// Environment variables from secrets of the configuration reference them
+	declaredSecrets := make(map[string]bool)
+	for _, secret := range secretManager.GetSecrets() {
+		declaredSecrets[secret.Name] = true
+	}
+
 	// Create template context with dependency information
 	ctx := &TemplateContext{
 		Data: cloudRun,
 		Dependencies: &DependencyInfo{
-			RequiresProjectAPIs:     true,
-			ProjectAPIs:            []string{"run.googleapis.com", "vpcaccess.googleapis.com"},
-			RequiresNetworking:     false, // Cloud Run doesn't directly depend on networking resources
-			NetworkDependencies:    []string{},
+			RequiresProjectAPIs: true,
+			APIPropagationDelay: g.apiPropagationDelay,
+			ProjectAPIs:         []string{"run.googleapis.com", "vpcaccess.googleapis.com"},
+			RequiresNetworking:  false, // Cloud Run doesn't directly depend on networking resources
+			NetworkDependencies: []string{},
+			DeclaredSecrets:     declaredSecrets,
 		},
+		CommonLabels: g.commonLabels,
+		Module:       g.moduleInputs,
 	}
-	
+
 	var output strings.Builder
 	err := g.templates.ExecuteTemplate(&output, "cloud_run.tf", ctx)
 	if err != nil {
@@ -732,18 +1612,22 @@ func (g *Generator) generateCloudRun(cloudRun *config.CloudRun) (string, error)
 //   - google_sql_user for database users and authentication
 //   - google_spanner_instance for globally distributed databases
 //   - google_spanner_database for Spanner databases with DDL schema
-func (g *Generator) generateDatabases(databases *config.Databases) (string, error) {
+func (g *Generator) generateDatabases(databases *config.Databases, keyGrants map[string]string) (string, error) {
 	// Create template context with dependency information
 	ctx := &TemplateContext{
 		Data: databases,
 		Dependencies: &DependencyInfo{
-			RequiresProjectAPIs:     true,
-			ProjectAPIs:            []string{"sqladmin.googleapis.com", "spanner.googleapis.com"},
-			RequiresNetworking:     false, // Database networking is separate from VPC resources
-			NetworkDependencies:    []string{},
+			RequiresProjectAPIs: true,
+			APIPropagationDelay: g.apiPropagationDelay,
+			ProjectAPIs:         []string{"sqladmin.googleapis.com", "spanner.googleapis.com"},
+			RequiresNetworking:  false, // Database networking is separate from VPC resources
+			NetworkDependencies: []string{},
+			CryptoKeyGrants:     keyGrants,
 		},
+		CommonLabels: g.commonLabels,
+		Module:       g.moduleInputs,
 	}
-	
+
 	var output strings.Builder
 	err := g.templates.ExecuteTemplate(&output, "databases.tf", ctx)
 	if err != nil {
@@ -764,17 +1648,20 @@ func (g *Generator) generateDatabases(databases *config.Databases) (string, erro
 //   - google_secret_manager_secret_version for secret values and versions
 //   - Variables for injecting secret values from environment/GitHub
 func (g *Generator) generateSecretManager(secretManager *config.SecretManager) (string, error) {
-	// Create template context with dependency information  
+	// Create template context with dependency information
 	ctx := &TemplateContext{
 		Data: secretManager,
 		Dependencies: &DependencyInfo{
-			RequiresProjectAPIs:     true,
-			ProjectAPIs:            []string{"secretmanager.googleapis.com"},
-			RequiresNetworking:     false, // Secret Manager doesn't depend on networking resources
-			NetworkDependencies:    []string{},
+			RequiresProjectAPIs: true,
+			APIPropagationDelay: g.apiPropagationDelay,
+			ProjectAPIs:         []string{"secretmanager.googleapis.com"},
+			RequiresNetworking:  false, // Secret Manager doesn't depend on networking resources
+			NetworkDependencies: []string{},
 		},
+		CommonLabels: g.commonLabels,
+		Module:       g.moduleInputs,
 	}
-	
+
 	var output strings.Builder
 	err := g.templates.ExecuteTemplate(&output, "secret_manager.tf", ctx)
 	if err != nil {
@@ -782,3 +1669,536 @@ func (g *Generator) generateSecretManager(secretManager *config.SecretManager) (
 	}
 	return output.String(), nil
 }
+
+// KMSData is the template data for kms.tf. It embeds the KMS configuration and
+// adds the service agent grants derived from CMEK references elsewhere in the config.
+type KMSData struct {
+	*config.Kms
+	// ServiceAgentBindings lists the key grants to generate when GrantServiceAgents is set
+	ServiceAgentBindings []ServiceAgentBinding
+}
+
+// ServiceAgentBinding grants a Google service agent use of a crypto key
+type ServiceAgentBinding struct {
+	// Name is the Terraform resource name for the binding
+	Name string
+	// Service is the service whose agent is granted (storage, compute or sql)
+	Service string
+	// CryptoKey is the name of the crypto key
+	CryptoKey string
+	// Member is the IAM member of the service agent
+	Member string
+}
+
+// cmekServiceAgents maps services that use CMEK to their service agent member
+var cmekServiceAgents = map[string]string{
+	"storage": "serviceAccount:service-${google_project.project.number}@gs-project-accounts.iam.gserviceaccount.com",
+	"compute": "serviceAccount:service-${google_project.project.number}@compute-system.iam.gserviceaccount.com",
+	"sql":     "serviceAccount:service-${google_project.project.number}@gcp-sa-cloud-sql.iam.gserviceaccount.com",
+}
+
+// generateKMS generates Terraform configuration for Cloud KMS resources.
+//
+// This includes key rings and the crypto keys they contain. Crypto keys can be
+// referenced by name from buckets, instance template disks, and Cloud SQL
+// instances for customer-managed encryption (CMEK). When grant_service_agents is
+// set, each service agent that uses a key is granted the encrypter/decrypter role.
+//
+// Generated resources:
+//   - google_kms_key_ring for key containers
+//   - google_kms_crypto_key for keys with rotation and protection settings
+//   - google_kms_crypto_key_iam_member for service agent grants
+func (g *Generator) generateKMS(cfg *config.Config) (string, error) {
+	data := &KMSData{Kms: cfg.Kms}
+	if cfg.Kms.GrantServiceAgents {
+		data.ServiceAgentBindings = collectServiceAgentBindings(cfg)
+	}
+
+	// Create template context with dependency information
+	ctx := &TemplateContext{
+		Data: data,
+		Dependencies: &DependencyInfo{
+			RequiresProjectAPIs: true,
+			APIPropagationDelay: g.apiPropagationDelay,
+			ProjectAPIs:         []string{"cloudkms.googleapis.com"},
+			RequiresNetworking:  false, // KMS doesn't depend on networking resources
+			NetworkDependencies: []string{},
+		},
+		CommonLabels: g.commonLabels,
+		Module:       g.moduleInputs,
+	}
+
+	var output strings.Builder
+	err := g.templates.ExecuteTemplate(&output, "kms.tf", ctx)
+	if err != nil {
+		return "", fmt.Errorf("template execution failed for KMS configuration: %w", err)
+	}
+	return output.String(), nil
+}
+
+// generateMonitoring generates Terraform configuration for Cloud Monitoring resources.
+//
+// This includes notification channels (email, Slack, PagerDuty) and alert
+// policies with threshold conditions that notify those channels, along with
+// log-based metrics that alert policies can reference. Channel credentials are
+// read from sensitive variables declared in variables.tf.
+//
+// Generated resources:
+//   - google_logging_metric for log-based metrics
+//   - google_monitoring_notification_channel for alert destinations
+//   - google_monitoring_alert_policy for threshold-based alerting
+func (g *Generator) generateMonitoring(monitoring *config.Monitoring) (string, error) {
+	// Create template context with dependency information
+	ctx := &TemplateContext{
+		Data: monitoring,
+		Dependencies: &DependencyInfo{
+			RequiresProjectAPIs: true,
+			APIPropagationDelay: g.apiPropagationDelay,
+			ProjectAPIs:         []string{"monitoring.googleapis.com"},
+			RequiresNetworking:  false, // Monitoring doesn't depend on networking resources
+			NetworkDependencies: []string{},
+		},
+		CommonLabels: g.commonLabels,
+		Module:       g.moduleInputs,
+	}
+
+	var output strings.Builder
+	err := g.templates.ExecuteTemplate(&output, "monitoring.tf", ctx)
+	if err != nil {
+		return "", fmt.Errorf("template execution failed for monitoring configuration: %w", err)
+	}
+	return output.String(), nil
+}
+
+// generateLogSinks generates Terraform configuration for log sinks.
+//
+// Each sink exports matching log entries to a Cloud Storage bucket, Pub/Sub
+// topic, or BigQuery dataset, and grants the sink's writer identity the role
+// it needs to write to that destination. External destinations are exported
+// to without a binding since their IAM is managed elsewhere.
+//
+// Generated resources:
+//   - google_logging_project_sink for log exports
+//   - google_storage_bucket_iam_member, google_pubsub_topic_iam_member, or
+//     google_bigquery_dataset_iam_member for the writer identity
+func (g *Generator) generateLogSinks(sinks []*config.LogSink) (string, error) {
+	ctx := &TemplateContext{
+		Data: sinks,
+		Dependencies: &DependencyInfo{
+			RequiresProjectAPIs: true,
+			APIPropagationDelay: g.apiPropagationDelay,
+			ProjectAPIs:         []string{"logging.googleapis.com"},
+			RequiresNetworking:  false, // Log sinks don't depend on networking resources
+			NetworkDependencies: []string{},
+		},
+	}
+
+	var output strings.Builder
+	err := g.templates.ExecuteTemplate(&output, "logging.tf", ctx)
+	if err != nil {
+		return "", fmt.Errorf("template execution failed for logging configuration: %w", err)
+	}
+	return output.String(), nil
+}
+
+// generateResourceTags generates Terraform configuration for Resource Manager tags.
+//
+// Tag keys are created under the project unless another parent is given, and
+// bindings attach tag values to the project or to a named resource. Bindings
+// with a location use the location-scoped binding resource required for
+// regional and zonal resources.
+//
+// Generated resources:
+//   - google_tags_tag_key and google_tags_tag_value for tag definitions
+//   - google_tags_tag_binding for project and global resource bindings
+//   - google_tags_location_tag_binding for location-scoped resource bindings
+func (g *Generator) generateResourceTags(tags *config.ResourceTags) (string, error) {
+	ctx := &TemplateContext{
+		Data: tags,
+		Dependencies: &DependencyInfo{
+			RequiresProjectAPIs: true,
+			APIPropagationDelay: g.apiPropagationDelay,
+			ProjectAPIs:         []string{"cloudresourcemanager.googleapis.com"},
+			RequiresNetworking:  false, // Tags don't depend on networking resources
+			NetworkDependencies: []string{},
+		},
+	}
+
+	var output strings.Builder
+	err := g.templates.ExecuteTemplate(&output, "tags.tf", ctx)
+	if err != nil {
+		return "", fmt.Errorf("template execution failed for resource tags configuration: %w", err)
+	}
+	return output.String(), nil
+}
+
+// generatePubSub generates Terraform configuration for Pub/Sub topics.
+//
+// Generated resources:
+//   - google_pubsub_topic for topics with labels and message retention
+func (g *Generator) generatePubSub(pubsub *config.PubSub) (string, error) {
+	ctx := &TemplateContext{
+		Data: pubsub,
+		Dependencies: &DependencyInfo{
+			RequiresProjectAPIs: true,
+			APIPropagationDelay: g.apiPropagationDelay,
+			ProjectAPIs:         []string{"pubsub.googleapis.com"},
+			RequiresNetworking:  false, // Pub/Sub doesn't depend on networking resources
+			NetworkDependencies: []string{},
+		},
+		CommonLabels: g.commonLabels,
+		Module:       g.moduleInputs,
+	}
+
+	var output strings.Builder
+	err := g.templates.ExecuteTemplate(&output, "pubsub.tf", ctx)
+	if err != nil {
+		return "", fmt.Errorf("template execution failed for Pub/Sub configuration: %w", err)
+	}
+	return output.String(), nil
+}
+
+// generateTasks generates Terraform configuration for Cloud Tasks queues.
+//
+// Generated resources:
+//   - google_cloud_tasks_queue for queues with rate limits and retry configuration
+func (g *Generator) generateTasks(tasks *config.Tasks) (string, error) {
+	ctx := &TemplateContext{
+		Data: tasks,
+		Dependencies: &DependencyInfo{
+			RequiresProjectAPIs: true,
+			APIPropagationDelay: g.apiPropagationDelay,
+			ProjectAPIs:         []string{"cloudtasks.googleapis.com"},
+			RequiresNetworking:  false, // Queues don't depend on networking resources
+			NetworkDependencies: []string{},
+		},
+	}
+
+	var output strings.Builder
+	err := g.templates.ExecuteTemplate(&output, "tasks.tf", ctx)
+	if err != nil {
+		return "", fmt.Errorf("template execution failed for Cloud Tasks configuration: %w", err)
+	}
+	return output.String(), nil
+}
+
+// generateFilestore generates Terraform configuration for Filestore instances.
+//
+// Instances wait for the VPCs they are attached to.
+//
+// Generated resources:
+//   - google_filestore_instance with a file share and network
+func (g *Generator) generateFilestore(filestore *config.Filestore) (string, error) {
+	var networkDeps []string
+	for _, instance := range filestore.Instances {
+		if instance.Network != "" {
+			networkDeps = append(networkDeps, fmt.Sprintf("google_compute_network.%s", instance.Network))
+		}
+	}
+	networkDeps = sortedUnique(networkDeps)
+
+	ctx := &TemplateContext{
+		Data: filestore,
+		Dependencies: &DependencyInfo{
+			RequiresProjectAPIs: true,
+			APIPropagationDelay: g.apiPropagationDelay,
+			ProjectAPIs:         []string{"file.googleapis.com"},
+			RequiresNetworking:  len(networkDeps) > 0,
+			NetworkDependencies: networkDeps,
+		},
+		CommonLabels: g.commonLabels,
+		Module:       g.moduleInputs,
+	}
+
+	var output strings.Builder
+	err := g.templates.ExecuteTemplate(&output, "filestore.tf", ctx)
+	if err != nil {
+		return "", fmt.Errorf("template execution failed for Filestore configuration: %w", err)
+	}
+	return output.String(), nil
+}
+
+// generateGke generates Terraform configuration for GKE clusters.
+//
+// Clusters wait for the VPCs and subnets they run in; node pools reference
+// their cluster and service account directly.
+//
+// Generated resources:
+//   - google_container_cluster with the default node pool removed
+//   - google_container_node_pool for each node pool, with optional autoscaling
+func (g *Generator) generateGke(gke *config.Gke) (string, error) {
+	var networkDeps []string
+	for _, cluster := range gke.Clusters {
+		if cluster.Network != "" {
+			networkDeps = append(networkDeps, fmt.Sprintf("google_compute_network.%s", cluster.Network))
+		}
+		if cluster.Subnetwork != "" {
+			networkDeps = append(networkDeps, fmt.Sprintf("google_compute_subnetwork.%s", cluster.Subnetwork))
+		}
+	}
+	networkDeps = sortedUnique(networkDeps)
+
+	ctx := &TemplateContext{
+		Data: gke,
+		Dependencies: &DependencyInfo{
+			RequiresProjectAPIs: true,
+			APIPropagationDelay: g.apiPropagationDelay,
+			ProjectAPIs:         []string{"container.googleapis.com"},
+			RequiresNetworking:  len(networkDeps) > 0,
+			NetworkDependencies: networkDeps,
+		},
+		CommonLabels: g.commonLabels,
+		Module:       g.moduleInputs,
+	}
+
+	var output strings.Builder
+	err := g.templates.ExecuteTemplate(&output, "gke.tf", ctx)
+	if err != nil {
+		return "", fmt.Errorf("template execution failed for GKE configuration: %w", err)
+	}
+	return output.String(), nil
+}
+
+// generateDns generates Terraform configuration for Cloud DNS.
+//
+// Private zones wait for the VPCs that can resolve them; record sets
+// reference their zone directly.
+//
+// Generated resources:
+//   - google_dns_managed_zone, with private visibility for private zones
+//   - google_dns_record_set for each record set of a zone
+func (g *Generator) generateDns(dns *config.Dns) (string, error) {
+	var networkDeps []string
+	for _, zone := range dns.ManagedZones {
+		for _, network := range zone.Networks {
+			networkDeps = append(networkDeps, fmt.Sprintf("google_compute_network.%s", network))
+		}
+	}
+	networkDeps = sortedUnique(networkDeps)
+
+	ctx := &TemplateContext{
+		Data: dns,
+		Dependencies: &DependencyInfo{
+			RequiresProjectAPIs: true,
+			APIPropagationDelay: g.apiPropagationDelay,
+			ProjectAPIs:         []string{"dns.googleapis.com"},
+			RequiresNetworking:  len(networkDeps) > 0,
+			NetworkDependencies: networkDeps,
+		},
+		CommonLabels: g.commonLabels,
+		Module:       g.moduleInputs,
+	}
+
+	var output strings.Builder
+	err := g.templates.ExecuteTemplate(&output, "dns.tf", ctx)
+	if err != nil {
+		return "", fmt.Errorf("template execution failed for Cloud DNS configuration: %w", err)
+	}
+	return output.String(), nil
+}
+
+// generateBigQuery generates Terraform configuration for BigQuery.
+//
+// Datasets wait for the BigQuery API; tables reference their dataset
+// directly. Table schemas are written as JSON heredocs, from either the
+// structured fields or the schema JSON of the configuration.
+//
+// Generated resources:
+//   - google_bigquery_dataset, with its access entries
+//   - google_bigquery_table for each table, named <dataset>_<table>
+func (g *Generator) generateBigQuery(bigquery *config.BigQuery) (string, error) {
+	ctx := &TemplateContext{
+		Data: bigquery,
+		Dependencies: &DependencyInfo{
+			RequiresProjectAPIs: true,
+			APIPropagationDelay: g.apiPropagationDelay,
+			ProjectAPIs:         []string{"bigquery.googleapis.com"},
+		},
+		CommonLabels: g.commonLabels,
+		Module:       g.moduleInputs,
+	}
+
+	var output strings.Builder
+	err := g.templates.ExecuteTemplate(&output, "bigquery.tf", ctx)
+	if err != nil {
+		return "", fmt.Errorf("template execution failed for BigQuery configuration: %w", err)
+	}
+	return output.String(), nil
+}
+
+// FunctionsData is the template data for functions.tf. It embeds the Cloud
+// Functions configuration and adds the buckets and topics the configuration
+// declares, which functions reference by resource instead of by name.
+type FunctionsData struct {
+	*config.CloudFunctions
+	// Buckets holds the names of the declared storage buckets
+	Buckets map[string]bool
+	// Topics holds the names of the declared Pub/Sub topics
+	Topics map[string]bool
+}
+
+// generateFunctions generates Terraform configuration for 2nd gen Cloud Functions.
+//
+// Functions are built from a source archive in Cloud Storage and triggered by
+// HTTP requests, or by events through Eventarc when they have an event
+// trigger. Functions wait for the Cloud Functions API, and for the Eventarc
+// API when any function has an event trigger.
+//
+// Generated resources:
+//   - google_cloudfunctions2_function
+func (g *Generator) generateFunctions(cfg *config.Config) (string, error) {
+	data := &FunctionsData{CloudFunctions: cfg.CloudFunctions, Buckets: make(map[string]bool), Topics: make(map[string]bool)}
+	for _, bucket := range cfg.GetStorage().GetBuckets() {
+		data.Buckets[bucket.Name] = true
+	}
+	for _, topic := range cfg.GetPubsub().GetTopics() {
+		data.Topics[topic.Name] = true
+	}
+
+	apis := []string{"cloudfunctions.googleapis.com"}
+	for _, function := range cfg.CloudFunctions.Functions {
+		if function.EventTrigger != nil {
+			apis = append(apis, "eventarc.googleapis.com")
+			break
+		}
+	}
+
+	ctx := &TemplateContext{
+		Data: data,
+		Dependencies: &DependencyInfo{
+			RequiresProjectAPIs: true,
+			APIPropagationDelay: g.apiPropagationDelay,
+			ProjectAPIs:         apis,
+		},
+		CommonLabels: g.commonLabels,
+		Module:       g.moduleInputs,
+	}
+
+	var output strings.Builder
+	err := g.templates.ExecuteTemplate(&output, "functions.tf", ctx)
+	if err != nil {
+		return "", fmt.Errorf("template execution failed for Cloud Functions configuration: %w", err)
+	}
+	return output.String(), nil
+}
+
+// generateArtifactRegistry generates Terraform configuration for Artifact Registry.
+//
+// Repositories wait for the Artifact Registry API. Cleanup policy durations
+// are written in the seconds format the API expects.
+//
+// Generated resources:
+//   - google_artifact_registry_repository, with its cleanup policies
+func (g *Generator) generateArtifactRegistry(registry *config.ArtifactRegistry) (string, error) {
+	ctx := &TemplateContext{
+		Data: registry,
+		Dependencies: &DependencyInfo{
+			RequiresProjectAPIs: true,
+			APIPropagationDelay: g.apiPropagationDelay,
+			ProjectAPIs:         []string{"artifactregistry.googleapis.com"},
+		},
+		CommonLabels: g.commonLabels,
+		Module:       g.moduleInputs,
+	}
+
+	var output strings.Builder
+	err := g.templates.ExecuteTemplate(&output, "artifacts.tf", ctx)
+	if err != nil {
+		return "", fmt.Errorf("template execution failed for Artifact Registry configuration: %w", err)
+	}
+	return output.String(), nil
+}
+
+// SchedulerData is the template data for scheduler.tf. It embeds the Cloud
+// Scheduler configuration and adds the topics declared in pubsub.topics, which
+// Pub/Sub targets reference by resource instead of by ID.
+type SchedulerData struct {
+	*config.Scheduler
+	// Topics holds the names of the declared Pub/Sub topics
+	Topics map[string]bool
+}
+
+// generateScheduler generates Terraform configuration for Cloud Scheduler jobs.
+//
+// Jobs invoke an HTTP endpoint (optionally with an OIDC token), publish to a
+// Pub/Sub topic, or call an App Engine handler on a unix-cron schedule.
+//
+// Generated resources:
+//   - google_cloud_scheduler_job for scheduled jobs with retry configuration
+func (g *Generator) generateScheduler(cfg *config.Config) (string, error) {
+	data := &SchedulerData{Scheduler: cfg.Scheduler, Topics: make(map[string]bool)}
+	for _, topic := range cfg.GetPubsub().GetTopics() {
+		data.Topics[topic.Name] = true
+	}
+
+	ctx := &TemplateContext{
+		Data: data,
+		Dependencies: &DependencyInfo{
+			RequiresProjectAPIs: true,
+			APIPropagationDelay: g.apiPropagationDelay,
+			ProjectAPIs:         []string{"cloudscheduler.googleapis.com"},
+			RequiresNetworking:  false, // Scheduler jobs don't depend on networking resources
+			NetworkDependencies: []string{},
+		},
+	}
+
+	var output strings.Builder
+	err := g.templates.ExecuteTemplate(&output, "scheduler.tf", ctx)
+	if err != nil {
+		return "", fmt.Errorf("template execution failed for Cloud Scheduler configuration: %w", err)
+	}
+	return output.String(), nil
+}
+
+// collectServiceAgentBindings returns one binding per crypto key and service
+// that uses it, in configuration order
+func collectServiceAgentBindings(cfg *config.Config) []ServiceAgentBinding {
+	var bindings []ServiceAgentBinding
+	seen := make(map[string]bool)
+	add := func(service, key string) {
+		if key == "" || seen[service+"/"+key] {
+			return
+		}
+		seen[service+"/"+key] = true
+		bindings = append(bindings, ServiceAgentBinding{
+			Name:      fmt.Sprintf("%s_%s_agent", key, service),
+			Service:   service,
+			CryptoKey: key,
+			Member:    cmekServiceAgents[service],
+		})
+	}
+
+	if cfg.Storage != nil {
+		for _, bucket := range cfg.Storage.Buckets {
+			add("storage", bucket.KmsKey)
+		}
+	}
+	if cfg.Compute != nil {
+		for _, template := range cfg.Compute.InstanceTemplates {
+			add("compute", template.KmsKey)
+		}
+	}
+	if cfg.Databases != nil {
+		for _, instance := range cfg.Databases.CloudSqlInstances {
+			add("sql", instance.KmsKey)
+		}
+	}
+
+	return bindings
+}
+
+// cryptoKeyGrants returns the addresses of the service agent grants generated
+// in kms.tf for service, by crypto key name. It is nil unless
+// grant_service_agents is set.
+func cryptoKeyGrants(cfg *config.Config, service string) map[string]string {
+	if !cfg.GetKms().GetGrantServiceAgents() {
+		return nil
+	}
+	grants := make(map[string]string)
+	for _, binding := range collectServiceAgentBindings(cfg) {
+		if binding.Service == service {
+			grants[binding.CryptoKey] = "google_kms_crypto_key_iam_member." + binding.Name
+		}
+	}
+	return grants
+}
@@ -1,9 +1,20 @@
 package generator
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"custoodian/pkg/config"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 func TestNew(t *testing.T) {
@@ -52,3 +63,2665 @@ func TestGenerate(t *testing.T) {
 		t.Error("Expected variables.tf to be generated")
 	}
 }
+
+func TestGenerateOutputFormat(t *testing.T) {
+	cfg := &config.Config{
+		Project: &config.Project{
+			Id:   "test-project-123",
+			Name: "Test Project",
+		},
+	}
+
+	tests := []struct {
+		format string
+		source string
+	}{
+		{"", `source  = "hashicorp/google"`},
+		{OutputFormatTerraform, `source  = "hashicorp/google"`},
+		{OutputFormatOpenTofu, `source  = "registry.opentofu.org/hashicorp/google"`},
+	}
+
+	for _, test := range tests {
+		gen, err := NewWithOptions("builtin", &NewOptions{OutputFormat: test.format})
+		if err != nil {
+			t.Fatalf("Failed to create generator for format %q: %v", test.format, err)
+		}
+
+		files, err := gen.Generate(cfg)
+		if err != nil {
+			t.Fatalf("Expected no error generating for format %q, got: %v", test.format, err)
+		}
+
+		if !strings.Contains(files["project.tf"], test.source) {
+			t.Errorf("Expected project.tf for format %q to contain %q", test.format, test.source)
+		}
+	}
+
+	// Unknown formats are rejected
+	if _, err := NewWithOptions("builtin", &NewOptions{OutputFormat: "pulumi"}); err == nil {
+		t.Error("Expected error for unsupported output format, got nil")
+	}
+}
+
+func TestGenerateMonitoring(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project: &config.Project{
+			Id:   "test-project-123",
+			Name: "Test Project",
+		},
+		Monitoring: &config.Monitoring{
+			NotificationChannels: []*config.NotificationChannel{
+				{Name: "pager", Type: "pagerduty"},
+			},
+			AlertPolicies: []*config.AlertPolicy{{
+				Name: "high-cpu",
+				Conditions: []*config.AlertCondition{{
+					DisplayName:    "CPU above 80%",
+					Filter:         `metric.type="compute.googleapis.com/instance/cpu/utilization"`,
+					Comparison:     "COMPARISON_GT",
+					ThresholdValue: 0.8,
+				}},
+				NotificationChannels: []string{"pager"},
+			}},
+		},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	monitoring := files["monitoring.tf"]
+	for _, want := range []string{
+		`filter          = "metric.type=\"compute.googleapis.com/instance/cpu/utilization\""`,
+		`google_monitoring_notification_channel.pager.name`,
+		`service_key = var.notification_pager_service_key`,
+	} {
+		if !strings.Contains(monitoring, want) {
+			t.Errorf("Expected monitoring.tf to contain %q", want)
+		}
+	}
+
+	if !strings.Contains(files["variables.tf"], `variable "notification_pager_service_key"`) {
+		t.Error("Expected variables.tf to declare the PagerDuty service key variable")
+	}
+}
+
+func TestGenerateScheduler(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project", Apis: []config.GcpApi{config.GcpApi_GCP_API_CLOUD_SCHEDULER}},
+		Pubsub: &config.PubSub{
+			Topics: []*config.PubSubTopic{{Name: "nightly-jobs", MessageRetentionDuration: "7d"}},
+		},
+		Scheduler: &config.Scheduler{
+			Jobs: []*config.SchedulerJob{
+				{
+					Name:     "nightly-export",
+					Schedule: "30 2 * * MON-FRI",
+					TimeZone: "America/New_York",
+					Target: &config.SchedulerJob_PubsubTarget{PubsubTarget: &config.SchedulerPubSubTarget{
+						Topic: "nightly-jobs",
+						Data:  "export",
+					}},
+				},
+				{
+					Name:     "external-topic",
+					Schedule: "0 * * * *",
+					Target: &config.SchedulerJob_PubsubTarget{PubsubTarget: &config.SchedulerPubSubTarget{
+						Topic: "projects/shared/topics/events",
+						Data:  "tick",
+					}},
+				},
+				{
+					Name:     "refresh-cache",
+					Schedule: "*/15 * * * *",
+					Target: &config.SchedulerJob_HttpTarget{HttpTarget: &config.SchedulerHttpTarget{
+						Uri:                 "https://api.example.com/refresh",
+						ServiceAccountEmail: "scheduler@test-project-123.iam.gserviceaccount.com",
+					}},
+				},
+			},
+		},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	if !strings.Contains(files["pubsub.tf"], `message_retention_duration = "604800s"`) {
+		t.Errorf("Expected topic retention in seconds, got:\n%s", files["pubsub.tf"])
+	}
+
+	scheduler := files["scheduler.tf"]
+	for _, want := range []string{
+		`time_zone = "America/New_York"`,
+		`topic_name = google_pubsub_topic.nightly-jobs.id`,
+		`data       = base64encode("export")`,
+		`topic_name = "projects/shared/topics/events"`,
+		`time_zone = "Etc/UTC"`,
+		`http_method = "POST"`,
+		`audience              = "https://api.example.com/refresh"`,
+	} {
+		if !strings.Contains(scheduler, want) {
+			t.Errorf("Expected scheduler.tf to contain %q, got:\n%s", want, scheduler)
+		}
+	}
+	if !strings.Contains(files["project.tf"], `service = "cloudscheduler.googleapis.com"`) {
+		t.Errorf("Expected the Cloud Scheduler API to be enabled, got:\n%s", files["project.tf"])
+	}
+}
+
+func TestGenerateTasks(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project", Apis: []config.GcpApi{config.GcpApi_GCP_API_CLOUD_TASKS}},
+		Tasks: &config.Tasks{
+			Queues: []*config.TaskQueue{{
+				Name:        "image-resize",
+				Location:    config.Region_REGION_US_EAST1,
+				RateLimits:  &config.TaskQueueRateLimits{MaxDispatchesPerSecond: 2.5},
+				RetryConfig: &config.TaskQueueRetryConfig{MaxAttempts: 5, MaxBackoff: "1h"},
+			}},
+		},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	tasks := files["tasks.tf"]
+	for _, want := range []string{
+		`resource "google_cloud_tasks_queue" "image-resize"`,
+		`location = "us-east1"`,
+		`max_dispatches_per_second = 2.5`,
+		`max_attempts = 5`,
+		`max_backoff  = "3600s"`,
+	} {
+		if !strings.Contains(tasks, want) {
+			t.Errorf("Expected tasks.tf to contain %q, got:\n%s", want, tasks)
+		}
+	}
+	if strings.Contains(tasks, "max_concurrent_dispatches") {
+		t.Error("Expected unset max_concurrent_dispatches to be omitted")
+	}
+	if !strings.Contains(files["project.tf"], `service = "cloudtasks.googleapis.com"`) {
+		t.Errorf("Expected the Cloud Tasks API to be enabled, got:\n%s", files["project.tf"])
+	}
+}
+
+func TestGenerateFilestore(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project", Apis: []config.GcpApi{config.GcpApi_GCP_API_FILESTORE}},
+		Filestore: &config.Filestore{
+			Instances: []*config.FilestoreInstance{{
+				Name:      "shared-nfs",
+				Tier:      "BASIC_HDD",
+				Zone:      config.Zone_ZONE_US_CENTRAL1_A,
+				FileShare: &config.FilestoreFileShare{Name: "share1", CapacityGb: 1024},
+				Network:   "main-vpc",
+			}},
+		},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	filestore := files["filestore.tf"]
+	for _, want := range []string{
+		`resource "google_filestore_instance" "shared-nfs"`,
+		`location = "us-central1-a"`,
+		`tier     = "BASIC_HDD"`,
+		`capacity_gb = 1024`,
+		`network = google_compute_network.main-vpc.name`,
+		`google_compute_network.main-vpc,`,
+	} {
+		if !strings.Contains(filestore, want) {
+			t.Errorf("Expected filestore.tf to contain %q, got:\n%s", want, filestore)
+		}
+	}
+	if !strings.Contains(files["project.tf"], `service = "file.googleapis.com"`) {
+		t.Errorf("Expected the Filestore API to be enabled, got:\n%s", files["project.tf"])
+	}
+}
+
+func TestGenerateKMS(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Kms: &config.Kms{KeyRings: []*config.KmsKeyRing{{
+			Name:     "app-keys",
+			Location: "us-central1",
+			CryptoKeys: []*config.KmsCryptoKey{
+				{Name: "data", RotationPeriod: "90d", ProtectionLevel: "HSM"},
+				{Name: "sign", Purpose: "ASYMMETRIC_SIGN", Algorithm: "EC_SIGN_P256_SHA256"},
+			},
+		}}},
+		Storage: &config.Storage{Buckets: []*config.StorageBucket{{Name: "assets", Location: "US", KmsKey: "data"}}},
+		Compute: &config.Compute{InstanceTemplates: []*config.InstanceTemplate{{
+			Name:        "web",
+			MachineType: config.MachineType_MACHINE_TYPE_E2_MEDIUM,
+			Image:       "debian-cloud/debian-12",
+			KmsKey:      "data",
+		}}},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	kms := files["kms.tf"]
+	for _, want := range []string{
+		`resource "google_kms_key_ring" "app-keys" {
+  name     = "app-keys"
+  location = "us-central1"`,
+		`resource "google_kms_crypto_key" "data" {
+  name            = "data"
+  key_ring        = google_kms_key_ring.app-keys.id
+  rotation_period = "7776000s"
+  version_template {
+    algorithm        = "GOOGLE_SYMMETRIC_ENCRYPTION"
+    protection_level = "HSM"
+  }`,
+		`purpose  = "ASYMMETRIC_SIGN"`,
+		`algorithm = "EC_SIGN_P256_SHA256"`,
+		`prevent_destroy = true`,
+	} {
+		if !strings.Contains(kms, want) {
+			t.Errorf("Expected kms.tf to contain %q, got:\n%s", want, kms)
+		}
+	}
+
+	if want := `encryption {
+    default_kms_key_name = google_kms_crypto_key.data.id
+  }`; !strings.Contains(files["storage.tf"], want) {
+		t.Errorf("Expected storage.tf to contain %q, got:\n%s", want, files["storage.tf"])
+	}
+	if want := `disk_encryption_key {
+      kms_key_self_link = google_kms_crypto_key.data.id
+    }`; !strings.Contains(files["compute.tf"], want) {
+		t.Errorf("Expected compute.tf to contain %q, got:\n%s", want, files["compute.tf"])
+	}
+}
+
+func TestGenerateKMSServiceAgents(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Kms: &config.Kms{
+			GrantServiceAgents: true,
+			KeyRings: []*config.KmsKeyRing{{
+				Name:       "app-keys",
+				Location:   "us-central1",
+				CryptoKeys: []*config.KmsCryptoKey{{Name: "data"}},
+			}},
+		},
+		Storage: &config.Storage{Buckets: []*config.StorageBucket{
+			{Name: "assets", Location: "US", KmsKey: "data"},
+			{Name: "logs", Location: "US"},
+		}},
+		Compute: &config.Compute{InstanceTemplates: []*config.InstanceTemplate{{
+			Name:        "web",
+			MachineType: config.MachineType_MACHINE_TYPE_E2_MEDIUM,
+			Image:       "debian-cloud/debian-12",
+			KmsKey:      "data",
+		}}},
+		Databases: &config.Databases{CloudSqlInstances: []*config.CloudSqlInstance{{
+			Name:            "main-db",
+			DatabaseVersion: "POSTGRES_15",
+			Region:          config.Region_REGION_US_CENTRAL1,
+			Tier:            "db-f1-micro",
+			KmsKey:          "data",
+		}}},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	for _, want := range []string{
+		`resource "google_kms_crypto_key_iam_member" "data_storage_agent"`,
+		`resource "google_kms_crypto_key_iam_member" "data_compute_agent"`,
+		`resource "google_kms_crypto_key_iam_member" "data_sql_agent"`,
+	} {
+		if !strings.Contains(files["kms.tf"], want) {
+			t.Errorf("Expected kms.tf to contain %q, got:\n%s", want, files["kms.tf"])
+		}
+	}
+
+	// Each resource encrypted with the key waits for its service agent's grant
+	for file, want := range map[string]string{
+		"storage.tf":   "google_kms_crypto_key_iam_member.data_storage_agent",
+		"compute.tf":   "google_kms_crypto_key_iam_member.data_compute_agent",
+		"databases.tf": "google_kms_crypto_key_iam_member.data_sql_agent",
+	} {
+		_, depends, _ := strings.Cut(files[file], "depends_on")
+		if !strings.Contains(depends, want) {
+			t.Errorf("Expected %s to depend on %s, got:\n%s", file, want, files[file])
+		}
+	}
+	if strings.Count(files["storage.tf"], "depends_on") != 1 {
+		t.Errorf("Expected only the encrypted bucket to depend on the grant, got:\n%s", files["storage.tf"])
+	}
+
+	cfg.Kms.GrantServiceAgents = false
+	files, err = gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+	for _, file := range []string{"kms.tf", "storage.tf", "compute.tf", "databases.tf"} {
+		if strings.Contains(files[file], "google_kms_crypto_key_iam_member") {
+			t.Errorf("Expected no service agent grants without grant_service_agents, got in %s:\n%s", file, files[file])
+		}
+	}
+}
+
+func TestGenerateDns(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Dns: &config.Dns{
+			ManagedZones: []*config.DnsManagedZone{
+				{
+					Name:    "public",
+					DnsName: "example.com.",
+					RecordSets: []*config.DnsRecordSet{
+						{Name: "@", Type: "TXT", Rrdatas: []string{`"v=spf1 -all"`}},
+						{Name: "*.api", Type: "CNAME", Ttl: 60, Rrdatas: []string{"api.example.com."}},
+					},
+				},
+				{
+					Name:       "internal",
+					DnsName:    "internal.example.com.",
+					Visibility: "private",
+					Networks:   []string{"main-vpc"},
+					RecordSets: []*config.DnsRecordSet{{Name: "db", Type: "A", Rrdatas: []string{"10.0.1.5"}}},
+				},
+			},
+		},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	dns := files["dns.tf"]
+	for _, want := range []string{
+		`resource "google_dns_managed_zone" "public"`,
+		`dns_name = "example.com."`,
+		`resource "google_dns_record_set" "public_apex_txt"`,
+		`rrdatas = [
+    "\"v=spf1 -all\"",
+  ]`,
+		`resource "google_dns_record_set" "public_wildcard_api_cname"`,
+		`name         = "*.api.example.com."`,
+		`ttl          = 60`,
+		`visibility = "private"`,
+		`network_url = google_compute_network.main-vpc.id`,
+		`google_compute_network.main-vpc,
+    google_project_service.api_0`,
+		`resource "google_dns_record_set" "internal_db_a"`,
+		`managed_zone = google_dns_managed_zone.internal.name`,
+		`ttl          = 300`,
+	} {
+		if !strings.Contains(dns, want) {
+			t.Errorf("Expected dns.tf to contain %q, got:\n%s", want, dns)
+		}
+	}
+}
+
+func TestGenerateBigQuery(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	protect := false
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Bigquery: &config.BigQuery{
+			Datasets: []*config.BigQueryDataset{{
+				DatasetId:              "analytics",
+				Location:               "EU",
+				DefaultTableExpiration: "30d",
+				Access: []*config.BigQueryAccess{
+					{Role: "OWNER", SpecialGroup: "projectOwners"},
+					{Role: "READER", GroupByEmail: "analysts@example.com"},
+				},
+			}},
+			Tables: []*config.BigQueryTable{
+				{
+					TableId: "events",
+					Dataset: "analytics",
+					Fields: []*config.BigQueryField{
+						{Name: "id", Type: "STRING", Mode: "REQUIRED"},
+						{Name: "created_at", Type: "TIMESTAMP", Description: "Costs ${cost}"},
+					},
+					TimePartitioning:   &config.BigQueryTimePartitioning{Type: "DAY", Field: "created_at", Expiration: "90d"},
+					Clustering:         []string{"id"},
+					DeletionProtection: &protect,
+				},
+				{TableId: "raw", Dataset: "analytics", SchemaJson: `[{"name":"line","type":"STRING"}]`},
+			},
+		},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	bigquery := files["bigquery.tf"]
+	for _, want := range []string{
+		`resource "google_bigquery_dataset" "analytics"`,
+		`default_table_expiration_ms = 2592000000`,
+		`special_group = "projectOwners"`,
+		`group_by_email = "analysts@example.com"`,
+		`resource "google_bigquery_table" "analytics_events"`,
+		`dataset_id          = google_bigquery_dataset.analytics.dataset_id`,
+		`deletion_protection = false`,
+		`"mode": "REQUIRED"`,
+		`"mode": "NULLABLE"`,
+		`"description": "Costs $${cost}"`,
+		`expiration_ms = 7776000000`,
+		`resource "google_bigquery_table" "analytics_raw"`,
+		`"name": "line"`,
+	} {
+		if !strings.Contains(bigquery, want) {
+			t.Errorf("Expected bigquery.tf to contain %q, got:\n%s", want, bigquery)
+		}
+	}
+	if strings.Count(bigquery, "deletion_protection") != 1 {
+		t.Errorf("Expected only the events table to set deletion_protection, got:\n%s", bigquery)
+	}
+
+	// Tables depend on their dataset
+	graph, err := DependencyGraph(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error building the graph, got: %v", err)
+	}
+	found := false
+	for _, edge := range graph.Edges {
+		if edge.From == "google_bigquery_table.analytics_events" && edge.To == "google_bigquery_dataset.analytics" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected an edge from the events table to its dataset, got %+v", graph.Edges)
+	}
+}
+
+func TestGenerateFunctions(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project", Apis: []config.GcpApi{config.GcpApi_GCP_API_CLOUD_FUNCTIONS, config.GcpApi_GCP_API_EVENTARC}},
+		Iam:     &config.Iam{ServiceAccounts: []*config.ServiceAccount{{AccountId: "fn-runner"}}},
+		Storage: &config.Storage{Buckets: []*config.StorageBucket{{Name: "test-project-123-source", Location: "US"}}},
+		Pubsub:  &config.PubSub{Topics: []*config.PubSubTopic{{Name: "events"}}},
+		CloudFunctions: &config.CloudFunctions{Functions: []*config.CloudFunction{
+			{
+				Name:           "hello",
+				Region:         config.Region_REGION_EUROPE_WEST1,
+				Runtime:        "python312",
+				EntryPoint:     "handler",
+				SourceBucket:   "test-project-123-source",
+				SourceObject:   "hello.zip",
+				EnvVars:        map[string]string{"LOG_LEVEL": "info"},
+				ServiceAccount: "fn-runner@test-project-123.iam.gserviceaccount.com",
+				MaxInstances:   5,
+			},
+			{
+				Name:         "on-event",
+				Runtime:      "nodejs20",
+				EntryPoint:   "onEvent",
+				SourceBucket: "shared-artifacts",
+				SourceObject: "on-event.zip",
+				EventTrigger: &config.CloudFunctionEventTrigger{PubsubTopic: "events", Retry: true},
+			},
+		}},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	functions := files["functions.tf"]
+	for _, want := range []string{
+		`resource "google_cloudfunctions2_function" "hello"`,
+		`location = "europe-west1"`,
+		`runtime     = "python312"`,
+		`bucket = google_storage_bucket.test-project-123-source.name`,
+		`"LOG_LEVEL" = "info"`,
+		`service_account_email = "fn-runner@test-project-123.iam.gserviceaccount.com"`,
+		`max_instance_count    = 5`,
+		`resource "google_cloudfunctions2_function" "on-event"`,
+		`location = var.region`,
+		`bucket = "shared-artifacts"`,
+		`event_type     = "google.cloud.pubsub.topic.v1.messagePublished"`,
+		`pubsub_topic   = google_pubsub_topic.events.id`,
+		`retry_policy   = "RETRY_POLICY_RETRY"`,
+		`google_project_service.api_1`,
+	} {
+		if !strings.Contains(functions, want) {
+			t.Errorf("Expected functions.tf to contain %q, got:\n%s", want, functions)
+		}
+	}
+	if strings.Count(functions, "service_config") != 1 {
+		t.Errorf("Expected only the hello function to have a service_config block, got:\n%s", functions)
+	}
+
+	// HTTP-only functions don't wait for the Eventarc API
+	cfg.CloudFunctions.Functions = cfg.CloudFunctions.Functions[:1]
+	files, err = gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+	if functions := files["functions.tf"]; strings.Contains(functions, "api_1") || strings.Contains(functions, "event_trigger") {
+		t.Errorf("Expected an HTTP function to depend on the Cloud Functions API only, got:\n%s", functions)
+	}
+}
+
+func TestGenerateArtifactRegistry(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project", Apis: []config.GcpApi{config.GcpApi_GCP_API_ARTIFACT_REGISTRY}},
+		ArtifactRegistry: &config.ArtifactRegistry{Repositories: []*config.ArtifactRepository{
+			{
+				RepositoryId: "containers",
+				Format:       config.ArtifactFormat_ARTIFACT_FORMAT_DOCKER,
+				Location:     "us-central1",
+				CleanupPolicies: []*config.ArtifactCleanupPolicy{
+					{Id: "delete-untagged", Action: "DELETE", TagState: "UNTAGGED", OlderThan: "30d"},
+					{Id: "keep-recent", Action: "KEEP", KeepCount: 10},
+				},
+			},
+			{RepositoryId: "libraries", Format: config.ArtifactFormat_ARTIFACT_FORMAT_MAVEN},
+		}},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	artifacts := files["artifacts.tf"]
+	for _, want := range []string{
+		`resource "google_artifact_registry_repository" "containers"`,
+		`format        = "DOCKER"`,
+		`location      = "us-central1"`,
+		`tag_state  = "UNTAGGED"`,
+		`older_than = "2592000s"`,
+		`keep_count = 10`,
+		`resource "google_artifact_registry_repository" "libraries"`,
+		`format        = "MAVEN"`,
+		`location      = var.region`,
+		`google_project_service.api_0`,
+	} {
+		if !strings.Contains(artifacts, want) {
+			t.Errorf("Expected artifacts.tf to contain %q, got:\n%s", want, artifacts)
+		}
+	}
+	if strings.Count(artifacts, "cleanup_policies {") != 2 {
+		t.Errorf("Expected two cleanup policies, got:\n%s", artifacts)
+	}
+}
+
+func TestGenerateVpcPeerings(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Networking: &config.Networking{
+			Vpcs: []*config.Vpc{{Name: "main-vpc"}, {Name: "data-vpc"}},
+			Peerings: []*config.VpcPeering{
+				{Name: "main-to-data", Network: "main-vpc", PeerNetwork: "data-vpc", ExportCustomRoutes: true},
+				{Name: "main-to-shared", Network: "main-vpc", PeerNetwork: "projects/shared-project/global/networks/shared-vpc", ImportCustomRoutes: true},
+			},
+		},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	// Formatting aligns the attributes, so whitespace is collapsed
+	networking := strings.Join(strings.Fields(files["networking.tf"]), " ")
+	for _, want := range []string{
+		`resource "google_compute_network_peering" "main-to-data" { name = "main-to-data" network = google_compute_network.main-vpc.self_link peer_network = google_compute_network.data-vpc.self_link export_custom_routes = true }`,
+		`resource "google_compute_network_peering" "main-to-shared" { name = "main-to-shared" network = google_compute_network.main-vpc.self_link peer_network = "projects/shared-project/global/networks/shared-vpc" import_custom_routes = true }`,
+	} {
+		if !strings.Contains(networking, want) {
+			t.Errorf("Expected networking.tf to contain %q, got:\n%s", want, files["networking.tf"])
+		}
+	}
+}
+
+func TestGenerateCloudRunSecretEnv(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project:       &config.Project{Id: "test-project-123", Name: "Test Project"},
+		SecretManager: &config.SecretManager{Secrets: []*config.Secret{{Name: "db-url"}}},
+		CloudRun: &config.CloudRun{Services: []*config.CloudRunService{{
+			Name:     "api",
+			Location: config.Region_REGION_US_CENTRAL1,
+			Image:    "gcr.io/test-project-123/api:latest",
+			Config: &config.CloudRunServiceConfig{EnvFromSecrets: []*config.CloudRunEnvFromSecret{
+				{Name: "DATABASE_URL", SecretName: "db-url"},
+				{Name: "API_KEY", SecretName: "api-key", Version: "3"},
+			}},
+		}}},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	// Declared secrets are referenced, so they are created first
+	cloudRun := strings.Join(strings.Fields(files["cloud_run.tf"]), " ")
+	for _, want := range []string{
+		`env { name = "DATABASE_URL" value_from { secret_key_ref { name = google_secret_manager_secret.db-url.secret_id key = "latest" } } }`,
+		`env { name = "API_KEY" value_from { secret_key_ref { name = "api-key" key = "3" } } }`,
+	} {
+		if !strings.Contains(cloudRun, want) {
+			t.Errorf("Expected cloud_run.tf to contain %q, got:\n%s", want, files["cloud_run.tf"])
+		}
+	}
+}
+
+func TestGenerateCloudRunVpcAccess(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		CloudRun: &config.CloudRun{
+			VpcConnectors: []*config.CloudRunVpcConnector{{Name: "conn", Region: config.Region_REGION_US_CENTRAL1, Network: "main-vpc", IpCidrRange: "10.8.0.0/28"}},
+			Services: []*config.CloudRunService{
+				{
+					Name:     "api",
+					Location: config.Region_REGION_US_CENTRAL1,
+					Image:    "gcr.io/test-project-123/api:latest",
+					Config: &config.CloudRunServiceConfig{VpcAccess: &config.CloudRunVpcAccess{
+						Connector: "conn",
+						Egress:    config.VpcEgress_VPC_EGRESS_ALL_TRAFFIC,
+					}},
+				},
+				{
+					Name:     "worker",
+					Location: config.Region_REGION_US_CENTRAL1,
+					Image:    "gcr.io/test-project-123/worker:latest",
+					Config:   &config.CloudRunServiceConfig{VpcAccess: &config.CloudRunVpcAccess{Connector: "shared-conn"}},
+				},
+			},
+		},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	// Declared connectors are referenced, external ones are used by name
+	cloudRun := strings.Join(strings.Fields(files["cloud_run.tf"]), " ")
+	for _, want := range []string{
+		`"run.googleapis.com/vpc-access-connector" = google_vpc_access_connector.conn.id "run.googleapis.com/vpc-access-egress" = "all-traffic"`,
+		`"run.googleapis.com/vpc-access-connector" = "shared-conn"`,
+		`resource "google_vpc_access_connector" "conn" { name = "conn" region = "us-central1"`,
+	} {
+		if !strings.Contains(cloudRun, want) {
+			t.Errorf("Expected cloud_run.tf to contain %q, got:\n%s", want, files["cloud_run.tf"])
+		}
+	}
+	if strings.Count(cloudRun, "vpc-access-egress") != 1 {
+		t.Errorf("Expected only the api service to set an egress, got:\n%s", files["cloud_run.tf"])
+	}
+}
+
+func TestGenerateOutputFileNames(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project:    &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Networking: &config.Networking{Vpcs: []*config.Vpc{{Name: "main-vpc"}}},
+		Output:     &config.Output{Numbered: true, FileMap: map[string]string{"networking.tf": "network.tf"}},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+	for _, name := range []string{"00-project.tf", "network.tf", "23-variables.tf", "24-outputs.tf"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("Expected %s to be generated", name)
+		}
+	}
+	for _, name := range []string{"project.tf", "networking.tf", "02-networking.tf"} {
+		if _, ok := files[name]; ok {
+			t.Errorf("Expected %s not to be generated", name)
+		}
+	}
+	if !strings.Contains(files["network.tf"], `resource "google_compute_network" "main-vpc"`) {
+		t.Errorf("Expected network.tf to contain the VPC, got:\n%s", files["network.tf"])
+	}
+
+	cfg.Output = &config.Output{FileMap: map[string]string{"networking.tf": "iam.tf"}}
+	if _, err := gen.Generate(cfg); err == nil || !strings.Contains(err.Error(), "would both be written to iam.tf") {
+		t.Errorf("Expected an error for colliding file names, got: %v", err)
+	}
+}
+
+func TestGenerateHealthChecks(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		LoadBalancers: []*config.LoadBalancer{
+			{Name: "web-lb", Backend: "web-group", HealthCheck: &config.HealthCheck{Name: "web-hc", Port: 80, RequestPath: "/health"}},
+			{Name: "tcp-lb", Backend: "tcp-group", HealthCheck: &config.HealthCheck{Name: "tcp-hc", Type: "TCP", Port: 5432, ProxyHeader: "PROXY_V1"}},
+		},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	// Formatting aligns the attributes, so whitespace is collapsed
+	lbs := strings.Join(strings.Fields(files["load_balancers.tf"]), " ")
+	for _, want := range []string{
+		`resource "google_compute_health_check" "web-hc" { name = "web-hc" http_health_check { port = 80 request_path = "/health" } }`,
+		`resource "google_compute_health_check" "tcp-hc" { name = "tcp-hc" tcp_health_check { port = 5432 proxy_header = "PROXY_V1" } }`,
+	} {
+		if !strings.Contains(lbs, want) {
+			t.Errorf("Expected load_balancers.tf to contain %q, got:\n%s", want, files["load_balancers.tf"])
+		}
+	}
+}
+
+func TestGenerateHttpsLoadBalancers(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		LoadBalancers: []*config.LoadBalancer{
+			{Name: "web-lb", Backend: "web-group", SslEnabled: true, ManagedDomains: []string{"example.com", "www.example.com"}},
+			{Name: "api-lb", Backend: "api-group", SslEnabled: true, SslCertificate: "api-cert"},
+			{Name: "plain-lb", Backend: "plain-group"},
+		},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	// Formatting aligns the attributes, so whitespace is collapsed
+	lbs := strings.Join(strings.Fields(files["load_balancers.tf"]), " ")
+	for _, want := range []string{
+		`resource "google_compute_global_forwarding_rule" "web-lb_https" { name = "web-lb-https" target = google_compute_target_https_proxy.web-lb.id port_range = "443" }`,
+		`ssl_certificates = [google_compute_managed_ssl_certificate.web-lb.id]`,
+		`resource "google_compute_managed_ssl_certificate" "web-lb" { name = "web-lb-cert" managed { domains = [ "example.com", "www.example.com", ] } }`,
+		`ssl_certificates = ["api-cert"]`,
+	} {
+		if !strings.Contains(lbs, want) {
+			t.Errorf("Expected load_balancers.tf to contain %q, got:\n%s", want, files["load_balancers.tf"])
+		}
+	}
+	for _, unwanted := range []string{`"plain-lb_https"`, `google_compute_managed_ssl_certificate" "api-lb"`} {
+		if strings.Contains(lbs, unwanted) {
+			t.Errorf("Expected load_balancers.tf not to contain %q, got:\n%s", unwanted, files["load_balancers.tf"])
+		}
+	}
+}
+
+func TestGenerateSqlReplicas(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Databases: &config.Databases{CloudSqlInstances: []*config.CloudSqlInstance{
+			{Name: "main-db", DatabaseVersion: "POSTGRES_15", Region: config.Region_REGION_US_EAST1, Tier: "db-custom-2-7680"},
+			{Name: "main-db-replica", DatabaseVersion: "POSTGRES_15", Tier: "db-custom-2-7680", MasterInstanceName: "main-db"},
+			{Name: "main-db-west", DatabaseVersion: "POSTGRES_15", Region: config.Region_REGION_US_WEST1, Tier: "db-custom-2-7680", MasterInstanceName: "main-db"},
+		}},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	databases := strings.Join(strings.Fields(files["databases.tf"]), " ")
+	for _, want := range []string{
+		`resource "google_sql_database_instance" "main-db-replica" { name = "main-db-replica" database_version = "POSTGRES_15" region = google_sql_database_instance.main-db.region master_instance_name = google_sql_database_instance.main-db.name`,
+		`region = "us-west1" master_instance_name = google_sql_database_instance.main-db.name`,
+	} {
+		if !strings.Contains(databases, want) {
+			t.Errorf("Expected databases.tf to contain %q, got:\n%s", want, files["databases.tf"])
+		}
+	}
+	if strings.Count(databases, "master_instance_name") != 2 {
+		t.Errorf("Expected only the replicas to set master_instance_name, got:\n%s", files["databases.tf"])
+	}
+}
+
+func TestGenerateGke(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Gke: &config.Gke{
+			Clusters: []*config.GkeCluster{{
+				Name:                   "main",
+				Zone:                   config.Zone_ZONE_US_CENTRAL1_A,
+				Network:                "gke-vpc",
+				Subnetwork:             "gke-subnet",
+				PodsSecondaryRange:     "pods",
+				ServicesSecondaryRange: "services",
+				ReleaseChannel:         "REGULAR",
+				WorkloadIdentity:       true,
+				NodePools: []*config.GkeNodePool{
+					{Name: "default", MachineType: config.MachineType_MACHINE_TYPE_E2_STANDARD_4, MinNodeCount: 1, MaxNodeCount: 3, ServiceAccount: "gke-nodes"},
+					{Name: "batch", MachineType: config.MachineType_MACHINE_TYPE_E2_MEDIUM, NodeCount: 2, Spot: true},
+				},
+			}},
+		},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	gke := files["gke.tf"]
+	for _, want := range []string{
+		`resource "google_container_cluster" "main"`,
+		`location = "us-central1-a"`,
+		`network    = google_compute_network.gke-vpc.id`,
+		`remove_default_node_pool = true`,
+		`cluster_secondary_range_name  = "pods"`,
+		`channel = "REGULAR"`,
+		`workload_pool = "${var.project_id}.svc.id.goog"`,
+		"google_compute_network.gke-vpc,\n    google_compute_subnetwork.gke-subnet,",
+		`resource "google_container_node_pool" "main_default"`,
+		`cluster  = google_container_cluster.main.id`,
+		`max_node_count = 3`,
+		`service_account = google_service_account.gke-nodes.email`,
+		`resource "google_container_node_pool" "main_batch"`,
+		`node_count = 2`,
+		`spot         = true`,
+		`mode = "GKE_METADATA"`,
+	} {
+		if !strings.Contains(gke, want) {
+			t.Errorf("Expected gke.tf to contain %q, got:\n%s", want, gke)
+		}
+	}
+	if strings.Contains(gke, "deletion_protection") {
+		t.Errorf("Expected the provider default for deletion_protection, got:\n%s", gke)
+	}
+}
+
+func TestGenerateGeneratedPasswords(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	noSpecial := false
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		SecretManager: &config.SecretManager{
+			Secrets: []*config.Secret{{
+				Name:        "api-key",
+				ValueSource: &config.Secret_Generate{Generate: &config.GeneratedPassword{Length: 40, Special: &noSpecial}},
+			}},
+		},
+		Databases: &config.Databases{
+			CloudSqlInstances: []*config.CloudSqlInstance{{
+				Name:            "main-db",
+				DatabaseVersion: "POSTGRES_15",
+				Region:          config.Region_REGION_US_CENTRAL1,
+				Tier:            "db-f1-micro",
+				Users: []*config.CloudSqlUser{{
+					Name:             "root",
+					GeneratePassword: &config.GeneratedPassword{MinSpecial: 2, OverrideSpecial: "!#%"},
+				}},
+			}},
+		},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	for file, wants := range map[string][]string{
+		"project.tf": {`random = {`, `source  = "hashicorp/random"`},
+		"secret_manager.tf": {
+			`resource "random_password" "secret_api-key"`,
+			`length  = 40`,
+			`special = false`,
+			`secret_data = random_password.secret_api-key.result`,
+		},
+		"databases.tf": {
+			`resource "random_password" "sql_main-db_root"`,
+			`length           = 32`,
+			`min_special      = 2`,
+			`override_special = "!#%"`,
+			`password = random_password.sql_main-db_root.result`,
+		},
+	} {
+		for _, want := range wants {
+			if !strings.Contains(files[file], want) {
+				t.Errorf("Expected %s to contain %q, got:\n%s", file, want, files[file])
+			}
+		}
+	}
+	if strings.Contains(files["variables.tf"], "secret_api-key_value") {
+		t.Error("Expected no variable for a generated secret")
+	}
+
+	// The random provider is only declared when a password is generated
+	cfg.SecretManager = nil
+	cfg.Databases = nil
+	files, err = gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+	if strings.Contains(files["project.tf"], "random") {
+		t.Errorf("Expected project.tf without the random provider, got:\n%s", files["project.tf"])
+	}
+}
+
+func TestGenerateSecretVariables(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		SecretManager: &config.SecretManager{Secrets: []*config.Secret{
+			{Name: "from-env", ValueSource: &config.Secret_FromEnvVar{FromEnvVar: "API_KEY"}},
+			{Name: "unset"},
+			{Name: "plain", ValueSource: &config.Secret_PlainText{PlainText: "value"}},
+		}},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	// Every var.secret_*_value referenced by a secret version must be declared
+	for _, name := range []string{"from-env", "unset"} {
+		if !strings.Contains(files["secret_manager.tf"], "secret_data = var.secret_"+name+"_value") {
+			t.Errorf("Expected secret %s to read its value from a variable, got:\n%s", name, files["secret_manager.tf"])
+		}
+		if !strings.Contains(files["variables.tf"], `variable "secret_`+name+`_value"`) {
+			t.Errorf("Expected a variable for secret %s, got:\n%s", name, files["variables.tf"])
+		}
+	}
+	if strings.Contains(files["variables.tf"], "secret_plain_value") {
+		t.Error("Expected no variable for a plain text secret")
+	}
+}
+
+func TestGenerateInstanceGroupVersions(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Compute: &config.Compute{
+			InstanceTemplates: []*config.InstanceTemplate{
+				{Name: "web-v1", MachineType: config.MachineType_MACHINE_TYPE_E2_MEDIUM},
+				{Name: "web-v2", MachineType: config.MachineType_MACHINE_TYPE_E2_MEDIUM},
+			},
+			InstanceGroups: []*config.InstanceGroup{{
+				Name:  "web-group",
+				Size:  10,
+				Zones: []config.Zone{config.Zone_ZONE_US_CENTRAL1_A},
+				Versions: []*config.InstanceGroupVersion{
+					{Name: "stable", Template: "web-v1"},
+					{Name: "canary", Template: "web-v2", TargetSizePercent: 10},
+				},
+			}},
+		},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	compute := files["compute.tf"]
+	stable := strings.Index(compute, `name              = "stable"`)
+	canary := strings.Index(compute, `name              = "canary"`)
+	if stable < 0 || canary < stable {
+		t.Fatalf("Expected the stable version followed by the canary version, got:\n%s", compute)
+	}
+	for _, want := range []string{
+		"instance_template = google_compute_instance_template.web-v1.id",
+		"instance_template = google_compute_instance_template.web-v2.id",
+		"percent = 10",
+	} {
+		if !strings.Contains(compute, want) {
+			t.Errorf("Expected compute.tf to contain %q, got:\n%s", want, compute)
+		}
+	}
+	if strings.Count(compute, "target_size {") != 1 {
+		t.Errorf("Expected only the canary version to set a target size, got:\n%s", compute)
+	}
+}
+
+func TestGenerateComputeLocations(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Compute: &config.Compute{
+			InstanceTemplates: []*config.InstanceTemplate{
+				{Name: "eu-template", MachineType: config.MachineType_MACHINE_TYPE_E2_MEDIUM, Region: config.Region_REGION_EUROPE_WEST1},
+				{Name: "default-template", MachineType: config.MachineType_MACHINE_TYPE_E2_MEDIUM},
+			},
+			Instances: []*config.Instance{
+				{Name: "us-vm", MachineType: config.MachineType_MACHINE_TYPE_E2_MEDIUM, Zone: config.Zone_ZONE_US_EAST1_B},
+				{Name: "eu-vm", MachineType: config.MachineType_MACHINE_TYPE_E2_MEDIUM, Zone: config.Zone_ZONE_EUROPE_WEST1_C},
+				{Name: "default-vm", MachineType: config.MachineType_MACHINE_TYPE_E2_MEDIUM},
+			},
+		},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+	compute := files["compute.tf"]
+
+	tests := []struct {
+		resource string
+		want     string
+	}{
+		{`resource "google_compute_instance_template" "eu-template"`, `region       = "europe-west1"`},
+		{`resource "google_compute_instance_template" "default-template"`, `region       = var.region`},
+		{`resource "google_compute_instance" "us-vm"`, `zone         = "us-east1-b"`},
+		{`resource "google_compute_instance" "eu-vm"`, `zone         = "europe-west1-c"`},
+		{`resource "google_compute_instance" "default-vm"`, `zone         = var.zone`},
+	}
+	for _, test := range tests {
+		start := strings.Index(compute, test.resource)
+		if start < 0 {
+			t.Errorf("Expected compute.tf to contain %s, got:\n%s", test.resource, compute)
+			continue
+		}
+		block := compute[start:]
+		if end := strings.Index(block, "\n}\n"); end >= 0 {
+			block = block[:end]
+		}
+		if !strings.Contains(block, test.want) {
+			t.Errorf("Expected %s to set %q, got:\n%s", test.resource, test.want, block)
+		}
+	}
+}
+
+func TestGenerateIAMBindingDependencies(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Iam: &config.Iam{
+			ServiceAccounts: []*config.ServiceAccount{{AccountId: "web-sa"}, {AccountId: "batch-sa"}},
+			RoleBindings: []*config.RoleBinding{
+				{Role: "roles/storage.objectViewer", Members: []string{
+					"serviceAccount:web-sa@test-project-123.iam.gserviceaccount.com",
+					"serviceAccount:batch-sa@test-project-123.iam.gserviceaccount.com",
+					"serviceAccount:web-sa@test-project-123.iam.gserviceaccount.com",
+				}},
+				{Role: "roles/viewer", Members: []string{
+					"user:admin@example.com",
+					"serviceAccount:other-sa@other-project.iam.gserviceaccount.com",
+				}},
+			},
+		},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	iam := files["iam.tf"]
+	want := "  depends_on = [\n    google_service_account.batch-sa,\n    google_service_account.web-sa\n  ]"
+	if !strings.Contains(iam, want) {
+		t.Errorf("Expected the service account binding to depend on both accounts, got:\n%s", iam)
+	}
+	if strings.Count(iam, "depends_on") != 1 {
+		t.Errorf("Expected only bindings to declared service accounts to have depends_on, got:\n%s", iam)
+	}
+}
+
+func TestGenerateInputFormats(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	generate := func(filename string) map[string]string {
+		t.Helper()
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", filename, err)
+		}
+		cfg := &config.Config{}
+		if err := config.Unmarshal(content, config.InputFormatForFile(filename), cfg); err != nil {
+			t.Fatalf("Failed to parse %s: %v", filename, err)
+		}
+		files, err := gen.Generate(cfg)
+		if err != nil {
+			t.Fatalf("Expected no error generating %s, got: %v", filename, err)
+		}
+		return files
+	}
+
+	want := generate("../../examples/simple.textproto")
+	got := generate("../../examples/simple.yaml")
+	if len(got) != len(want) {
+		t.Errorf("Expected %d files from YAML, got %d", len(want), len(got))
+	}
+	for name, content := range want {
+		if got[name] != content {
+			t.Errorf("Expected %s generated from YAML to match textproto, got:\n%s\n\nwant:\n%s", name, got[name], content)
+		}
+	}
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	newConfig := func(reverse bool) *config.Config {
+		templates := []*config.InstanceTemplate{
+			{Name: "web-template", MachineType: config.MachineType_MACHINE_TYPE_E2_MEDIUM, NetworkInterfaces: []*config.NetworkInterface{{Network: "web-vpc", Subnetwork: "web-subnet"}}},
+			{Name: "app-template", MachineType: config.MachineType_MACHINE_TYPE_E2_MEDIUM, NetworkInterfaces: []*config.NetworkInterface{{Network: "app-vpc"}}},
+		}
+		buckets := []*config.StorageBucket{{Name: "logs-bucket"}, {Name: "assets-bucket"}}
+		accounts := []*config.ServiceAccount{{AccountId: "web-sa"}, {AccountId: "app-sa"}}
+		vpcs := []*config.Vpc{
+			{Name: "web-vpc", Subnets: []*config.Subnet{{Name: "web-subnet", Cidr: "10.0.1.0/24"}}},
+			{Name: "app-vpc", Subnets: []*config.Subnet{{Name: "app-subnet-b", Cidr: "10.1.2.0/24"}, {Name: "app-subnet-a", Cidr: "10.1.1.0/24"}}},
+		}
+		if reverse {
+			templates[0], templates[1] = templates[1], templates[0]
+			buckets[0], buckets[1] = buckets[1], buckets[0]
+			accounts[0], accounts[1] = accounts[1], accounts[0]
+			vpcs[0], vpcs[1] = vpcs[1], vpcs[0]
+			vpcs[0].Subnets[0], vpcs[0].Subnets[1] = vpcs[0].Subnets[1], vpcs[0].Subnets[0]
+		}
+		return &config.Config{
+			Project:    &config.Project{Id: "test-project-123", Name: "Test Project", Labels: map[string]string{"team": "web", "env": "prod"}},
+			Networking: &config.Networking{Vpcs: vpcs},
+			Compute:    &config.Compute{InstanceTemplates: templates},
+			Storage:    &config.Storage{Buckets: buckets},
+			Iam:        &config.Iam{ServiceAccounts: accounts},
+		}
+	}
+
+	first, err := gen.Generate(newConfig(false))
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	for _, reverse := range []bool{false, true} {
+		files, err := gen.Generate(newConfig(reverse))
+		if err != nil {
+			t.Fatalf("Expected no error generating, got: %v", err)
+		}
+		if len(files) != len(first) {
+			t.Errorf("reverse=%v: expected %d files, got %d", reverse, len(first), len(files))
+		}
+		for name, content := range first {
+			if files[name] != content {
+				t.Errorf("reverse=%v: expected %s to be identical across runs, got:\n%s\n\nwant:\n%s", reverse, name, files[name], content)
+			}
+		}
+	}
+}
+
+func TestFormatOutput(t *testing.T) {
+	cfg := &config.Config{Project: &config.Project{Id: "test-project-123", Name: "Test Project"}}
+	gen, err := NewWithOptions("builtin", &NewOptions{DisableFormat: true})
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	unformatted, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+	gen, err = New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	formatted, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+	for name, content := range unformatted {
+		if !strings.HasSuffix(name, ".tf") {
+			continue
+		}
+		want := string(hclwrite.Format([]byte(content)))
+		if formatted[name] != want {
+			t.Errorf("Expected %s to be formatted by default, got:\n%s", name, formatted[name])
+		}
+		if again := string(hclwrite.Format([]byte(want))); again != want {
+			t.Errorf("Expected formatting %s to be idempotent, got:\n%s", name, again)
+		}
+	}
+}
+
+func TestGenerateMakefile(t *testing.T) {
+	tests := []struct {
+		format string
+		binary string
+	}{
+		{OutputFormatTerraform, "TF ?= terraform"},
+		{OutputFormatOpenTofu, "TF ?= tofu"},
+	}
+
+	for _, test := range tests {
+		gen, err := NewWithOptions("builtin", &NewOptions{OutputFormat: test.format})
+		if err != nil {
+			t.Fatalf("Failed to create generator for format %q: %v", test.format, err)
+		}
+
+		makefile, err := gen.GenerateMakefile()
+		if err != nil {
+			t.Fatalf("Expected no error generating Makefile, got: %v", err)
+		}
+
+		if !strings.Contains(makefile, test.binary) {
+			t.Errorf("Expected Makefile for format %q to contain %q", test.format, test.binary)
+		}
+		for _, target := range []string{"init:", "plan:", "apply:", "fmt:", "validate:"} {
+			if !strings.Contains(makefile, "\n"+target) {
+				t.Errorf("Expected Makefile to define target %q", target)
+			}
+		}
+		// Recipes must be indented with tabs
+		if !strings.Contains(makefile, "\n\t$(TF) -chdir=$(TF_DIR) fmt") {
+			t.Error("Expected Makefile recipes to be tab-indented")
+		}
+	}
+}
+
+func TestGenerateAsModule(t *testing.T) {
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Storage: &config.Storage{Buckets: []*config.StorageBucket{{Name: "app-assets", Location: "EU"}}},
+		Databases: &config.Databases{CloudSqlInstances: []*config.CloudSqlInstance{
+			{Name: "main-db", DatabaseVersion: "POSTGRES_15", Region: config.Region_REGION_EUROPE_WEST1, Tier: "db-f1-micro"},
+		}},
+	}
+
+	plain, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	files, err := plain.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+	if _, ok := files[TfvarsFileName]; ok || strings.Contains(files["storage.tf"], "var.") {
+		t.Errorf("Expected no module inputs without AsModule, got:\n%s", files["storage.tf"])
+	}
+
+	gen, err := NewWithOptions("builtin", &NewOptions{AsModule: true})
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	files, err = gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	// Formatting aligns the attributes, so whitespace is collapsed
+	for name, want := range map[string][]string{
+		"project.tf":   {`project = var.project_id`, `name = var.project_name`, `project_id = var.project_id`},
+		"storage.tf":   {`name = var.bucket_app_assets_name`, `location = var.bucket_app_assets_location`},
+		"databases.tf": {`name = var.sql_main_db_name`, `region = var.sql_main_db_region`, `tier = var.sql_main_db_tier`},
+		"variables.tf": {`variable "sql_main_db_tier" { description = "Machine tier of Cloud SQL instance main-db" type = string }`},
+		TfvarsFileName: {`project_id = "test-project-123"`, `bucket_app_assets_location = "EU"`, `sql_main_db_region = "europe-west1"`, `project_name = "Test Project"`},
+	} {
+		content := strings.Join(strings.Fields(files[name]), " ")
+		for _, line := range want {
+			if !strings.Contains(content, line) {
+				t.Errorf("Expected %s to contain %q, got:\n%s", name, line, files[name])
+			}
+		}
+	}
+
+	// Every module input is set in terraform.tfvars, besides project_id
+	_, inputs, _ := strings.Cut(files["variables.tf"], "# Module inputs")
+	if declared, set := strings.Count(inputs, "variable \""), strings.Count(files[TfvarsFileName], " = ")-1; declared != 6 || declared != set {
+		t.Errorf("Expected 6 module inputs set in %s, got %d declared and %d set", TfvarsFileName, declared, set)
+	}
+
+	if _, err := (&TemplateContext{}).Var("bad", "", 1.5); err == nil {
+		t.Error("Expected an error for an unsupported value type")
+	}
+}
+
+func TestGenerateTfvars(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want map[string][]string
+		skip []string
+	}{
+		{
+			name: "region from subnet and zone from instance",
+			cfg: &config.Config{
+				Project: &config.Project{Id: "test-project-123"},
+				Networking: &config.Networking{Vpcs: []*config.Vpc{{
+					Name:    "main-vpc",
+					Subnets: []*config.Subnet{{Name: "app-subnet", Region: config.Region_REGION_EUROPE_WEST1}},
+				}}},
+				Compute: &config.Compute{Instances: []*config.Instance{{Name: "web", Zone: config.Zone_ZONE_EUROPE_WEST1_B}}},
+			},
+			want: map[string][]string{TfvarsFileName: {`project_id = "test-project-123"`, `region     = "europe-west1"`, `zone       = "europe-west1-b"`}},
+		},
+		{
+			name: "region derived from zone",
+			cfg: &config.Config{
+				Project: &config.Project{Id: "test-project-123"},
+				Compute: &config.Compute{InstanceGroups: []*config.InstanceGroup{{Name: "app-group", Zones: []config.Zone{config.Zone_ZONE_US_CENTRAL1_A}}}},
+			},
+			want: map[string][]string{TfvarsFileName: {`region     = "us-central1"`, `zone       = "us-central1-a"`}},
+		},
+		{
+			name: "no location resources",
+			cfg:  &config.Config{Project: &config.Project{Id: "test-project-123"}},
+			want: map[string][]string{TfvarsFileName: {`project_id = "test-project-123"`}},
+			skip: []string{"region", "zone"},
+		},
+		{
+			name: "multiple projects",
+			cfg: &config.Config{
+				Projects: []*config.Project{{Id: "web-prod-123"}, {Id: "data-prod-123"}},
+				Compute:  &config.Compute{Instances: []*config.Instance{{Name: "web", Zone: config.Zone_ZONE_US_EAST1_B, Project: "web-prod-123"}}},
+			},
+			want: map[string][]string{
+				"web-prod-123/" + TfvarsFileName:  {`project_id = "web-prod-123"`, `zone       = "us-east1-b"`},
+				"data-prod-123/" + TfvarsFileName: {`project_id = "data-prod-123"`},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			files, err := gen.GenerateTfvars(test.cfg)
+			if err != nil {
+				t.Fatalf("Expected no error generating, got: %v", err)
+			}
+			if len(files) != len(test.want) {
+				t.Errorf("Expected %d files, got %d", len(test.want), len(files))
+			}
+			for name, lines := range test.want {
+				content, ok := files[name]
+				if !ok {
+					t.Errorf("Expected %s to be generated", name)
+					continue
+				}
+				for _, line := range lines {
+					if !strings.Contains(content, line) {
+						t.Errorf("Expected %s to contain %q, got:\n%s", name, line, content)
+					}
+				}
+				for _, variable := range test.skip {
+					if strings.Contains(content, "\n"+variable+" ") {
+						t.Errorf("Expected %s not to set %s, got:\n%s", name, variable, content)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateDetailed(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		// files lists generated files in the order they must appear, with
+		// their resource types
+		files [][2]string
+		apis  []string
+	}{
+		{
+			name: "single project",
+			cfg: &config.Config{
+				Project:    &config.Project{Id: "test-project-123"},
+				Networking: &config.Networking{Vpcs: []*config.Vpc{{Name: "main-vpc"}}},
+				Storage:    &config.Storage{Buckets: []*config.StorageBucket{{Name: "test-project-123-assets", Location: "US"}}},
+				Pubsub:     &config.PubSub{Topics: []*config.PubSubTopic{{Name: "events"}}},
+			},
+			files: [][2]string{
+				{"project.tf", "project"},
+				{"networking.tf", "networking"},
+				{"storage.tf", "storage"},
+				{"pubsub.tf", "pubsub"},
+				{"variables.tf", "variables"},
+				{"outputs.tf", "outputs"},
+			},
+			apis: []string{"compute.googleapis.com", "pubsub.googleapis.com"},
+		},
+		{
+			name: "multiple projects in declaration order",
+			cfg: &config.Config{
+				Projects: []*config.Project{{Id: "web-prod-123"}, {Id: "data-prod-123"}},
+				Compute:  &config.Compute{Instances: []*config.Instance{{Name: "web", Project: "web-prod-123"}}},
+			},
+			files: [][2]string{
+				{"web-prod-123/project.tf", "project"},
+				{"web-prod-123/compute.tf", "compute"},
+				{"web-prod-123/variables.tf", "variables"},
+				{"data-prod-123/project.tf", "project"},
+				{"data-prod-123/variables.tf", "variables"},
+			},
+			apis: []string{"compute.googleapis.com"},
+		},
+		{
+			name: "renamed files in apply order",
+			cfg: &config.Config{
+				Project:    &config.Project{Id: "test-project-123"},
+				Networking: &config.Networking{Vpcs: []*config.Vpc{{Name: "main-vpc"}}},
+				Pubsub:     &config.PubSub{Topics: []*config.PubSubTopic{{Name: "events"}}},
+				Output:     &config.Output{Numbered: true, FileMap: map[string]string{"pubsub.tf": "messaging.tf"}},
+			},
+			files: [][2]string{
+				{"00-project.tf", "project"},
+				{"02-networking.tf", "networking"},
+				{"messaging.tf", "pubsub"},
+				{"23-variables.tf", "variables"},
+			},
+			apis: []string{"compute.googleapis.com", "pubsub.googleapis.com"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := gen.GenerateDetailed(test.cfg)
+			if err != nil {
+				t.Fatalf("Expected no error generating, got: %v", err)
+			}
+
+			files, err := gen.Generate(test.cfg)
+			if err != nil {
+				t.Fatalf("Expected no error generating, got: %v", err)
+			}
+			if result.FileCount != len(files) || len(result.Files) != len(files) {
+				t.Errorf("Expected %d files, got %d (FileCount %d)", len(files), len(result.Files), result.FileCount)
+			}
+			totalBytes := 0
+			for _, file := range result.Files {
+				if file.Content != files[file.Name] {
+					t.Errorf("Expected %s to match Generate", file.Name)
+				}
+				totalBytes += len(file.Content)
+			}
+			if result.TotalBytes != totalBytes {
+				t.Errorf("Expected TotalBytes %d, got %d", totalBytes, result.TotalBytes)
+			}
+
+			next := 0
+			for _, file := range result.Files {
+				if next < len(test.files) && file.Name == test.files[next][0] {
+					if file.ResourceType != test.files[next][1] {
+						t.Errorf("Expected %s to have resource type %q, got %q", file.Name, test.files[next][1], file.ResourceType)
+					}
+					next++
+				}
+			}
+			if next < len(test.files) {
+				t.Errorf("Expected %s in order, got %v", test.files[next][0], fileNames(result.Files))
+			}
+
+			if strings.Join(result.APIs, ",") != strings.Join(test.apis, ",") {
+				t.Errorf("Expected APIs %v, got %v", test.apis, result.APIs)
+			}
+		})
+	}
+}
+
+func fileNames(files []GeneratedFile) []string {
+	names := make([]string, len(files))
+	for i, file := range files {
+		names[i] = file.Name
+	}
+	return names
+}
+
+func TestCountResources(t *testing.T) {
+	content := `resource "google_compute_network" "main-vpc" {
+  name = "main-vpc"
+}
+
+data "google_project" "project" {}
+
+  resource "google_compute_subnetwork" "app-subnet" {
+  description = "not a resource \"block\""
+}
+`
+	if count := CountResources(content); count != 2 {
+		t.Errorf("Expected 2 resources, got %d", count)
+	}
+	if count := CountResources(""); count != 0 {
+		t.Errorf("Expected no resources in an empty file, got %d", count)
+	}
+}
+
+func TestCombineFiles(t *testing.T) {
+	files := map[string]string{
+		"variables.tf":        "variable \"region\" {}\n",
+		"project.tf":          "provider \"google\" {}\n",
+		"outputs.tf":          "output \"id\" {}\n",
+		"compute.tf":          "\nresource \"a\" \"b\" {}\n\n",
+		"Makefile":            "init:\n",
+		"web-prod/project.tf": "provider \"google\" {}\n",
+		"web-prod/Makefile":   "init:\n",
+	}
+
+	combined := CombineFiles(files)
+
+	want := map[string]string{
+		"main.tf": "# === project.tf ===\n\nprovider \"google\" {}\n\n" +
+			"# === compute.tf ===\n\nresource \"a\" \"b\" {}\n\n" +
+			"# === outputs.tf ===\n\noutput \"id\" {}\n\n" +
+			"# === variables.tf ===\n\nvariable \"region\" {}\n",
+		"Makefile":          "init:\n",
+		"web-prod/main.tf":  "# === project.tf ===\n\nprovider \"google\" {}\n",
+		"web-prod/Makefile": "init:\n",
+	}
+	if len(combined) != len(want) {
+		t.Errorf("Expected %d files, got %d: %v", len(want), len(combined), combined)
+	}
+	for name, content := range want {
+		if combined[name] != content {
+			t.Errorf("Expected %s to be:\n%s\ngot:\n%s", name, content, combined[name])
+		}
+	}
+}
+
+func TestGenerateProviderDefaultLabels(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	files, err := gen.Generate(&config.Config{
+		Project: &config.Project{
+			Id:                    "test-project-123",
+			Name:                  "Test Project",
+			ProviderDefaultLabels: map[string]string{"team": "web", "environment": "prod"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	expected := "  default_labels = {\n    \"environment\" = \"prod\"\n    \"team\"        = \"web\"\n  }\n}"
+	if !strings.Contains(files["project.tf"], expected) {
+		t.Errorf("Expected provider block to contain sorted default_labels, got:\n%s", files["project.tf"])
+	}
+}
+
+func TestGenerateProviderBillingProject(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	files, err := gen.Generate(&config.Config{
+		Project: &config.Project{
+			Id:                        "test-project-123",
+			Name:                      "Test Project",
+			UserProjectOverride:       true,
+			BillingProject:            "billing-project-123",
+			GoogleBetaProviderVersion: "~> 5.0",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	// Both providers bill requests to the same project
+	project := strings.Join(strings.Fields(files["project.tf"]), " ")
+	want := `user_project_override = true billing_project = "billing-project-123"`
+	if strings.Count(project, want) != 2 {
+		t.Errorf("Expected both provider blocks to contain %q, got:\n%s", want, files["project.tf"])
+	}
+}
+
+func TestGenerateProviderVersions(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	files, err := gen.Generate(&config.Config{Project: &config.Project{Id: "test-project-123", Name: "Test Project"}})
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+	project := files["project.tf"]
+	if !strings.Contains(project, `version = "~> 5.0"`) {
+		t.Errorf("Expected the google provider to default to ~> 5.0, got:\n%s", project)
+	}
+	if strings.Contains(project, "google-beta") {
+		t.Errorf("Expected no google-beta provider by default, got:\n%s", project)
+	}
+
+	files, err = gen.Generate(&config.Config{
+		Project: &config.Project{
+			Id:                        "test-project-123",
+			Name:                      "Test Project",
+			GoogleProviderVersion:     ">= 5.10, < 6.0",
+			GoogleBetaProviderVersion: "~> 5.40",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+	project = files["project.tf"]
+	for _, want := range []string{
+		`version = ">= 5.10, < 6.0"`,
+		"google-beta = {\n      source  = \"hashicorp/google-beta\"\n      version = \"~> 5.40\"",
+		`provider "google-beta" {`,
+	} {
+		if !strings.Contains(project, want) {
+			t.Errorf("Expected project.tf to contain %q, got:\n%s", want, project)
+		}
+	}
+}
+
+func TestGenerateBackend(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		backend *config.Backend
+		want    string
+	}{
+		{"none", nil, ""},
+		{"gcs", &config.Backend{Type: "gcs", Bucket: "tf-state-bucket", Prefix: "web/prod"},
+			"terraform {\n  backend \"gcs\" {\n    bucket = \"tf-state-bucket\"\n    prefix = \"web/prod\"\n  }\n}\n"},
+		{"gcs without prefix", &config.Backend{Type: "gcs", Bucket: "tf-state-bucket"},
+			"terraform {\n  backend \"gcs\" {\n    bucket = \"tf-state-bucket\"\n  }\n}\n"},
+		{"local", &config.Backend{Type: "local", Path: "state/terraform.tfstate"},
+			"terraform {\n  backend \"local\" {\n    path = \"state/terraform.tfstate\"\n  }\n}\n"},
+	}
+
+	for _, test := range tests {
+		files, err := gen.Generate(&config.Config{Project: &config.Project{Id: "test-project-123", Name: "Test Project", Backend: test.backend}})
+		if err != nil {
+			t.Fatalf("%s: expected no error generating, got: %v", test.name, err)
+		}
+		backend, ok := files["backend.tf"]
+		if test.want == "" {
+			if ok {
+				t.Errorf("%s: expected no backend.tf, got:\n%s", test.name, backend)
+			}
+			continue
+		}
+		if !strings.HasSuffix(backend, test.want) {
+			t.Errorf("%s: expected backend.tf to end with:\n%s\ngot:\n%s", test.name, test.want, backend)
+		}
+	}
+}
+
+func TestGenerateStamp(t *testing.T) {
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+	}
+
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+	if _, ok := files["metadata.tf"]; ok {
+		t.Error("Expected no metadata.tf without a stamp")
+	}
+
+	stamp := &Stamp{GeneratedAt: time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC), Version: "v1.2.3", ConfigHash: "4a5e1e4b"}
+	gen, err = NewWithOptions("builtin", &NewOptions{Stamp: stamp})
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	files, err = gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	metadata := files["metadata.tf"]
+	for _, expected := range []string{
+		`generated_at       = "2024-03-01T12:30:00Z"`,
+		`custoodian_version = "v1.2.3"`,
+		`output "generated_at"`,
+		`output "custoodian_version"`,
+		`config_hash        = "4a5e1e4b"`,
+		`output "config_hash"`,
+	} {
+		if !strings.Contains(metadata, expected) {
+			t.Errorf("Expected metadata.tf to contain %q, got:\n%s", expected, metadata)
+		}
+	}
+}
+
+func TestGenerateAPIPropagationDelay(t *testing.T) {
+	cfg := &config.Config{
+		Project: &config.Project{
+			Id:                  "test-project-123",
+			Name:                "Test Project",
+			Apis:                []config.GcpApi{config.GcpApi_GCP_API_COMPUTE},
+			ApiPropagationDelay: "60s",
+		},
+		Networking: &config.Networking{
+			Vpcs: []*config.Vpc{{Name: "main-vpc"}},
+		},
+	}
+
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	for _, expected := range []string{`source  = "hashicorp/time"`, `resource "time_sleep" "api_propagation"`, `create_duration = "60s"`} {
+		if !strings.Contains(files["project.tf"], expected) {
+			t.Errorf("Expected project.tf to contain %q, got:\n%s", expected, files["project.tf"])
+		}
+	}
+	if !strings.Contains(files["networking.tf"], "time_sleep.api_propagation") {
+		t.Errorf("Expected VPC to depend on time_sleep.api_propagation, got:\n%s", files["networking.tf"])
+	}
+
+	cfg.Project.ApiPropagationDelay = ""
+	files, err = gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+	if strings.Contains(files["project.tf"], "time_sleep") || strings.Contains(files["networking.tf"], "time_sleep") {
+		t.Error("Expected no time_sleep without api_propagation_delay")
+	}
+}
+
+func TestTemplateCacheLocalDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate := func(content string) {
+		if err := os.WriteFile(filepath.Join(dir, "project.tf"), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write template: %v", err)
+		}
+	}
+	load := func() (*Generator, string) {
+		gen, err := New(dir)
+		if err != nil {
+			t.Fatalf("Failed to create generator: %v", err)
+		}
+		var b strings.Builder
+		if err := gen.templates.ExecuteTemplate(&b, "project.tf", nil); err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+		return gen, b.String()
+	}
+
+	writeTemplate("# first\n")
+	first, content := load()
+	if content != "# first\n" {
+		t.Errorf("Expected the first template, got %q", content)
+	}
+
+	writeTemplate("# second\n")
+	second, content := load()
+	if content != "# second\n" {
+		t.Errorf("Expected the modified template to be used, got %q", content)
+	}
+	if second.templates == first.templates {
+		t.Error("Expected the modified directory not to use the cached templates")
+	}
+
+	third, _ := load()
+	if third.templates != second.templates {
+		t.Error("Expected the unchanged directory to use the cached templates")
+	}
+}
+
+func TestClearTemplateCache(t *testing.T) {
+	first, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	cached, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	if cached.templates != first.templates {
+		t.Error("Expected the second generator to use the cached templates")
+	}
+
+	ClearTemplateCache()
+	reloaded, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	if reloaded.templates == first.templates {
+		t.Error("Expected templates to be parsed again after clearing the cache")
+	}
+}
+
+func TestExtraFuncs(t *testing.T) {
+	dir := t.TempDir()
+	content := `{{ shout "hi" }} {{ quote "x" }}`
+	if err := os.WriteFile(filepath.Join(dir, "project.tf"), []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	gen, err := NewWithOptions(dir, &NewOptions{ExtraFuncs: map[string]any{
+		"shout": strings.ToUpper,
+		"quote": func(s string) string { return "overridden" },
+	}})
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	var b strings.Builder
+	if err := gen.templates.ExecuteTemplate(&b, "project.tf", nil); err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+	if got := b.String(); got != `HI "x"` {
+		t.Errorf("Expected the extra function and the built-in quote, got %q", got)
+	}
+
+	// Templates parsed with extra functions must not be served from the cache
+	if _, err := New(dir); err == nil || !strings.Contains(err.Error(), `function "shout" not defined`) {
+		t.Errorf("Expected templates with extra functions not to be cached, got: %v", err)
+	}
+
+	names := ReservedFuncNames()
+	for _, name := range []string{"quote", "mergeLabels", "printf"} {
+		if !isReservedFuncName(name) {
+			t.Errorf("Expected %s to be reserved", name)
+		}
+		found := false
+		for _, reserved := range names {
+			found = found || reserved == name
+		}
+		if !found {
+			t.Errorf("Expected ReservedFuncNames to include %s, got %v", name, names)
+		}
+	}
+}
+
+func TestGenerateStrictTemplates(t *testing.T) {
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+	}
+
+	for _, strict := range []bool{false, true} {
+		gen, err := NewWithOptions("builtin", &NewOptions{DisableCache: true, StrictTemplates: strict})
+		if err != nil {
+			t.Fatalf("Failed to create generator: %v", err)
+		}
+		// A custom project template that reads a label the configuration lacks
+		if _, err := gen.templates.New("project.tf").Parse(`owner = "{{ .Data.Labels.owner }}"`); err != nil {
+			t.Fatalf("Failed to parse template: %v", err)
+		}
+
+		files, err := gen.Generate(cfg)
+		if !strict {
+			if err != nil || !strings.Contains(files["project.tf"], "<no value>") {
+				t.Errorf("Expected <no value> without strict templates, got %q (error: %v)", files["project.tf"], err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), `map has no entry for key "owner"`) {
+			t.Errorf("Expected missing key error with strict templates, got: %v", err)
+		}
+	}
+
+	err := checkMissingValues(map[string]string{"iam.tf": "resource \"x\" \"y\" {\n  role = <no value>\n}\n"})
+	if err == nil || !strings.Contains(err.Error(), "iam.tf:2:") {
+		t.Errorf("Expected error naming iam.tf line 2, got: %v", err)
+	}
+}
+
+func TestGenerateProjectTemplateData(t *testing.T) {
+	gen, err := NewWithOptions("builtin", &NewOptions{DisableCache: true, DisableFormat: true})
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	// Custom project templates may read the project directly, as before
+	// project.tf got a TemplateContext, or through the context
+	content := `project = "{{ .Id }}" apis = {{ len .Apis }} name = "{{ .Data.Name }}" format = "{{ .OutputFormat }}"`
+	if _, err := gen.templates.New("project.tf").Parse(content); err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+
+	files, err := gen.Generate(&config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project", Apis: []config.GcpApi{config.GcpApi_GCP_API_COMPUTE}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+	want := `project = "test-project-123" apis = 1 name = "Test Project" format = "terraform"`
+	if files["project.tf"] != want {
+		t.Errorf("Expected project.tf %q, got %q", want, files["project.tf"])
+	}
+}
+
+func TestGenerateSkipsDisabledResources(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	enabled, disabled := true, false
+	cfg := &config.Config{
+		Project: &config.Project{
+			Id:   "test-project-123",
+			Name: "Test Project",
+		},
+		Storage: &config.Storage{
+			Buckets: []*config.StorageBucket{
+				{Name: "kept-bucket", Location: "US", Enabled: &enabled},
+				{Name: "dropped-bucket", Location: "US", Enabled: &disabled},
+				{Name: "default-bucket", Location: "US"},
+			},
+		},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	storage := files["storage.tf"]
+	if strings.Contains(storage, "dropped-bucket") {
+		t.Error("Expected disabled bucket to be excluded from storage.tf")
+	}
+	for _, name := range []string{"kept-bucket", "default-bucket"} {
+		if !strings.Contains(storage, name) {
+			t.Errorf("Expected storage.tf to contain %s", name)
+		}
+	}
+
+	// The caller's configuration is left untouched
+	if len(cfg.Storage.Buckets) != 3 {
+		t.Errorf("Expected input configuration to keep 3 buckets, got %d", len(cfg.Storage.Buckets))
+	}
+}
+
+func TestGenerateCommonLabels(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project:      &config.Project{Id: "test-project-123", Name: "Test Project"},
+		CommonLabels: map[string]string{"team": "web", "environment": "prod"},
+		Storage: &config.Storage{
+			Buckets: []*config.StorageBucket{
+				{Name: "assets-bucket", Location: "US", Labels: map[string]string{"environment": "staging", "tier": "hot"}},
+			},
+		},
+		Monitoring: &config.Monitoring{
+			NotificationChannels: []*config.NotificationChannel{{Name: "pager", Type: "pagerduty", Labels: map[string]string{"service_key": "abc"}}},
+		},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	// Resource labels override the common labels, which fill in the rest;
+	// whitespace is collapsed as the output is aligned by formatting
+	storage := strings.Join(strings.Fields(files["storage.tf"]), " ")
+	for _, want := range []string{`"environment" = "staging"`, `"team" = "web"`, `"tier" = "hot"`} {
+		if !strings.Contains(storage, want) {
+			t.Errorf("Expected storage.tf to contain %s, got:\n%s", want, storage)
+		}
+	}
+	if strings.Contains(storage, `"environment" = "prod"`) {
+		t.Error("Expected the bucket's environment label to override the common one")
+	}
+
+	// Resources without labels of their own get the common labels
+	if project := strings.Join(strings.Fields(files["project.tf"]), " "); !strings.Contains(project, `"team" = "web"`) {
+		t.Errorf("Expected project.tf to carry the common labels, got:\n%s", project)
+	}
+
+	// Notification channel labels are channel settings, not resource labels
+	if monitoring := files["monitoring.tf"]; strings.Contains(monitoring, `"team"`) {
+		t.Errorf("Expected notification channel labels to exclude the common labels, got:\n%s", monitoring)
+	}
+}
+
+func TestGenerateMultipleProjects(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Projects: []*config.Project{
+			{Id: "web-prod-123", Name: "Web Prod"},
+			{Id: "data-prod-123", Name: "Data Prod"},
+		},
+		Networking: &config.Networking{
+			Vpcs: []*config.Vpc{{Name: "web-vpc", Project: "web-prod-123"}},
+		},
+		Storage: &config.Storage{
+			Buckets: []*config.StorageBucket{
+				{Name: "web-assets-bucket", Location: "US", Project: "web-prod-123"},
+				{Name: "data-lake-bucket", Location: "US", Project: "data-prod-123"},
+			},
+		},
+	}
+
+	files, err := gen.Generate(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating, got: %v", err)
+	}
+
+	for _, name := range []string{"web-prod-123/project.tf", "web-prod-123/networking.tf", "data-prod-123/project.tf"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("Expected %s to be generated", name)
+		}
+	}
+	if _, ok := files["data-prod-123/networking.tf"]; ok {
+		t.Error("Expected no networking.tf for a project without networking resources")
+	}
+	if !strings.Contains(files["data-prod-123/project.tf"], `"data-prod-123"`) {
+		t.Error("Expected data-prod-123/project.tf to declare its own project")
+	}
+	if storage := files["web-prod-123/storage.tf"]; !strings.Contains(storage, "web-assets-bucket") || strings.Contains(storage, "data-lake-bucket") {
+		t.Errorf("Expected web-prod-123/storage.tf to contain only its own bucket, got:\n%s", storage)
+	}
+
+	// With several projects, every resource must choose one
+	cfg.Storage.Buckets[1].Project = ""
+	if _, err := gen.Generate(cfg); err == nil || !strings.Contains(err.Error(), "StorageBucket data-lake-bucket must specify a project") {
+		t.Errorf("Expected unassigned bucket error, got: %v", err)
+	}
+}
+
+func TestImportTargets(t *testing.T) {
+	cfg := &config.Config{
+		Project: &config.Project{
+			Id:       "test-project-123",
+			Name:     "Test Project",
+			ImportId: "test-project-123",
+		},
+		Networking: &config.Networking{
+			Vpcs: []*config.Vpc{{
+				Name:     "main-vpc",
+				ImportId: "projects/test-project-123/global/networks/main-vpc",
+				Subnets:  []*config.Subnet{{Name: "app-subnet", ImportId: "projects/test-project-123/regions/us-central1/subnetworks/app-subnet"}},
+			}},
+		},
+		Iam: &config.Iam{
+			ServiceAccounts: []*config.ServiceAccount{{AccountId: "app-sa"}},
+		},
+	}
+
+	targets, err := ImportTargets(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error collecting import targets, got: %v", err)
+	}
+
+	expected := []ImportTarget{
+		{Address: "google_project.project", ID: "test-project-123"},
+		{Address: "google_compute_network.main-vpc", ID: "projects/test-project-123/global/networks/main-vpc"},
+		{Address: "google_compute_subnetwork.app-subnet", ID: "projects/test-project-123/regions/us-central1/subnetworks/app-subnet"},
+	}
+	if len(targets) != len(expected) {
+		t.Fatalf("Expected %d import targets, got %d: %v", len(expected), len(targets), targets)
+	}
+	for i := range expected {
+		if targets[i] != expected[i] {
+			t.Errorf("Expected import target %d to be %v, got %v", i, expected[i], targets[i])
+		}
+	}
+
+	// Every message with an import_id field must map to a Terraform resource type
+	messages := cfg.ProtoReflect().Descriptor().ParentFile().Messages()
+	for i := 0; i < messages.Len(); i++ {
+		md := messages.Get(i)
+		if md.Fields().ByName("import_id") == nil {
+			continue
+		}
+		if _, ok := importResourceTypes[md.Name()]; !ok {
+			t.Errorf("Message %s has import_id but no import resource type", md.Name())
+		}
+	}
+}
+
+func TestGenerateImports(t *testing.T) {
+	gen, err := New("builtin")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	cfg := &config.Config{
+		Projects: []*config.Project{{Id: "web-prod-123"}, {Id: "data-prod-123"}},
+		Networking: &config.Networking{Vpcs: []*config.Vpc{{
+			Name:     "main-vpc",
+			Project:  "web-prod-123",
+			ImportId: "projects/web-prod-123/global/networks/main-vpc",
+		}}},
+		Storage: &config.Storage{Buckets: []*config.StorageBucket{{Name: "data-prod-123-assets", Location: "US", Project: "data-prod-123"}}},
+	}
+
+	files, err := gen.GenerateImports(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error generating imports, got: %v", err)
+	}
+
+	// Only projects that import resources get an imports.tf
+	if len(files) != 1 {
+		t.Fatalf("Expected a single imports.tf, got %d files", len(files))
+	}
+	imports := strings.Join(strings.Fields(files["web-prod-123/imports.tf"]), " ")
+	want := `import { to = google_compute_network.main-vpc id = "projects/web-prod-123/global/networks/main-vpc" }`
+	if !strings.Contains(imports, want) {
+		t.Errorf("Expected imports.tf to contain %q, got:\n%s", want, files["web-prod-123/imports.tf"])
+	}
+
+	cfg.Networking.Vpcs[0].ImportId = ""
+	if files, err := gen.GenerateImports(cfg); err != nil || len(files) != 0 {
+		t.Errorf("Expected no files without import_id, got %d (error: %v)", len(files), err)
+	}
+}
+
+func TestDependencyGraph(t *testing.T) {
+	disabled := false
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project", Apis: []config.GcpApi{config.GcpApi_GCP_API_COMPUTE}},
+		Networking: &config.Networking{
+			Vpcs: []*config.Vpc{{
+				Name:    "main-vpc",
+				Subnets: []*config.Subnet{{Name: "app-subnet"}},
+			}},
+			FirewallRules: []*config.FirewallRule{{Name: "external", Network: "shared-vpc"}},
+		},
+		Compute: &config.Compute{
+			InstanceTemplates: []*config.InstanceTemplate{{
+				Name:              "app-template",
+				NetworkInterfaces: []*config.NetworkInterface{{Subnetwork: "app-subnet"}},
+				ServiceAccount:    "app-sa@test-project-123.iam.gserviceaccount.com",
+			}},
+			InstanceGroups: []*config.InstanceGroup{{Name: "app-group", Template: "app-template"}},
+		},
+		LoadBalancers: []*config.LoadBalancer{{Name: "app-lb", Backend: "app-group"}},
+		Iam: &config.Iam{
+			ServiceAccounts: []*config.ServiceAccount{{AccountId: "app-sa"}, {AccountId: "old-sa", Enabled: &disabled}},
+		},
+	}
+
+	graph, err := DependencyGraph(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error building the graph, got: %v", err)
+	}
+
+	nodes := make(map[string]bool)
+	for _, node := range graph.Nodes {
+		nodes[node.Address] = true
+	}
+	for _, address := range []string{"google_project.project", "google_compute_network.main-vpc", "google_compute_backend_service.app-lb", "google_service_account.app-sa"} {
+		if !nodes[address] {
+			t.Errorf("Expected node %s, got: %v", address, graph.Nodes)
+		}
+	}
+	if nodes["google_service_account.old-sa"] {
+		t.Error("Expected no node for a disabled resource")
+	}
+
+	edges := make(map[string]bool)
+	for _, edge := range graph.Edges {
+		edges[edge.From+" -> "+edge.To] = true
+	}
+	for _, want := range []string{
+		"google_compute_subnetwork.app-subnet -> google_compute_network.main-vpc",
+		"google_compute_instance_template.app-template -> google_compute_subnetwork.app-subnet",
+		"google_compute_instance_template.app-template -> google_service_account.app-sa",
+		"google_compute_instance_group_manager.app-group -> google_compute_instance_template.app-template",
+		"google_compute_global_forwarding_rule.app-lb -> google_compute_target_http_proxy.app-lb",
+		"google_compute_backend_service.app-lb -> google_compute_instance_group_manager.app-group",
+		"google_compute_network.main-vpc -> google_project_service.api_0",
+		"google_project_service.api_0 -> google_project.project",
+	} {
+		if !edges[want] {
+			t.Errorf("Expected edge %s", want)
+		}
+	}
+	// Networks managed elsewhere are not part of the graph
+	for edge := range edges {
+		if strings.Contains(edge, "shared-vpc") {
+			t.Errorf("Expected no edge to an undeclared resource, got %s", edge)
+		}
+	}
+
+	dot := graph.DOT()
+	for _, want := range []string{
+		"digraph custoodian {\n",
+		"  \"google_project_service.api_0\" [label = \"compute.googleapis.com\"];\n",
+		"  \"google_compute_subnetwork.app-subnet\" -> \"google_compute_network.main-vpc\";\n",
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("Expected DOT output to contain %q, got:\n%s", want, dot)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	disabled := false
+	cfg := &config.Config{
+		Projects: []*config.Project{
+			{Id: "web-prod-123", Name: "Web", Apis: []config.GcpApi{config.GcpApi_GCP_API_COMPUTE}},
+			{Id: "data-prod-123"},
+		},
+		Networking: &config.Networking{Vpcs: []*config.Vpc{{
+			Name:    "main-vpc",
+			Project: "web-prod-123",
+			Subnets: []*config.Subnet{{Name: "web-subnet"}, {Name: "app-subnet"}},
+		}}},
+		Storage: &config.Storage{Buckets: []*config.StorageBucket{
+			{Name: "data-bucket", Project: "data-prod-123"},
+			{Name: "old-bucket", Project: "data-prod-123", Enabled: &disabled},
+		}},
+		Pubsub: &config.PubSub{Topics: []*config.PubSubTopic{{Name: "events", Project: "data-prod-123"}}},
+	}
+
+	summary, err := Summarize(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error summarizing, got: %v", err)
+	}
+	if len(summary.Projects) != 2 {
+		t.Fatalf("Expected 2 projects, got %d", len(summary.Projects))
+	}
+
+	web := summary.Projects[0]
+	wantResources := []ResourceGroup{
+		{Type: "google_compute_network", Names: []string{"main-vpc"}},
+		{Type: "google_compute_subnetwork", Names: []string{"web-subnet", "app-subnet"}},
+	}
+	if web.Id != "web-prod-123" || web.Name != "Web" || fmt.Sprint(web.Resources) != fmt.Sprint(wantResources) {
+		t.Errorf("Expected web-prod-123 with %v, got %s with %v", wantResources, web.Id, web.Resources)
+	}
+	if fmt.Sprint(web.APIs) != "[compute.googleapis.com]" || len(web.MissingAPIs) != 0 {
+		t.Errorf("Expected the compute API enabled and none missing, got %v and %v", web.APIs, web.MissingAPIs)
+	}
+
+	data := summary.Projects[1]
+	wantResources = []ResourceGroup{
+		{Type: "google_storage_bucket", Names: []string{"data-bucket"}},
+		{Type: "google_pubsub_topic", Names: []string{"events"}},
+	}
+	if fmt.Sprint(data.Resources) != fmt.Sprint(wantResources) {
+		t.Errorf("Expected %v without the disabled bucket, got %v", wantResources, data.Resources)
+	}
+	if len(data.APIs) != 0 || fmt.Sprint(data.MissingAPIs) != "[pubsub.googleapis.com]" {
+		t.Errorf("Expected no enabled APIs and the Pub/Sub API missing, got %v and %v", data.APIs, data.MissingAPIs)
+	}
+}
+
+func TestDurationSeconds(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"86400s", "86400s"},
+		{"1h", "3600s"},
+		{"1h30m", "5400s"},
+		{"90d", "7776000s"},
+		{"3600", "3600s"},
+		{"1.5s", "1.5s"},
+		{"soon", "soon"},
+	}
+
+	for _, test := range tests {
+		if result := durationSeconds(test.input); result != test.expected {
+			t.Errorf("durationSeconds(%q): expected %q, got %q", test.input, test.expected, result)
+		}
+	}
+
+	if result := durationMillis("30d"); result != 2592000000 {
+		t.Errorf("Expected 30d in milliseconds, got %d", result)
+	}
+
+	if result := spannerDuration("3d"); result != "3d" {
+		t.Errorf("Expected single-unit Spanner duration to be kept, got %q", result)
+	}
+	if result := spannerDuration("1h30m"); result != "5400s" {
+		t.Errorf("Expected multi-unit Spanner duration in seconds, got %q", result)
+	}
+}
+
+func TestNormalizePorts(t *testing.T) {
+	tests := []struct {
+		name     string
+		ports    []string
+		collapse bool
+		expected []string
+	}{
+		{"sorted and deduplicated", []string{"443", "80", "443", "22"}, false, []string{"22", "80", "443"}},
+		{"overlapping ranges merged", []string{"8000-8080", "8050", "8070-8090"}, false, []string{"8000-8090"}},
+		{"consecutive ports kept without collapse", []string{"82", "80", "81"}, false, []string{"80", "81", "82"}},
+		{"consecutive ports collapsed", []string{"82", "80", "81", "443"}, true, []string{"80-82", "443"}},
+		{"adjacent range collapsed", []string{"8000-8079", "8080"}, true, []string{"8000-8080"}},
+		{"invalid entries kept", []string{"8080-", "80", "8080-"}, false, []string{"80", "8080-"}},
+	}
+
+	for _, test := range tests {
+		result := normalizePorts(test.ports, test.collapse)
+		if strings.Join(result, ",") != strings.Join(test.expected, ",") {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, result)
+		}
+	}
+}
+
+func TestSchemaMarkdown(t *testing.T) {
+	content, err := SchemaMarkdown()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := []string{
+		"### Config\n\nRoot configuration message\n",
+		"| `project` | [Project](#project) | Conditional | Project configuration (required unless projects is set) |",
+		"| `id` | string | Yes | Project ID (required, must be globally unique) |",
+		"| `load_balancers` | repeated [LoadBalancer](#loadbalancer) | No |",
+		"| `labels` | map<string, string> | No | Labels for the project |",
+		"| `enabled` | bool | No |",
+		"### Region\n",
+		"| `REGION_US_CENTRAL1` | `us-central1` |",
+		"| `GCP_API_SECRET_MANAGER` | `secretmanager.googleapis.com` |",
+		"| `REGION_UNSPECIFIED` | — |",
+		"### DiskType\n",
+	}
+	for _, want := range expected {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected schema documentation to contain %q", want)
+		}
+	}
+
+	// Messages are documented in the order they are reached from Config
+	if strings.Index(content, "### Config\n") > strings.Index(content, "### Project\n") {
+		t.Error("Expected Config to be documented before Project")
+	}
+}
+
+func TestProtoComments(t *testing.T) {
+	fsys := fstest.MapFS{"example/example.proto": {Data: []byte(`syntax = "proto3";
+
+package example;
+
+// A thing
+message Thing {
+  // The name
+  // of the thing
+  string name = 1;
+
+  repeated string tags = 2; // Tags of the thing
+  map<string, int32> counts = 3;
+
+  oneof source {
+    // From a file
+    string file = 4;
+  }
+
+  // A nested part
+  message Part {
+    // Part size
+    optional int64 size = 1;
+  }
+}
+
+// Colors
+enum Color {
+  COLOR_UNSPECIFIED = 0;
+  // Red
+  COLOR_RED = 1;
+}
+`)}}
+
+	comments, err := protoComments(fsys)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := map[protoreflect.FullName]string{
+		"example.Thing":           "A thing",
+		"example.Thing.name":      "The name of the thing",
+		"example.Thing.tags":      "Tags of the thing",
+		"example.Thing.file":      "From a file",
+		"example.Thing.Part":      "A nested part",
+		"example.Thing.Part.size": "Part size",
+		"example.Color":           "Colors",
+		"example.COLOR_RED":       "Red",
+	}
+	for name, want := range expected {
+		if comments[name] != want {
+			t.Errorf("%s: expected comment %q, got %q", name, want, comments[name])
+		}
+	}
+	if len(comments) != len(expected) {
+		t.Errorf("Expected %d comments, got %d: %v", len(expected), len(comments), comments)
+	}
+}
+
+// benchmarkConfig returns a configuration that populates every resource type:
+// the example configurations merged together, plus the types they lack
+func benchmarkConfig(b *testing.B) *config.Config {
+	b.Helper()
+	cfg := &config.Config{}
+	for _, filename := range []string{
+		"../../examples/advanced.textproto",
+		"../../examples/cloud-run-demo.textproto",
+		"../../examples/databases-demo.textproto",
+		"../../examples/secret-manager-demo.textproto",
+	} {
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			b.Fatalf("Failed to read %s: %v", filename, err)
+		}
+		example := &config.Config{}
+		if err := config.Unmarshal(content, config.InputFormatForFile(filename), example); err != nil {
+			b.Fatalf("Failed to parse %s: %v", filename, err)
+		}
+		proto.Merge(cfg, example)
+	}
+
+	proto.Merge(cfg, &config.Config{
+		Kms: &config.Kms{KeyRings: []*config.KmsKeyRing{{
+			Name:       "app-keys",
+			Location:   "us-central1",
+			CryptoKeys: []*config.KmsCryptoKey{{Name: "data", RotationPeriod: "90d"}},
+		}}},
+		Monitoring: &config.Monitoring{
+			NotificationChannels: []*config.NotificationChannel{{Name: "pager", Type: "pagerduty"}},
+			AlertPolicies: []*config.AlertPolicy{{
+				Name: "high-cpu",
+				Conditions: []*config.AlertCondition{{
+					DisplayName:    "CPU above 80%",
+					Filter:         `metric.type="compute.googleapis.com/instance/cpu/utilization"`,
+					Comparison:     "COMPARISON_GT",
+					ThresholdValue: 0.8,
+				}},
+				NotificationChannels: []string{"pager"},
+			}},
+		},
+		LogSinks: []*config.LogSink{{
+			Name:        "audit",
+			Filter:      `logName:"cloudaudit.googleapis.com"`,
+			Destination: &config.LogSinkDestination{Target: &config.LogSinkDestination_PubsubTopic{PubsubTopic: "events"}},
+		}},
+		ResourceTags: &config.ResourceTags{Keys: []*config.TagKey{{
+			ShortName: "env",
+			Values:    []*config.TagValue{{ShortName: "prod"}},
+		}}},
+		Pubsub: &config.PubSub{Topics: []*config.PubSubTopic{{Name: "events"}}},
+		Scheduler: &config.Scheduler{Jobs: []*config.SchedulerJob{{
+			Name:     "nightly",
+			Schedule: "0 2 * * *",
+			Target:   &config.SchedulerJob_PubsubTarget{PubsubTarget: &config.SchedulerPubSubTarget{Topic: "events", Data: "run"}},
+		}}},
+		Tasks: &config.Tasks{Queues: []*config.TaskQueue{{Name: "work", Location: config.Region_REGION_US_CENTRAL1}}},
+		Filestore: &config.Filestore{Instances: []*config.FilestoreInstance{{
+			Name:      "shared-nfs",
+			Tier:      "BASIC_HDD",
+			Zone:      config.Zone_ZONE_US_CENTRAL1_A,
+			FileShare: &config.FilestoreFileShare{Name: "share1", CapacityGb: 1024},
+			Network:   "main-vpc",
+		}}},
+		Gke: &config.Gke{Clusters: []*config.GkeCluster{{
+			Name:       "main",
+			Region:     config.Region_REGION_US_CENTRAL1,
+			Network:    "main-vpc",
+			Subnetwork: "main-subnet",
+			NodePools:  []*config.GkeNodePool{{Name: "default", MachineType: config.MachineType_MACHINE_TYPE_E2_MEDIUM, NodeCount: 1}},
+		}}},
+		Dns: &config.Dns{ManagedZones: []*config.DnsManagedZone{{
+			Name:       "example",
+			DnsName:    "example.com.",
+			RecordSets: []*config.DnsRecordSet{{Name: "www", Type: "A", Rrdatas: []string{"203.0.113.10"}}},
+		}}},
+	})
+	return cfg
+}
+
+// BenchmarkGenerate compares generation limited to one CPU, where the files
+// are effectively rendered one after another, with generation on all CPUs
+func BenchmarkGenerate(b *testing.B) {
+	gen, err := New("builtin")
+	if err != nil {
+		b.Fatalf("Failed to create generator: %v", err)
+	}
+	cfg := benchmarkConfig(b)
+
+	procs := []int{1}
+	if n := runtime.NumCPU(); n > 1 {
+		procs = append(procs, n)
+	}
+	for _, n := range procs {
+		b.Run(fmt.Sprintf("procs=%d", n), func(b *testing.B) {
+			defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(n))
+			for i := 0; i < b.N; i++ {
+				if _, err := gen.Generate(cfg); err != nil {
+					b.Fatalf("Expected no error generating, got: %v", err)
+				}
+			}
+		})
+	}
+}
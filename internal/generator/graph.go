@@ -0,0 +1,461 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"custoodian/pkg/config"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Graph is the dependency graph of the resources generated for a configuration
+type Graph struct {
+	// Nodes are the generated resources, in configuration order
+	Nodes []GraphNode
+	// Edges point from a resource to a resource it depends on
+	Edges []GraphEdge
+}
+
+// GraphNode is a generated resource
+type GraphNode struct {
+	// Address is the Terraform resource address (e.g. "google_compute_network.main-vpc")
+	Address string
+	// Label describes the node when the address alone doesn't, e.g. the
+	// service enabled by a google_project_service resource
+	Label string
+	// Dir is the output subdirectory holding the resource in a multi-project
+	// configuration, or "" for a single-project configuration
+	Dir string
+}
+
+// GraphEdge is a dependency of the resource at From on the resource at To
+type GraphEdge struct {
+	From string
+	To   string
+	// Dir is the output subdirectory holding both resources
+	Dir string
+}
+
+// resourceAPIs maps Terraform resource type prefixes to the API the resources
+// need, matching the ProjectAPIs of the file generating them
+var resourceAPIs = []struct {
+	prefix string
+	api    string
+}{
+	{"google_compute_", "compute.googleapis.com"},
+	{"google_container_", "container.googleapis.com"},
+	{"google_cloud_run_", "run.googleapis.com"},
+	{"google_vpc_access_", "vpcaccess.googleapis.com"},
+	{"google_sql_", "sqladmin.googleapis.com"},
+	{"google_spanner_", "spanner.googleapis.com"},
+	{"google_secret_manager_", "secretmanager.googleapis.com"},
+	{"google_kms_", "cloudkms.googleapis.com"},
+	{"google_monitoring_", "monitoring.googleapis.com"},
+	{"google_logging_", "logging.googleapis.com"},
+	{"google_pubsub_", "pubsub.googleapis.com"},
+	{"google_cloud_tasks_", "cloudtasks.googleapis.com"},
+	{"google_cloud_scheduler_", "cloudscheduler.googleapis.com"},
+	{"google_filestore_", "file.googleapis.com"},
+	{"google_dns_", "dns.googleapis.com"},
+	{"google_bigquery_", "bigquery.googleapis.com"},
+	{"google_tags_", "cloudresourcemanager.googleapis.com"},
+	{"google_cloudfunctions2_", "cloudfunctions.googleapis.com"},
+	{"google_artifact_registry_", "artifactregistry.googleapis.com"},
+}
+
+// DependencyGraph returns the resources generated for the enabled resources of
+// cfg and the dependencies between them: references between resources (e.g.
+// subnet → VPC, instance group → instance template, load balancer → backend)
+// and resources on the project API they need. References to resources that
+// are not declared in cfg, such as networks managed elsewhere, are omitted.
+//
+// Like ImportTargets, addresses follow the resource names used by the built-in
+// templates.
+func DependencyGraph(cfg *config.Config) (*Graph, error) {
+	scoped, err := config.SplitByProject(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &Graph{}
+	for _, projectCfg := range scoped {
+		b := &graphBuilder{graph: graph, declared: make(map[string]bool)}
+		if len(cfg.Projects) > 0 {
+			b.dir = projectCfg.Project.Id
+		}
+		b.build(config.WithoutDisabled(projectCfg))
+	}
+	return graph, nil
+}
+
+// graphBuilder adds the nodes and edges of a single project to a graph
+type graphBuilder struct {
+	graph    *Graph
+	dir      string
+	declared map[string]bool
+	// edges are candidate dependencies, kept once both ends are declared
+	edges     []GraphEdge
+	projectId string
+}
+
+func (b *graphBuilder) build(cfg *config.Config) {
+	b.projectId = cfg.GetProject().GetId()
+	b.collectNodes(cfg.ProtoReflect())
+
+	// Project APIs, which resources wait for before being created
+	apis := make(map[string]string)
+	if cfg.Project != nil {
+		for i, api := range cfg.Project.Apis {
+			address := fmt.Sprintf("google_project_service.api_%d", i)
+			b.node(address, apiToString(api))
+			b.edge(address, "google_project.project")
+			apis[apiToString(api)] = address
+		}
+	}
+
+	b.addReferences(cfg)
+
+	// Every resource depends on the API of its type, if the project enables it
+	for _, node := range b.graph.Nodes {
+		if node.Dir != b.dir {
+			continue
+		}
+		for _, r := range resourceAPIs {
+			if strings.HasPrefix(node.Address, r.prefix) && apis[r.api] != "" {
+				b.edge(node.Address, apis[r.api])
+			}
+		}
+	}
+
+	seen := make(map[GraphEdge]bool)
+	for _, edge := range b.edges {
+		if b.declared[edge.From] && b.declared[edge.To] && edge.From != edge.To && !seen[edge] {
+			seen[edge] = true
+			b.graph.Edges = append(b.graph.Edges, edge)
+		}
+	}
+}
+
+// collectNodes adds a node for every resource in m and its nested resources
+// whose message is generated as a single Terraform resource
+func (b *graphBuilder) collectNodes(m protoreflect.Message) {
+	if resourceType, ok := importResourceTypes[m.Descriptor().Name()]; ok {
+		name := "project" // the project resource has a fixed name
+		if m.Descriptor().Name() != "Project" {
+			name = importResourceName(m)
+		}
+		b.node(resourceType+"."+name, "")
+	}
+
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Kind() != protoreflect.MessageKind || fd.IsMap() || !m.Has(fd) {
+			continue
+		}
+		if fd.IsList() {
+			list := m.Get(fd).List()
+			for j := 0; j < list.Len(); j++ {
+				b.collectNodes(list.Get(j).Message())
+			}
+		} else {
+			b.collectNodes(m.Get(fd).Message())
+		}
+	}
+}
+
+// addReferences adds the dependencies of resources on the resources they reference
+func (b *graphBuilder) addReferences(cfg *config.Config) {
+	if cfg.Networking != nil {
+		for _, vpc := range cfg.Networking.Vpcs {
+			for _, subnet := range vpc.Subnets {
+				b.edge("google_compute_subnetwork."+subnet.Name, "google_compute_network."+vpc.Name)
+			}
+		}
+		for _, rule := range cfg.Networking.FirewallRules {
+			b.edge("google_compute_firewall."+rule.Name, "google_compute_network."+rule.Network)
+		}
+		for _, nat := range cfg.Networking.NatGateways {
+			from := "google_compute_router_nat." + nat.Name
+			for _, ip := range nat.NatIps {
+				b.edge(from, "google_compute_address."+ip)
+			}
+			for _, subnet := range nat.SourceSubnetworkIpRangesToNat {
+				b.edge(from, "google_compute_subnetwork."+subnet.Name)
+			}
+		}
+		for _, peering := range cfg.Networking.Peerings {
+			from := "google_compute_network_peering." + peering.Name
+			b.edge(from, "google_compute_network."+peering.Network)
+			b.edge(from, "google_compute_network."+peering.PeerNetwork)
+		}
+	}
+
+	if cfg.Compute != nil {
+		for _, template := range cfg.Compute.InstanceTemplates {
+			from := "google_compute_instance_template." + template.Name
+			b.edgesTo(from, interfaceNetworkDeps(template.NetworkInterfaces))
+			b.edge(from, "google_kms_crypto_key."+template.KmsKey)
+			b.edge(from, b.serviceAccountAddress(template.ServiceAccount))
+		}
+		for _, group := range cfg.Compute.InstanceGroups {
+			from := "google_compute_instance_group_manager." + group.Name
+			b.edge(from, "google_compute_instance_template."+group.Template)
+			for _, version := range group.Versions {
+				b.edge(from, "google_compute_instance_template."+version.Template)
+			}
+		}
+		for _, group := range cfg.Compute.NodeGroups {
+			b.edge("google_compute_node_group."+group.Name, "google_compute_node_template."+group.NodeTemplate)
+		}
+		for _, instance := range cfg.Compute.Instances {
+			from := "google_compute_instance." + instance.Name
+			b.edgesTo(from, interfaceNetworkDeps(instance.NetworkInterfaces))
+			b.edge(from, b.serviceAccountAddress(instance.ServiceAccount))
+		}
+	}
+
+	// A load balancer is a chain of resources from the forwarding rule to the backend
+	for _, lb := range cfg.LoadBalancers {
+		rule := "google_compute_global_forwarding_rule." + lb.Name
+		proxy := "google_compute_target_http_proxy." + lb.Name
+		urlMap := "google_compute_url_map." + lb.Name
+		backend := "google_compute_backend_service." + lb.Name
+		for _, address := range []string{rule, proxy, urlMap, backend} {
+			b.node(address, "")
+		}
+		b.edge(rule, proxy)
+		b.edge(proxy, urlMap)
+		b.edge(urlMap, backend)
+		b.edge(rule, "google_compute_address."+lb.Ip)
+		b.edge(backend, "google_compute_instance_group_manager."+lb.Backend)
+		if lb.SslEnabled {
+			httpsRule := "google_compute_global_forwarding_rule." + lb.Name + "_https"
+			httpsProxy := "google_compute_target_https_proxy." + lb.Name
+			b.node(httpsRule, "")
+			b.node(httpsProxy, "")
+			b.edge(httpsRule, httpsProxy)
+			b.edge(httpsProxy, urlMap)
+			b.edge(httpsRule, "google_compute_address."+lb.Ip)
+			if len(lb.ManagedDomains) > 0 {
+				certificate := "google_compute_managed_ssl_certificate." + lb.Name
+				b.node(certificate, "")
+				b.edge(httpsProxy, certificate)
+			}
+		}
+		if lb.HealthCheck != nil {
+			healthCheck := "google_compute_health_check." + lb.HealthCheck.Name
+			b.node(healthCheck, "")
+			b.edge(backend, healthCheck)
+		}
+	}
+
+	if cfg.Storage != nil {
+		for _, bucket := range cfg.Storage.Buckets {
+			b.edge("google_storage_bucket."+bucket.Name, "google_kms_crypto_key."+bucket.KmsKey)
+		}
+	}
+
+	if cfg.Kms != nil {
+		for _, keyRing := range cfg.Kms.KeyRings {
+			for _, key := range keyRing.CryptoKeys {
+				b.edge("google_kms_crypto_key."+key.Name, "google_kms_key_ring."+keyRing.Name)
+			}
+		}
+	}
+
+	if cfg.CloudRun != nil {
+		for _, connector := range cfg.CloudRun.VpcConnectors {
+			from := "google_vpc_access_connector." + connector.Name
+			b.edge(from, "google_compute_network."+connector.Network)
+			b.edge(from, "google_compute_subnetwork."+connector.Subnet)
+		}
+		for _, service := range cfg.CloudRun.Services {
+			from := "google_cloud_run_service." + service.Name
+			b.edge(from, "google_vpc_access_connector."+service.GetConfig().GetVpcConnector())
+			b.edge(from, "google_vpc_access_connector."+service.GetConfig().GetVpcAccess().GetConnector())
+			b.edge(from, b.serviceAccountAddress(service.GetConfig().GetServiceAccount()))
+			for _, env := range service.GetConfig().GetEnvFromSecrets() {
+				b.edge(from, "google_secret_manager_secret."+env.SecretName)
+			}
+		}
+	}
+
+	if cfg.Databases != nil {
+		for _, instance := range cfg.Databases.CloudSqlInstances {
+			from := "google_sql_database_instance." + instance.Name
+			b.edge(from, "google_compute_network."+instance.GetNetwork().GetPrivateNetwork().GetPrivateNetwork())
+			b.edge(from, "google_kms_crypto_key."+instance.KmsKey)
+			b.edge(from, "google_sql_database_instance."+instance.MasterInstanceName)
+		}
+	}
+
+	if cfg.Monitoring != nil {
+		for _, policy := range cfg.Monitoring.AlertPolicies {
+			from := "google_monitoring_alert_policy." + policy.Name
+			for _, channel := range policy.NotificationChannels {
+				b.edge(from, "google_monitoring_notification_channel."+channel)
+			}
+			for _, condition := range policy.Conditions {
+				b.edge(from, "google_logging_metric."+condition.LogMetric)
+			}
+		}
+	}
+
+	for _, sink := range cfg.LogSinks {
+		b.edge("google_logging_project_sink."+sink.Name, "google_storage_bucket."+sink.GetDestination().GetStorageBucket())
+	}
+
+	if cfg.Scheduler != nil {
+		for _, job := range cfg.Scheduler.Jobs {
+			b.edge("google_cloud_scheduler_job."+job.Name, "google_pubsub_topic."+job.GetPubsubTarget().GetTopic())
+		}
+	}
+
+	if cfg.Filestore != nil {
+		for _, instance := range cfg.Filestore.Instances {
+			b.edge("google_filestore_instance."+instance.Name, "google_compute_network."+instance.Network)
+		}
+	}
+
+	if cfg.Gke != nil {
+		for _, cluster := range cfg.Gke.Clusters {
+			from := "google_container_cluster." + cluster.Name
+			b.edge(from, "google_compute_network."+cluster.Network)
+			b.edge(from, "google_compute_subnetwork."+cluster.Subnetwork)
+			for _, pool := range cluster.NodePools {
+				address := fmt.Sprintf("google_container_node_pool.%s_%s", cluster.Name, pool.Name)
+				b.node(address, "")
+				b.edge(address, from)
+				b.edge(address, "google_service_account."+pool.ServiceAccount)
+			}
+		}
+	}
+
+	if cfg.Dns != nil {
+		for _, zone := range cfg.Dns.ManagedZones {
+			from := "google_dns_managed_zone." + zone.Name
+			for _, network := range zone.Networks {
+				b.edge(from, "google_compute_network."+network)
+			}
+			for _, record := range zone.RecordSets {
+				address := "google_dns_record_set." + dnsRecordResourceName(zone, record)
+				b.node(address, "")
+				b.edge(address, from)
+			}
+		}
+	}
+
+	if cfg.Bigquery != nil {
+		for _, table := range cfg.Bigquery.Tables {
+			address := "google_bigquery_table." + table.Dataset + "_" + table.TableId
+			b.node(address, "")
+			b.edge(address, "google_bigquery_dataset."+table.Dataset)
+		}
+	}
+
+	if cfg.CloudFunctions != nil {
+		for _, function := range cfg.CloudFunctions.Functions {
+			from := "google_cloudfunctions2_function." + function.Name
+			b.edge(from, "google_storage_bucket."+function.SourceBucket)
+			b.edge(from, "google_pubsub_topic."+function.GetEventTrigger().GetPubsubTopic())
+			b.edge(from, b.serviceAccountAddress(function.ServiceAccount))
+			b.edge(from, b.serviceAccountAddress(function.GetEventTrigger().GetServiceAccount()))
+		}
+	}
+}
+
+// serviceAccountAddress returns the address of the declared service account an
+// account ID or email of the project refers to, or "" for other accounts
+func (b *graphBuilder) serviceAccountAddress(account string) string {
+	if accountId, domain, ok := strings.Cut(account, "@"); ok {
+		if domain != b.projectId+".iam.gserviceaccount.com" {
+			return ""
+		}
+		account = accountId
+	}
+	return "google_service_account." + account
+}
+
+func (b *graphBuilder) node(address, label string) {
+	if b.declared[address] {
+		return
+	}
+	b.declared[address] = true
+	b.graph.Nodes = append(b.graph.Nodes, GraphNode{Address: address, Label: label, Dir: b.dir})
+}
+
+func (b *graphBuilder) edge(from, to string) {
+	b.edges = append(b.edges, GraphEdge{From: from, To: to, Dir: b.dir})
+}
+
+func (b *graphBuilder) edgesTo(from string, to []string) {
+	for _, address := range to {
+		b.edge(from, address)
+	}
+}
+
+// DOT renders the graph in the Graphviz DOT language, with the resources of
+// each project of a multi-project configuration in their own cluster
+func (g *Graph) DOT() string {
+	var out strings.Builder
+	out.WriteString("digraph custoodian {\n")
+	out.WriteString("  rankdir = \"LR\";\n")
+	out.WriteString("  node [shape = box];\n")
+
+	id := func(dir, address string) string {
+		if dir != "" {
+			address = dir + "/" + address
+		}
+		return dotQuote(address)
+	}
+
+	var dirs []string
+	nodes := make(map[string][]GraphNode)
+	for _, node := range g.Nodes {
+		if _, ok := nodes[node.Dir]; !ok {
+			dirs = append(dirs, node.Dir)
+		}
+		nodes[node.Dir] = append(nodes[node.Dir], node)
+	}
+
+	for _, dir := range dirs {
+		indent := "  "
+		if dir != "" {
+			fmt.Fprintf(&out, "\n  subgraph %s {\n", dotQuote("cluster_"+dir))
+			fmt.Fprintf(&out, "    label = %s;\n", dotQuote(dir))
+			indent = "    "
+		} else {
+			out.WriteString("\n")
+		}
+		for _, node := range nodes[dir] {
+			switch {
+			case node.Label != "":
+				fmt.Fprintf(&out, "%s%s [label = %s];\n", indent, id(dir, node.Address), dotQuote(node.Label))
+			case dir != "":
+				fmt.Fprintf(&out, "%s%s [label = %s];\n", indent, id(dir, node.Address), dotQuote(node.Address))
+			default:
+				fmt.Fprintf(&out, "%s%s;\n", indent, id(dir, node.Address))
+			}
+		}
+		if dir != "" {
+			out.WriteString("  }\n")
+		}
+	}
+
+	if len(g.Edges) > 0 {
+		out.WriteString("\n")
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&out, "  %s -> %s;\n", id(edge.Dir, edge.From), id(edge.Dir, edge.To))
+	}
+
+	out.WriteString("}\n")
+	return out.String()
+}
+
+// dotQuote returns s as a quoted DOT ID
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -1,165 +1,439 @@
 package generator
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"custoodian/pkg/config"
 )
 
-// regionToString converts a Region enum to its string representation
+// regionNames are the GCP region names of the Region values
+var regionNames = map[config.Region]string{
+	config.Region_REGION_US_CENTRAL1:     "us-central1",
+	config.Region_REGION_US_EAST1:        "us-east1",
+	config.Region_REGION_US_EAST4:        "us-east4",
+	config.Region_REGION_US_WEST1:        "us-west1",
+	config.Region_REGION_US_WEST2:        "us-west2",
+	config.Region_REGION_US_WEST3:        "us-west3",
+	config.Region_REGION_US_WEST4:        "us-west4",
+	config.Region_REGION_EUROPE_WEST1:    "europe-west1",
+	config.Region_REGION_EUROPE_WEST2:    "europe-west2",
+	config.Region_REGION_EUROPE_WEST3:    "europe-west3",
+	config.Region_REGION_EUROPE_WEST4:    "europe-west4",
+	config.Region_REGION_EUROPE_WEST6:    "europe-west6",
+	config.Region_REGION_EUROPE_NORTH1:   "europe-north1",
+	config.Region_REGION_ASIA_EAST1:      "asia-east1",
+	config.Region_REGION_ASIA_EAST2:      "asia-east2",
+	config.Region_REGION_ASIA_NORTHEAST1: "asia-northeast1",
+	config.Region_REGION_ASIA_NORTHEAST2: "asia-northeast2",
+	config.Region_REGION_ASIA_NORTHEAST3: "asia-northeast3",
+	config.Region_REGION_ASIA_SOUTH1:     "asia-south1",
+	config.Region_REGION_ASIA_SOUTHEAST1: "asia-southeast1",
+	config.Region_REGION_ASIA_SOUTHEAST2: "asia-southeast2",
+}
+
+// regionToString converts a Region enum to its string representation.
+// Unspecified regions default to us-central1, which validate --strict rejects
+// where a resource needs a region.
 func regionToString(r config.Region) string {
-	regionMap := map[config.Region]string{
-		config.Region_REGION_US_CENTRAL1:     "us-central1",
-		config.Region_REGION_US_EAST1:        "us-east1",
-		config.Region_REGION_US_EAST4:        "us-east4",
-		config.Region_REGION_US_WEST1:        "us-west1",
-		config.Region_REGION_US_WEST2:        "us-west2",
-		config.Region_REGION_US_WEST3:        "us-west3",
-		config.Region_REGION_US_WEST4:        "us-west4",
-		config.Region_REGION_EUROPE_WEST1:    "europe-west1",
-		config.Region_REGION_EUROPE_WEST2:    "europe-west2",
-		config.Region_REGION_EUROPE_WEST3:    "europe-west3",
-		config.Region_REGION_EUROPE_WEST4:    "europe-west4",
-		config.Region_REGION_EUROPE_WEST6:    "europe-west6",
-		config.Region_REGION_EUROPE_NORTH1:   "europe-north1",
-		config.Region_REGION_ASIA_EAST1:      "asia-east1",
-		config.Region_REGION_ASIA_EAST2:      "asia-east2",
-		config.Region_REGION_ASIA_NORTHEAST1: "asia-northeast1",
-		config.Region_REGION_ASIA_NORTHEAST2: "asia-northeast2",
-		config.Region_REGION_ASIA_NORTHEAST3: "asia-northeast3",
-		config.Region_REGION_ASIA_SOUTH1:     "asia-south1",
-		config.Region_REGION_ASIA_SOUTHEAST1: "asia-southeast1",
-		config.Region_REGION_ASIA_SOUTHEAST2: "asia-southeast2",
-	}
-
-	if str, ok := regionMap[r]; ok {
+	if str, ok := regionNames[r]; ok {
 		return str
 	}
 	return "us-central1" // default
 }
 
+// regionExpression returns the quoted name of a region, or a reference to the
+// region variable when it is unspecified
+func regionExpression(r config.Region) string {
+	if r == config.Region_REGION_UNSPECIFIED {
+		return "var.region"
+	}
+	return quote(regionToString(r))
+}
+
+// mergeLabels returns the common labels with a resource's labels merged over
+// them, or nil when there are neither
+func mergeLabels(common, labels map[string]string) map[string]string {
+	if len(common) == 0 {
+		return labels
+	}
+	merged := make(map[string]string, len(common)+len(labels))
+	for key, value := range common {
+		merged[key] = value
+	}
+	for key, value := range labels {
+		merged[key] = value
+	}
+	return merged
+}
+
+// zoneNames are the GCP zone names of the Zone values
+var zoneNames = map[config.Zone]string{
+	config.Zone_ZONE_US_CENTRAL1_A:  "us-central1-a",
+	config.Zone_ZONE_US_CENTRAL1_B:  "us-central1-b",
+	config.Zone_ZONE_US_CENTRAL1_C:  "us-central1-c",
+	config.Zone_ZONE_US_CENTRAL1_F:  "us-central1-f",
+	config.Zone_ZONE_US_EAST1_B:     "us-east1-b",
+	config.Zone_ZONE_US_EAST1_C:     "us-east1-c",
+	config.Zone_ZONE_US_EAST1_D:     "us-east1-d",
+	config.Zone_ZONE_US_EAST4_A:     "us-east4-a",
+	config.Zone_ZONE_US_EAST4_B:     "us-east4-b",
+	config.Zone_ZONE_US_EAST4_C:     "us-east4-c",
+	config.Zone_ZONE_US_WEST1_A:     "us-west1-a",
+	config.Zone_ZONE_US_WEST1_B:     "us-west1-b",
+	config.Zone_ZONE_US_WEST1_C:     "us-west1-c",
+	config.Zone_ZONE_US_WEST2_A:     "us-west2-a",
+	config.Zone_ZONE_US_WEST2_B:     "us-west2-b",
+	config.Zone_ZONE_US_WEST2_C:     "us-west2-c",
+	config.Zone_ZONE_EUROPE_WEST1_B: "europe-west1-b",
+	config.Zone_ZONE_EUROPE_WEST1_C: "europe-west1-c",
+	config.Zone_ZONE_EUROPE_WEST1_D: "europe-west1-d",
+	config.Zone_ZONE_ASIA_EAST1_A:   "asia-east1-a",
+	config.Zone_ZONE_ASIA_EAST1_B:   "asia-east1-b",
+	config.Zone_ZONE_ASIA_EAST1_C:   "asia-east1-c",
+}
+
 // zoneToString converts a Zone enum to its string representation
 func zoneToString(z config.Zone) string {
-	zoneMap := map[config.Zone]string{
-		config.Zone_ZONE_US_CENTRAL1_A:  "us-central1-a",
-		config.Zone_ZONE_US_CENTRAL1_B:  "us-central1-b",
-		config.Zone_ZONE_US_CENTRAL1_C:  "us-central1-c",
-		config.Zone_ZONE_US_CENTRAL1_F:  "us-central1-f",
-		config.Zone_ZONE_US_EAST1_B:     "us-east1-b",
-		config.Zone_ZONE_US_EAST1_C:     "us-east1-c",
-		config.Zone_ZONE_US_EAST1_D:     "us-east1-d",
-		config.Zone_ZONE_US_EAST4_A:     "us-east4-a",
-		config.Zone_ZONE_US_EAST4_B:     "us-east4-b",
-		config.Zone_ZONE_US_EAST4_C:     "us-east4-c",
-		config.Zone_ZONE_US_WEST1_A:     "us-west1-a",
-		config.Zone_ZONE_US_WEST1_B:     "us-west1-b",
-		config.Zone_ZONE_US_WEST1_C:     "us-west1-c",
-		config.Zone_ZONE_US_WEST2_A:     "us-west2-a",
-		config.Zone_ZONE_US_WEST2_B:     "us-west2-b",
-		config.Zone_ZONE_US_WEST2_C:     "us-west2-c",
-		config.Zone_ZONE_EUROPE_WEST1_B: "europe-west1-b",
-		config.Zone_ZONE_EUROPE_WEST1_C: "europe-west1-c",
-		config.Zone_ZONE_EUROPE_WEST1_D: "europe-west1-d",
-		config.Zone_ZONE_ASIA_EAST1_A:   "asia-east1-a",
-		config.Zone_ZONE_ASIA_EAST1_B:   "asia-east1-b",
-		config.Zone_ZONE_ASIA_EAST1_C:   "asia-east1-c",
-	}
-
-	if str, ok := zoneMap[z]; ok {
+	if str, ok := zoneNames[z]; ok {
 		return str
 	}
 	return "us-central1-a" // default
 }
 
+// zoneExpression returns the quoted name of a zone, or a reference to the zone
+// variable when it is unspecified
+func zoneExpression(z config.Zone) string {
+	if z == config.Zone_ZONE_UNSPECIFIED {
+		return "var.zone"
+	}
+	return quote(zoneToString(z))
+}
+
+// machineTypeNames are the Compute Engine machine types of the MachineType values
+var machineTypeNames = map[config.MachineType]string{
+	config.MachineType_MACHINE_TYPE_E2_MICRO:       "e2-micro",
+	config.MachineType_MACHINE_TYPE_E2_SMALL:       "e2-small",
+	config.MachineType_MACHINE_TYPE_E2_MEDIUM:      "e2-medium",
+	config.MachineType_MACHINE_TYPE_E2_STANDARD_2:  "e2-standard-2",
+	config.MachineType_MACHINE_TYPE_E2_STANDARD_4:  "e2-standard-4",
+	config.MachineType_MACHINE_TYPE_E2_STANDARD_8:  "e2-standard-8",
+	config.MachineType_MACHINE_TYPE_E2_STANDARD_16: "e2-standard-16",
+	config.MachineType_MACHINE_TYPE_N1_STANDARD_1:  "n1-standard-1",
+	config.MachineType_MACHINE_TYPE_N1_STANDARD_2:  "n1-standard-2",
+	config.MachineType_MACHINE_TYPE_N1_STANDARD_4:  "n1-standard-4",
+	config.MachineType_MACHINE_TYPE_N1_STANDARD_8:  "n1-standard-8",
+	config.MachineType_MACHINE_TYPE_N1_STANDARD_16: "n1-standard-16",
+	config.MachineType_MACHINE_TYPE_N2_STANDARD_2:  "n2-standard-2",
+	config.MachineType_MACHINE_TYPE_N2_STANDARD_4:  "n2-standard-4",
+	config.MachineType_MACHINE_TYPE_N2_STANDARD_8:  "n2-standard-8",
+	config.MachineType_MACHINE_TYPE_N2_STANDARD_16: "n2-standard-16",
+	config.MachineType_MACHINE_TYPE_C2_STANDARD_4:  "c2-standard-4",
+	config.MachineType_MACHINE_TYPE_C2_STANDARD_8:  "c2-standard-8",
+	config.MachineType_MACHINE_TYPE_C2_STANDARD_16: "c2-standard-16",
+}
+
 // machineTypeToString converts a MachineType enum to its string representation
 func machineTypeToString(mt config.MachineType) string {
-	machineTypeMap := map[config.MachineType]string{
-		config.MachineType_MACHINE_TYPE_E2_MICRO:       "e2-micro",
-		config.MachineType_MACHINE_TYPE_E2_SMALL:       "e2-small",
-		config.MachineType_MACHINE_TYPE_E2_MEDIUM:      "e2-medium",
-		config.MachineType_MACHINE_TYPE_E2_STANDARD_2:  "e2-standard-2",
-		config.MachineType_MACHINE_TYPE_E2_STANDARD_4:  "e2-standard-4",
-		config.MachineType_MACHINE_TYPE_E2_STANDARD_8:  "e2-standard-8",
-		config.MachineType_MACHINE_TYPE_E2_STANDARD_16: "e2-standard-16",
-		config.MachineType_MACHINE_TYPE_N1_STANDARD_1:  "n1-standard-1",
-		config.MachineType_MACHINE_TYPE_N1_STANDARD_2:  "n1-standard-2",
-		config.MachineType_MACHINE_TYPE_N1_STANDARD_4:  "n1-standard-4",
-		config.MachineType_MACHINE_TYPE_N1_STANDARD_8:  "n1-standard-8",
-		config.MachineType_MACHINE_TYPE_N1_STANDARD_16: "n1-standard-16",
-		config.MachineType_MACHINE_TYPE_N2_STANDARD_2:  "n2-standard-2",
-		config.MachineType_MACHINE_TYPE_N2_STANDARD_4:  "n2-standard-4",
-		config.MachineType_MACHINE_TYPE_N2_STANDARD_8:  "n2-standard-8",
-		config.MachineType_MACHINE_TYPE_N2_STANDARD_16: "n2-standard-16",
-		config.MachineType_MACHINE_TYPE_C2_STANDARD_4:  "c2-standard-4",
-		config.MachineType_MACHINE_TYPE_C2_STANDARD_8:  "c2-standard-8",
-		config.MachineType_MACHINE_TYPE_C2_STANDARD_16: "c2-standard-16",
-	}
-
-	if str, ok := machineTypeMap[mt]; ok {
+	if str, ok := machineTypeNames[mt]; ok {
 		return str
 	}
 	return "e2-medium" // default
 }
 
+// apiNames are the service names of the GcpApi values
+var apiNames = map[config.GcpApi]string{
+	config.GcpApi_GCP_API_COMPUTE:           "compute.googleapis.com",
+	config.GcpApi_GCP_API_CONTAINER:         "container.googleapis.com",
+	config.GcpApi_GCP_API_SQL_ADMIN:         "sqladmin.googleapis.com",
+	config.GcpApi_GCP_API_STORAGE:           "storage.googleapis.com",
+	config.GcpApi_GCP_API_BIGQUERY:          "bigquery.googleapis.com",
+	config.GcpApi_GCP_API_PUBSUB:            "pubsub.googleapis.com",
+	config.GcpApi_GCP_API_DATAFLOW:          "dataflow.googleapis.com",
+	config.GcpApi_GCP_API_MONITORING:        "monitoring.googleapis.com",
+	config.GcpApi_GCP_API_LOGGING:           "logging.googleapis.com",
+	config.GcpApi_GCP_API_IAM:               "iam.googleapis.com",
+	config.GcpApi_GCP_API_RESOURCE_MANAGER:  "cloudresourcemanager.googleapis.com",
+	config.GcpApi_GCP_API_CLOUD_BUILD:       "cloudbuild.googleapis.com",
+	config.GcpApi_GCP_API_CLOUD_FUNCTIONS:   "cloudfunctions.googleapis.com",
+	config.GcpApi_GCP_API_CLOUD_RUN:         "run.googleapis.com",
+	config.GcpApi_GCP_API_KUBERNETES_ENGINE: "container.googleapis.com",
+	config.GcpApi_GCP_API_CLOUD_DNS:         "dns.googleapis.com",
+	config.GcpApi_GCP_API_CLOUD_CDN:         "compute.googleapis.com",
+	config.GcpApi_GCP_API_LOAD_BALANCING:    "compute.googleapis.com",
+	config.GcpApi_GCP_API_VPC_ACCESS:        "vpcaccess.googleapis.com",
+	config.GcpApi_GCP_API_FIREWALL:          "compute.googleapis.com",
+	config.GcpApi_GCP_API_SECRET_MANAGER:    "secretmanager.googleapis.com",
+	config.GcpApi_GCP_API_CLOUD_KMS:         "cloudkms.googleapis.com",
+	config.GcpApi_GCP_API_EVENTARC:          "eventarc.googleapis.com",
+	config.GcpApi_GCP_API_ARTIFACT_REGISTRY: "artifactregistry.googleapis.com",
+	config.GcpApi_GCP_API_CLOUD_SCHEDULER:   "cloudscheduler.googleapis.com",
+	config.GcpApi_GCP_API_CLOUD_TASKS:       "cloudtasks.googleapis.com",
+	config.GcpApi_GCP_API_FILESTORE:         "file.googleapis.com",
+}
+
 // apiToString converts a GcpApi enum to its service name
 func apiToString(api config.GcpApi) string {
-	apiMap := map[config.GcpApi]string{
-		config.GcpApi_GCP_API_COMPUTE:           "compute.googleapis.com",
-		config.GcpApi_GCP_API_CONTAINER:         "container.googleapis.com",
-		config.GcpApi_GCP_API_SQL_ADMIN:         "sqladmin.googleapis.com",
-		config.GcpApi_GCP_API_STORAGE:           "storage.googleapis.com",
-		config.GcpApi_GCP_API_BIGQUERY:          "bigquery.googleapis.com",
-		config.GcpApi_GCP_API_PUBSUB:            "pubsub.googleapis.com",
-		config.GcpApi_GCP_API_DATAFLOW:          "dataflow.googleapis.com",
-		config.GcpApi_GCP_API_MONITORING:        "monitoring.googleapis.com",
-		config.GcpApi_GCP_API_LOGGING:           "logging.googleapis.com",
-		config.GcpApi_GCP_API_IAM:               "iam.googleapis.com",
-		config.GcpApi_GCP_API_RESOURCE_MANAGER:  "cloudresourcemanager.googleapis.com",
-		config.GcpApi_GCP_API_CLOUD_BUILD:       "cloudbuild.googleapis.com",
-		config.GcpApi_GCP_API_CLOUD_FUNCTIONS:   "cloudfunctions.googleapis.com",
-		config.GcpApi_GCP_API_CLOUD_RUN:         "run.googleapis.com",
-		config.GcpApi_GCP_API_KUBERNETES_ENGINE: "container.googleapis.com",
-		config.GcpApi_GCP_API_CLOUD_DNS:         "dns.googleapis.com",
-		config.GcpApi_GCP_API_CLOUD_CDN:         "compute.googleapis.com",
-		config.GcpApi_GCP_API_LOAD_BALANCING:    "compute.googleapis.com",
-		config.GcpApi_GCP_API_VPC_ACCESS:        "vpcaccess.googleapis.com",
-		config.GcpApi_GCP_API_FIREWALL:          "compute.googleapis.com",
-		config.GcpApi_GCP_API_SECRET_MANAGER:    "secretmanager.googleapis.com",
-	}
-
-	if str, ok := apiMap[api]; ok {
+	if str, ok := apiNames[api]; ok {
 		return str
 	}
 	return ""
 }
 
+// networkTierNames are the network tiers of the NetworkTier values
+var networkTierNames = map[config.NetworkTier]string{
+	config.NetworkTier_NETWORK_TIER_PREMIUM:  "PREMIUM",
+	config.NetworkTier_NETWORK_TIER_STANDARD: "STANDARD",
+}
+
 // networkTierToString converts a NetworkTier enum to its string representation
 func networkTierToString(nt config.NetworkTier) string {
-	networkTierMap := map[config.NetworkTier]string{
-		config.NetworkTier_NETWORK_TIER_PREMIUM:  "PREMIUM",
-		config.NetworkTier_NETWORK_TIER_STANDARD: "STANDARD",
+	if str, ok := networkTierNames[nt]; ok {
+		return str
 	}
+	return "PREMIUM" // default
+}
+
+// vpcEgressNames are the Cloud Run annotation values of the VpcEgress values
+var vpcEgressNames = map[config.VpcEgress]string{
+	config.VpcEgress_VPC_EGRESS_ALL_TRAFFIC:         "all-traffic",
+	config.VpcEgress_VPC_EGRESS_PRIVATE_RANGES_ONLY: "private-ranges-only",
+}
 
-	if str, ok := networkTierMap[nt]; ok {
+// vpcEgressToString converts a VpcEgress enum to its Cloud Run annotation value
+func vpcEgressToString(e config.VpcEgress) string {
+	if str, ok := vpcEgressNames[e]; ok {
 		return str
 	}
-	return "PREMIUM" // default
+	return "private-ranges-only" // default
 }
 
-// indent adds indentation to each line of the input string
-func indent(spaces int, text string) string {
-	indentation := strings.Repeat(" ", spaces)
-	lines := strings.Split(text, "\n")
-	for i, line := range lines {
-		if strings.TrimSpace(line) != "" {
-			lines[i] = indentation + line
-		}
+// artifactFormatNames are the Artifact Registry formats of the ArtifactFormat values
+var artifactFormatNames = map[config.ArtifactFormat]string{
+	config.ArtifactFormat_ARTIFACT_FORMAT_DOCKER: "DOCKER",
+	config.ArtifactFormat_ARTIFACT_FORMAT_MAVEN:  "MAVEN",
+	config.ArtifactFormat_ARTIFACT_FORMAT_NPM:    "NPM",
+	config.ArtifactFormat_ARTIFACT_FORMAT_PYTHON: "PYTHON",
+}
+
+// artifactFormatToString converts an ArtifactFormat enum to its repository
+// format, or "" for an unspecified format, which validation rejects
+func artifactFormatToString(f config.ArtifactFormat) string {
+	return artifactFormatNames[f]
+}
+
+// providerSource returns the required_providers source address for a provider.
+// OpenTofu resolves providers from its own registry, so the address is fully
+// qualified there; Terraform uses the short HashiCorp registry form.
+func providerSource(outputFormat, provider string) string {
+	if outputFormat == "opentofu" {
+		return "registry.opentofu.org/hashicorp/" + provider
+	}
+	return "hashicorp/" + provider
+}
+
+// spannerDurationPattern matches the single-unit durations Spanner accepts
+var spannerDurationPattern = regexp.MustCompile(`^[0-9]+[smhd]$`)
+
+// durationSeconds normalizes a duration field (e.g. "1h", "7d") to the
+// "<seconds>s" format GCP APIs expect. Unparseable values, which validation
+// rejects, are returned unchanged.
+func durationSeconds(d string) string {
+	parsed, err := config.ParseDuration(d)
+	if err != nil {
+		return d
 	}
-	return strings.Join(lines, "\n")
+	return config.FormatSeconds(parsed)
 }
 
-// quote wraps a string in double quotes
+// durationMillis converts a duration field (e.g. "30d") to the milliseconds
+// BigQuery expects. Unparseable values, which validation rejects, become 0.
+func durationMillis(d string) int64 {
+	parsed, err := config.ParseDuration(d)
+	if err != nil {
+		return 0
+	}
+	return parsed.Milliseconds()
+}
+
+// spannerDuration formats a Spanner version_retention_period. Spanner accepts
+// a number with a single unit (s, m, h, or d), which is kept as written so it
+// matches what the API reports; anything else is normalized to seconds.
+func spannerDuration(d string) string {
+	if spannerDurationPattern.MatchString(d) {
+		return d
+	}
+	return durationSeconds(d)
+}
+
+// quote wraps a string in double quotes, escaping any embedded double quotes
+// (e.g. in monitoring filters). Backslashes are passed through so that escape
+// sequences such as \n keep their meaning in HCL.
 func quote(s string) string {
-	return fmt.Sprintf(`"%s"`, s)
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(s, `"`, `\"`))
+}
+
+// bigQueryField is a BigQuery schema field in the JSON form of the API
+type bigQueryField struct {
+	Name        string          `json:"name"`
+	Type        string          `json:"type"`
+	Mode        string          `json:"mode"`
+	Description string          `json:"description,omitempty"`
+	Fields      []bigQueryField `json:"fields,omitempty"`
+}
+
+// bigQueryFields converts schema fields to their JSON form, with modes
+// defaulting to NULLABLE as the API reports them
+func bigQueryFields(fields []*config.BigQueryField) []bigQueryField {
+	converted := make([]bigQueryField, 0, len(fields))
+	for _, field := range fields {
+		mode := field.Mode
+		if mode == "" {
+			mode = "NULLABLE"
+		}
+		converted = append(converted, bigQueryField{
+			Name:        field.Name,
+			Type:        field.Type,
+			Mode:        mode,
+			Description: field.Description,
+			Fields:      bigQueryFields(field.Fields),
+		})
+	}
+	return converted
+}
+
+// bigQuerySchema returns the schema of a BigQuery table, from its fields or
+// its schema_json, as indented JSON for a heredoc. Template sequences such as
+// ${ are escaped so Terraform keeps them literal.
+func bigQuerySchema(table *config.BigQueryTable) (string, error) {
+	var schema any = bigQueryFields(table.Fields)
+	if table.SchemaJson != "" {
+		if err := json.Unmarshal([]byte(table.SchemaJson), &schema); err != nil {
+			return "", fmt.Errorf("invalid schema_json for table %s: %w", table.TableId, err)
+		}
+	}
+
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	// the template places the schema in a heredoc indented by four spaces
+	encoder.SetIndent("    ", "  ")
+	if err := encoder.Encode(schema); err != nil {
+		return "", fmt.Errorf("failed to encode schema for table %s: %w", table.TableId, err)
+	}
+	escaped := strings.NewReplacer("${", "$${", "%{", "%%{").Replace(strings.TrimSpace(b.String()))
+	return escaped, nil
+}
+
+// dnsRecordResourceName returns the Terraform resource name of a record set:
+// the zone name, the record name relative to the zone ("apex" for the zone
+// apex, with * as "wildcard" and dots as underscores), and the lowercase
+// record type, joined by underscores (e.g. "main_www_a")
+func dnsRecordResourceName(zone *config.DnsManagedZone, record *config.DnsRecordSet) string {
+	relative := strings.TrimSuffix(config.DnsRecordName(zone.DnsName, record.Name), zone.DnsName)
+	relative = strings.TrimSuffix(relative, ".")
+	if relative == "" {
+		relative = "apex"
+	}
+	relative = strings.ReplaceAll(relative, "*", "wildcard")
+	relative = strings.ReplaceAll(relative, ".", "_")
+	return fmt.Sprintf("%s_%s_%s", zone.Name, relative, strings.ToLower(record.Type))
+}
+
+// normalizePorts sorts and deduplicates firewall port entries ("80" or
+// "8000-8080"), merging entries whose ranges overlap. With collapse set,
+// consecutive ports are also merged (80, 81, 82 become 80-82). Entries that are
+// not valid ports or ranges are kept as-is after the normalized ones.
+func normalizePorts(ports []string, collapse bool) []string {
+	type portRange struct{ start, end int }
+
+	var ranges []portRange
+	var invalid []string
+	seenInvalid := make(map[string]bool)
+	for _, port := range ports {
+		startStr, endStr, isRange := strings.Cut(port, "-")
+		start, err := strconv.Atoi(startStr)
+		end := start
+		if err == nil && isRange {
+			end, err = strconv.Atoi(endStr)
+		}
+		if err != nil || start > end {
+			if !seenInvalid[port] {
+				seenInvalid[port] = true
+				invalid = append(invalid, port)
+			}
+			continue
+		}
+		ranges = append(ranges, portRange{start, end})
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		if ranges[i].start != ranges[j].start {
+			return ranges[i].start < ranges[j].start
+		}
+		return ranges[i].end < ranges[j].end
+	})
+
+	var merged []portRange
+	for _, r := range ranges {
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+			gap := 0
+			if collapse {
+				gap = 1
+			}
+			if r.start <= last.end+gap {
+				if r.end > last.end {
+					last.end = r.end
+				}
+				continue
+			}
+		}
+		merged = append(merged, r)
+	}
+
+	result := make([]string, 0, len(merged)+len(invalid))
+	for _, r := range merged {
+		if r.start == r.end {
+			result = append(result, strconv.Itoa(r.start))
+		} else {
+			result = append(result, fmt.Sprintf("%d-%d", r.start, r.end))
+		}
+	}
+	return append(result, invalid...)
+}
+
+// sortedUnique returns the distinct values in sorted order, so that lists
+// built from the configuration (e.g. depends_on) don't vary with declaration order
+func sortedUnique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
+
+// serviceAccountMemberId returns the account ID of a user-managed service
+// account IAM member (e.g. "serviceAccount:web-sa@my-project.iam.gserviceaccount.com"
+// returns "web-sa"), or the empty string for other members
+func serviceAccountMemberId(member string) string {
+	email, ok := strings.CutPrefix(member, "serviceAccount:")
+	if !ok {
+		return ""
+	}
+	accountId, domain, ok := strings.Cut(email, "@")
+	if !ok || !strings.HasSuffix(domain, ".iam.gserviceaccount.com") {
+		return ""
+	}
+	return accountId
 }
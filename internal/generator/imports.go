@@ -0,0 +1,205 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"custoodian/pkg/config"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ImportTarget identifies an existing resource to bring under Terraform management
+type ImportTarget struct {
+	// Address is the Terraform resource address (e.g. "google_compute_network.main-vpc")
+	Address string
+	// ID is the provider-specific ID of the existing resource
+	ID string
+	// Dir is the output subdirectory holding the resource in a multi-project
+	// configuration, or "" for a single-project configuration
+	Dir string
+}
+
+// ImportsFileName is the name of the file of import blocks written by
+// GenerateImports
+const ImportsFileName = "imports.tf"
+
+// importResourceTypes maps configuration messages that support import_id to the
+// Terraform resource type generated for them by the built-in templates
+var importResourceTypes = map[protoreflect.Name]string{
+	"Project":              "google_project",
+	"ReservedIp":           "google_compute_address",
+	"Vpc":                  "google_compute_network",
+	"Subnet":               "google_compute_subnetwork",
+	"FirewallRule":         "google_compute_firewall",
+	"NatGateway":           "google_compute_router_nat",
+	"VpcPeering":           "google_compute_network_peering",
+	"InstanceTemplate":     "google_compute_instance_template",
+	"InstanceGroup":        "google_compute_instance_group_manager",
+	"Instance":             "google_compute_instance",
+	"NodeTemplate":         "google_compute_node_template",
+	"NodeGroup":            "google_compute_node_group",
+	"ServiceAccount":       "google_service_account",
+	"CustomRole":           "google_project_iam_custom_role",
+	"StorageBucket":        "google_storage_bucket",
+	"CloudRunService":      "google_cloud_run_service",
+	"CloudRunVpcConnector": "google_vpc_access_connector",
+	"CloudSqlInstance":     "google_sql_database_instance",
+	"CloudSpannerInstance": "google_spanner_instance",
+	"Secret":               "google_secret_manager_secret",
+	"KmsKeyRing":           "google_kms_key_ring",
+	"KmsCryptoKey":         "google_kms_crypto_key",
+	"NotificationChannel":  "google_monitoring_notification_channel",
+	"AlertPolicy":          "google_monitoring_alert_policy",
+	"LogMetric":            "google_logging_metric",
+	"LogSink":              "google_logging_project_sink",
+	"PubSubTopic":          "google_pubsub_topic",
+	"SchedulerJob":         "google_cloud_scheduler_job",
+	"TaskQueue":            "google_cloud_tasks_queue",
+	"FilestoreInstance":    "google_filestore_instance",
+	"GkeCluster":           "google_container_cluster",
+	"DnsManagedZone":       "google_dns_managed_zone",
+	"BigQueryDataset":      "google_bigquery_dataset",
+	"CloudFunction":        "google_cloudfunctions2_function",
+	"ArtifactRepository":   "google_artifact_registry_repository",
+}
+
+// ImportTargets returns the Terraform address and import ID of every enabled
+// resource in cfg that sets import_id, in configuration order. Resources of a
+// multi-project configuration are grouped by project.
+//
+// Addresses follow the resource names used by the built-in templates; custom
+// templates that rename resources need matching addresses.
+func ImportTargets(cfg *config.Config) ([]ImportTarget, error) {
+	scoped, err := config.SplitByProject(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []ImportTarget
+	for _, projectCfg := range scoped {
+		var projectTargets []ImportTarget
+		if err := collectImportTargets(config.WithoutDisabled(projectCfg).ProtoReflect(), &projectTargets); err != nil {
+			return nil, err
+		}
+		if len(cfg.Projects) > 0 {
+			for i := range projectTargets {
+				projectTargets[i].Dir = projectCfg.Project.Id
+			}
+		}
+		targets = append(targets, projectTargets...)
+	}
+	return targets, nil
+}
+
+// collectImportTargets appends the import targets of m and its nested resources to targets
+func collectImportTargets(m protoreflect.Message, targets *[]ImportTarget) error {
+	fields := m.Descriptor().Fields()
+	if fd := fields.ByName("import_id"); fd != nil && m.Get(fd).String() != "" {
+		resourceType, ok := importResourceTypes[m.Descriptor().Name()]
+		if !ok {
+			return fmt.Errorf("import is not supported for %s", m.Descriptor().Name())
+		}
+
+		name := "project" // the project resource has a fixed name
+		if m.Descriptor().Name() != "Project" {
+			name = importResourceName(m)
+		}
+
+		*targets = append(*targets, ImportTarget{
+			Address: resourceType + "." + name,
+			ID:      m.Get(fd).String(),
+		})
+	}
+
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Kind() != protoreflect.MessageKind || fd.IsMap() || !m.Has(fd) {
+			continue
+		}
+		if fd.IsList() {
+			list := m.Get(fd).List()
+			for j := 0; j < list.Len(); j++ {
+				if err := collectImportTargets(list.Get(j).Message(), targets); err != nil {
+					return err
+				}
+			}
+		} else if err := collectImportTargets(m.Get(fd).Message(), targets); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// importResourceName returns the field value templates use as the Terraform resource name
+func importResourceName(m protoreflect.Message) string {
+	for _, field := range []protoreflect.Name{"name", "account_id", "role_id", "dataset_id", "repository_id"} {
+		if fd := m.Descriptor().Fields().ByName(field); fd != nil {
+			return m.Get(fd).String()
+		}
+	}
+	return ""
+}
+
+// GenerateImports renders imports.tf with a Terraform import block for every
+// enabled resource in cfg that sets import_id, so that 'terraform plan'
+// adopts existing resources instead of creating them. Import blocks require
+// Terraform 1.5 or later; the imports command covers earlier versions.
+//
+// The result is keyed by file name like Generate's, with a file in each
+// project directory of a multi-project configuration that imports resources.
+// It is empty if no resource sets import_id. Template sources without an
+// imports.tf template use the built-in one.
+func (g *Generator) GenerateImports(cfg *config.Config) (map[string]string, error) {
+	targets, err := ImportTargets(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Targets are grouped by project, so each directory's are contiguous
+	var dirs []string
+	byDir := make(map[string][]ImportTarget)
+	for _, target := range targets {
+		if _, ok := byDir[target.Dir]; !ok {
+			dirs = append(dirs, target.Dir)
+		}
+		byDir[target.Dir] = append(byDir[target.Dir], target)
+	}
+
+	files := make(map[string]string)
+	for _, dir := range dirs {
+		content, err := g.renderImports(byDir[dir])
+		if err != nil {
+			return nil, err
+		}
+		files[path.Join(dir, ImportsFileName)] = content
+	}
+	return files, nil
+}
+
+// renderImports renders imports.tf for the import targets of a Terraform root
+func (g *Generator) renderImports(targets []ImportTarget) (string, error) {
+	tmpl, err := g.lookupOrBuiltin(ImportsFileName)
+	if err != nil {
+		return "", err
+	}
+
+	ctx := &TemplateContext{
+		Data:         targets,
+		Dependencies: &DependencyInfo{},
+		OutputFormat: g.outputFormat,
+	}
+
+	var output strings.Builder
+	if err := tmpl.Execute(&output, ctx); err != nil {
+		return "", fmt.Errorf("template execution failed for %s: %w", ImportsFileName, err)
+	}
+
+	if !g.formatOutput {
+		return output.String(), nil
+	}
+	return string(hclwrite.Format([]byte(output.String()))), nil
+}
@@ -0,0 +1,82 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ModuleVariable is an input variable of a generated module, recorded by
+// TemplateContext.Var for a value moved out of the resources
+type ModuleVariable struct {
+	// Name is the variable name (e.g. "bucket_assets_location")
+	Name string
+	// Type is the Terraform type of the variable: string, number, or bool
+	Type string
+	// Description describes the variable in variables.tf
+	Description string
+	// Value is the HCL literal of the configuration value, written to
+	// terraform.tfvars
+	Value string
+}
+
+// ModuleInputs collects the input variables of a generated module while its
+// files are rendered. It is safe for concurrent use.
+type ModuleInputs struct {
+	mu        sync.Mutex
+	variables map[string]*ModuleVariable
+}
+
+func newModuleInputs() *ModuleInputs {
+	return &ModuleInputs{variables: make(map[string]*ModuleVariable)}
+}
+
+// Variables returns the recorded variables sorted by name
+func (m *ModuleInputs) Variables() []*ModuleVariable {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	variables := make([]*ModuleVariable, 0, len(m.variables))
+	for _, variable := range m.variables {
+		variables = append(variables, variable)
+	}
+	sort.Slice(variables, func(i, j int) bool { return variables[i].Name < variables[j].Name })
+	return variables
+}
+
+func (m *ModuleInputs) add(variable *ModuleVariable) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.variables[variable.Name] = variable
+}
+
+// Var returns the HCL expression templates use for a name, region, or size.
+// When generating a module, value is recorded as the input variable name,
+// whose hyphens become underscores, and a reference to it is returned;
+// otherwise the value itself is returned as an HCL literal. Values are
+// strings, integers, or booleans.
+//
+// Example template usage:
+//
+//	location = {{ $.Var (printf "bucket_%s_location" .Name) (printf "Location of bucket %s" .Name) .Location }}
+func (c *TemplateContext) Var(name, description string, value interface{}) (string, error) {
+	var typ, literal string
+	switch v := value.(type) {
+	case string:
+		typ, literal = "string", quote(v)
+	case int, int32, int64, uint32, uint64:
+		typ, literal = "number", fmt.Sprintf("%d", v)
+	case bool:
+		typ, literal = "bool", fmt.Sprintf("%t", v)
+	default:
+		return "", fmt.Errorf("variable %s has unsupported value type %T", name, value)
+	}
+
+	if c.Module == nil {
+		return literal, nil
+	}
+	name = strings.ReplaceAll(name, "-", "_")
+	c.Module.add(&ModuleVariable{Name: name, Type: typ, Description: description, Value: literal})
+	return "var." + name, nil
+}
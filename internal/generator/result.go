@@ -0,0 +1,164 @@
+package generator
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"custoodian/pkg/config"
+)
+
+// GeneratedFile is a file generated by GenerateDetailed
+type GeneratedFile struct {
+	// Name is the path of the file relative to the output directory, as
+	// Generate keys it (e.g. "networking.tf" or "web-prod/networking.tf")
+	Name string
+	// Content is the content of the file
+	Content string
+	// ResourceType is the configuration section the file is generated from
+	// (e.g. "networking" or "cloud_run"), or for the files every configuration
+	// gets, "project", "backend", "variables", "outputs", or "metadata"
+	ResourceType string
+}
+
+// GenerateResult is the result of GenerateDetailed
+type GenerateResult struct {
+	// Files in a stable order: by project directory in declaration order,
+	// and within a directory in the order Generate documents them, starting
+	// with project.tf
+	Files []GeneratedFile
+	// FileCount is the number of files
+	FileCount int
+	// TotalBytes is the combined size of the file contents
+	TotalBytes int
+	// APIs are the service APIs the generated resources need, sorted
+	// (e.g. "compute.googleapis.com")
+	APIs []string
+}
+
+// generatedFileTypes lists the files Generate produces, in the order
+// GenerateDetailed returns them, with their resource type
+var generatedFileTypes = []struct {
+	name         string
+	resourceType string
+}{
+	{"project.tf", "project"},
+	{"backend.tf", "backend"},
+	{"networking.tf", "networking"},
+	{"compute.tf", "compute"},
+	{"load_balancers.tf", "load_balancers"},
+	{"iam.tf", "iam"},
+	{"storage.tf", "storage"},
+	{"cloud_run.tf", "cloud_run"},
+	{"databases.tf", "databases"},
+	{"secret_manager.tf", "secret_manager"},
+	{"kms.tf", "kms"},
+	{"monitoring.tf", "monitoring"},
+	{"logging.tf", "log_sinks"},
+	{"tags.tf", "resource_tags"},
+	{"pubsub.tf", "pubsub"},
+	{"scheduler.tf", "scheduler"},
+	{"tasks.tf", "tasks"},
+	{"filestore.tf", "filestore"},
+	{"gke.tf", "gke"},
+	{"dns.tf", "dns"},
+	{"bigquery.tf", "bigquery"},
+	{"functions.tf", "cloud_functions"},
+	{"artifacts.tf", "artifact_registry"},
+	{"variables.tf", "variables"},
+	{"terraform.tfvars", "variables"},
+	{"outputs.tf", "outputs"},
+	{"metadata.tf", "metadata"},
+}
+
+// GenerateDetailed generates the same files as Generate, returned in a
+// stable order with the resource type of each file and aggregate statistics,
+// for callers that lay out or report on the output themselves.
+func (g *Generator) GenerateDetailed(cfg *config.Config) (*GenerateResult, error) {
+	files, err := g.Generate(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := DependencyGraph(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Project directories come in declaration order
+	dirOrder := make(map[string]int)
+	for i, project := range cfg.Projects {
+		dirOrder[project.GetId()] = i
+	}
+	// Files renamed by the output settings keep the place of their default name
+	outputNames, err := config.OutputFileNames(cfg.Output)
+	if err != nil {
+		return nil, err
+	}
+	fileOrder := make(map[string]int)
+	resourceTypes := make(map[string]string)
+	for i, file := range generatedFileTypes {
+		name := file.name
+		if outputName, ok := outputNames[name]; ok {
+			name = outputName
+		}
+		fileOrder[name] = i
+		resourceTypes[name] = file.resourceType
+	}
+	rank := func(name string, order map[string]int) int {
+		if i, ok := order[name]; ok {
+			return i
+		}
+		return len(order)
+	}
+
+	result := &GenerateResult{Files: make([]GeneratedFile, 0, len(files))}
+	for name, content := range files {
+		result.Files = append(result.Files, GeneratedFile{
+			Name:         name,
+			Content:      content,
+			ResourceType: resourceTypes[path.Base(name)],
+		})
+		result.TotalBytes += len(content)
+	}
+	sort.Slice(result.Files, func(i, j int) bool {
+		a, b := result.Files[i].Name, result.Files[j].Name
+		if da, db := rank(path.Dir(a), dirOrder), rank(path.Dir(b), dirOrder); da != db {
+			return da < db
+		}
+		if fa, fb := rank(path.Base(a), fileOrder), rank(path.Base(b), fileOrder); fa != fb {
+			return fa < fb
+		}
+		return a < b
+	})
+	result.FileCount = len(result.Files)
+
+	apis := make(map[string]bool)
+	for _, node := range graph.Nodes {
+		for _, r := range resourceAPIs {
+			if strings.HasPrefix(node.Address, r.prefix) {
+				apis[r.api] = true
+			}
+		}
+	}
+	result.APIs = make([]string, 0, len(apis))
+	for api := range apis {
+		result.APIs = append(result.APIs, api)
+	}
+	sort.Strings(result.APIs)
+
+	return result, nil
+}
+
+// CountResources returns the number of resource blocks in generated
+// Terraform: the lines starting with `resource "`. Data sources, modules, and
+// import blocks are not counted.
+func CountResources(content string) int {
+	count := 0
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), `resource "`) {
+			count++
+		}
+	}
+	return count
+}
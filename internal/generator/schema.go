@@ -0,0 +1,260 @@
+package generator
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+
+	"custoodian/pkg/config"
+	"custoodian/proto"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// gcpEnumValues maps enums to the GCP strings their values generate, reusing
+// the conversion tables of the template functions
+var gcpEnumValues = map[protoreflect.FullName]func(protoreflect.EnumNumber) (string, bool){
+	config.Region(0).Descriptor().FullName():         enumValueNames(regionNames),
+	config.Zone(0).Descriptor().FullName():           enumValueNames(zoneNames),
+	config.MachineType(0).Descriptor().FullName():    enumValueNames(machineTypeNames),
+	config.GcpApi(0).Descriptor().FullName():         enumValueNames(apiNames),
+	config.NetworkTier(0).Descriptor().FullName():    enumValueNames(networkTierNames),
+	config.VpcEgress(0).Descriptor().FullName():      enumValueNames(vpcEgressNames),
+	config.ArtifactFormat(0).Descriptor().FullName(): enumValueNames(artifactFormatNames),
+}
+
+func enumValueNames[E ~int32](names map[E]string) func(protoreflect.EnumNumber) (string, bool) {
+	return func(n protoreflect.EnumNumber) (string, bool) {
+		name, ok := names[E(n)]
+		return name, ok
+	}
+}
+
+// SchemaMarkdown returns reference documentation for the configuration
+// schema in Markdown: a section per message, starting with Config, with a
+// table of its fields, followed by a section per enum listing its values and
+// the GCP strings they generate. Descriptions are the comments of the
+// embedded proto files.
+func SchemaMarkdown() (string, error) {
+	comments, err := protoComments(proto.Files)
+	if err != nil {
+		return "", err
+	}
+
+	var messages []protoreflect.MessageDescriptor
+	var enums []protoreflect.EnumDescriptor
+	seen := map[protoreflect.FullName]bool{}
+	var visit func(md protoreflect.MessageDescriptor)
+	visit = func(md protoreflect.MessageDescriptor) {
+		if seen[md.FullName()] {
+			return
+		}
+		seen[md.FullName()] = true
+		messages = append(messages, md)
+
+		// Document messages in the order their fields first use them
+		var nested []protoreflect.MessageDescriptor
+		fields := md.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			fd := fields.Get(i)
+			if fd.IsMap() {
+				fd = fd.MapValue()
+			}
+			switch {
+			case fd.Message() != nil:
+				nested = append(nested, fd.Message())
+			case fd.Enum() != nil && !seen[fd.Enum().FullName()]:
+				seen[fd.Enum().FullName()] = true
+				enums = append(enums, fd.Enum())
+			}
+		}
+		for _, child := range nested {
+			visit(child)
+		}
+	}
+	visit((&config.Config{}).ProtoReflect().Descriptor())
+
+	var b strings.Builder
+	b.WriteString("# Configuration Reference\n\n")
+	b.WriteString("Generated from the Protocol Buffer schema in `proto/custoodian`. Field names are written ")
+	b.WriteString("as in textproto, JSON, and YAML configuration files. Every field may be omitted in the ")
+	b.WriteString("encoding; the Required column follows the field's description, and `validate` reports ")
+	b.WriteString("fields a resource is missing.\n\n")
+
+	b.WriteString("## Messages\n")
+	for _, md := range messages {
+		fmt.Fprintf(&b, "\n### %s\n\n", md.Name())
+		if comment := comments[md.FullName()]; comment != "" {
+			fmt.Fprintf(&b, "%s\n\n", comment)
+		}
+		b.WriteString("| Field | Type | Required | Description |\n")
+		b.WriteString("|-------|------|----------|-------------|\n")
+		fields := md.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			fd := fields.Get(i)
+			comment := comments[fd.FullName()]
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", fd.Name(), fieldType(fd), fieldRequirement(comment), tableCell(comment))
+		}
+	}
+
+	b.WriteString("\n## Enums\n")
+	for _, ed := range enums {
+		fmt.Fprintf(&b, "\n### %s\n\n", ed.Name())
+		if comment := comments[ed.FullName()]; comment != "" {
+			fmt.Fprintf(&b, "%s\n\n", comment)
+		}
+		gcpValue := gcpEnumValues[ed.FullName()]
+		if gcpValue != nil {
+			b.WriteString("| Value | GCP value | Description |\n")
+			b.WriteString("|-------|-----------|-------------|\n")
+		} else {
+			b.WriteString("| Value | Description |\n")
+			b.WriteString("|-------|-------------|\n")
+		}
+		values := ed.Values()
+		for i := 0; i < values.Len(); i++ {
+			vd := values.Get(i)
+			description := tableCell(comments[vd.FullName()])
+			if gcpValue == nil {
+				fmt.Fprintf(&b, "| `%s` | %s |\n", vd.Name(), description)
+				continue
+			}
+			gcp := "—"
+			if name, ok := gcpValue(vd.Number()); ok {
+				gcp = "`" + name + "`"
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s |\n", vd.Name(), gcp, description)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// fieldType describes the type of a field, linking to the section of its
+// message or enum
+func fieldType(fd protoreflect.FieldDescriptor) string {
+	switch {
+	case fd.IsMap():
+		return fmt.Sprintf("map<%s, %s>", fd.MapKey().Kind(), valueType(fd.MapValue()))
+	case fd.IsList():
+		return "repeated " + valueType(fd)
+	default:
+		return valueType(fd)
+	}
+}
+
+func valueType(fd protoreflect.FieldDescriptor) string {
+	var name protoreflect.Name
+	switch {
+	case fd.Message() != nil:
+		name = fd.Message().Name()
+	case fd.Enum() != nil:
+		name = fd.Enum().Name()
+	default:
+		return fd.Kind().String()
+	}
+	return fmt.Sprintf("[%s](#%s)", name, strings.ToLower(string(name)))
+}
+
+// fieldRequirement classifies a field by what its comment says about it
+func fieldRequirement(comment string) string {
+	lower := strings.ToLower(comment)
+	switch {
+	case strings.Contains(lower, "(required)") || strings.Contains(lower, "(required,") || strings.HasPrefix(lower, "required"):
+		return "Yes"
+	case strings.Contains(lower, "required"):
+		return "Conditional"
+	default:
+		return "No"
+	}
+}
+
+// tableCell makes text safe to put in a Markdown table cell
+func tableCell(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
+
+var (
+	protoPackage = regexp.MustCompile(`^package\s+([\w.]+)\s*;`)
+	protoScope   = regexp.MustCompile(`^(message|enum|oneof)\s+(\w+)\s*\{`)
+	protoField   = regexp.MustCompile(`^(?:(?:optional|repeated)\s+)?(?:map<[^>]*>|[\w.]+)\s+(\w+)\s*=\s*\d+`)
+	protoValue   = regexp.MustCompile(`^(\w+)\s*=\s*-?\d+`)
+)
+
+// protoComments returns the comments of the messages, enums, fields, and enum
+// values declared in the .proto files of fsys, keyed by full name. A comment
+// is the run of // lines directly above a declaration, or failing that a
+// trailing // comment on its line.
+func protoComments(fsys fs.FS) (map[protoreflect.FullName]string, error) {
+	files, err := fs.Glob(fsys, "*/*.proto")
+	if err != nil {
+		return nil, err
+	}
+
+	comments := map[protoreflect.FullName]string{}
+	for _, file := range files {
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		var pkg protoreflect.FullName
+		// Names of the enclosing declarations; a oneof repeats its message's
+		// name, since its fields belong to the message
+		var scopes []protoreflect.FullName
+		var pending []string
+
+		scanner := bufio.NewScanner(strings.NewReader(string(content)))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if text, ok := strings.CutPrefix(line, "//"); ok {
+				pending = append(pending, strings.TrimSpace(text))
+				continue
+			}
+
+			code, trailing, _ := strings.Cut(line, "//")
+			code = strings.TrimSpace(code)
+			comment := strings.Join(pending, " ")
+			if comment == "" {
+				comment = strings.TrimSpace(trailing)
+			}
+			pending = nil
+
+			parent := pkg
+			enclosing := len(scopes) > 0
+			if enclosing {
+				parent = scopes[len(scopes)-1]
+			}
+
+			var name protoreflect.FullName
+			if m := protoPackage.FindStringSubmatch(code); m != nil {
+				pkg = protoreflect.FullName(m[1])
+			} else if m := protoScope.FindStringSubmatch(code); m != nil {
+				if m[1] == "oneof" {
+					scopes = append(scopes, parent)
+					continue
+				}
+				name = parent.Append(protoreflect.Name(m[2]))
+				scopes = append(scopes, name)
+			} else if m := protoField.FindStringSubmatch(code); m != nil && enclosing {
+				name = parent.Append(protoreflect.Name(m[1]))
+			} else if m := protoValue.FindStringSubmatch(code); m != nil && enclosing {
+				// Enum values are scoped like their enum, as siblings of it
+				name = parent.Parent().Append(protoreflect.Name(m[1]))
+			} else if strings.HasPrefix(code, "}") && enclosing {
+				scopes = scopes[:len(scopes)-1]
+			}
+
+			if name != "" && comment != "" {
+				comments[name] = comment
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+	}
+
+	return comments, nil
+}
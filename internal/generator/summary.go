@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"sort"
+	"strings"
+
+	"custoodian/pkg/config"
+)
+
+// Summary describes the resources generated for a configuration
+type Summary struct {
+	// Projects in declaration order, one for a single-project configuration
+	Projects []ProjectSummary
+}
+
+// ProjectSummary describes the resources generated for a project
+type ProjectSummary struct {
+	// Id is the project ID
+	Id string
+	// Name is the display name of the project
+	Name string
+	// Resources are the generated resources grouped by Terraform resource
+	// type, in the order of the first resource of each type in the
+	// configuration. The project and its API services are not included.
+	Resources []ResourceGroup
+	// APIs are the services the project enables, sorted
+	APIs []string
+	// MissingAPIs are the services the generated resources need that the
+	// project doesn't enable, sorted
+	MissingAPIs []string
+}
+
+// ResourceGroup is the generated resources of a Terraform resource type
+type ResourceGroup struct {
+	// Type is the Terraform resource type (e.g. "google_compute_network")
+	Type string
+	// Names are the resource names, in configuration order
+	Names []string
+}
+
+// Summarize returns the resources generated for the enabled resources of cfg
+// by project, with the APIs the project enables and those its resources need
+// but it doesn't enable. Like DependencyGraph, which it is built from,
+// resource names follow the built-in templates.
+func Summarize(cfg *config.Config) (*Summary, error) {
+	graph, err := DependencyGraph(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := cfg.Projects
+	if len(projects) == 0 {
+		projects = []*config.Project{cfg.Project}
+	}
+
+	summary := &Summary{}
+	for _, project := range projects {
+		dir := ""
+		if len(cfg.Projects) > 0 {
+			dir = project.GetId()
+		}
+		summary.Projects = append(summary.Projects, summarizeProject(graph, dir, project))
+	}
+	return summary, nil
+}
+
+// summarizeProject summarizes the nodes of graph in the output directory dir
+func summarizeProject(graph *Graph, dir string, project *config.Project) ProjectSummary {
+	summary := ProjectSummary{Id: project.GetId(), Name: project.GetName()}
+
+	groups := make(map[string]*ResourceGroup)
+	var types []string
+	enabled := make(map[string]bool)
+	required := make(map[string]bool)
+	for _, node := range graph.Nodes {
+		if node.Dir != dir {
+			continue
+		}
+		resourceType, name, _ := strings.Cut(node.Address, ".")
+		switch resourceType {
+		case "google_project":
+			continue
+		case "google_project_service":
+			enabled[node.Label] = true
+			continue
+		}
+
+		for _, r := range resourceAPIs {
+			if strings.HasPrefix(resourceType, r.prefix) {
+				required[r.api] = true
+			}
+		}
+		if groups[resourceType] == nil {
+			groups[resourceType] = &ResourceGroup{Type: resourceType}
+			types = append(types, resourceType)
+		}
+		groups[resourceType].Names = append(groups[resourceType].Names, name)
+	}
+
+	for _, resourceType := range types {
+		summary.Resources = append(summary.Resources, *groups[resourceType])
+	}
+	for api := range enabled {
+		summary.APIs = append(summary.APIs, api)
+	}
+	for api := range required {
+		if !enabled[api] {
+			summary.MissingAPIs = append(summary.MissingAPIs, api)
+		}
+	}
+	sort.Strings(summary.APIs)
+	sort.Strings(summary.MissingAPIs)
+	return summary
+}
@@ -12,57 +12,143 @@ func GetBuiltinTemplates() map[string]string {
 		"cloud_run.tf":      cloudRunTemplate,
 		"databases.tf":      databasesTemplate,
 		"secret_manager.tf": secretManagerTemplate,
+		"kms.tf":            kmsTemplate,
+		"monitoring.tf":     monitoringTemplate,
+		"logging.tf":        loggingTemplate,
+		"tags.tf":           tagsTemplate,
+		"pubsub.tf":         pubsubTemplate,
+		"scheduler.tf":      schedulerTemplate,
+		"tasks.tf":          tasksTemplate,
+		"filestore.tf":      filestoreTemplate,
+		"gke.tf":            gkeTemplate,
+		"dns.tf":            dnsTemplate,
+		"bigquery.tf":       bigQueryTemplate,
+		"functions.tf":      functionsTemplate,
+		"artifacts.tf":      artifactRegistryTemplate,
 		"variables.tf":      variablesTemplate,
 		"outputs.tf":        outputsTemplate,
+		"metadata.tf":       metadataTemplate,
+		"backend.tf":        backendTemplate,
+		"terraform.tfvars":  tfvarsTemplate,
+		"imports.tf":        importsTemplate,
+		"module_variables":  moduleVariablesTemplate,
+		"Makefile":          makefileTemplate,
 	}
 }
 
 const projectTemplate = `# Project Configuration
 # Generated by custoodian
 
-{{if .}}
+{{- $data := .Data -}}
+{{if $data}}
 # Configure the Google Cloud Provider
 terraform {
   required_providers {
     google = {
-      source  = "hashicorp/google"
-      version = "~> 5.0"
+      source  = {{ quote (providerSource .OutputFormat "google") }}
+      version = {{ quote (or $data.GoogleProviderVersion "~> 5.0") }}
+    }
+    {{- if $data.GoogleBetaProviderVersion}}
+    google-beta = {
+      source  = {{ quote (providerSource .OutputFormat "google-beta") }}
+      version = {{ quote $data.GoogleBetaProviderVersion }}
+    }
+    {{- end}}
+    {{- if and $data.Apis $data.ApiPropagationDelay}}
+    time = {
+      source  = {{ quote (providerSource .OutputFormat "time") }}
+      version = "~> 0.9"
+    }
+    {{- end}}
+    {{- if .Dependencies.RequiresRandomProvider}}
+    random = {
+      source  = {{ quote (providerSource .OutputFormat "random") }}
+      version = "~> 3.5"
     }
+    {{- end}}
   }
 }
 
 provider "google" {
-  project = {{ quote .Id }}
+  {{- if $.Module}}
+  project = var.project_id
+  region  = var.region
+  zone    = var.zone
+  {{- else}}
+  project = {{ quote $data.Id }}
+  region  = "us-central1"
+  zone    = "us-central1-a"
+  {{- end}}
+  {{- if $data.UserProjectOverride}}
+  user_project_override = true
+  {{- end}}
+  {{- if $data.BillingProject}}
+  billing_project = {{ quote $data.BillingProject }}
+  {{- end}}
+  {{- if $data.ProviderDefaultLabels}}
+
+  default_labels = {
+    {{- range $key, $value := $data.ProviderDefaultLabels}}
+    {{ quote $key }} = {{ quote $value }}
+    {{- end}}
+  }
+  {{- end}}
+}
+{{- if $data.GoogleBetaProviderVersion}}
+
+provider "google-beta" {
+  {{- if $.Module}}
+  project = var.project_id
+  region  = var.region
+  zone    = var.zone
+  {{- else}}
+  project = {{ quote $data.Id }}
   region  = "us-central1"
   zone    = "us-central1-a"
+  {{- end}}
+  {{- if $data.UserProjectOverride}}
+  user_project_override = true
+  {{- end}}
+  {{- if $data.BillingProject}}
+  billing_project = {{ quote $data.BillingProject }}
+  {{- end}}
+  {{- if $data.ProviderDefaultLabels}}
+
+  default_labels = {
+    {{- range $key, $value := $data.ProviderDefaultLabels}}
+    {{ quote $key }} = {{ quote $value }}
+    {{- end}}
+  }
+  {{- end}}
 }
+{{- end}}
 
 # Create the project
 resource "google_project" "project" {
-  name            = {{ quote .Name }}
-  project_id      = {{ quote .Id }}
-  {{- if .BillingAccount}}
-  billing_account = {{ quote .BillingAccount }}
+  name            = {{ $.Var "project_name" "Display name of the project" $data.Name }}
+  project_id      = {{ if $.Module }}var.project_id{{ else }}{{ quote $data.Id }}{{ end }}
+  {{- if $data.BillingAccount}}
+  billing_account = {{ $.Var "billing_account" "Billing account of the project" $data.BillingAccount }}
   {{- end}}
-  {{- if .OrganizationId}}
-  org_id          = {{ quote .OrganizationId }}
+  {{- if $data.OrganizationId}}
+  org_id          = {{ quote $data.OrganizationId }}
   {{- end}}
-  {{- if .FolderId}}
-  folder_id       = {{ quote .FolderId }}
+  {{- if $data.FolderId}}
+  folder_id       = {{ quote $data.FolderId }}
   {{- end}}
   
-  {{- if .Labels}}
+  {{- with mergeLabels $.CommonLabels $data.Labels}}
   labels = {
-    {{- range $key, $value := .Labels}}
+    {{- range $key, $value := .}}
     {{ quote $key }} = {{ quote $value }}
     {{- end}}
   }
   {{- end}}
 }
 
-{{- if .Apis}}
+{{- if $data.Apis}}
 # Enable required APIs
-{{- range $i, $api := .Apis}}
+{{- range $i, $api := $data.Apis}}
 resource "google_project_service" "api_{{ $i }}" {
   project = google_project.project.project_id
   service = {{ quote (apiToString $api) }}
@@ -72,6 +158,40 @@ resource "google_project_service" "api_{{ $i }}" {
 }
 {{- end}}
 {{- end}}
+
+{{- if and $data.Apis $data.ApiPropagationDelay}}
+
+# Give API enablement time to propagate before dependent resources are created
+resource "time_sleep" "api_propagation" {
+  create_duration = {{ quote (durationSeconds $data.ApiPropagationDelay) }}
+
+  depends_on = [
+    {{- range $i, $api := $data.Apis}}
+    {{- if $i}},{{end}}
+    google_project_service.api_{{ $i }}
+    {{- end}}
+  ]
+}
+{{- end}}
+
+{{- if and $data.DefaultServiceAccountsAction (ne $data.DefaultServiceAccountsAction "KEEP")}}
+
+# Manage the default Compute Engine and App Engine service accounts
+resource "google_project_default_service_accounts" "default" {
+  project = google_project.project.project_id
+  action  = {{ quote $data.DefaultServiceAccountsAction }}
+
+  {{- if $data.Apis}}
+  # Default service accounts are created when their APIs are enabled
+  depends_on = [
+    {{- range $i, $api := $data.Apis}}
+    {{- if $i}},{{end}}
+    google_project_service.api_{{ $i }}
+    {{- end}}
+  ]
+  {{- end}}
+}
+{{- end}}
 {{end}}
 `
 
@@ -86,11 +206,12 @@ const networkingTemplate = `# Networking Configuration
 {{- range $data.ReservedIps}}
 resource "google_compute_address" "{{ .Name }}" {
   name         = {{ quote .Name }}
+  {{- if .Address}}
+  address      = {{ quote .Address }}
+  {{- end}}
+  address_type = {{ quote (or .AddressType "EXTERNAL") }}
   {{- if eq .Type.String "REGIONAL"}}
-  address_type = "EXTERNAL"
   region       = {{ quote (regionToString .Region) }}
-  {{- else}}
-  address_type = "EXTERNAL"
   {{- end}}
   {{- if .Description}}
   description  = {{ quote .Description }}
@@ -122,6 +243,9 @@ resource "google_compute_network" "{{ .Name }}" {
     {{- if $i}},{{end}}
     google_project_service.api_{{ $i }}
     {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
   ]
   {{- end}}
 }
@@ -132,8 +256,8 @@ resource "google_compute_network" "{{ .Name }}" {
 {{- range .Subnets}}
 resource "google_compute_subnetwork" "{{ .Name }}" {
   name          = {{ quote .Name }}
-  ip_cidr_range = {{ quote .Cidr }}
-  region        = {{ quote (regionToString .Region) }}
+  ip_cidr_range = {{ $.Var (printf "subnet_%s_cidr" .Name) (printf "Primary IP range of subnet %s" .Name) .Cidr }}
+  region        = {{ $.Var (printf "subnet_%s_region" .Name) (printf "Region of subnet %s" .Name) (regionToString .Region) }}
   network       = google_compute_network.{{ $vpcName }}.id
   {{- if .Description}}
   description   = {{ quote .Description }}
@@ -217,7 +341,7 @@ resource "google_compute_firewall" "{{ .Name }}" {
     protocol = {{ quote .Protocol }}
     {{- if .Ports}}
     ports    = [
-      {{- range .Ports}}
+      {{- range normalizePorts .Ports $rule.CollapsePortRanges}}
       {{ quote . }},
       {{- end}}
     ]
@@ -232,7 +356,7 @@ resource "google_compute_firewall" "{{ .Name }}" {
     protocol = {{ quote .Protocol }}
     {{- if .Ports}}
     ports    = [
-      {{- range .Ports}}
+      {{- range normalizePorts .Ports $rule.CollapsePortRanges}}
       {{ quote . }},
       {{- end}}
     ]
@@ -287,6 +411,28 @@ resource "google_compute_router_nat" "{{ .Name }}" {
 }
 {{- end}}
 {{- end}}
+
+{{- if $data.Peerings}}
+# VPC Network Peerings
+{{- range $data.Peerings}}
+{{- $peering := .}}
+{{- $peerNetwork := quote .PeerNetwork}}
+{{- range $data.Vpcs}}
+{{- if eq .Name $peering.PeerNetwork}}{{ $peerNetwork = printf "google_compute_network.%s.self_link" .Name }}{{- end}}
+{{- end}}
+resource "google_compute_network_peering" "{{ .Name }}" {
+  name         = {{ quote .Name }}
+  network      = google_compute_network.{{ .Network }}.self_link
+  peer_network = {{ $peerNetwork }}
+  {{- if .ExportCustomRoutes}}
+  export_custom_routes = true
+  {{- end}}
+  {{- if .ImportCustomRoutes}}
+  import_custom_routes = true
+  {{- end}}
+}
+{{- end}}
+{{- end}}
 {{end}}
 `
 
@@ -295,6 +441,7 @@ const computeTemplate = `# Compute Configuration
 
 {{- $data := .Data -}}
 {{- $deps := .Dependencies -}}
+{{- $locations := .Locations -}}
 {{if $data}}
 {{- if $data.InstanceTemplates}}
 # Instance Templates
@@ -304,18 +451,31 @@ resource "google_compute_instance_template" "{{ .Name }}" {
   {{- if .Description}}
   description  = {{ quote .Description }}
   {{- end}}
-  machine_type = {{ quote (machineTypeToString .MachineType) }}
+  machine_type = {{ $.Var (printf "instance_template_%s_machine_type" .Name) (printf "Machine type of instance template %s" .Name) (machineTypeToString .MachineType) }}
+  region       = {{ index $locations.TemplateRegions .Name }}
   
   disk {
     source_image = {{ quote .Image }}
     auto_delete  = true
     boot         = true
     {{- if .DiskSizeGb}}
-    disk_size_gb = {{ .DiskSizeGb }}
+    disk_size_gb = {{ $.Var (printf "instance_template_%s_disk_size_gb" .Name) (printf "Boot disk size in GB of instance template %s" .Name) .DiskSizeGb }}
     {{- end}}
     {{- if .DiskType}}
     disk_type    = {{ quote .DiskType.String }}
     {{- end}}
+    {{- if .KmsKey}}
+
+    disk_encryption_key {
+      kms_key_self_link = google_kms_crypto_key.{{ .KmsKey }}.id
+    }
+    {{- end}}
+    {{- range .GuestOsFeatures}}
+
+    guest_os_features {
+      type = {{ quote . }}
+    }
+    {{- end}}
   }
   
   {{- if .NetworkInterfaces}}
@@ -328,6 +488,9 @@ resource "google_compute_instance_template" "{{ .Name }}" {
     {{- if .Subnetwork}}
     subnetwork = {{ quote .Subnetwork }}
     {{- end}}
+    {{- if .NicType}}
+    nic_type = {{ quote .NicType }}
+    {{- end}}
     
     {{- if .AccessConfigs}}
     {{- range .AccessConfigs}}
@@ -390,19 +553,54 @@ EOF
   ]
   {{- end}}
   
-  {{- if .Labels}}
+  {{- with mergeLabels $.CommonLabels .Labels}}
   labels = {
-    {{- range $key, $value := .Labels}}
+    {{- range $key, $value := .}}
     {{ quote $key }} = {{ quote $value }}
     {{- end}}
   }
   {{- end}}
   
-  {{- if .Preemptible}}
+  {{- if .ReservationAffinity}}
+  reservation_affinity {
+    type = {{ quote .ReservationAffinity.Type }}
+    {{- if eq .ReservationAffinity.Type "SPECIFIC_RESERVATION"}}
+    specific_reservation {
+      key    = {{ quote .ReservationAffinity.Key }}
+      values = [
+        {{- range .ReservationAffinity.Values}}
+        {{ quote . }},
+        {{- end}}
+      ]
+    }
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- if or .Preemptible .NodeAffinities}}
   scheduling {
+    {{- if .Preemptible}}
     preemptible = {{ .Preemptible }}
+    {{- end}}
+    {{- range .NodeAffinities}}
+    node_affinities {
+      key      = {{ quote .Key }}
+      operator = {{ if .Operator }}{{ quote .Operator }}{{ else }}"IN"{{ end }}
+      values   = [
+        {{- $key := .Key}}
+        {{- range .Values}}
+        {{- if eq $key "compute.googleapis.com/node-group-name"}}
+        google_compute_node_group.{{ . }}.name,
+        {{- else}}
+        {{ quote . }},
+        {{- end}}
+        {{- end}}
+      ]
+    }
+    {{- end}}
   }
   {{- end}}
+  {{- $grant := index $deps.CryptoKeyGrants .KmsKey}}
   
   {{- if $deps.RequiresNetworking}}
   # Wait for networking resources to be ready
@@ -417,13 +615,61 @@ EOF
     {{- if $i}},{{end}}
     google_project_service.api_{{ $i }}
     {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
     {{- end}}
+    {{- with $grant}},
+    {{ . }}
+    {{- end}}
+  ]
+  {{- else if $grant}}
+  # Wait for the Compute Engine service agent to be granted the disk key
+  depends_on = [
+    {{ $grant }}
   ]
   {{- end}}
 }
 {{- end}}
 {{- end}}
 
+{{- if $data.NodeTemplates}}
+# Sole-tenant Node Templates
+{{- range $data.NodeTemplates}}
+resource "google_compute_node_template" "{{ .Name }}" {
+  name      = {{ quote .Name }}
+  region    = {{ quote (regionToString .Region) }}
+  node_type = {{ quote .NodeType }}
+  {{- if .Description}}
+  description = {{ quote .Description }}
+  {{- end}}
+
+  {{- if .NodeAffinityLabels}}
+  node_affinity_labels = {
+    {{- range $key, $value := .NodeAffinityLabels}}
+    {{ quote $key }} = {{ quote $value }}
+    {{- end}}
+  }
+  {{- end}}
+}
+{{- end}}
+{{- end}}
+
+{{- if $data.NodeGroups}}
+# Sole-tenant Node Groups
+{{- range $data.NodeGroups}}
+resource "google_compute_node_group" "{{ .Name }}" {
+  name          = {{ quote .Name }}
+  zone          = {{ quote (zoneToString .Zone) }}
+  node_template = google_compute_node_template.{{ .NodeTemplate }}.id
+  initial_size  = {{ if .InitialSize }}{{ .InitialSize }}{{ else }}1{{ end }}
+  {{- if .Description}}
+  description   = {{ quote .Description }}
+  {{- end}}
+}
+{{- end}}
+{{- end}}
+
 {{- if $data.InstanceGroups}}
 # Instance Groups
 {{- range $data.InstanceGroups}}
@@ -435,7 +681,7 @@ resource "google_compute_instance_group_manager" "{{ .Name }}" {
   {{- if .BaseInstanceName}}
   base_instance_name = {{ quote .BaseInstanceName }}
   {{- end}}
-  target_size        = {{ .Size }}
+  target_size        = {{ $.Var (printf "instance_group_%s_size" .Name) (printf "Number of instances in instance group %s" .Name) .Size }}
   
   {{- if .Zones}}
   {{- $zoneCount := len .Zones}}
@@ -447,9 +693,33 @@ resource "google_compute_instance_group_manager" "{{ .Name }}" {
   {{- end}}
   {{- end}}
   
+  {{- if .Versions}}
+  {{- range .Versions}}
+
+  version {
+    {{- if .Name}}
+    name              = {{ quote .Name }}
+    {{- end}}
+    instance_template = google_compute_instance_template.{{ .Template }}.id
+    {{- if .TargetSizeFixed}}
+
+    target_size {
+      fixed = {{ .TargetSizeFixed }}
+    }
+    {{- else if .TargetSizePercent}}
+
+    target_size {
+      percent = {{ .TargetSizePercent }}
+    }
+    {{- end}}
+  }
+  {{- end}}
+  {{- else}}
+
   version {
     instance_template = google_compute_instance_template.{{ .Template }}.id
   }
+  {{- end}}
   
   {{- if .NamedPorts}}
   {{- range .NamedPorts}}
@@ -489,8 +759,11 @@ resource "google_compute_autoscaler" "{{ .Name }}_autoscaler" {
 {{- range $data.Instances}}
 resource "google_compute_instance" "{{ .Name }}" {
   name         = {{ quote .Name }}
+  {{- if .Description}}
+  description  = {{ quote .Description }}
+  {{- end}}
   machine_type = {{ quote (machineTypeToString .MachineType) }}
-  zone         = {{ quote (zoneToString .Zone) }}
+  zone         = {{ index $locations.InstanceZones .Name }}
 
   boot_disk {
     initialize_params {
@@ -508,6 +781,9 @@ resource "google_compute_instance" "{{ .Name }}" {
     {{- if .Subnetwork}}
     subnetwork = {{ quote .Subnetwork }}
     {{- end}}
+    {{- if .NicType}}
+    nic_type = {{ quote .NicType }}
+    {{- end}}
     
     {{- if .AccessConfigs}}
     {{- range .AccessConfigs}}
@@ -550,6 +826,35 @@ EOF
     {{- end}}
   ]
   {{- end}}
+
+  {{- with mergeLabels $.CommonLabels .Labels}}
+  labels = {
+    {{- range $key, $value := .}}
+    {{ quote $key }} = {{ quote $value }}
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- if .NodeAffinities}}
+  scheduling {
+    {{- range .NodeAffinities}}
+    node_affinities {
+      key      = {{ quote .Key }}
+      operator = {{ if .Operator }}{{ quote .Operator }}{{ else }}"IN"{{ end }}
+      values   = [
+        {{- $key := .Key}}
+        {{- range .Values}}
+        {{- if eq $key "compute.googleapis.com/node-group-name"}}
+        google_compute_node_group.{{ . }}.name,
+        {{- else}}
+        {{ quote . }},
+        {{- end}}
+        {{- end}}
+      ]
+    }
+    {{- end}}
+  }
+  {{- end}}
   
   {{- if $deps.RequiresNetworking}}
   # Wait for networking resources to be ready
@@ -564,6 +869,9 @@ EOF
     {{- if $i}},{{end}}
     google_project_service.api_{{ $i }}
     {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
     {{- end}}
   ]
   {{- end}}
@@ -581,6 +889,9 @@ const loadBalancersTemplate = `# Load Balancers Configuration
 # Load Balancer: {{ .Name }}
 resource "google_compute_global_forwarding_rule" "{{ .Name }}" {
   name       = {{ quote .Name }}
+  {{- if .Description}}
+  description = {{ quote .Description }}
+  {{- end}}
   target     = google_compute_target_http_proxy.{{ .Name }}.id
   {{- if .Ip}}
   ip_address = google_compute_address.{{ .Ip }}.address
@@ -595,6 +906,45 @@ resource "google_compute_target_http_proxy" "{{ .Name }}" {
   url_map = google_compute_url_map.{{ .Name }}.id
 }
 
+{{- if .SslEnabled}}
+
+resource "google_compute_global_forwarding_rule" "{{ .Name }}_https" {
+  name       = "{{ .Name }}-https"
+  {{- if .Description}}
+  description = {{ quote .Description }}
+  {{- end}}
+  target     = google_compute_target_https_proxy.{{ .Name }}.id
+  {{- if .Ip}}
+  ip_address = google_compute_address.{{ .Ip }}.address
+  {{- end}}
+  port_range = "443"
+}
+
+resource "google_compute_target_https_proxy" "{{ .Name }}" {
+  name             = "{{ .Name }}-https-proxy"
+  url_map          = google_compute_url_map.{{ .Name }}.id
+  {{- if .ManagedDomains}}
+  ssl_certificates = [google_compute_managed_ssl_certificate.{{ .Name }}.id]
+  {{- else}}
+  ssl_certificates = [{{ quote .SslCertificate }}]
+  {{- end}}
+}
+{{- if .ManagedDomains}}
+
+resource "google_compute_managed_ssl_certificate" "{{ .Name }}" {
+  name = "{{ .Name }}-cert"
+
+  managed {
+    domains = [
+      {{- range .ManagedDomains}}
+      {{ quote . }},
+      {{- end}}
+    ]
+  }
+}
+{{- end}}
+{{- end}}
+
 resource "google_compute_url_map" "{{ .Name }}" {
   name            = "{{ .Name }}-url-map"
   default_service = google_compute_backend_service.{{ .Name }}.id
@@ -602,7 +952,13 @@ resource "google_compute_url_map" "{{ .Name }}" {
 
 resource "google_compute_backend_service" "{{ .Name }}" {
   name        = "{{ .Name }}-backend"
+  {{- if .Description}}
+  description = {{ quote .Description }}
+  {{- end}}
   protocol    = "HTTP"
+  {{- if .PortName}}
+  port_name   = {{ quote .PortName }}
+  {{- end}}
   timeout_sec = 10
 
   backend {
@@ -617,8 +973,11 @@ resource "google_compute_backend_service" "{{ .Name }}" {
 {{- if .HealthCheck}}
 resource "google_compute_health_check" "{{ .HealthCheck.Name }}" {
   name = {{ quote .HealthCheck.Name }}
+  {{- if .HealthCheck.Description}}
+  description = {{ quote .HealthCheck.Description }}
+  {{- end}}
 
-  {{- if eq .HealthCheck.Type "HTTP"}}
+  {{- if or (eq .HealthCheck.Type "HTTP") (not .HealthCheck.Type)}}
   http_health_check {
     {{- if .HealthCheck.Port}}
     port         = {{ .HealthCheck.Port }}
@@ -626,6 +985,9 @@ resource "google_compute_health_check" "{{ .HealthCheck.Name }}" {
     {{- if .HealthCheck.RequestPath}}
     request_path = {{ quote .HealthCheck.RequestPath }}
     {{- end}}
+    {{- if .HealthCheck.Host}}
+    host         = {{ quote .HealthCheck.Host }}
+    {{- end}}
   }
   {{- else if eq .HealthCheck.Type "HTTPS"}}
   https_health_check {
@@ -635,12 +997,18 @@ resource "google_compute_health_check" "{{ .HealthCheck.Name }}" {
     {{- if .HealthCheck.RequestPath}}
     request_path = {{ quote .HealthCheck.RequestPath }}
     {{- end}}
+    {{- if .HealthCheck.Host}}
+    host         = {{ quote .HealthCheck.Host }}
+    {{- end}}
   }
   {{- else if eq .HealthCheck.Type "TCP"}}
   tcp_health_check {
     {{- if .HealthCheck.Port}}
     port = {{ .HealthCheck.Port }}
     {{- end}}
+    {{- if .HealthCheck.ProxyHeader}}
+    proxy_header = {{ quote .HealthCheck.ProxyHeader }}
+    {{- end}}
   }
   {{- end}}
 
@@ -722,6 +1090,15 @@ resource "google_project_iam_binding" "binding_{{ $i }}" {
     expression  = {{ quote $binding.Condition.Expression }}
   }
   {{- end}}
+  {{- with index $deps.ServiceAccountDependencies $i}}
+
+  depends_on = [
+    {{- range $j, $account := .}}
+    {{- if $j}},{{end}}
+    {{ $account }}
+    {{- end}}
+  ]
+  {{- end}}
 }
 {{- end}}
 {{- end}}
@@ -759,8 +1136,8 @@ const storageTemplate = `# Storage Configuration
 # Cloud Storage Buckets
 {{- range $data.Buckets}}
 resource "google_storage_bucket" "{{ .Name }}" {
-  name          = {{ quote .Name }}
-  location      = {{ quote .Location }}
+  name          = {{ $.Var (printf "bucket_%s_name" .Name) (printf "Name of bucket %s" .Name) .Name }}
+  location      = {{ $.Var (printf "bucket_%s_location" .Name) (printf "Location of bucket %s" .Name) .Location }}
   {{- if .StorageClass}}
   storage_class = {{ quote .StorageClass }}
   {{- end}}
@@ -775,9 +1152,15 @@ resource "google_storage_bucket" "{{ .Name }}" {
   }
   {{- end}}
 
-  {{- if .Labels}}
+  {{- if .KmsKey}}
+  encryption {
+    default_kms_key_name = google_kms_crypto_key.{{ .KmsKey }}.id
+  }
+  {{- end}}
+
+  {{- with mergeLabels $.CommonLabels .Labels}}
   labels = {
-    {{- range $key, $value := .Labels}}
+    {{- range $key, $value := .}}
     {{ quote $key }} = {{ quote $value }}
     {{- end}}
   }
@@ -811,6 +1194,13 @@ resource "google_storage_bucket" "{{ .Name }}" {
   }
   {{- end}}
   {{- end}}
+  {{- with index $deps.CryptoKeyGrants .KmsKey}}
+
+  # Wait for the Cloud Storage service agent to be granted the key
+  depends_on = [
+    {{ . }}
+  ]
+  {{- end}}
 }
 {{- end}}
 {{- end}}
@@ -840,9 +1230,29 @@ variable "zone" {
   default     = "us-central1-a"
 }
 
+{{- if .Monitoring}}
+{{- range .Monitoring.NotificationChannels}}
+{{- if eq .Type "slack"}}
+# Variable for notification channel: {{ .Name }}
+variable "notification_{{ .Name }}_auth_token" {
+  description = "Slack auth token for notification channel {{ .Name }}"
+  type        = string
+  sensitive   = true
+}
+{{- else if eq .Type "pagerduty"}}
+# Variable for notification channel: {{ .Name }}
+variable "notification_{{ .Name }}_service_key" {
+  description = "PagerDuty service key for notification channel {{ .Name }}"
+  type        = string
+  sensitive   = true
+}
+{{- end}}
+{{- end}}
+{{- end}}
+
 {{- if .SecretManager}}
 {{- range .SecretManager.Secrets}}
-{{- if or .GetFromEnvVar .GetFromGithubSecret}}
+{{- if not (or .GetPlainText .GetBase64Value .GetGenerate)}}
 # Variable for secret: {{ .Name }}
 variable "secret_{{ .Name }}_value" {
   description = "Value for secret {{ .Name }}"
@@ -852,6 +1262,8 @@ variable "secret_{{ .Name }}_value" {
   # Set via environment variable: {{ .GetFromEnvVar }}
   {{- else if .GetFromGithubSecret}}
   # Set via GitHub secret: {{ .GetFromGithubSecret }}
+  {{- else}}
+  # Set via TF_VAR_secret_{{ .Name }}_value or a .tfvars file
   {{- end}}
 }
 {{- end}}
@@ -949,9 +1361,9 @@ resource "google_cloud_run_service" "{{ .Name }}" {
   {{- if .Description}}
   
   metadata {
-    {{- if .Labels}}
+    {{- with mergeLabels $.CommonLabels .Labels}}
     labels = {
-      {{- range $key, $value := .Labels}}
+      {{- range $key, $value := .}}
       {{ quote $key }} = {{ quote $value }}
       {{- end}}
     }
@@ -991,6 +1403,25 @@ resource "google_cloud_run_service" "{{ .Name }}" {
         {{- if .Config.VpcConnector}}
         "run.googleapis.com/vpc-access-connector" = {{ quote .Config.VpcConnector }}
         {{- end}}
+        {{- with .Config.VpcAccess}}
+        {{- $vpcAccess := . }}
+        {{- if .Connector}}
+        {{- /* Reference the connector if it is defined in this config, otherwise use its name */}}
+        {{- $connectorFound := false }}
+        {{- range $data.VpcConnectors }}
+          {{- if eq .Name $vpcAccess.Connector }}
+            {{- $connectorFound = true }}
+        "run.googleapis.com/vpc-access-connector" = google_vpc_access_connector.{{ .Name }}.id
+          {{- end }}
+        {{- end }}
+        {{- if not $connectorFound }}
+        "run.googleapis.com/vpc-access-connector" = {{ quote .Connector }}
+        {{- end }}
+        {{- end}}
+        {{- if .Egress}}
+        "run.googleapis.com/vpc-access-egress" = {{ quote (vpcEgressToString .Egress) }}
+        {{- end}}
+        {{- end}}
         {{- if .Config.CpuThrottling}}
         "run.googleapis.com/cpu-throttling" = "true"
         {{- else}}
@@ -1043,8 +1474,12 @@ resource "google_cloud_run_service" "{{ .Name }}" {
           name = {{ quote .Name }}
           value_from {
             secret_key_ref {
+              {{- if index $deps.DeclaredSecrets .SecretName}}
+              name = google_secret_manager_secret.{{ .SecretName }}.secret_id
+              {{- else}}
               name = {{ quote .SecretName }}
-              key  = {{ quote .SecretName }}
+              {{- end}}
+              key  = {{ quote (or .Version "latest") }}
             }
           }
         }
@@ -1095,8 +1530,8 @@ resource "google_cloud_run_service" "{{ .Name }}" {
 
   {{- if .Traffic}}
   # Traffic allocation
+  {{- range .Traffic}}
   traffic {
-    {{- range .Traffic}}
     percent         = {{ .Percent }}
     {{- if .RevisionName}}
     revision_name   = {{ quote .RevisionName }}
@@ -1106,8 +1541,8 @@ resource "google_cloud_run_service" "{{ .Name }}" {
     {{- if .Tag}}
     tag             = {{ quote .Tag }}
     {{- end}}
-    {{- end}}
   }
+  {{- end}}
   {{- else}}
   traffic {
     percent         = 100
@@ -1122,6 +1557,9 @@ resource "google_cloud_run_service" "{{ .Name }}" {
     {{- if $i}},{{end}}
     google_project_service.api_{{ $i }}
     {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
   ]
   {{- end}}
 }
@@ -1146,12 +1584,16 @@ resource "google_cloud_run_service_iam_member" "{{ $service.Name }}_{{ $i }}" {
 {{- range $data.VpcConnectors}}
 resource "google_vpc_access_connector" "{{ .Name }}" {
   name          = {{ quote .Name }}
-  ip_cidr_range = {{ quote .IpCidrRange }}
-  network       = {{ quote .Network }}
+  {{- if .Region}}
+  region        = {{ quote (regionToString .Region) }}
+  {{- end}}
   {{- if .Subnet}}
   subnet {
     name = {{ quote .Subnet }}
   }
+  {{- else}}
+  ip_cidr_range = {{ quote .IpCidrRange }}
+  network       = {{ quote .Network }}
   {{- end}}
   {{- if .MachineType}}
   machine_type = {{ quote .MachineType }}
@@ -1175,6 +1617,9 @@ resource "google_vpc_access_connector" "{{ .Name }}" {
     {{- if $i}},{{end}}
     google_project_service.api_{{ $i }}
     {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
   ]
   {{- end}}
 }
@@ -1196,20 +1641,30 @@ const databasesTemplate = `# Database Configuration
 {{- range $data.CloudSqlInstances}}
 {{- $instance := . }}
 resource "google_sql_database_instance" "{{ .Name }}" {
-  name             = {{ quote .Name }}
+  name             = {{ $.Var (printf "sql_%s_name" .Name) (printf "Name of Cloud SQL instance %s" .Name) .Name }}
   database_version = {{ quote .DatabaseVersion }}
-  region           = {{ quote (regionToString .Region) }}
+  {{- if and .MasterInstanceName (not .Region)}}
+  region           = google_sql_database_instance.{{ .MasterInstanceName }}.region
+  {{- else}}
+  region           = {{ $.Var (printf "sql_%s_region" .Name) (printf "Region of Cloud SQL instance %s" .Name) (regionToString .Region) }}
+  {{- end}}
+  {{- if .MasterInstanceName}}
+  master_instance_name = google_sql_database_instance.{{ .MasterInstanceName }}.name
+  {{- end}}
   
   {{- if .DeletionProtection}}
   deletion_protection = {{ .DeletionProtection }}
   {{- end}}
+  {{- if .KmsKey}}
+  encryption_key_name = google_kms_crypto_key.{{ .KmsKey }}.id
+  {{- end}}
 
   settings {
-    tier = {{ quote .Tier }}
+    tier = {{ $.Var (printf "sql_%s_tier" .Name) (printf "Machine tier of Cloud SQL instance %s" .Name) .Tier }}
     
     {{- if .Storage}}
     disk_type       = {{ quote .Storage.Type }}
-    disk_size       = {{ .Storage.SizeGb }}
+    disk_size       = {{ $.Var (printf "sql_%s_disk_size" .Name) (printf "Disk size in GB of Cloud SQL instance %s" .Name) .Storage.SizeGb }}
     disk_autoresize = {{ .Storage.AutoResize }}
     {{- if .Storage.AutoResizeLimit}}
     disk_autoresize_limit = {{ .Storage.AutoResizeLimit }}
@@ -1295,9 +1750,9 @@ resource "google_sql_database_instance" "{{ .Name }}" {
     {{- end}}
     {{- end}}
 
-    {{- if .Labels}}
+    {{- with mergeLabels $.CommonLabels .Labels}}
     user_labels = {
-      {{- range $key, $value := .Labels}}
+      {{- range $key, $value := .}}
       {{ quote $key }} = {{ quote $value }}
       {{- end}}
     }
@@ -1308,6 +1763,7 @@ resource "google_sql_database_instance" "{{ .Name }}" {
   root_password = {{ quote .RootPassword }}
   {{- end}}
 
+  {{- $grant := index $deps.CryptoKeyGrants .KmsKey}}
   {{- if $deps.RequiresProjectAPIs}}
   # Wait for SQL Admin API to be enabled
   depends_on = [
@@ -1315,6 +1771,18 @@ resource "google_sql_database_instance" "{{ .Name }}" {
     {{- if $i}},{{end}}
     google_project_service.api_{{ $i }}
     {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
+    {{- with $grant}},
+    {{ . }}
+    {{- end}}
+  ]
+  {{- else if $grant}}
+
+  # Wait for the Cloud SQL service agent to be granted the instance key
+  depends_on = [
+    {{ $grant }}
   ]
   {{- end}}
 }
@@ -1338,10 +1806,36 @@ resource "google_sql_database" "{{ $instance.Name }}_{{ .Name }}" {
 {{- if .Users}}
 # Users for {{ .Name }}
 {{- range .Users}}
+{{- $user := . }}
+{{- with .GeneratePassword}}
+resource "random_password" "sql_{{ $instance.Name }}_{{ $user.Name }}" {
+  length = {{ if .Length }}{{ .Length }}{{ else }}32{{ end }}
+  {{- if and .Special (not .GetSpecial)}}
+  special = false
+  {{- end}}
+  {{- if .MinLower}}
+  min_lower = {{ .MinLower }}
+  {{- end}}
+  {{- if .MinUpper}}
+  min_upper = {{ .MinUpper }}
+  {{- end}}
+  {{- if .MinNumeric}}
+  min_numeric = {{ .MinNumeric }}
+  {{- end}}
+  {{- if .MinSpecial}}
+  min_special = {{ .MinSpecial }}
+  {{- end}}
+  {{- if .OverrideSpecial}}
+  override_special = {{ quote .OverrideSpecial }}
+  {{- end}}
+}
+{{- end}}
 resource "google_sql_user" "{{ $instance.Name }}_{{ .Name }}" {
   name     = {{ quote .Name }}
   instance = google_sql_database_instance.{{ $instance.Name }}.name
-  {{- if .Password}}
+  {{- if .GeneratePassword}}
+  password = random_password.sql_{{ $instance.Name }}_{{ .Name }}.result
+  {{- else if .Password}}
   password = {{ quote .Password }}
   {{- end}}
   {{- if .Host}}
@@ -1371,15 +1865,15 @@ resource "google_spanner_instance" "{{ .Name }}" {
   name         = {{ quote .Name }}
   
   {{- if .NodeCount}}
-  num_nodes = {{ .NodeCount }}
+  num_nodes = {{ $.Var (printf "spanner_%s_num_nodes" .Name) (printf "Number of nodes of Spanner instance %s" .Name) .NodeCount }}
   {{- end}}
   {{- if .ProcessingUnits}}
-  processing_units = {{ .ProcessingUnits }}
+  processing_units = {{ $.Var (printf "spanner_%s_processing_units" .Name) (printf "Processing units of Spanner instance %s" .Name) .ProcessingUnits }}
   {{- end}}
 
-  {{- if .Labels}}
+  {{- with mergeLabels $.CommonLabels .Labels}}
   labels = {
-    {{- range $key, $value := .Labels}}
+    {{- range $key, $value := .}}
     {{ quote $key }} = {{ quote $value }}
     {{- end}}
   }
@@ -1396,6 +1890,9 @@ resource "google_spanner_instance" "{{ .Name }}" {
     {{- if $i}},{{end}}
     google_project_service.api_{{ $i }}
     {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
   ]
   {{- end}}
 }
@@ -1420,7 +1917,7 @@ resource "google_spanner_database" "{{ $instance.Name }}_{{ .Name }}" {
   {{- end}}
 
   {{- if .VersionRetentionPeriod}}
-  version_retention_period = {{ quote .VersionRetentionPeriod }}
+  version_retention_period = {{ quote (spannerDuration .VersionRetentionPeriod) }}
   {{- end}}
 
   {{- if .Ddl}}
@@ -1453,9 +1950,9 @@ const secretManagerTemplate = `# Secret Manager Configuration
 resource "google_secret_manager_secret" "{{ .Name }}" {
   secret_id = {{ quote .Name }}
   
-  {{- if .Labels}}
+  {{- with mergeLabels $.CommonLabels .Labels}}
   labels = {
-    {{- range $key, $value := .Labels}}
+    {{- range $key, $value := .}}
     {{ quote $key }} = {{ quote $value }}
     {{- end}}
   }
@@ -1470,7 +1967,7 @@ resource "google_secret_manager_secret" "{{ .Name }}" {
   {{- end}}
   
   {{- if .Ttl}}
-  ttl = {{ quote .Ttl }}
+  ttl = {{ quote (durationSeconds .Ttl) }}
   {{- end}}
   
   {{- if .Topics}}
@@ -1518,15 +2015,45 @@ resource "google_secret_manager_secret" "{{ .Name }}" {
     {{- if $i}},{{end}}
     google_project_service.api_{{ $i }}
     {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
   ]
   {{- end}}
 }
+{{- with .GetGenerate}}
 
-# Secret version for {{ .Name }}
+resource "random_password" "secret_{{ $secret.Name }}" {
+  length = {{ if .Length }}{{ .Length }}{{ else }}32{{ end }}
+  {{- if and .Special (not .GetSpecial)}}
+  special = false
+  {{- end}}
+  {{- if .MinLower}}
+  min_lower = {{ .MinLower }}
+  {{- end}}
+  {{- if .MinUpper}}
+  min_upper = {{ .MinUpper }}
+  {{- end}}
+  {{- if .MinNumeric}}
+  min_numeric = {{ .MinNumeric }}
+  {{- end}}
+  {{- if .MinSpecial}}
+  min_special = {{ .MinSpecial }}
+  {{- end}}
+  {{- if .OverrideSpecial}}
+  override_special = {{ quote .OverrideSpecial }}
+  {{- end}}
+}
+{{- end}}
+
+# Secret version for {{ .Name }}
 resource "google_secret_manager_secret_version" "{{ .Name }}_version" {
   secret = google_secret_manager_secret.{{ .Name }}.id
   
-  {{- if .GetFromEnvVar}}
+  {{- if .GetGenerate}}
+  # Generated by Terraform and kept in the state
+  secret_data = random_password.secret_{{ .Name }}.result
+  {{- else if .GetFromEnvVar}}
   # Read value from environment variable: {{ .GetFromEnvVar }}
   secret_data = var.secret_{{ .Name }}_value
   {{- else if .GetFromGithubSecret}}
@@ -1567,3 +2094,1387 @@ resource "google_secret_manager_secret_version" "{{ $secret.Name }}_{{ . }}" {
 
 {{end}}
 `
+
+const kmsTemplate = `# Cloud KMS Configuration
+# Generated by custoodian
+
+{{- $data := .Data -}}
+{{- $deps := .Dependencies -}}
+{{if $data}}
+{{- if $data.KeyRings}}
+# KMS Key Rings
+{{- range $data.KeyRings}}
+{{- $keyRing := . }}
+resource "google_kms_key_ring" "{{ .Name }}" {
+  name     = {{ quote .Name }}
+  location = {{ quote .Location }}
+
+  {{- if $deps.RequiresProjectAPIs}}
+  # Wait for Cloud KMS API to be enabled
+  depends_on = [
+    {{- range $i, $api := $deps.ProjectAPIs}}
+    {{- if $i}},{{end}}
+    google_project_service.api_{{ $i }}
+    {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
+  ]
+  {{- end}}
+}
+
+{{- if .CryptoKeys}}
+# Crypto keys for {{ .Name }}
+{{- range .CryptoKeys}}
+resource "google_kms_crypto_key" "{{ .Name }}" {
+  name     = {{ quote .Name }}
+  key_ring = google_kms_key_ring.{{ $keyRing.Name }}.id
+  {{- if .Purpose}}
+  purpose  = {{ quote .Purpose }}
+  {{- end}}
+  {{- if .RotationPeriod}}
+  rotation_period = {{ quote (durationSeconds .RotationPeriod) }}
+  {{- end}}
+
+  {{- if or .ProtectionLevel .Algorithm}}
+  version_template {
+    {{- if .Algorithm}}
+    algorithm        = {{ quote .Algorithm }}
+    {{- else}}
+    algorithm        = "GOOGLE_SYMMETRIC_ENCRYPTION"
+    {{- end}}
+    {{- if .ProtectionLevel}}
+    protection_level = {{ quote .ProtectionLevel }}
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- with mergeLabels $.CommonLabels .Labels}}
+  labels = {
+    {{- range $key, $value := .}}
+    {{ quote $key }} = {{ quote $value }}
+    {{- end}}
+  }
+  {{- end}}
+
+  lifecycle {
+    prevent_destroy = true
+  }
+}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- end}}
+
+{{- if $data.ServiceAgentBindings}}
+# Service agent access to CMEK keys
+{{- range $data.ServiceAgentBindings}}
+resource "google_kms_crypto_key_iam_member" "{{ .Name }}" {
+  crypto_key_id = google_kms_crypto_key.{{ .CryptoKey }}.id
+  role          = "roles/cloudkms.cryptoKeyEncrypterDecrypter"
+  member        = {{ quote .Member }}
+}
+{{- end}}
+{{- end}}
+{{end}}
+`
+
+const monitoringTemplate = `# Monitoring Configuration
+# Generated by custoodian
+
+{{- $data := .Data -}}
+{{- $deps := .Dependencies -}}
+{{if $data}}
+{{- if $data.NotificationChannels}}
+# Notification Channels
+{{- range $data.NotificationChannels}}
+resource "google_monitoring_notification_channel" "{{ .Name }}" {
+  {{- if .DisplayName}}
+  display_name = {{ quote .DisplayName }}
+  {{- else}}
+  display_name = {{ quote .Name }}
+  {{- end}}
+  type         = {{ quote .Type }}
+  {{- if .Description}}
+  description  = {{ quote .Description }}
+  {{- end}}
+
+  {{- if .Labels}}
+  labels = {
+    {{- range $key, $value := .Labels}}
+    {{ quote $key }} = {{ quote $value }}
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- if eq .Type "slack"}}
+  sensitive_labels {
+    auth_token = var.notification_{{ .Name }}_auth_token
+  }
+  {{- else if eq .Type "pagerduty"}}
+  sensitive_labels {
+    service_key = var.notification_{{ .Name }}_service_key
+  }
+  {{- end}}
+
+  {{- if $deps.RequiresProjectAPIs}}
+  # Wait for Monitoring API to be enabled
+  depends_on = [
+    {{- range $i, $api := $deps.ProjectAPIs}}
+    {{- if $i}},{{end}}
+    google_project_service.api_{{ $i }}
+    {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
+  ]
+  {{- end}}
+}
+{{- end}}
+{{- end}}
+
+{{- if $data.LogMetrics}}
+# Log-based Metrics
+{{- range $data.LogMetrics}}
+resource "google_logging_metric" "{{ .Name }}" {
+  name   = {{ quote .Name }}
+  filter = {{ quote .Filter }}
+  {{- if .Description}}
+  description = {{ quote .Description }}
+  {{- end}}
+
+  metric_descriptor {
+    metric_kind = {{ if .MetricKind }}{{ quote .MetricKind }}{{ else }}"DELTA"{{ end }}
+    value_type  = {{ if .ValueType }}{{ quote .ValueType }}{{ else }}"INT64"{{ end }}
+    {{- if .Unit}}
+    unit        = {{ quote .Unit }}
+    {{- end}}
+  }
+
+  {{- if .ValueExtractor}}
+  value_extractor = {{ quote .ValueExtractor }}
+
+  # Default exponential buckets for distribution metrics
+  bucket_options {
+    exponential_buckets {
+      num_finite_buckets = 64
+      growth_factor      = 2
+      scale              = 0.01
+    }
+  }
+  {{- end}}
+}
+{{- end}}
+{{- end}}
+
+{{- if $data.AlertPolicies}}
+# Alert Policies
+{{- range $data.AlertPolicies}}
+resource "google_monitoring_alert_policy" "{{ .Name }}" {
+  {{- if .DisplayName}}
+  display_name = {{ quote .DisplayName }}
+  {{- else}}
+  display_name = {{ quote .Name }}
+  {{- end}}
+  {{- if .Combiner}}
+  combiner     = {{ quote .Combiner }}
+  {{- else}}
+  combiner     = "OR"
+  {{- end}}
+
+  {{- range .Conditions}}
+  conditions {
+    display_name = {{ quote .DisplayName }}
+    condition_threshold {
+      {{- if .LogMetric}}
+      {{- $metricFilter := printf "metric.type=\"logging.googleapis.com/user/${google_logging_metric.%s.name}\"" .LogMetric}}
+      filter          = {{ if .Filter }}{{ quote (printf "%s AND %s" $metricFilter .Filter) }}{{ else }}{{ quote $metricFilter }}{{ end }}
+      {{- else}}
+      filter          = {{ quote .Filter }}
+      {{- end}}
+      comparison      = {{ quote .Comparison }}
+      threshold_value = {{ .ThresholdValue }}
+      {{- if .Duration}}
+      duration        = {{ quote (durationSeconds .Duration) }}
+      {{- else}}
+      duration        = "0s"
+      {{- end}}
+      {{- if or .AlignmentPeriod .PerSeriesAligner}}
+      aggregations {
+        {{- if .AlignmentPeriod}}
+        alignment_period   = {{ quote (durationSeconds .AlignmentPeriod) }}
+        {{- end}}
+        {{- if .PerSeriesAligner}}
+        per_series_aligner = {{ quote .PerSeriesAligner }}
+        {{- end}}
+      }
+      {{- end}}
+    }
+  }
+  {{- end}}
+
+  {{- if .NotificationChannels}}
+  notification_channels = [
+    {{- range .NotificationChannels}}
+    google_monitoring_notification_channel.{{ . }}.name,
+    {{- end}}
+  ]
+  {{- end}}
+
+  {{- if .Documentation}}
+  documentation {
+    content   = {{ quote .Documentation }}
+    mime_type = "text/markdown"
+  }
+  {{- end}}
+
+  {{- with mergeLabels $.CommonLabels .Labels}}
+  user_labels = {
+    {{- range $key, $value := .}}
+    {{ quote $key }} = {{ quote $value }}
+    {{- end}}
+  }
+  {{- end}}
+}
+{{- end}}
+{{- end}}
+{{end}}
+`
+
+const loggingTemplate = `# Logging Configuration
+# Generated by custoodian
+
+{{- $data := .Data -}}
+{{- $deps := .Dependencies -}}
+{{if $data}}
+# Log Sinks
+{{- range $data}}
+{{- $dest := .Destination}}
+resource "google_logging_project_sink" "{{ .Name }}" {
+  name        = {{ quote .Name }}
+  filter      = {{ quote .Filter }}
+  {{- if .Description}}
+  description = {{ quote .Description }}
+  {{- end}}
+  {{- if $dest.GetStorageBucket}}
+  destination = "storage.googleapis.com/${google_storage_bucket.{{ $dest.GetStorageBucket }}.name}"
+  {{- else if $dest.GetPubsubTopic}}
+  destination = "pubsub.googleapis.com/projects/${var.project_id}/topics/{{ $dest.GetPubsubTopic }}"
+  {{- else if $dest.GetBigqueryDataset}}
+  destination = "bigquery.googleapis.com/projects/${var.project_id}/datasets/{{ $dest.GetBigqueryDataset }}"
+  {{- else}}
+  destination = {{ quote $dest.GetExternal }}
+  {{- end}}
+
+  unique_writer_identity = {{ .UniqueWriterIdentity }}
+
+  {{- if $deps.RequiresProjectAPIs}}
+  # Wait for Logging API to be enabled
+  depends_on = [
+    {{- range $i, $api := $deps.ProjectAPIs}}
+    {{- if $i}},{{end}}
+    google_project_service.api_{{ $i }}
+    {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
+  ]
+  {{- end}}
+}
+
+{{- if $dest.GetStorageBucket}}
+
+# Allow sink {{ .Name }} to write to its destination
+resource "google_storage_bucket_iam_member" "{{ .Name }}_writer" {
+  bucket = google_storage_bucket.{{ $dest.GetStorageBucket }}.name
+  role   = "roles/storage.objectCreator"
+  member = google_logging_project_sink.{{ .Name }}.writer_identity
+}
+{{- else if $dest.GetPubsubTopic}}
+
+# Allow sink {{ .Name }} to write to its destination
+resource "google_pubsub_topic_iam_member" "{{ .Name }}_writer" {
+  project = var.project_id
+  topic   = {{ quote $dest.GetPubsubTopic }}
+  role    = "roles/pubsub.publisher"
+  member  = google_logging_project_sink.{{ .Name }}.writer_identity
+}
+{{- else if $dest.GetBigqueryDataset}}
+
+# Allow sink {{ .Name }} to write to its destination
+resource "google_bigquery_dataset_iam_member" "{{ .Name }}_writer" {
+  project    = var.project_id
+  dataset_id = {{ quote $dest.GetBigqueryDataset }}
+  role       = "roles/bigquery.dataEditor"
+  member     = google_logging_project_sink.{{ .Name }}.writer_identity
+}
+{{- end}}
+{{- end}}
+{{end}}
+`
+
+const tagsTemplate = `# Resource Manager Tags Configuration
+# Generated by custoodian
+
+{{- $data := .Data -}}
+{{- $deps := .Dependencies -}}
+{{if $data}}
+{{- if $data.Keys}}
+# Tag Keys and Values
+{{- range $data.Keys}}
+{{- $key := .}}
+resource "google_tags_tag_key" "{{ replace .ShortName "." "_" }}" {
+  {{- if .Parent}}
+  parent      = {{ quote .Parent }}
+  {{- else}}
+  parent      = "projects/${google_project.project.project_id}"
+  {{- end}}
+  short_name  = {{ quote .ShortName }}
+  {{- if .Description}}
+  description = {{ quote .Description }}
+  {{- end}}
+
+  {{- if $deps.RequiresProjectAPIs}}
+  # Wait for Resource Manager API to be enabled
+  depends_on = [
+    {{- range $i, $api := $deps.ProjectAPIs}}
+    {{- if $i}},{{end}}
+    google_project_service.api_{{ $i }}
+    {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
+  ]
+  {{- end}}
+}
+{{- range .Values}}
+
+resource "google_tags_tag_value" "{{ replace $key.ShortName "." "_" }}_{{ replace .ShortName "." "_" }}" {
+  parent      = google_tags_tag_key.{{ replace $key.ShortName "." "_" }}.id
+  short_name  = {{ quote .ShortName }}
+  {{- if .Description}}
+  description = {{ quote .Description }}
+  {{- end}}
+}
+{{- end}}
+{{- end}}
+{{- end}}
+
+{{- if $data.Bindings}}
+# Tag Bindings
+{{- range $i, $binding := $data.Bindings}}
+{{- if $binding.Location}}
+resource "google_tags_location_tag_binding" "binding_{{ $i }}" {
+  location  = {{ quote $binding.Location }}
+{{- else}}
+resource "google_tags_tag_binding" "binding_{{ $i }}" {
+{{- end}}
+  {{- if $binding.Resource}}
+  parent    = {{ quote $binding.Resource }}
+  {{- else}}
+  parent    = "//cloudresourcemanager.googleapis.com/projects/${google_project.project.number}"
+  {{- end}}
+  tag_value = google_tags_tag_value.{{ replace $binding.Key "." "_" }}_{{ replace $binding.Value "." "_" }}.id
+}
+{{- end}}
+{{- end}}
+{{end}}
+`
+
+const pubsubTemplate = `# Pub/Sub Configuration
+# Generated by custoodian
+
+{{- $data := .Data -}}
+{{- $deps := .Dependencies -}}
+{{if $data}}
+{{- if $data.Topics}}
+# Pub/Sub Topics
+{{- range $data.Topics}}
+resource "google_pubsub_topic" "{{ .Name }}" {
+  name = {{ quote .Name }}
+  {{- if .MessageRetentionDuration}}
+  message_retention_duration = {{ quote (durationSeconds .MessageRetentionDuration) }}
+  {{- end}}
+
+  {{- with mergeLabels $.CommonLabels .Labels}}
+  labels = {
+    {{- range $key, $value := .}}
+    {{ quote $key }} = {{ quote $value }}
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- if $deps.RequiresProjectAPIs}}
+  # Wait for Pub/Sub API to be enabled
+  depends_on = [
+    {{- range $i, $api := $deps.ProjectAPIs}}
+    {{- if $i}},{{end}}
+    google_project_service.api_{{ $i }}
+    {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
+  ]
+  {{- end}}
+}
+{{- end}}
+{{- end}}
+{{end}}
+`
+
+const schedulerTemplate = `# Cloud Scheduler Configuration
+# Generated by custoodian
+
+{{- $data := .Data -}}
+{{- $deps := .Dependencies -}}
+{{if $data}}
+{{- if $data.Jobs}}
+# Cloud Scheduler Jobs
+{{- range $data.Jobs}}
+resource "google_cloud_scheduler_job" "{{ .Name }}" {
+  name             = {{ quote .Name }}
+  {{- if .Description}}
+  description      = {{ quote .Description }}
+  {{- end}}
+  schedule         = {{ quote .Schedule }}
+  time_zone        = {{ if .TimeZone }}{{ quote .TimeZone }}{{ else }}"Etc/UTC"{{ end }}
+  region           = {{ quote (regionToString .Region) }}
+  {{- if .AttemptDeadline}}
+  attempt_deadline = {{ quote (durationSeconds .AttemptDeadline) }}
+  {{- end}}
+  {{- if .Paused}}
+  paused           = true
+  {{- end}}
+
+  {{- with .RetryConfig}}
+
+  retry_config {
+    {{- if .RetryCount}}
+    retry_count          = {{ .RetryCount }}
+    {{- end}}
+    {{- if .MaxRetryDuration}}
+    max_retry_duration   = {{ quote (durationSeconds .MaxRetryDuration) }}
+    {{- end}}
+    {{- if .MinBackoffDuration}}
+    min_backoff_duration = {{ quote (durationSeconds .MinBackoffDuration) }}
+    {{- end}}
+    {{- if .MaxBackoffDuration}}
+    max_backoff_duration = {{ quote (durationSeconds .MaxBackoffDuration) }}
+    {{- end}}
+    {{- if .MaxDoublings}}
+    max_doublings        = {{ .MaxDoublings }}
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- with .GetHttpTarget}}
+
+  http_target {
+    uri         = {{ quote .Uri }}
+    http_method = {{ if .HttpMethod }}{{ quote .HttpMethod }}{{ else }}"POST"{{ end }}
+    {{- if .Body}}
+    body        = base64encode({{ quote .Body }})
+    {{- end}}
+    {{- if .Headers}}
+    headers = {
+      {{- range $key, $value := .Headers}}
+      {{ quote $key }} = {{ quote $value }}
+      {{- end}}
+    }
+    {{- end}}
+    {{- if .ServiceAccountEmail}}
+
+    oidc_token {
+      service_account_email = {{ quote .ServiceAccountEmail }}
+      audience              = {{ if .OidcAudience }}{{ quote .OidcAudience }}{{ else }}{{ quote .Uri }}{{ end }}
+    }
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- with .GetPubsubTarget}}
+
+  pubsub_target {
+    {{- if index $data.Topics .Topic}}
+    topic_name = google_pubsub_topic.{{ .Topic }}.id
+    {{- else}}
+    topic_name = {{ quote .Topic }}
+    {{- end}}
+    {{- if .Data}}
+    data       = base64encode({{ quote .Data }})
+    {{- end}}
+    {{- if .Attributes}}
+    attributes = {
+      {{- range $key, $value := .Attributes}}
+      {{ quote $key }} = {{ quote $value }}
+      {{- end}}
+    }
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- with .GetAppEngineTarget}}
+
+  app_engine_http_target {
+    relative_uri = {{ quote .RelativeUri }}
+    http_method  = {{ if .HttpMethod }}{{ quote .HttpMethod }}{{ else }}"POST"{{ end }}
+    {{- if .Body}}
+    body         = base64encode({{ quote .Body }})
+    {{- end}}
+    {{- if or .Service .Version}}
+
+    app_engine_routing {
+      {{- if .Service}}
+      service = {{ quote .Service }}
+      {{- end}}
+      {{- if .Version}}
+      version = {{ quote .Version }}
+      {{- end}}
+    }
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- if $deps.RequiresProjectAPIs}}
+  # Wait for Cloud Scheduler API to be enabled
+  depends_on = [
+    {{- range $i, $api := $deps.ProjectAPIs}}
+    {{- if $i}},{{end}}
+    google_project_service.api_{{ $i }}
+    {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
+  ]
+  {{- end}}
+}
+{{- end}}
+{{- end}}
+{{end}}
+`
+
+const tasksTemplate = `# Cloud Tasks Configuration
+# Generated by custoodian
+
+{{- $data := .Data -}}
+{{- $deps := .Dependencies -}}
+{{if $data}}
+{{- if $data.Queues}}
+# Cloud Tasks Queues
+{{- range $data.Queues}}
+resource "google_cloud_tasks_queue" "{{ .Name }}" {
+  name     = {{ quote .Name }}
+  location = {{ quote (regionToString .Location) }}
+
+  {{- with .RateLimits}}
+
+  rate_limits {
+    {{- if .MaxDispatchesPerSecond}}
+    max_dispatches_per_second = {{ .MaxDispatchesPerSecond }}
+    {{- end}}
+    {{- if .MaxConcurrentDispatches}}
+    max_concurrent_dispatches = {{ .MaxConcurrentDispatches }}
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- with .RetryConfig}}
+
+  retry_config {
+    {{- if .MaxAttempts}}
+    max_attempts       = {{ .MaxAttempts }}
+    {{- end}}
+    {{- if .MaxRetryDuration}}
+    max_retry_duration = {{ quote (durationSeconds .MaxRetryDuration) }}
+    {{- end}}
+    {{- if .MinBackoff}}
+    min_backoff        = {{ quote (durationSeconds .MinBackoff) }}
+    {{- end}}
+    {{- if .MaxBackoff}}
+    max_backoff        = {{ quote (durationSeconds .MaxBackoff) }}
+    {{- end}}
+    {{- if .MaxDoublings}}
+    max_doublings      = {{ .MaxDoublings }}
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- if $deps.RequiresProjectAPIs}}
+  # Wait for Cloud Tasks API to be enabled
+  depends_on = [
+    {{- range $i, $api := $deps.ProjectAPIs}}
+    {{- if $i}},{{end}}
+    google_project_service.api_{{ $i }}
+    {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
+  ]
+  {{- end}}
+}
+{{- end}}
+{{- end}}
+{{end}}
+`
+
+const filestoreTemplate = `# Filestore Configuration
+# Generated by custoodian
+
+{{- $data := .Data -}}
+{{- $deps := .Dependencies -}}
+{{if $data}}
+{{- if $data.Instances}}
+# Filestore Instances
+{{- range $data.Instances}}
+resource "google_filestore_instance" "{{ .Name }}" {
+  name     = {{ quote .Name }}
+  {{- if .Region}}
+  location = {{ quote (regionToString .Region) }}
+  {{- else}}
+  location = {{ quote (zoneToString .Zone) }}
+  {{- end}}
+  tier     = {{ quote .Tier }}
+  {{- if .Description}}
+  description = {{ quote .Description }}
+  {{- end}}
+
+  file_shares {
+    name        = {{ quote .FileShare.Name }}
+    capacity_gb = {{ .FileShare.CapacityGb }}
+  }
+
+  networks {
+    network = google_compute_network.{{ .Network }}.name
+    modes   = ["MODE_IPV4"]
+    {{- if .ConnectMode}}
+    connect_mode = {{ quote .ConnectMode }}
+    {{- end}}
+    {{- if .ReservedIpRange}}
+    reserved_ip_range = {{ quote .ReservedIpRange }}
+    {{- end}}
+  }
+
+  {{- with mergeLabels $.CommonLabels .Labels}}
+
+  labels = {
+    {{- range $key, $value := .}}
+    {{ quote $key }} = {{ quote $value }}
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- if or $deps.RequiresNetworking $deps.RequiresProjectAPIs}}
+  # Wait for the network and Filestore API
+  depends_on = [
+    google_compute_network.{{ .Network }}
+    {{- if $deps.RequiresProjectAPIs}}
+    {{- range $i, $api := $deps.ProjectAPIs}},
+    google_project_service.api_{{ $i }}
+    {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
+    {{- end}}
+  ]
+  {{- end}}
+}
+{{- end}}
+{{- end}}
+{{end}}
+`
+
+const gkeTemplate = `# GKE Configuration
+# Generated by custoodian
+
+{{- $data := .Data -}}
+{{- $deps := .Dependencies -}}
+{{if $data}}
+{{- if $data.Clusters}}
+# GKE Clusters
+{{- range $cluster := $data.Clusters}}
+{{- $location := "" }}
+{{- if $cluster.Region}}{{ $location = regionToString $cluster.Region }}{{ else }}{{ $location = zoneToString $cluster.Zone }}{{ end }}
+resource "google_container_cluster" "{{ $cluster.Name }}" {
+  name     = {{ quote $cluster.Name }}
+  location = {{ quote $location }}
+  {{- if $cluster.Description}}
+  description = {{ quote $cluster.Description }}
+  {{- end}}
+
+  network    = google_compute_network.{{ $cluster.Network }}.id
+  subnetwork = google_compute_subnetwork.{{ $cluster.Subnetwork }}.id
+
+  # Node pools are managed separately
+  remove_default_node_pool = true
+  initial_node_count       = 1
+  {{- if $cluster.DeletionProtection}}
+  deletion_protection      = {{ $cluster.GetDeletionProtection }}
+  {{- end}}
+
+  {{- if or $cluster.PodsSecondaryRange $cluster.ServicesSecondaryRange}}
+
+  ip_allocation_policy {
+    {{- if $cluster.PodsSecondaryRange}}
+    cluster_secondary_range_name  = {{ quote $cluster.PodsSecondaryRange }}
+    {{- end}}
+    {{- if $cluster.ServicesSecondaryRange}}
+    services_secondary_range_name = {{ quote $cluster.ServicesSecondaryRange }}
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- if $cluster.ReleaseChannel}}
+
+  release_channel {
+    channel = {{ quote $cluster.ReleaseChannel }}
+  }
+  {{- end}}
+
+  {{- if $cluster.WorkloadIdentity}}
+
+  workload_identity_config {
+    workload_pool = "${var.project_id}.svc.id.goog"
+  }
+  {{- end}}
+
+  {{- with mergeLabels $.CommonLabels $cluster.Labels}}
+
+  resource_labels = {
+    {{- range $key, $value := .}}
+    {{ quote $key }} = {{ quote $value }}
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- if $deps.RequiresNetworking}}
+
+  # Wait for networking resources and the Kubernetes Engine API
+  depends_on = [
+    {{- range $i, $net := $deps.NetworkDependencies}}
+    {{- if $i}},{{end}}
+    {{ $net }}
+    {{- end}}
+    {{- if and $deps.RequiresProjectAPIs (len $deps.ProjectAPIs)}}
+    {{- range $i, $api := $deps.ProjectAPIs}},
+    google_project_service.api_{{ $i }}
+    {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
+    {{- end}}
+  ]
+  {{- end}}
+}
+
+{{- range $pool := $cluster.NodePools}}
+
+resource "google_container_node_pool" "{{ $cluster.Name }}_{{ $pool.Name }}" {
+  name     = {{ quote $pool.Name }}
+  cluster  = google_container_cluster.{{ $cluster.Name }}.id
+  location = {{ quote $location }}
+  {{- if $pool.MaxNodeCount}}
+
+  autoscaling {
+    min_node_count = {{ $pool.MinNodeCount }}
+    max_node_count = {{ $pool.MaxNodeCount }}
+  }
+  {{- else}}
+  node_count = {{ $pool.NodeCount }}
+  {{- end}}
+
+  node_config {
+    machine_type = {{ quote (machineTypeToString $pool.MachineType) }}
+    {{- if $pool.DiskSizeGb}}
+    disk_size_gb = {{ $pool.DiskSizeGb }}
+    {{- end}}
+    {{- if $pool.Spot}}
+    spot         = true
+    {{- end}}
+    {{- if $pool.ServiceAccount}}
+    service_account = google_service_account.{{ $pool.ServiceAccount }}.email
+    {{- end}}
+    oauth_scopes = ["https://www.googleapis.com/auth/cloud-platform"]
+    {{- if $pool.Tags}}
+    tags = [
+      {{- range $pool.Tags}}
+      {{ quote . }},
+      {{- end}}
+    ]
+    {{- end}}
+    {{- if $pool.Labels}}
+
+    labels = {
+      {{- range $key, $value := $pool.Labels}}
+      {{ quote $key }} = {{ quote $value }}
+      {{- end}}
+    }
+    {{- end}}
+    {{- if $cluster.WorkloadIdentity}}
+
+    workload_metadata_config {
+      mode = "GKE_METADATA"
+    }
+    {{- end}}
+  }
+}
+{{- end}}
+{{- end}}
+{{- end}}
+{{end}}
+`
+
+const dnsTemplate = `# Cloud DNS Configuration
+# Generated by custoodian
+
+{{- $data := .Data -}}
+{{- $deps := .Dependencies -}}
+{{if $data}}
+{{- if $data.ManagedZones}}
+# Managed Zones
+{{- range $zone := $data.ManagedZones}}
+resource "google_dns_managed_zone" "{{ $zone.Name }}" {
+  name     = {{ quote $zone.Name }}
+  dns_name = {{ quote $zone.DnsName }}
+  {{- if $zone.Description}}
+  description = {{ quote $zone.Description }}
+  {{- end}}
+  {{- if $zone.Visibility}}
+  visibility  = {{ quote $zone.Visibility }}
+  {{- end}}
+
+  {{- if eq $zone.Visibility "private"}}
+
+  private_visibility_config {
+    {{- range $zone.Networks}}
+    networks {
+      network_url = google_compute_network.{{ . }}.id
+    }
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- with mergeLabels $.CommonLabels $zone.Labels}}
+
+  labels = {
+    {{- range $key, $value := .}}
+    {{ quote $key }} = {{ quote $value }}
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- if or $zone.Networks $deps.RequiresProjectAPIs}}
+
+  # Wait for the networks and Cloud DNS API
+  depends_on = [
+    {{- range $i, $net := $zone.Networks}}
+    {{- if $i}},{{end}}
+    google_compute_network.{{ $net }}
+    {{- end}}
+    {{- if $deps.RequiresProjectAPIs}}
+    {{- range $i, $api := $deps.ProjectAPIs}}
+    {{- if or $i $zone.Networks}},{{end}}
+    google_project_service.api_{{ $i }}
+    {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
+    {{- end}}
+  ]
+  {{- end}}
+}
+
+{{- range $record := $zone.RecordSets}}
+
+resource "google_dns_record_set" "{{ dnsRecordResourceName $zone $record }}" {
+  managed_zone = google_dns_managed_zone.{{ $zone.Name }}.name
+  name         = {{ quote (dnsRecordName $zone.DnsName $record.Name) }}
+  type         = {{ quote $record.Type }}
+  ttl          = {{ if $record.Ttl }}{{ $record.Ttl }}{{ else }}300{{ end }}
+  rrdatas = [
+    {{- range $record.Rrdatas}}
+    {{ quote . }},
+    {{- end}}
+  ]
+}
+{{- end}}
+{{- end}}
+{{- end}}
+{{end}}
+`
+
+const bigQueryTemplate = `# BigQuery Configuration
+# Generated by custoodian
+
+{{- $data := .Data -}}
+{{- $deps := .Dependencies -}}
+{{if $data}}
+{{- if $data.Datasets}}
+# Datasets
+{{- range $dataset := $data.Datasets}}
+resource "google_bigquery_dataset" "{{ $dataset.DatasetId }}" {
+  dataset_id = {{ quote $dataset.DatasetId }}
+  {{- if $dataset.FriendlyName}}
+  friendly_name = {{ quote $dataset.FriendlyName }}
+  {{- end}}
+  {{- if $dataset.Description}}
+  description = {{ quote $dataset.Description }}
+  {{- end}}
+  {{- if $dataset.Location}}
+  location = {{ quote $dataset.Location }}
+  {{- end}}
+  {{- if $dataset.DefaultTableExpiration}}
+  default_table_expiration_ms = {{ durationMillis $dataset.DefaultTableExpiration }}
+  {{- end}}
+  {{- if $dataset.DeleteContentsOnDestroy}}
+  delete_contents_on_destroy = true
+  {{- end}}
+
+  {{- range $dataset.Access}}
+
+  access {
+    role = {{ quote .Role }}
+    {{- if .UserByEmail}}
+    user_by_email = {{ quote .UserByEmail }}
+    {{- end}}
+    {{- if .GroupByEmail}}
+    group_by_email = {{ quote .GroupByEmail }}
+    {{- end}}
+    {{- if .Domain}}
+    domain = {{ quote .Domain }}
+    {{- end}}
+    {{- if .SpecialGroup}}
+    special_group = {{ quote .SpecialGroup }}
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- with mergeLabels $.CommonLabels $dataset.Labels}}
+
+  labels = {
+    {{- range $key, $value := .}}
+    {{ quote $key }} = {{ quote $value }}
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- if $deps.RequiresProjectAPIs}}
+
+  # Wait for the BigQuery API
+  depends_on = [
+    {{- range $i, $api := $deps.ProjectAPIs}}
+    {{- if $i}},{{end}}
+    google_project_service.api_{{ $i }}
+    {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
+  ]
+  {{- end}}
+}
+{{- end}}
+{{- end}}
+
+{{- if $data.Tables}}
+
+# Tables
+{{- range $table := $data.Tables}}
+resource "google_bigquery_table" "{{ $table.Dataset }}_{{ $table.TableId }}" {
+  dataset_id = google_bigquery_dataset.{{ $table.Dataset }}.dataset_id
+  table_id   = {{ quote $table.TableId }}
+  {{- if $table.Description}}
+  description = {{ quote $table.Description }}
+  {{- end}}
+  {{- if $table.DeletionProtection}}
+  deletion_protection = {{ $table.GetDeletionProtection }}
+  {{- end}}
+  {{- if or $table.Fields $table.SchemaJson}}
+
+  schema = <<-EOF
+    {{ bigQuerySchema $table }}
+  EOF
+  {{- end}}
+
+  {{- with $table.TimePartitioning}}
+
+  time_partitioning {
+    type = {{ quote .Type }}
+    {{- if .Field}}
+    field = {{ quote .Field }}
+    {{- end}}
+    {{- if .Expiration}}
+    expiration_ms = {{ durationMillis .Expiration }}
+    {{- end}}
+  }
+  {{- if .RequirePartitionFilter}}
+  require_partition_filter = true
+  {{- end}}
+  {{- end}}
+
+  {{- if $table.Clustering}}
+  clustering = [
+    {{- range $table.Clustering}}
+    {{ quote . }},
+    {{- end}}
+  ]
+  {{- end}}
+
+  {{- with mergeLabels $.CommonLabels $table.Labels}}
+
+  labels = {
+    {{- range $key, $value := .}}
+    {{ quote $key }} = {{ quote $value }}
+    {{- end}}
+  }
+  {{- end}}
+}
+{{- end}}
+{{- end}}
+{{end}}
+`
+
+const functionsTemplate = `# Cloud Functions Configuration
+# Generated by custoodian
+
+{{- $data := .Data -}}
+{{- $deps := .Dependencies -}}
+{{if $data}}
+{{- if $data.Functions}}
+# 2nd Gen Cloud Functions
+{{- range $function := $data.Functions}}
+resource "google_cloudfunctions2_function" "{{ $function.Name }}" {
+  name     = {{ quote $function.Name }}
+  location = {{ regionExpression $function.Region }}
+  {{- if $function.Description}}
+  description = {{ quote $function.Description }}
+  {{- end}}
+
+  build_config {
+    runtime     = {{ quote $function.Runtime }}
+    entry_point = {{ quote $function.EntryPoint }}
+
+    source {
+      storage_source {
+        {{- if index $data.Buckets $function.SourceBucket}}
+        bucket = google_storage_bucket.{{ $function.SourceBucket }}.name
+        {{- else}}
+        bucket = {{ quote $function.SourceBucket }}
+        {{- end}}
+        object = {{ quote $function.SourceObject }}
+      }
+    }
+  }
+  {{- if or $function.AvailableMemory $function.TimeoutSeconds $function.MinInstances $function.MaxInstances $function.ServiceAccount $function.EnvVars}}
+
+  service_config {
+    {{- if $function.AvailableMemory}}
+    available_memory = {{ quote $function.AvailableMemory }}
+    {{- end}}
+    {{- if $function.TimeoutSeconds}}
+    timeout_seconds = {{ $function.TimeoutSeconds }}
+    {{- end}}
+    {{- if $function.MinInstances}}
+    min_instance_count = {{ $function.MinInstances }}
+    {{- end}}
+    {{- if $function.MaxInstances}}
+    max_instance_count = {{ $function.MaxInstances }}
+    {{- end}}
+    {{- if $function.ServiceAccount}}
+    service_account_email = {{ quote $function.ServiceAccount }}
+    {{- end}}
+    {{- if $function.EnvVars}}
+
+    environment_variables = {
+      {{- range $key, $value := $function.EnvVars}}
+      {{ quote $key }} = {{ quote $value }}
+      {{- end}}
+    }
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- with $function.EventTrigger}}
+
+  event_trigger {
+    trigger_region = {{ regionExpression $function.Region }}
+    {{- if .EventType}}
+    event_type     = {{ quote .EventType }}
+    {{- else}}
+    event_type     = "google.cloud.pubsub.topic.v1.messagePublished"
+    {{- end}}
+    {{- if .PubsubTopic}}
+    {{- if index $data.Topics .PubsubTopic}}
+    pubsub_topic   = google_pubsub_topic.{{ .PubsubTopic }}.id
+    {{- else}}
+    pubsub_topic   = {{ quote .PubsubTopic }}
+    {{- end}}
+    {{- end}}
+    retry_policy   = {{ if .Retry }}"RETRY_POLICY_RETRY"{{ else }}"RETRY_POLICY_DO_NOT_RETRY"{{ end }}
+    {{- if .ServiceAccount}}
+    service_account_email = {{ quote .ServiceAccount }}
+    {{- end}}
+    {{- range $attribute, $value := .EventFilters}}
+
+    event_filters {
+      attribute = {{ quote $attribute }}
+      value     = {{ quote $value }}
+    }
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- with mergeLabels $.CommonLabels $function.Labels}}
+
+  labels = {
+    {{- range $key, $value := .}}
+    {{ quote $key }} = {{ quote $value }}
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- if $deps.RequiresProjectAPIs}}
+
+  # Wait for the Cloud Functions APIs
+  depends_on = [
+    {{- range $i, $api := $deps.ProjectAPIs}}
+    {{- if $i}},{{end}}
+    google_project_service.api_{{ $i }}
+    {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
+  ]
+  {{- end}}
+}
+{{- end}}
+{{- end}}
+{{end}}
+`
+
+const artifactRegistryTemplate = `# Artifact Registry Configuration
+# Generated by custoodian
+
+{{- $data := .Data -}}
+{{- $deps := .Dependencies -}}
+{{if $data}}
+{{- if $data.Repositories}}
+# Artifact Registry Repositories
+{{- range $repository := $data.Repositories}}
+resource "google_artifact_registry_repository" "{{ $repository.RepositoryId }}" {
+  repository_id = {{ quote $repository.RepositoryId }}
+  format        = {{ quote (artifactFormatToString $repository.Format) }}
+  {{- if $repository.Location}}
+  location      = {{ quote $repository.Location }}
+  {{- else}}
+  location      = var.region
+  {{- end}}
+  {{- if $repository.Description}}
+  description   = {{ quote $repository.Description }}
+  {{- end}}
+  {{- if $repository.CleanupPolicyDryRun}}
+  cleanup_policy_dry_run = true
+  {{- end}}
+  {{- range $repository.CleanupPolicies}}
+
+  cleanup_policies {
+    id     = {{ quote .Id }}
+    action = {{ quote .Action }}
+    {{- if .KeepCount}}
+
+    most_recent_versions {
+      keep_count = {{ .KeepCount }}
+      {{- if .PackageNamePrefixes}}
+      package_name_prefixes = [
+        {{- range .PackageNamePrefixes}}
+        {{ quote . }},
+        {{- end}}
+      ]
+      {{- end}}
+    }
+    {{- else}}
+
+    condition {
+      {{- if .TagState}}
+      tag_state = {{ quote .TagState }}
+      {{- end}}
+      {{- if .TagPrefixes}}
+      tag_prefixes = [
+        {{- range .TagPrefixes}}
+        {{ quote . }},
+        {{- end}}
+      ]
+      {{- end}}
+      {{- if .PackageNamePrefixes}}
+      package_name_prefixes = [
+        {{- range .PackageNamePrefixes}}
+        {{ quote . }},
+        {{- end}}
+      ]
+      {{- end}}
+      {{- if .OlderThan}}
+      older_than = {{ quote (durationSeconds .OlderThan) }}
+      {{- end}}
+      {{- if .NewerThan}}
+      newer_than = {{ quote (durationSeconds .NewerThan) }}
+      {{- end}}
+    }
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- with mergeLabels $.CommonLabels $repository.Labels}}
+
+  labels = {
+    {{- range $key, $value := .}}
+    {{ quote $key }} = {{ quote $value }}
+    {{- end}}
+  }
+  {{- end}}
+
+  {{- if $deps.RequiresProjectAPIs}}
+
+  # Wait for the Artifact Registry API
+  depends_on = [
+    {{- range $i, $api := $deps.ProjectAPIs}}
+    {{- if $i}},{{end}}
+    google_project_service.api_{{ $i }}
+    {{- end}}
+    {{- if $deps.APIPropagationDelay}},
+    time_sleep.api_propagation
+    {{- end}}
+  ]
+  {{- end}}
+}
+{{- end}}
+{{- end}}
+{{end}}
+`
+
+const metadataTemplate = `# Generation Metadata
+# Generated by custoodian
+
+{{- $data := .Data }}
+
+locals {
+  generated_at       = {{ quote $data.GeneratedAt }}
+  custoodian_version = {{ quote $data.Version }}
+  {{- if $data.ConfigHash}}
+  config_hash        = {{ quote $data.ConfigHash }}
+  {{- end}}
+}
+
+output "generated_at" {
+  description = "When this code was generated by custoodian"
+  value       = local.generated_at
+}
+
+output "custoodian_version" {
+  description = "The custoodian version that generated this code"
+  value       = local.custoodian_version
+}
+{{- if $data.ConfigHash}}
+
+output "config_hash" {
+  description = "The fingerprint of the configuration this code was generated from"
+  value       = local.config_hash
+}
+{{- end}}
+`
+
+const backendTemplate = `# Terraform Backend Configuration
+# Generated by custoodian
+
+{{- $data := .Data }}
+
+terraform {
+  backend {{ quote $data.Type }} {
+    {{- if eq $data.Type "gcs"}}
+    bucket = {{ quote $data.Bucket }}
+    {{- if $data.Prefix}}
+    prefix = {{ quote $data.Prefix }}
+    {{- end}}
+    {{- else if and (eq $data.Type "local") $data.Path}}
+    path = {{ quote $data.Path }}
+    {{- end}}
+  }
+}
+`
+
+const tfvarsTemplate = `# Variable values for the generated configuration
+# Generated by custoodian
+#
+# Sensitive variables, such as secret values and notification channel tokens,
+# are never written here; set them with TF_VAR_<name> environment variables.
+
+{{- $data := .Data }}
+
+project_id = {{ quote $data.ProjectId }}
+{{- if $data.Region}}
+region = {{ quote $data.Region }}
+{{- end}}
+{{- if $data.Zone}}
+zone = {{ quote $data.Zone }}
+{{- end}}
+{{- range $data.Variables}}
+{{ .Name }} = {{ .Value }}
+{{- end}}
+`
+
+const importsTemplate = `# Import blocks for existing resources
+# Generated by custoodian
+#
+# Requires Terraform 1.5 or later. 'terraform plan' shows the resources to be
+# imported, and 'terraform apply' brings them under management. Once they are
+# in the state, this file can be removed.
+{{- range .Data}}
+
+import {
+  to = {{ .Address }}
+  id = {{ quote .ID }}
+}
+{{- end}}
+`
+
+const moduleVariablesTemplate = `
+# Module inputs
+{{- range .Data}}
+
+variable "{{ .Name }}" {
+  description = {{ quote .Description }}
+  type        = {{ .Type }}
+}
+{{- end}}
+`
+
+const makefileTemplate = `# Makefile for Terraform code generated by custoodian
+#
+# Usage:
+#   make init                            # initialize (uses backend.hcl if present)
+#   make init BACKEND_CONFIG=prod.hcl    # initialize with a specific backend config
+#   make plan
+#   make apply
+#
+# BACKEND_CONFIG paths are relative to this directory.
+
+TF ?= {{ if eq .OutputFormat "opentofu" }}tofu{{ else }}terraform{{ end }}
+TF_DIR ?= $(patsubst %/,%,$(dir $(abspath $(lastword $(MAKEFILE_LIST)))))
+BACKEND_CONFIG ?= $(notdir $(wildcard $(TF_DIR)/backend.hcl))
+PLAN_FILE ?= custoodian.tfplan
+
+.PHONY: init plan apply fmt validate
+
+init:
+	$(TF) -chdir=$(TF_DIR) init -input=false$(if $(BACKEND_CONFIG), -backend-config=$(BACKEND_CONFIG))
+
+plan:
+	$(TF) -chdir=$(TF_DIR) plan -input=false -out=$(PLAN_FILE)
+
+apply:
+	$(TF) -chdir=$(TF_DIR) apply -input=false $(PLAN_FILE)
+
+fmt:
+	$(TF) -chdir=$(TF_DIR) fmt
+
+validate:
+	$(TF) -chdir=$(TF_DIR) init -input=false -backend=false
+	$(TF) -chdir=$(TF_DIR) validate
+`
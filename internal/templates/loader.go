@@ -1,12 +1,33 @@
 package templates
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
 )
 
+// cloneTimeout bounds how long fetching a template repository may take
+const cloneTimeout = 2 * time.Minute
+
+// allowedGitHosts are the Git hosts templates may be loaded from
+var allowedGitHosts = map[string]bool{
+	"github.com":    true,
+	"gitlab.com":    true,
+	"bitbucket.org": true,
+}
+
+// gitRefPattern matches branch names, tags, and commit SHAs. Refs may not
+// start with "-", so they can't be mistaken for git options.
+var gitRefPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._/-]*$`)
+
 // LoadFromDirectory loads templates from a local directory
 func LoadFromDirectory(dir string) (map[string]string, error) {
 	templates := make(map[string]string)
@@ -16,8 +37,13 @@ func LoadFromDirectory(dir string) (map[string]string, error) {
 			return err
 		}
 
-		// Skip directories and non-template files
-		if info.IsDir() || !strings.HasSuffix(path, ".tf") {
+		// Skip the metadata of cloned repositories
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		// Skip directories and non-template files (the Makefile template is the only non-.tf template)
+		if info.IsDir() || (!strings.HasSuffix(path, ".tf") && info.Name() != "Makefile") {
 			return nil
 		}
 
@@ -48,21 +74,37 @@ func LoadFromDirectory(dir string) (map[string]string, error) {
 	return templates, nil
 }
 
+// IsGitURL reports whether a template source names a Git repository rather
+// than a local directory: an HTTPS or SSH URL, or a short form starting with an
+// allowed host (e.g. github.com/org/repo)
+func IsGitURL(source string) bool {
+	if strings.Contains(source, "://") || strings.HasPrefix(source, "git@") {
+		return true
+	}
+	host, _, _ := strings.Cut(source, "/")
+	return allowedGitHosts[host]
+}
+
 // LoadFromGit loads templates from a Git repository
 //
-// This function clones a Git repository to a temporary directory and loads
-// all .tf template files from it. The repository is cleaned up automatically.
+// This function fetches a Git repository with go-git into a temporary
+// directory and loads all .tf template files from it. Only the requested commit
+// is fetched (depth 1), and the repository is cleaned up automatically.
 //
 // Supported URL formats:
 //   - HTTPS: https://github.com/org/repo.git
 //   - SSH: git@github.com:org/repo.git
 //   - Short form: github.com/org/repo
 //
+// Any format may end in @<ref> to select a branch, tag, or commit SHA instead
+// of the default branch (e.g. github.com/org/repo@v1.2.0).
+//
 // Security considerations:
 //   - Only allows known Git hosts (GitHub, GitLab, Bitbucket)
 //   - Clones to a secure temporary directory with restricted permissions
 //   - Automatic cleanup prevents disk space leaks
-//   - URL validation prevents command injection
+//   - URL and ref validation rejects unknown hosts and malformed refs
+//   - Fetching is aborted after cloneTimeout, and never prompts for credentials
 //
 // Parameters:
 //   - repoURL: Git repository URL in any supported format
@@ -71,12 +113,23 @@ func LoadFromDirectory(dir string) (map[string]string, error) {
 //   - map[string]string: Template name to content mapping
 //   - error: Any error during cloning, reading, or validation
 func LoadFromGit(repoURL string) (map[string]string, error) {
+	url, ref := splitGitRef(repoURL)
+	if ref != "" && !isValidGitRef(ref) {
+		return nil, fmt.Errorf("invalid Git repository URL: invalid ref %q", ref)
+	}
+
 	// Validate and normalize the repository URL
-	normalizedURL, err := validateAndNormalizeGitURL(repoURL)
+	normalizedURL, err := validateAndNormalizeGitURL(url)
 	if err != nil {
 		return nil, fmt.Errorf("invalid Git repository URL: %w", err)
 	}
 
+	return loadFromRepository(normalizedURL, ref)
+}
+
+// loadFromRepository fetches ref (or the default branch) of a validated
+// repository URL into a temporary directory and loads its templates
+func loadFromRepository(repoURL, ref string) (map[string]string, error) {
 	// Create a temporary directory for cloning
 	tempDir, err := os.MkdirTemp("", "custodian-templates-*")
 	if err != nil {
@@ -90,8 +143,8 @@ func LoadFromGit(repoURL string) (map[string]string, error) {
 	}()
 
 	// Clone the repository
-	if err := cloneGitRepository(normalizedURL, tempDir); err != nil {
-		return nil, fmt.Errorf("failed to clone repository %s: %w", repoURL, err)
+	if err := cloneGitRepository(repoURL, ref, tempDir); err != nil {
+		return nil, fmt.Errorf("failed to clone repository: %w", err)
 	}
 
 	// Load templates from the cloned repository
@@ -103,15 +156,37 @@ func LoadFromGit(repoURL string) (map[string]string, error) {
 	return templates, nil
 }
 
-// validateAndNormalizeGitURL validates and normalizes a Git repository URL
-func validateAndNormalizeGitURL(repoURL string) (string, error) {
-	// List of allowed Git hosts for security
-	allowedHosts := map[string]bool{
-		"github.com":    true,
-		"gitlab.com":    true,
-		"bitbucket.org": true,
+// splitGitRef splits an optional @<ref> suffix off a repository URL. Only an
+// @ in the repository path starts a ref: the @ of SSH URLs
+// (git@github.com:org/repo) and of user info in HTTPS URLs comes before it.
+// Refs may contain / (e.g. github.com/org/repo@release/1.x).
+func splitGitRef(repoURL string) (string, string) {
+	pathStart := 0
+	if strings.HasPrefix(repoURL, "git@") {
+		pathStart = strings.Index(repoURL, ":") + 1
+	} else {
+		if i := strings.Index(repoURL, "://"); i != -1 {
+			pathStart = i + len("://")
+		}
+		if i := strings.Index(repoURL[pathStart:], "/"); i != -1 {
+			pathStart += i
+		}
+	}
+
+	at := strings.Index(repoURL[pathStart:], "@")
+	if at == -1 {
+		return repoURL, ""
 	}
+	return repoURL[:pathStart+at], repoURL[pathStart+at+1:]
+}
 
+// isValidGitRef reports whether ref is safe to pass to git as a ref name
+func isValidGitRef(ref string) bool {
+	return gitRefPattern.MatchString(ref) && !strings.Contains(ref, "..") && !strings.HasSuffix(ref, ".lock")
+}
+
+// validateAndNormalizeGitURL validates and normalizes a Git repository URL
+func validateAndNormalizeGitURL(repoURL string) (string, error) {
 	// Handle short form URLs (e.g., github.com/org/repo)
 	if !strings.Contains(repoURL, "://") && !strings.HasPrefix(repoURL, "git@") {
 		// Convert short form to HTTPS
@@ -136,7 +211,7 @@ func validateAndNormalizeGitURL(repoURL string) (string, error) {
 		}
 
 		host := hostAndPath[:colonIndex]
-		if !allowedHosts[host] {
+		if !allowedGitHosts[host] {
 			return "", fmt.Errorf("Git host %s is not allowed", host)
 		}
 	} else {
@@ -152,7 +227,7 @@ func validateAndNormalizeGitURL(repoURL string) (string, error) {
 		}
 
 		host := urlParts[2]
-		if !allowedHosts[host] {
+		if !allowedGitHosts[host] {
 			return "", fmt.Errorf("Git host %s is not allowed", host)
 		}
 	}
@@ -160,44 +235,88 @@ func validateAndNormalizeGitURL(repoURL string) (string, error) {
 	return repoURL, nil
 }
 
-// cloneGitRepository clones a Git repository to the specified directory
-func cloneGitRepository(repoURL, targetDir string) error {
-	// For now, we'll implement a simple approach using the git command
-	// In a production environment, you might want to use a Git library like go-git
+// fetchedRef is the local ref a template repository's commit is fetched into
+const fetchedRef = plumbing.ReferenceName("refs/custoodian/templates")
 
-	// Check if git command is available
-	if !isCommandAvailable("git") {
-		return fmt.Errorf("git command is not available")
+// cloneGitRepository fetches a single commit of a Git repository into
+// targetDir: the commit ref names (a branch, tag, or full commit SHA), or the
+// head of the default branch when ref is empty. History is not fetched
+// (depth 1).
+func cloneGitRepository(repoURL, ref, targetDir string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cloneTimeout)
+	defer cancel()
+
+	repo, err := git.PlainInit(targetDir, false)
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository: %w", err)
+	}
+	remote, err := repo.CreateRemote(&gitconfig.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{repoURL}})
+	if err != nil {
+		return fmt.Errorf("failed to add remote: %w", err)
 	}
 
-	// Execute git clone with security options
-	cmd := fmt.Sprintf("git clone --depth=1 --single-branch %s %s",
-		shellEscape(repoURL), shellEscape(targetDir))
+	// init and fetch instead of clone, because clone accepts branches and
+	// tags but not commit SHAs
+	source, err := remoteRefSource(ctx, remote, ref)
+	if err != nil {
+		return withTimeout(ctx, err)
+	}
+	err = remote.FetchContext(ctx, &git.FetchOptions{
+		RefSpecs: []gitconfig.RefSpec{gitconfig.RefSpec(source + ":" + fetchedRef.String())},
+		Depth:    1,
+		Tags:     git.NoTags,
+	})
+	if err != nil {
+		return withTimeout(ctx, fmt.Errorf("fetch failed: %w", err))
+	}
 
-	if err := executeCommand(cmd); err != nil {
-		return fmt.Errorf("git clone failed: %w", err)
+	// Annotated tags are fetched as tag objects, so peel them to their commit
+	commit, err := repo.ResolveRevision(plumbing.Revision(fetchedRef))
+	if err != nil {
+		return fmt.Errorf("failed to resolve fetched commit: %w", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to open work tree: %w", err)
+	}
+	if err := worktree.Checkout(&git.CheckoutOptions{Hash: *commit}); err != nil {
+		return fmt.Errorf("checkout failed: %w", err)
 	}
 
 	return nil
 }
 
-// isCommandAvailable checks if a command is available in the system PATH
-func isCommandAvailable(command string) bool {
-	cmd := fmt.Sprintf("command -v %s", shellEscape(command))
-	return executeCommand(cmd) == nil
-}
+// remoteRefSource returns the refspec source that fetches ref from remote:
+// HEAD when ref is empty, the branch or tag named ref, or ref itself when it
+// is a full commit SHA
+func remoteRefSource(ctx context.Context, remote *git.Remote, ref string) (string, error) {
+	if ref == "" {
+		return plumbing.HEAD.String(), nil
+	}
 
-// executeCommand executes a shell command with security measures
-func executeCommand(command string) error {
-	// This is a simplified implementation
-	// In production, you should use proper command execution with timeouts and resource limits
-	return fmt.Errorf("command execution not implemented in this version - please use local templates or implement using go-git library")
+	refs, err := remote.ListContext(ctx, &git.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list remote refs: %w", err)
+	}
+	for _, name := range []plumbing.ReferenceName{plumbing.NewBranchReferenceName(ref), plumbing.NewTagReferenceName(ref)} {
+		for _, remoteRef := range refs {
+			if remoteRef.Name() == name {
+				return name.String(), nil
+			}
+		}
+	}
+	if plumbing.IsHash(ref) {
+		return ref, nil
+	}
+	return "", fmt.Errorf("couldn't find remote ref %s", ref)
 }
 
-// shellEscape escapes a string for safe use in shell commands
-func shellEscape(s string) string {
-	// Simple escaping - in production, use proper shell escaping
-	return fmt.Sprintf("'%s'", strings.ReplaceAll(s, "'", "'\"'\"'"))
+// withTimeout reports err as a timeout when fetching ran out of time
+func withTimeout(ctx context.Context, err error) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("fetching timed out after %s", cloneTimeout)
+	}
+	return err
 }
 
 // readFileContent reads the entire content of a file
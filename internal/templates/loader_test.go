@@ -0,0 +1,156 @@
+package templates
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTemplateRepository creates a bare repository with two commits on main, a
+// tag v1 on the first, and a branch next with a third. It returns the
+// repository URL and the SHA of the first commit.
+func newTemplateRepository(t *testing.T) (string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	dir := t.TempDir()
+	work := filepath.Join(dir, "work")
+	bare := filepath.Join(dir, "templates.git")
+
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = work
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+		)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s failed: %v: %s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(file, content string) string {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(work, file), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+		git("add", file)
+		git("commit", "--quiet", "-m", "Update "+file)
+		return git("rev-parse", "HEAD")
+	}
+
+	if err := os.MkdirAll(work, 0750); err != nil {
+		t.Fatalf("Failed to create work tree: %v", err)
+	}
+	git("init", "--quiet", "--initial-branch=main")
+	first := commit("networking.tf", "# v1\n")
+	git("tag", "v1")
+	commit("networking.tf", "# v2\n")
+	git("checkout", "--quiet", "-b", "next")
+	commit("compute.tf", "# next\n")
+	git("checkout", "--quiet", "main")
+	git("clone", "--quiet", "--bare", work, bare)
+	// Let commits be fetched by SHA, as GitHub and GitLab allow
+	git("-C", bare, "config", "uploadpack.allowReachableSHA1InWant", "true")
+
+	return "file://" + bare, first
+}
+
+func TestLoadFromRepository(t *testing.T) {
+	repoURL, first := newTemplateRepository(t)
+
+	tests := []struct {
+		name string
+		ref  string
+		want map[string]string
+	}{
+		{"default branch", "", map[string]string{"networking.tf": "# v2\n"}},
+		{"tag", "v1", map[string]string{"networking.tf": "# v1\n"}},
+		{"branch", "next", map[string]string{"networking.tf": "# v2\n", "compute.tf": "# next\n"}},
+		{"commit", first, map[string]string{"networking.tf": "# v1\n"}},
+	}
+
+	for _, test := range tests {
+		templates, err := loadFromRepository(repoURL, test.ref)
+		if err != nil {
+			t.Errorf("%s: expected no error, got: %v", test.name, err)
+			continue
+		}
+		if len(templates) != len(test.want) {
+			t.Errorf("%s: expected templates %v, got %v", test.name, test.want, templates)
+		}
+		for name, content := range test.want {
+			if templates[name] != content {
+				t.Errorf("%s: expected %s to be %q, got %q", test.name, name, content, templates[name])
+			}
+		}
+	}
+
+	if _, err := loadFromRepository(repoURL, "missing"); err == nil {
+		t.Error("Expected an error for an unknown ref")
+	}
+}
+
+func TestLoadFromGitValidation(t *testing.T) {
+	tests := []string{
+		"file:///tmp/templates.git",
+		"https://example.com/org/templates.git",
+		"git@example.com:org/templates.git",
+		"github.com/org/templates@--upload-pack=evil",
+		"github.com/org/templates@v1..v2",
+	}
+
+	for _, repoURL := range tests {
+		if _, err := LoadFromGit(repoURL); err == nil || !strings.Contains(err.Error(), "invalid Git repository URL") {
+			t.Errorf("%s: expected an invalid URL error, got: %v", repoURL, err)
+		}
+	}
+}
+
+func TestSplitGitRef(t *testing.T) {
+	tests := []struct {
+		input, url, ref string
+	}{
+		{"github.com/org/repo", "github.com/org/repo", ""},
+		{"github.com/org/repo@v1.2.0", "github.com/org/repo", "v1.2.0"},
+		{"https://github.com/org/repo.git@main", "https://github.com/org/repo.git", "main"},
+		{"git@github.com:org/repo.git", "git@github.com:org/repo.git", ""},
+		{"git@github.com:org/repo.git@v2", "git@github.com:org/repo.git", "v2"},
+		{"github.com/org/repo@release/1.x", "github.com/org/repo", "release/1.x"},
+		{"https://user@github.com/org/repo.git", "https://user@github.com/org/repo.git", ""},
+		{"https://user@github.com/org/repo.git@v1", "https://user@github.com/org/repo.git", "v1"},
+	}
+
+	for _, test := range tests {
+		url, ref := splitGitRef(test.input)
+		if url != test.url || ref != test.ref {
+			t.Errorf("%s: expected (%q, %q), got (%q, %q)", test.input, test.url, test.ref, url, ref)
+		}
+	}
+}
+
+func TestIsGitURL(t *testing.T) {
+	tests := map[string]bool{
+		"github.com/org/templates":         true,
+		"gitlab.com/org/templates@v1":      true,
+		"https://github.com/org/templates": true,
+		"git@github.com:org/templates.git": true,
+		"./templates":                      false,
+		"/srv/templates":                   false,
+		"templates/github.com":             false,
+		"my-templates@2024":                false,
+	}
+
+	for source, want := range tests {
+		if got := IsGitURL(source); got != want {
+			t.Errorf("%s: expected %v, got %v", source, want, got)
+		}
+	}
+}
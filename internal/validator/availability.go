@@ -0,0 +1,124 @@
+package validator
+
+import (
+	"fmt"
+
+	"custoodian/pkg/config"
+)
+
+// machineFamilyRegions lists the regions of the Region enum each machine
+// family is offered in. It is a curated subset of GCP's availability data
+// (https://cloud.google.com/compute/docs/regions-zones#available), which
+// changes as families roll out, so it only reports families known to be
+// missing from a region. Families that aren't listed, such as e2 and n1, are
+// offered in every region.
+//
+// When a family becomes available in a region, add the region here.
+var machineFamilyRegions = map[string][]config.Region{
+	"n2": {
+		config.Region_REGION_US_CENTRAL1,
+		config.Region_REGION_US_EAST1,
+		config.Region_REGION_US_EAST4,
+		config.Region_REGION_US_WEST1,
+		config.Region_REGION_US_WEST2,
+		config.Region_REGION_US_WEST3,
+		config.Region_REGION_US_WEST4,
+		config.Region_REGION_EUROPE_WEST1,
+		config.Region_REGION_EUROPE_WEST2,
+		config.Region_REGION_EUROPE_WEST3,
+		config.Region_REGION_EUROPE_WEST4,
+		config.Region_REGION_EUROPE_WEST6,
+		config.Region_REGION_EUROPE_NORTH1,
+		config.Region_REGION_ASIA_EAST1,
+		config.Region_REGION_ASIA_EAST2,
+		config.Region_REGION_ASIA_NORTHEAST1,
+		config.Region_REGION_ASIA_NORTHEAST2,
+		config.Region_REGION_ASIA_NORTHEAST3,
+		config.Region_REGION_ASIA_SOUTH1,
+		config.Region_REGION_ASIA_SOUTHEAST1,
+		config.Region_REGION_ASIA_SOUTHEAST2,
+	},
+	// Compute-optimized machines are offered in fewer regions
+	"c2": {
+		config.Region_REGION_US_CENTRAL1,
+		config.Region_REGION_US_EAST1,
+		config.Region_REGION_US_EAST4,
+		config.Region_REGION_US_WEST1,
+		config.Region_REGION_US_WEST4,
+		config.Region_REGION_EUROPE_WEST1,
+		config.Region_REGION_EUROPE_WEST2,
+		config.Region_REGION_EUROPE_WEST3,
+		config.Region_REGION_EUROPE_WEST4,
+		config.Region_REGION_EUROPE_NORTH1,
+		config.Region_REGION_ASIA_EAST1,
+		config.Region_REGION_ASIA_NORTHEAST1,
+		config.Region_REGION_ASIA_NORTHEAST3,
+		config.Region_REGION_ASIA_SOUTH1,
+		config.Region_REGION_ASIA_SOUTHEAST1,
+	},
+}
+
+// machineFamilyAvailable reports whether family is offered in region, as far
+// as machineFamilyRegions knows
+func machineFamilyAvailable(family string, region config.Region) bool {
+	regions, listed := machineFamilyRegions[family]
+	if !listed {
+		return true
+	}
+	for _, r := range regions {
+		if r == region {
+			return true
+		}
+	}
+	return false
+}
+
+// machineAvailabilityWarnings warns about instances, and instance groups'
+// templates, whose machine family isn't offered in the region of their zone.
+// Terraform accepts such a machine type, but creating the instance fails.
+// Zones left to the zone variable aren't known, so they aren't checked.
+func machineAvailabilityWarnings(cfg *config.Config) []string {
+	if cfg.Compute == nil {
+		return nil
+	}
+
+	var warnings []string
+	check := func(resource string, machineType config.MachineType, zone config.Zone) {
+		if zone == config.Zone_ZONE_UNSPECIFIED {
+			return
+		}
+		family := instanceMachineFamily(machineType)
+		if region := zoneRegion(zone); !machineFamilyAvailable(family, region) {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s uses machine family %s, which is not available in region %s (zone %s); choose another machine type or zone",
+				resource, family, region, zone))
+		}
+	}
+
+	templates := make(map[string]*config.InstanceTemplate)
+	for _, template := range cfg.Compute.InstanceTemplates {
+		templates[template.Name] = template
+	}
+	for _, group := range cfg.Compute.InstanceGroups {
+		groupTemplates := []string{group.Template}
+		for _, version := range group.Versions {
+			groupTemplates = append(groupTemplates, version.Template)
+		}
+		checked := make(map[string]bool)
+		for _, name := range groupTemplates {
+			template, declared := templates[name]
+			if !declared || checked[name] {
+				continue
+			}
+			checked[name] = true
+			for _, zone := range group.Zones {
+				check(fmt.Sprintf("instance template %s of instance group %s", template.Name, group.Name), template.MachineType, zone)
+			}
+		}
+	}
+	for _, instance := range cfg.Compute.Instances {
+		check("instance "+instance.Name, instance.MachineType, instance.Zone)
+	}
+
+	return warnings
+}
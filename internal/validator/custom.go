@@ -0,0 +1,95 @@
+package validator
+
+import (
+	"sync"
+
+	"custoodian/pkg/config"
+)
+
+// Severity is the severity of a Diagnostic
+type Severity int
+
+const (
+	// SeverityError fails validation
+	SeverityError Severity = iota
+	// SeverityWarning is reported alongside the built-in warnings
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// MarshalText encodes the severity as "error" or "warning"
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Diagnostic is a finding reported by a custom validator
+type Diagnostic struct {
+	Severity Severity
+	// Code defaults to CodePolicyViolation for errors
+	Code    Code
+	Message string
+}
+
+// CustomValidator checks organization-specific policy, such as naming prefixes
+// or required labels. It is called with the configuration of a single project,
+// with disabled resources removed.
+type CustomValidator func(cfg *config.Config) []Diagnostic
+
+var (
+	customValidatorsMu sync.RWMutex
+	customValidators   []CustomValidator
+)
+
+// Register adds a custom validator that runs after the built-in checks in
+// ValidateConfig and Warnings. Validators run in registration order; the first
+// error diagnostic fails validation.
+func Register(v CustomValidator) {
+	customValidatorsMu.Lock()
+	defer customValidatorsMu.Unlock()
+	customValidators = append(customValidators, v)
+}
+
+// runCustomValidators returns the diagnostics of every registered validator
+func runCustomValidators(cfg *config.Config) []Diagnostic {
+	customValidatorsMu.RLock()
+	validators := append([]CustomValidator(nil), customValidators...)
+	customValidatorsMu.RUnlock()
+
+	var diagnostics []Diagnostic
+	for _, v := range validators {
+		diagnostics = append(diagnostics, v(cfg)...)
+	}
+	return diagnostics
+}
+
+// validateCustom returns the first error reported by a custom validator
+func validateCustom(cfg *config.Config) error {
+	for _, d := range runCustomValidators(cfg) {
+		if d.Severity != SeverityError {
+			continue
+		}
+		code := d.Code
+		if code == "" {
+			code = CodePolicyViolation
+		}
+		return &ValidationError{Code: code, Message: d.Message}
+	}
+	return nil
+}
+
+// customWarnings returns the warnings reported by custom validators
+func customWarnings(cfg *config.Config) []string {
+	var warnings []string
+	for _, d := range runCustomValidators(cfg) {
+		if d.Severity == SeverityWarning {
+			warnings = append(warnings, d.Message)
+		}
+	}
+	return warnings
+}
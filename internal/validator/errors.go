@@ -0,0 +1,654 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Code is a stable identifier for a class of validation error (e.g. "NET002").
+// Codes never change meaning once assigned, so they can be matched by scripts
+// and looked up with 'custodian explain-error'.
+type Code string
+
+// Validation error codes, grouped by prefix: CFG for general configuration
+// errors, then one prefix per configuration area
+const (
+	CodeSchemaViolation    Code = "CFG001"
+	CodeRequiredField      Code = "CFG002"
+	CodeInvalidValue       Code = "CFG003"
+	CodeDuplicateName      Code = "CFG004"
+	CodeUnknownReference   Code = "CFG005"
+	CodeDisabledReference  Code = "CFG006"
+	CodeDescriptionTooLong Code = "CFG007"
+	CodeInvalidDuration    Code = "CFG008"
+	CodeInvalidLabel       Code = "CFG009"
+	CodePolicyViolation    Code = "CFG010"
+	CodeGeneratedPassword  Code = "CFG011"
+	CodeUnspecifiedEnum    Code = "CFG012"
+	CodeOutputFileName     Code = "CFG013"
+
+	CodeProjectMissing        Code = "PRJ001"
+	CodeInvalidProjectID      Code = "PRJ002"
+	CodeInvalidBillingAccount Code = "PRJ003"
+	CodeProjectParentConflict Code = "PRJ004"
+	CodeProjectAssignment     Code = "PRJ005"
+
+	CodeInvalidCIDR             Code = "NET001"
+	CodeCIDROverlap             Code = "NET002"
+	CodeReservedIPRegion        Code = "NET003"
+	CodeFirewallDirection       Code = "NET004"
+	CodeFirewallAction          Code = "NET005"
+	CodeFirewallProtocol        Code = "NET006"
+	CodeNATManualIPs            Code = "NET007"
+	CodeVpcConnectorConfig      Code = "NET008"
+	CodeNetworkInterfaceMissing Code = "NET009"
+	CodeSubnetNetworkMismatch   Code = "NET010"
+	CodeFirewallPorts           Code = "NET011"
+	CodeReservedIPAddress       Code = "NET012"
+	CodeVpcPeering              Code = "NET013"
+
+	CodeDiskTooSmall          Code = "CMP001"
+	CodeAutoscalingBounds     Code = "CMP002"
+	CodeSoleTenancy           Code = "CMP003"
+	CodeReservationAffinity   Code = "CMP004"
+	CodeHealthCheck           Code = "CMP005"
+	CodeInstanceGroupVersions Code = "CMP006"
+	CodeBackendNamedPort      Code = "CMP007"
+	CodeLoadBalancerSsl       Code = "CMP008"
+	CodeNamedPort             Code = "CMP009"
+
+	CodeInvalidServiceAccountID Code = "IAM001"
+	CodeCustomRolePermissions   Code = "IAM002"
+
+	CodeInvalidBucketName Code = "STG001"
+	CodeLifecycleRule     Code = "STG002"
+
+	CodeCryptoKeySettings Code = "KMS001"
+
+	CodeChannelLabels      Code = "MON001"
+	CodeLogMetricValueType Code = "MON002"
+
+	CodeLogSinkDestination Code = "LOG001"
+
+	CodeInvalidTag        Code = "TAG001"
+	CodeTagBinding        Code = "TAG002"
+	CodeCloudRunAccess    Code = "RUN001"
+	CodeTrafficSplit      Code = "RUN002"
+	CodeCloudRunSecretEnv Code = "RUN003"
+
+	CodeInvalidTopic Code = "PUB001"
+
+	CodeInvalidSchedule Code = "SCH001"
+	CodeInvalidTimeZone Code = "SCH002"
+	CodeSchedulerTarget Code = "SCH003"
+	CodeSchedulerRetry  Code = "SCH004"
+
+	CodeQueueRateLimits Code = "TSK001"
+	CodeQueueRetry      Code = "TSK002"
+
+	CodeFilestoreCapacity Code = "FST001"
+	CodeFilestoreLocation Code = "FST002"
+
+	CodeGkeLocation       Code = "GKE001"
+	CodeGkeNodePoolSize   Code = "GKE002"
+	CodeGkeSecondaryRange Code = "GKE003"
+
+	CodeDnsName       Code = "DNS001"
+	CodeDnsRecordSet  Code = "DNS002"
+	CodeDnsVisibility Code = "DNS003"
+
+	CodeBigQueryName   Code = "BQ001"
+	CodeBigQueryAccess Code = "BQ002"
+	CodeBigQuerySchema Code = "BQ003"
+
+	CodeFunctionSource  Code = "FN001"
+	CodeFunctionTrigger Code = "FN002"
+	CodeFunctionScaling Code = "FN003"
+
+	CodeArtifactRepositoryId Code = "AR001"
+	CodeArtifactFormat       Code = "AR002"
+	CodeArtifactCleanup      Code = "AR003"
+
+	CodeSqlReplica Code = "SQL001"
+
+	CodeSpannerCapacity Code = "SPN001"
+)
+
+// ValidationError is a validation failure with a stable code. Errors returned by
+// ValidateConfig wrap a ValidationError; use CodeOf to retrieve its code.
+type ValidationError struct {
+	Code Code `json:"code,omitempty"`
+	// Path is the configuration field the finding is about (e.g. "networking"),
+	// or "" for findings across fields. It is set on the errors of a
+	// ValidationResult.
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	// Severity is SeverityWarning for the warnings of a ValidationResult
+	Severity Severity `json:"severity"`
+	// err is the underlying error wrapped by the message, if any
+	err error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("[%s] %s", e.Code, e.Message)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.err
+}
+
+// ValidationResult is the structured result of Validate
+type ValidationResult struct {
+	// Valid reports whether the configuration has no errors
+	Valid bool `json:"valid"`
+	// Errors are the errors found, or for a valid configuration, its warnings
+	Errors []*ValidationError `json:"errors"`
+}
+
+// Err collapses the result to the error ValidateConfig returns: the errors
+// found, each wrapped in the prefix of its check and joined with newlines, or
+// nil if the result is valid. CodeOf returns the code of the first error.
+func (r *ValidationResult) Err() error {
+	var errs []error
+	for _, e := range r.Errors {
+		if e.Severity == SeverityError {
+			errs = append(errs, e.err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// add records err, found by a check of the configuration field path. The
+// errors of a check that found several, joined with errors.Join, are recorded
+// one by one. The message leaves out the code, which the error carries
+// separately, and the prefix, which Err restores.
+func (r *ValidationResult) add(path, prefix string, err error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			r.add(path, prefix, err)
+		}
+		return
+	}
+
+	code := CodeOf(err)
+	message := err.Error()
+	if code != "" {
+		message = strings.Replace(message, "["+string(code)+"] ", "", 1)
+	}
+	if prefix != "" {
+		err = fmt.Errorf("%s: %w", prefix, err)
+	}
+	r.Errors = append(r.Errors, &ValidationError{Code: code, Path: path, Message: message, err: err})
+	r.Valid = false
+}
+
+// addProject records the errors of a project of a multi-project configuration
+func (r *ValidationResult) addProject(projectId string, result *ValidationResult) {
+	for _, e := range result.Errors {
+		r.Errors = append(r.Errors, &ValidationError{
+			Code:     e.Code,
+			Path:     e.Path,
+			Message:  fmt.Sprintf("project %s: %s", projectId, e.Message),
+			Severity: e.Severity,
+			err:      fmt.Errorf("project %s: %w", projectId, e.err),
+		})
+	}
+	r.Valid = r.Valid && result.Valid
+}
+
+// wrapEach wraps err in a formatted prefix, e.g. "invalid VPC main". Each error
+// of a joined error is wrapped on its own, so ValidationResult.add still
+// records them one by one.
+func wrapEach(err error, format string, args ...interface{}) error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []error
+		for _, err := range joined.Unwrap() {
+			errs = append(errs, wrapEach(err, format, args...))
+		}
+		return errors.Join(errs...)
+	}
+	return fmt.Errorf(format+": %w", append(args, err)...)
+}
+
+// errorf returns a ValidationError with the given code and formatted message.
+// An error wrapped with %w remains available to errors.Is and errors.As.
+func errorf(code Code, format string, args ...interface{}) error {
+	err := fmt.Errorf(format, args...)
+	return &ValidationError{Code: code, Message: err.Error(), err: errors.Unwrap(err)}
+}
+
+// CodeOf returns the code of the validation error wrapped by err, or "" if err
+// does not wrap a ValidationError
+func CodeOf(err error) Code {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return verr.Code
+	}
+	return ""
+}
+
+// Explanation describes a validation error code in detail
+type Explanation struct {
+	Code        Code
+	Title       string
+	Description string
+	Remediation string
+}
+
+// explanations documents every validation error code
+var explanations = map[Code]Explanation{
+	CodeSchemaViolation: {
+		Title:       "Schema constraint violated",
+		Description: "A field violates a constraint declared in the configuration schema, such as a required field, a string pattern, or a numeric range. The message lists the offending field paths.",
+		Remediation: "Fix the listed fields. 'custodian schema' shows the constraints declared on every field.",
+	},
+	CodeRequiredField: {
+		Title:       "Required field missing",
+		Description: "A resource is missing a field it needs to be generated, such as a name, filter, location, or zone.",
+		Remediation: "Set the field named in the message.",
+	},
+	CodeInvalidValue: {
+		Title:       "Invalid value",
+		Description: "A field has a value outside the set or range it accepts, such as an unknown storage class, NAT allocation option, or alert combiner, or a negative size.",
+		Remediation: "Use one of the values listed in the message or in the field's comment in the schema. Values are case sensitive.",
+	},
+	CodeDuplicateName: {
+		Title:       "Duplicate resource name",
+		Description: "Two resources of the same kind share a name. Names become Terraform resource names, which must be unique.",
+		Remediation: "Rename one of the resources. If both are needed in different environments, use 'enabled: false' to exclude one of them.",
+	},
+	CodeUnknownReference: {
+		Title:       "Reference to an undeclared resource",
+		Description: "A resource refers by name to another resource (a template, crypto key, bucket, reserved IP, node group, and so on) that is not declared in the configuration.",
+		Remediation: "Check the name for typos, or declare the referenced resource. Resources managed outside this configuration must be referenced by their full resource ID where the field allows it.",
+	},
+	CodeDisabledReference: {
+		Title:       "Reference to a disabled resource",
+		Description: "An enabled resource refers to a resource with 'enabled: false'. Disabled resources are not generated, so the reference would dangle.",
+		Remediation: "Enable the referenced resource, or disable the resource that refers to it.",
+	},
+	CodeDescriptionTooLong: {
+		Title:       "Description too long",
+		Description: "A description exceeds the length GCP accepts: 2048 characters for most resources and 256 for service accounts and custom roles.",
+		Remediation: "Shorten the description.",
+	},
+	CodeInvalidDuration: {
+		Title:       "Invalid duration",
+		Description: "A duration field could not be parsed. Durations are written as Go durations (\"90s\", \"15m\", \"1h30m\"), whole days (\"7d\"), or a bare number of seconds (\"3600\"), and are converted to the format each GCP API expects.",
+		Remediation: "Write the duration with a unit, e.g. \"60s\", \"24h\", or \"1d\" for one day.",
+	},
+	CodeInvalidLabel: {
+		Title:       "Invalid label",
+		Description: "Label keys must be 1-63 characters, start with a lowercase letter, and contain only lowercase letters, digits, underscores, and hyphens. Values follow the same rules but may be empty and may start with any allowed character. A resource can have at most 64 labels.",
+		Remediation: "Lowercase the label and replace other characters (such as dots, spaces, or slashes) with hyphens or underscores.",
+	},
+	CodePolicyViolation: {
+		Title:       "Custom policy violated",
+		Description: "A validator registered with validator.Register reported an error. These enforce organization-specific rules, such as naming prefixes or required labels, on top of the built-in checks.",
+		Remediation: "Follow the message from the custom validator, or ask the team that maintains the policy.",
+	},
+	CodeGeneratedPassword: {
+		Title:       "Invalid generated password settings",
+		Description: "A generated password must be 12-256 characters long (32 by default), its min_lower, min_upper, min_numeric, and min_special values must not be negative or add up to more than the length, min_special and override_special require special characters, and override_special may only contain printable ASCII symbols.",
+		Remediation: "Adjust the generate or generate_password settings as described in the message.",
+	},
+	CodeUnspecifiedEnum: {
+		Title:       "Unspecified region, zone, or machine type",
+		Description: "With --strict, a region, zone, or machine type that a resource needs must be set. Without --strict, generation silently defaults such fields (a region to us-central1, a zone to us-central1-a, a machine type to e2-medium), which can create resources in the wrong place. Fields that default to the region or zone variable are not affected.",
+		Remediation: "Set the field named in the message on the resource.",
+	},
+	CodeOutputFileName: {
+		Title:       "Invalid output file name",
+		Description: "The output section can rename the generated .tf files with file_map or number them with numbered. A file_map key must be the default name of a generated file, each new name must be a file name ending in .tf that isn't hidden and has no directories, and no two files may end up with the same name.",
+		Remediation: "Fix the file_map entry named in the message, or give the colliding files distinct names.",
+	},
+	CodeProjectMissing: {
+		Title:       "Project configuration missing",
+		Description: "Every configuration must contain a project block; all generated resources belong to it.",
+		Remediation: "Add a project block with at least an id, name, and billing_account.",
+	},
+	CodeInvalidProjectID: {
+		Title:       "Invalid project ID",
+		Description: "GCP project IDs must be 6-30 characters of lowercase letters, digits, and hyphens, starting with a letter and not ending with a hyphen.",
+		Remediation: "Choose a project ID that follows these rules. Project IDs are globally unique and cannot be changed after creation.",
+	},
+	CodeInvalidBillingAccount: {
+		Title:       "Invalid billing account",
+		Description: "Billing account IDs have the form XXXXXX-XXXXXX-XXXXXX, where each X is a digit or uppercase letter.",
+		Remediation: "Copy the billing account ID from the Cloud Console billing page or 'gcloud billing accounts list'.",
+	},
+	CodeProjectParentConflict: {
+		Title:       "Project has two parents",
+		Description: "A project belongs to either an organization or a folder, so organization_id and folder_id cannot both be set.",
+		Remediation: "Keep folder_id if the project lives in a folder (the folder already belongs to an organization); otherwise keep organization_id.",
+	},
+	CodeProjectAssignment: {
+		Title:       "Invalid project assignment",
+		Description: "A configuration that declares projects assigns resources to them with each resource's project field. The field must name a declared project, and is required when more than one project is declared. project and projects cannot both be set, and project IDs must be unique.",
+		Remediation: "Set project on the resource named in the message to the ID of one of the declared projects.",
+	},
+	CodeInvalidCIDR: {
+		Title:       "Invalid CIDR range",
+		Description: "An IP range is not valid CIDR notation, or does not have the prefix length the resource requires (VPC connectors need a /28).",
+		Remediation: "Write ranges as address/prefix, e.g. \"10.0.1.0/24\".",
+	},
+	CodeCIDROverlap: {
+		Title:       "CIDR ranges overlap",
+		Description: "Two subnet ranges overlap. Within a VPC, GCP rejects overlapping primary and secondary ranges because routing between them would be ambiguous. Across VPCs, overlapping ranges prevent the networks from being peered or connected through a VPN later.",
+		Remediation: "Give each subnet and secondary range a distinct range, e.g. 10.0.1.0/24 and 10.0.2.0/24, in every VPC. Plan ranges up front to leave room for growth.",
+	},
+	CodeReservedIPRegion: {
+		Title:       "Reserved IP region mismatch",
+		Description: "Regional reserved IPs must specify a region, and global reserved IPs must not.",
+		Remediation: "Set region for regional addresses, or remove it for global addresses used by global load balancers.",
+	},
+	CodeFirewallDirection: {
+		Title:       "Firewall field not valid for direction",
+		Description: "INGRESS rules match on sources, so they cannot set destination_ranges. EGRESS rules match on destinations, so they cannot set source_ranges or source_tags.",
+		Remediation: "Remove the fields that do not apply to the rule's direction, or change the direction.",
+	},
+	CodeFirewallAction: {
+		Title:       "Firewall rule must allow or deny",
+		Description: "A firewall rule must have allow blocks or deny blocks, but not both.",
+		Remediation: "Split rules that both allow and deny traffic into separate rules, using priority to order them.",
+	},
+	CodeFirewallProtocol: {
+		Title:       "Conflicting firewall protocols",
+		Description: "A protocol is listed more than once in the same allow or deny block, or 'all' is combined with specific protocols.",
+		Remediation: "List each protocol once, and use 'all' on its own.",
+	},
+	CodeNATManualIPs: {
+		Title:       "Manual NAT without IPs",
+		Description: "A NAT gateway with MANUAL_ONLY IP allocation must list the reserved IPs to use.",
+		Remediation: "Add nat_ips, or use AUTO_ONLY allocation.",
+	},
+	CodeVpcConnectorConfig: {
+		Title:       "Invalid VPC connector settings",
+		Description: "A serverless VPC access connector needs either a subnet or a network with a /28 ip_cidr_range (not both), between 2 and 10 instances, and a name of at most 25 characters.",
+		Remediation: "Adjust the connector settings named in the message.",
+	},
+	CodeNetworkInterfaceMissing: {
+		Title:       "Network interface without a network",
+		Description: "Each network interface must attach to a network or a subnetwork.",
+		Remediation: "Set network or subnetwork on the interface.",
+	},
+	CodeSubnetNetworkMismatch: {
+		Title:       "Subnetwork not in network",
+		Description: "A network interface names both a network and a subnetwork, but the subnetwork belongs to a different VPC. This usually happens when an interface block is copied from another resource.",
+		Remediation: "Set network to the VPC that declares the subnetwork, or omit network since the subnetwork implies it.",
+	},
+	CodeFirewallPorts: {
+		Title:       "Invalid firewall protocol or port",
+		Description: "Each allow or deny entry needs a protocol that is tcp, udp, icmp, esp, ah, sctp, ipip, all, or an IP protocol number from 0 to 255. Ports apply only to tcp, udp, and sctp, and each must be a port from 0 to 65535 or a start-end range with start no greater than end.",
+		Remediation: "Fix the protocol or port named in the message, e.g. \"8080\" or \"8080-8090\" rather than \"8080-\".",
+	},
+	CodeReservedIPAddress: {
+		Title:       "Invalid reserved IP address",
+		Description: "A reserved IP that pins a specific address must give a valid IPv4 or IPv6 address, and its address_type is EXTERNAL (the default) or INTERNAL. Validation also warns when an INTERNAL address is outside every subnet of the configuration.",
+		Remediation: "Fix the address named in the message, or remove it to let GCP choose one.",
+	},
+	CodeVpcPeering: {
+		Title:       "Invalid VPC peering",
+		Description: "A VPC peering connects a VPC of the configuration to a peer network: another VPC of the configuration, or a network elsewhere given by its self link or projects/<project>/global/networks/<network> path. A network can't peer with itself or with the same peer twice, and a peering can only import custom routes when the peering back from the peer network exports them.",
+		Remediation: "Fix the peering named in the message, e.g. set export_custom_routes on the peering back from the peer network.",
+	},
+	CodeDiskTooSmall: {
+		Title:       "Boot disk too small",
+		Description: "Boot disks must be at least 10 GB, the minimum size of public images.",
+		Remediation: "Set disk_size_gb to 10 or more.",
+	},
+	CodeAutoscalingBounds: {
+		Title:       "Invalid autoscaling bounds",
+		Description: "The autoscaling minimum is negative or exceeds the maximum, the CPU target is outside the range 0-1, or the cooldown period is negative. A maximum of 0 is allowed, with a warning, since the group then runs no instances.",
+		Remediation: "Make min between 0 and max, express the CPU target as a fraction (e.g. 0.6 for 60%), and give cooldown_period in seconds (0 uses the default of 60).",
+	},
+	CodeSoleTenancy: {
+		Title:       "Invalid sole-tenant placement",
+		Description: "A sole-tenant node group or node affinity is inconsistent: the group zone is outside the node template region, the machine type does not fit the node type or is unsupported on sole-tenant nodes (E2), or an affinity key matches no node template label.",
+		Remediation: "Match machine families to node types (e.g. n2 machines on n2-node types), keep groups in the template region, and place instances in the zone of their node group.",
+	},
+	CodeReservationAffinity: {
+		Title:       "Invalid reservation affinity",
+		Description: "SPECIFIC_RESERVATION affinities need a key and values naming the reservation; ANY_RESERVATION and NO_RESERVATION must not set them.",
+		Remediation: "Set key and values only for SPECIFIC_RESERVATION.",
+	},
+	CodeHealthCheck: {
+		Title:       "Invalid health check",
+		Description: "A health check port is outside 1-65535, its timeout is not shorter than its check interval, or it sets fields its type doesn't support. The type is HTTP (the default), HTTPS, or TCP; request_path (starting with /) and host apply to HTTP and HTTPS checks, and proxy_header (NONE or PROXY_V1) to TCP checks.",
+		Remediation: "Use a valid port and a timeout_sec lower than check_interval_sec, and only set the fields of the health check's type.",
+	},
+	CodeInstanceGroupVersions: {
+		Title:       "Invalid instance group versions",
+		Description: "An instance group's versions replace its template. Exactly one version must leave its target size unset so it runs on the remaining instances; the others set either target_size_fixed or target_size_percent (1-100). Percentages must not add up to more than 100, and fixed sizes must not exceed the group size.",
+		Remediation: "Remove template from the group and adjust the version target sizes as described in the message.",
+	},
+	CodeBackendNamedPort: {
+		Title:       "Load balancer backend lacks named port",
+		Description: "A load balancer's backend service sends traffic to a named port of its backend instance group: port_name, or \"http\" when unset. If the group does not declare that port in named_ports, the backends never become healthy.",
+		Remediation: "Add the port to the instance group's named_ports, or set the load balancer's port_name to a port the group declares.",
+	},
+	CodeLoadBalancerSsl: {
+		Title:       "Invalid load balancer SSL configuration",
+		Description: "A load balancer with ssl_enabled serves HTTPS on port 443 with either a Google-managed certificate for its managed_domains or an existing ssl_certificate, not both. Managed domains are plain domain names (no wildcards), at most 100 per certificate. managed_domains and ssl_certificate have no effect without ssl_enabled.",
+		Remediation: "Set ssl_enabled with either managed_domains or ssl_certificate, and list each domain once.",
+	},
+	CodeNamedPort: {
+		Title:       "Invalid named port",
+		Description: "Each named port of an instance group needs a unique name of 1-63 lowercase letters, digits, and hyphens starting with a letter, and a port between 1 and 65535.",
+		Remediation: "Rename or remove the duplicate named port, or fix its port number.",
+	},
+	CodeInvalidServiceAccountID: {
+		Title:       "Invalid service account ID",
+		Description: "Service account IDs must be 6-30 characters of lowercase letters, digits, and hyphens, starting with a letter.",
+		Remediation: "Choose an account_id that follows these rules.",
+	},
+	CodeCustomRolePermissions: {
+		Title:       "Custom role without permissions",
+		Description: "A custom role must grant at least one permission.",
+		Remediation: "Add permissions, or remove the role.",
+	},
+	CodeInvalidBucketName: {
+		Title:       "Invalid bucket name",
+		Description: "Bucket names must be 3-63 characters of lowercase letters, digits, hyphens, underscores, and dots, starting and ending with a letter or digit. Bucket names are globally unique.",
+		Remediation: "Choose a name that follows these rules; prefixing it with the project ID usually keeps it unique.",
+	},
+	CodeLifecycleRule: {
+		Title:       "Inconsistent lifecycle rule",
+		Description: "A bucket lifecycle rule is invalid or contradicts another rule. Actions must be Delete, SetStorageClass, or AbortIncompleteMultipartUpload, and only SetStorageClass takes a storage_class, which must be STANDARD, NEARLINE, COLDLINE, or ARCHIVE. Ages must not be negative, created_before must be a YYYY-MM-DD date, and an age-based Delete rule must act after every age-based SetStorageClass rule, since objects deleted first are never transitioned.",
+		Remediation: "Fix the rule named in the message, or raise the Delete rule's age above the transition ages.",
+	},
+	CodeCryptoKeySettings: {
+		Title:       "Invalid crypto key settings",
+		Description: "A crypto key's settings conflict with its purpose: asymmetric keys need an algorithm, and rotation_period is only supported for ENCRYPT_DECRYPT keys and must be at least one day.",
+		Remediation: "Adjust the key settings named in the message.",
+	},
+	CodeChannelLabels: {
+		Title:       "Notification channel missing labels",
+		Description: "Email channels need an email_address label and Slack channels need a channel_name label.",
+		Remediation: "Add the label named in the message to the channel.",
+	},
+	CodeLogMetricValueType: {
+		Title:       "Log metric value type mismatch",
+		Description: "Distribution log metrics need a value_extractor to read values from log entries, and other value types cannot use one.",
+		Remediation: "Set value_type DISTRIBUTION together with value_extractor, or remove value_extractor for counter metrics.",
+	},
+	CodeLogSinkDestination: {
+		Title:       "Invalid log sink destination",
+		Description: "External log sink destinations must be a storage.googleapis.com, pubsub.googleapis.com, bigquery.googleapis.com, or logging.googleapis.com URI.",
+		Remediation: "Use a full destination URI, or a storage_bucket, pubsub_topic, or bigquery_dataset destination for resources in this configuration.",
+	},
+	CodeInvalidTag: {
+		Title:       "Invalid resource tag",
+		Description: "A tag key or value short name does not follow GCP's rules, or a tag key parent is not organizations/<id> or projects/<id>.",
+		Remediation: "Short names are 1-63 characters, start and end with a letter or digit, and contain only letters, digits, hyphens, underscores, and dots.",
+	},
+	CodeTagBinding: {
+		Title:       "Invalid tag binding",
+		Description: "A tag binding targets an invalid resource name, sets a location without a resource, or binds the same key to a resource more than once.",
+		Remediation: "Use full resource names starting with //, and bind each key to a resource at most once.",
+	},
+	CodeCloudRunAccess: {
+		Title:       "Invalid Cloud Run VPC access",
+		Description: "A Cloud Run service's VPC access is inconsistent: egress is set without a connector, a connector is set in two places, or the connector is in a different region from the service.",
+		Remediation: "Set the connector once, in vpc_access, and keep it in the service's region.",
+	},
+	CodeTrafficSplit: {
+		Title:       "Invalid Cloud Run traffic split",
+		Description: "Traffic percentages must each be 0-100 and sum to 100, each revision and tag may appear once, and revisions must belong to the service.",
+		Remediation: "Adjust the traffic entries so every revision is targeted once and the percentages sum to 100.",
+	},
+	CodeCloudRunSecretEnv: {
+		Title:       "Invalid Cloud Run environment variable from a secret",
+		Description: "An environment variable read from a Secret Manager secret must have a name and a secret_name, must not also be set in env_vars or another env_from_secrets entry, and must read version \"latest\" or a version number.",
+		Remediation: "Complete the env_from_secrets entry, remove the duplicate variable, or set version to \"latest\" or a version number such as \"3\".",
+	},
+	CodeInvalidTopic: {
+		Title:       "Invalid Pub/Sub topic",
+		Description: "Topic names must be 3-255 characters, start with a letter, contain only letters, digits, and - _ . ~ + %, and must not start with \"goog\". Message retention must be between 10 minutes and 31 days.",
+		Remediation: "Rename the topic or adjust message_retention_duration.",
+	},
+	CodeInvalidSchedule: {
+		Title:       "Invalid Cloud Scheduler schedule",
+		Description: "Schedules use unix-cron format: five space-separated fields for minute (0-59), hour (0-23), day of month (1-31), month (1-12 or JAN-DEC), and day of week (0-7 or SUN-SAT). Fields accept *, values, ranges (a-b), lists (a,b), and steps (*/n or a-b/n).",
+		Remediation: "Fix the schedule, e.g. \"0 */6 * * *\" for every six hours or \"30 2 * * MON-FRI\" for 02:30 on weekdays.",
+	},
+	CodeInvalidTimeZone: {
+		Title:       "Invalid time zone",
+		Description: "A Cloud Scheduler job's time_zone is not a name in the IANA time zone database.",
+		Remediation: "Use an IANA name such as \"Etc/UTC\", \"America/New_York\", or \"Europe/Berlin\".",
+	},
+	CodeSchedulerTarget: {
+		Title:       "Invalid Cloud Scheduler target",
+		Description: "Every job needs exactly one of http_target, pubsub_target, or app_engine_target. HTTP targets need an http(s) URI, App Engine targets a relative URI starting with /, and methods must be GET, POST, PUT, DELETE, PATCH, HEAD, or OPTIONS.",
+		Remediation: "Set a single target with the fields described in the message.",
+	},
+	CodeSchedulerRetry: {
+		Title:       "Invalid Cloud Scheduler retry configuration",
+		Description: "retry_count must be 0-5, max_doublings must not be negative, and min_backoff_duration must not exceed max_backoff_duration.",
+		Remediation: "Adjust retry_config as described in the message.",
+	},
+	CodeQueueRateLimits: {
+		Title:       "Invalid Cloud Tasks rate limits",
+		Description: "max_dispatches_per_second must be positive and at most 500, and max_concurrent_dispatches must be positive and at most 5000. Leaving a limit unset uses the Cloud Tasks default.",
+		Remediation: "Set the rate limit within the allowed range or remove it.",
+	},
+	CodeQueueRetry: {
+		Title:       "Invalid Cloud Tasks retry configuration",
+		Description: "max_attempts must be 1-100, or -1 for unlimited attempts; max_doublings must not be negative; and min_backoff must not exceed max_backoff.",
+		Remediation: "Adjust retry_config as described in the message.",
+	},
+	CodeFilestoreCapacity: {
+		Title:       "Filestore capacity below tier minimum",
+		Description: "Each Filestore tier has a minimum file share capacity: 1024 GB for BASIC_HDD, ZONAL, REGIONAL, and ENTERPRISE, 2560 GB for BASIC_SSD, and 10240 GB for HIGH_SCALE_SSD.",
+		Remediation: "Increase file_share.capacity_gb to at least the tier minimum or choose a different tier.",
+	},
+	CodeFilestoreLocation: {
+		Title:       "Invalid Filestore location",
+		Description: "BASIC_HDD, BASIC_SSD, HIGH_SCALE_SSD, and ZONAL instances are zonal and need a zone; REGIONAL and ENTERPRISE instances need a region.",
+		Remediation: "Set zone or region to match the instance tier.",
+	},
+	CodeGkeLocation: {
+		Title:       "Invalid GKE cluster location",
+		Description: "A GKE cluster is either regional or zonal, so exactly one of region and zone must be set.",
+		Remediation: "Set region for a regional cluster (nodes in every zone of the region) or zone for a zonal cluster.",
+	},
+	CodeGkeNodePoolSize: {
+		Title:       "Invalid GKE node pool size",
+		Description: "A node pool either has a fixed node_count or autoscales between min_node_count and max_node_count, never both. Counts are per zone, must not be negative, and max_node_count must be at least 1 and at least min_node_count.",
+		Remediation: "Set node_count for a fixed size, or min_node_count and max_node_count for autoscaling.",
+	},
+	CodeGkeSecondaryRange: {
+		Title:       "Invalid GKE secondary range",
+		Description: "VPC-native clusters take pod and service IPs from two different secondary ranges of the cluster's subnetwork, so pods_secondary_range and services_secondary_range must be set together and name distinct secondary_ranges declared on that subnet.",
+		Remediation: "Declare both ranges in the subnet's secondary_ranges and reference them by range_name, or omit both.",
+	},
+	CodeDnsName: {
+		Title:       "Invalid DNS name",
+		Description: "A managed zone's dns_name is a domain name ending with a dot, such as example.com., and every record set name must be the zone's DNS name or a name within it. Record names are relative to the zone unless they end with a dot.",
+		Remediation: "Add the trailing dot to dns_name, or use a record name relative to the zone (e.g. www, or @ for the apex).",
+	},
+	CodeDnsRecordSet: {
+		Title:       "Invalid DNS record set",
+		Description: "A record set has a supported type, a non-negative ttl, and at least one rrdata, and each name and type pair appears once per zone. A CNAME has exactly one rrdata and cannot be at the zone apex.",
+		Remediation: "Fix the record set as described in the message; put multiple values of one name and type in a single record set's rrdatas.",
+	},
+	CodeDnsVisibility: {
+		Title:       "Invalid DNS zone visibility",
+		Description: "A managed zone is public (the default) or private. A private zone is only resolvable from the VPCs in its networks, so it needs at least one, and a public zone cannot list networks.",
+		Remediation: "Set visibility to private and list the VPCs that resolve the zone, or remove networks from a public zone.",
+	},
+	CodeBigQueryName: {
+		Title:       "Invalid BigQuery dataset or table ID",
+		Description: "Dataset IDs contain only letters, digits, and underscores, and table IDs may also contain hyphens; both are at most 1024 characters. Each dataset ID appears once per project and each table ID once per dataset.",
+		Remediation: "Rename the dataset or table, e.g. replacing hyphens in a dataset ID with underscores.",
+	},
+	CodeBigQueryAccess: {
+		Title:       "Invalid BigQuery access entry",
+		Description: "A dataset access entry grants a role to exactly one of user_by_email, group_by_email, domain, or special_group. Special groups are projectOwners, projectReaders, projectWriters, and allAuthenticatedUsers.",
+		Remediation: "Split entries that name several principals into one entry per principal, and set a role on each.",
+	},
+	CodeBigQuerySchema: {
+		Title:       "Invalid BigQuery table schema",
+		Description: "A table's schema comes from either fields or schema_json, not both. Fields have a name, a supported type and mode, and RECORD fields (only) have nested fields. Partitioning uses DAY, HOUR, MONTH, or YEAR on a TIMESTAMP, DATE, or DATETIME field, and a table clusters by at most four of its fields.",
+		Remediation: "Fix the field, partitioning, or clustering named in the message.",
+	},
+	CodeFunctionSource: {
+		Title:       "Invalid Cloud Function source",
+		Description: "A function is built from a source archive, so it needs a runtime such as python312 or nodejs20, the entry point to call, and the source_bucket and source_object of the archive.",
+		Remediation: "Set runtime, entry_point, source_bucket, and source_object on the function.",
+	},
+	CodeFunctionTrigger: {
+		Title:       "Invalid Cloud Function event trigger",
+		Description: "An event trigger needs an event_type, or a pubsub_topic for Pub/Sub events, which use the google.cloud.pubsub.topic.v1.messagePublished event type. The topic is a topic declared in pubsub.topics or a full projects/<project>/topics/<topic> ID.",
+		Remediation: "Set event_type or pubsub_topic, or remove event_trigger to trigger the function by HTTP.",
+	},
+	CodeFunctionScaling: {
+		Title:       "Invalid Cloud Function resources",
+		Description: "available_memory is a size such as 256M or 1Gi, min_instances cannot exceed max_instances, and timeout_seconds is at most 3600 for HTTP functions and 540 for event-driven functions.",
+		Remediation: "Adjust the memory, instance counts, or timeout named in the message.",
+	},
+	CodeArtifactRepositoryId: {
+		Title:       "Invalid Artifact Registry repository ID",
+		Description: "Repository IDs must be 1-63 characters of lowercase letters, digits, and hyphens, starting with a letter and not ending with a hyphen. They are unique within a location.",
+		Remediation: "Choose a repository_id that follows these rules.",
+	},
+	CodeArtifactFormat: {
+		Title:       "Invalid Artifact Registry format",
+		Description: "Every repository must set a format: ARTIFACT_FORMAT_DOCKER, ARTIFACT_FORMAT_MAVEN, ARTIFACT_FORMAT_NPM, or ARTIFACT_FORMAT_PYTHON. The format can't be changed once the repository exists.",
+		Remediation: "Set format to the kind of packages the repository stores.",
+	},
+	CodeArtifactCleanup: {
+		Title:       "Invalid Artifact Registry cleanup policy",
+		Description: "Cleanup policies need a unique id and an action of DELETE or KEEP, and a repository has at most 10. A policy either keeps the keep_count most recent versions (KEEP only) or matches versions by tag_state (TAGGED, UNTAGGED, or ANY), tag and package name prefixes, and older_than/newer_than durations; a DELETE policy must set at least one condition so it doesn't delete every version.",
+		Remediation: "Fix the policy named in the message.",
+	},
+	CodeSqlReplica: {
+		Title:       "Invalid Cloud SQL read replica",
+		Description: "A Cloud SQL instance with master_instance_name is a read replica of that primary instance, which can't itself be a replica. A replica copies the databases and users of its primary, so it can't define its own, and it must run the same database_version in the same geography (such as US or EUROPE) as the primary.",
+		Remediation: "Point master_instance_name at a primary instance, remove the replica's databases and users, and match the primary's database_version and geography, or leave region unset to use the primary's.",
+	},
+	CodeSpannerCapacity: {
+		Title:       "Invalid Spanner instance capacity",
+		Description: "A Spanner instance's compute capacity is set with exactly one of node_count (at least 1) or processing_units. Processing units are allocated in multiples of 100 up to 1000 and in multiples of 1000 beyond; 1000 processing units equal one node.",
+		Remediation: "Set either node_count or processing_units, such as 100, 500, 1000, or 2000 processing units.",
+	},
+}
+
+// Explain returns the explanation of a validation error code
+func Explain(code Code) (Explanation, bool) {
+	explanation, ok := explanations[code]
+	explanation.Code = code
+	return explanation, ok
+}
+
+// Codes returns all validation error codes in sorted order
+func Codes() []Code {
+	codes := make([]Code, 0, len(explanations))
+	for code := range explanations {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes
+}
@@ -0,0 +1,103 @@
+package validator
+
+import (
+	"fmt"
+
+	"custoodian/pkg/config"
+)
+
+// ValidateOptions configures ValidateWithOptions
+type ValidateOptions struct {
+	// Strict rejects required enum fields left unspecified that generation
+	// would silently default, such as a subnet without a region being
+	// created in us-central1
+	Strict bool
+}
+
+// strictFinding is an unspecified enum field found by strictFindings
+type strictFinding struct {
+	path string
+	err  error
+}
+
+// strictFindings returns the required region, zone, and machine type fields
+// of cfg that are unspecified and would be replaced by a hard-coded default
+// when generating. Fields that default to the region or zone variable, or
+// that other checks already require, are not reported.
+func strictFindings(cfg *config.Config) []strictFinding {
+	var findings []strictFinding
+	unspecified := func(path, resource, field, fallback string) {
+		findings = append(findings, strictFinding{path, errorf(CodeUnspecifiedEnum,
+			"%s: %s is unspecified and would default to %s", resource, field, fallback)})
+	}
+
+	for _, vpc := range cfg.GetNetworking().GetVpcs() {
+		for _, subnet := range vpc.Subnets {
+			if subnet.Region == config.Region_REGION_UNSPECIFIED {
+				unspecified("networking", "subnet "+subnet.Name, "region", "us-central1")
+			}
+		}
+	}
+	for _, nat := range cfg.GetNetworking().GetNatGateways() {
+		if nat.Region == config.Region_REGION_UNSPECIFIED {
+			unspecified("networking", "NAT gateway "+nat.Name, "region", "us-central1")
+		}
+	}
+
+	for _, template := range cfg.GetCompute().GetInstanceTemplates() {
+		if template.MachineType == config.MachineType_MACHINE_TYPE_UNSPECIFIED {
+			unspecified("compute", "instance template "+template.Name, "machine_type", "e2-medium")
+		}
+	}
+	for _, instance := range cfg.GetCompute().GetInstances() {
+		if instance.MachineType == config.MachineType_MACHINE_TYPE_UNSPECIFIED {
+			unspecified("compute", "instance "+instance.Name, "machine_type", "e2-medium")
+		}
+	}
+	for _, template := range cfg.GetCompute().GetNodeTemplates() {
+		if template.Region == config.Region_REGION_UNSPECIFIED {
+			unspecified("compute", "node template "+template.Name, "region", "us-central1")
+		}
+	}
+	for _, group := range cfg.GetCompute().GetInstanceGroups() {
+		for i, zone := range group.Zones {
+			if zone == config.Zone_ZONE_UNSPECIFIED {
+				unspecified("compute", "instance group "+group.Name, fmt.Sprintf("zones[%d]", i), "us-central1-a")
+			}
+		}
+	}
+
+	for _, service := range cfg.GetCloudRun().GetServices() {
+		if service.Location == config.Region_REGION_UNSPECIFIED {
+			unspecified("cloud_run", "Cloud Run service "+service.Name, "location", "us-central1")
+		}
+	}
+	for _, connector := range cfg.GetCloudRun().GetVpcConnectors() {
+		if connector.Region == config.Region_REGION_UNSPECIFIED {
+			unspecified("cloud_run", "VPC connector "+connector.Name, "region", "us-central1")
+		}
+	}
+
+	// Replicas without a region are created in the region of their primary
+	for _, instance := range cfg.GetDatabases().GetCloudSqlInstances() {
+		if instance.Region == config.Region_REGION_UNSPECIFIED && instance.MasterInstanceName == "" {
+			unspecified("databases", "Cloud SQL instance "+instance.Name, "region", "us-central1")
+		}
+	}
+
+	for _, secret := range cfg.GetSecretManager().GetSecrets() {
+		for i, replica := range secret.GetReplication().GetUserManaged().GetReplicas() {
+			if replica.Location == config.Region_REGION_UNSPECIFIED {
+				unspecified("secret_manager", "secret "+secret.Name, fmt.Sprintf("replicas[%d].location", i), "us-central1")
+			}
+		}
+	}
+
+	for _, job := range cfg.GetScheduler().GetJobs() {
+		if job.Region == config.Region_REGION_UNSPECIFIED {
+			unspecified("scheduler", "Cloud Scheduler job "+job.Name, "region", "us-central1")
+		}
+	}
+
+	return findings
+}
@@ -1,122 +1,581 @@
 package validator
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net"
+	"path"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	// Embedded so time zones validate without a system zoneinfo database
+	_ "time/tzdata"
+	"unicode"
+	"unicode/utf8"
 
 	"custoodian/pkg/config"
 
 	"github.com/bufbuild/protovalidate-go"
 )
 
-// ValidateConfig validates a complete configuration
+const (
+	// maxDescriptionLength is the description limit GCP enforces on most resources
+	maxDescriptionLength = 2048
+	// maxIAMDescriptionLength is the description limit for service accounts and custom roles
+	maxIAMDescriptionLength = 256
+	// maxLabels is the number of labels GCP allows on a resource
+	maxLabels = 64
+)
+
+// Label keys and values allow lowercase letters (including international
+// characters), digits, underscores, and hyphens; keys must start with a letter
+var (
+	labelKeyPattern   = regexp.MustCompile(`^\p{Ll}[\p{Ll}\p{Lo}\p{N}_-]{0,62}$`)
+	labelValuePattern = regexp.MustCompile(`^[\p{Ll}\p{Lo}\p{N}_-]{0,63}$`)
+)
+
+// ValidateConfig validates a complete configuration, returning the errors of
+// Validate joined into one error
 func ValidateConfig(cfg *config.Config) error {
+	return validateConfig(cfg, &ValidateOptions{}).Err()
+}
+
+// Validate validates a complete configuration and returns a structured
+// result. The warnings of a configuration without errors are included, as
+// reported by Warnings.
+func Validate(cfg *config.Config) *ValidationResult {
+	return ValidateWithOptions(cfg, nil)
+}
+
+// ValidateWithOptions is Validate with options; nil options behave like Validate
+func ValidateWithOptions(cfg *config.Config, opts *ValidateOptions) *ValidationResult {
+	if opts == nil {
+		opts = &ValidateOptions{}
+	}
+	result := validateConfig(cfg, opts)
+	if result.Valid {
+		for _, warning := range Warnings(cfg) {
+			result.Errors = append(result.Errors, &ValidationError{Severity: SeverityWarning, Message: warning})
+		}
+	}
+	return result
+}
+
+// configChecks are the checks of an enabled single-project configuration, in
+// order, with the configuration field each validates ("" for checks across
+// fields) and the prefix of its errors. Each check reports the errors of every
+// resource it validates, joined with errors.Join, and the checks are
+// independent, so all of them run.
+var configChecks = []struct {
+	path   string
+	prefix string
+	check  func(cfg *config.Config) error
+}{
+	{"project", "project validation failed", func(cfg *config.Config) error {
+		return validateProject(cfg.Project)
+	}},
+	{"common_labels", "common_labels validation failed", func(cfg *config.Config) error {
+		return validateLabels(cfg.CommonLabels)
+	}},
+	{"networking", "networking validation failed", func(cfg *config.Config) error {
+		if cfg.Networking == nil {
+			return nil
+		}
+		return validateNetworking(cfg.Networking)
+	}},
+	{"compute", "compute validation failed", func(cfg *config.Config) error {
+		if cfg.Compute == nil {
+			return nil
+		}
+		return validateCompute(cfg.Compute)
+	}},
+	{"load_balancers", "load balancer validation failed", func(cfg *config.Config) error {
+		if len(cfg.LoadBalancers) == 0 {
+			return nil
+		}
+		return validateLoadBalancers(cfg.LoadBalancers)
+	}},
+	{"iam", "IAM validation failed", func(cfg *config.Config) error {
+		if cfg.Iam == nil {
+			return nil
+		}
+		return validateIAM(cfg.Iam)
+	}},
+	{"storage", "storage validation failed", func(cfg *config.Config) error {
+		if cfg.Storage == nil {
+			return nil
+		}
+		return validateStorage(cfg.Storage)
+	}},
+	{"cloud_run", "Cloud Run validation failed", func(cfg *config.Config) error {
+		if cfg.CloudRun == nil {
+			return nil
+		}
+		return validateCloudRun(cfg.CloudRun)
+	}},
+	{"databases", "database validation failed", func(cfg *config.Config) error {
+		if cfg.Databases == nil {
+			return nil
+		}
+		return validateDatabases(cfg.Databases)
+	}},
+	{"secret_manager", "Secret Manager validation failed", func(cfg *config.Config) error {
+		if cfg.SecretManager == nil {
+			return nil
+		}
+		return validateSecretManager(cfg.SecretManager)
+	}},
+	{"kms", "KMS validation failed", func(cfg *config.Config) error {
+		if cfg.Kms == nil {
+			return nil
+		}
+		return validateKMS(cfg.Kms)
+	}},
+	{"monitoring", "monitoring validation failed", func(cfg *config.Config) error {
+		if cfg.Monitoring == nil {
+			return nil
+		}
+		return validateMonitoring(cfg.Monitoring)
+	}},
+	{"log_sinks", "log sink validation failed", func(cfg *config.Config) error {
+		if len(cfg.LogSinks) == 0 {
+			return nil
+		}
+		return validateLogSinks(cfg.LogSinks)
+	}},
+	{"resource_tags", "resource tags validation failed", func(cfg *config.Config) error {
+		if cfg.ResourceTags == nil {
+			return nil
+		}
+		return validateResourceTags(cfg.ResourceTags)
+	}},
+	{"pubsub", "Pub/Sub validation failed", func(cfg *config.Config) error {
+		if cfg.Pubsub == nil {
+			return nil
+		}
+		return validatePubSub(cfg.Pubsub)
+	}},
+	{"scheduler", "Cloud Scheduler validation failed", func(cfg *config.Config) error {
+		if cfg.Scheduler == nil {
+			return nil
+		}
+		return validateScheduler(cfg.Scheduler)
+	}},
+	{"tasks", "Cloud Tasks validation failed", func(cfg *config.Config) error {
+		if cfg.Tasks == nil {
+			return nil
+		}
+		return validateTasks(cfg.Tasks)
+	}},
+	{"filestore", "Filestore validation failed", func(cfg *config.Config) error {
+		if cfg.Filestore == nil {
+			return nil
+		}
+		return validateFilestore(cfg.Filestore)
+	}},
+	{"gke", "GKE validation failed", func(cfg *config.Config) error {
+		if cfg.Gke == nil {
+			return nil
+		}
+		return validateGke(cfg.Gke)
+	}},
+	{"dns", "Cloud DNS validation failed", func(cfg *config.Config) error {
+		if cfg.Dns == nil {
+			return nil
+		}
+		return validateDns(cfg.Dns)
+	}},
+	{"bigquery", "BigQuery validation failed", func(cfg *config.Config) error {
+		if cfg.Bigquery == nil {
+			return nil
+		}
+		return validateBigQuery(cfg.Bigquery)
+	}},
+	{"cloud_functions", "Cloud Functions validation failed", func(cfg *config.Config) error {
+		if cfg.CloudFunctions == nil {
+			return nil
+		}
+		return validateCloudFunctions(cfg.CloudFunctions)
+	}},
+	{"artifact_registry", "Artifact Registry validation failed", func(cfg *config.Config) error {
+		if cfg.ArtifactRegistry == nil {
+			return nil
+		}
+		return validateArtifactRegistry(cfg.ArtifactRegistry)
+	}},
+	{"output", "output validation failed", func(cfg *config.Config) error {
+		if cfg.Output == nil {
+			return nil
+		}
+		if _, err := config.OutputFileNames(cfg.Output); err != nil {
+			return errorf(CodeOutputFileName, "%w", err)
+		}
+		return nil
+	}},
+	{"", "cross-reference validation failed", validateCrossReferences},
+}
+
+// validateConfig validates a configuration, collecting the errors of every
+// check. Schema violations are reported on their own, as the other checks
+// assume a well-formed configuration.
+func validateConfig(cfg *config.Config, opts *ValidateOptions) *ValidationResult {
+	result := &ValidationResult{Valid: true, Errors: []*ValidationError{}}
+
 	// First, validate using protovalidate constraints
 	validator, err := protovalidate.New()
 	if err != nil {
-		return fmt.Errorf("failed to create validator: %w", err)
+		result.add("", "", fmt.Errorf("failed to create validator: %w", err))
+		return result
 	}
 
 	if err := validator.Validate(cfg); err != nil {
-		return fmt.Errorf("proto validation failed: %w", err)
+		result.add("", "", errorf(CodeSchemaViolation, "proto validation failed: %w", err))
+		return result
 	}
 
-	// Custom business logic validations
-	if err := validateProject(cfg.Project); err != nil {
-		return fmt.Errorf("project validation failed: %w", err)
+	// Each project of a multi-project configuration is validated on its own
+	if len(cfg.Projects) > 0 {
+		validateProjects(cfg, opts, result)
+		return result
 	}
 
-	if cfg.Networking != nil {
-		if err := validateNetworking(cfg.Networking); err != nil {
-			return fmt.Errorf("networking validation failed: %w", err)
-		}
+	// Disabled resources are not generated, so they are validated as if absent.
+	// References to them from enabled resources are checked first so they are
+	// reported as disabled rather than missing.
+	enabledCfg := config.WithoutDisabled(cfg)
+	if err := validateDisabledReferences(enabledCfg, disabledResourceNames(cfg, enabledCfg)); err != nil {
+		result.add("", "cross-reference validation failed", err)
 	}
 
-	if cfg.Compute != nil {
-		if err := validateCompute(cfg.Compute); err != nil {
-			return fmt.Errorf("compute validation failed: %w", err)
+	for _, c := range configChecks {
+		if err := c.check(enabledCfg); err != nil {
+			result.add(c.path, c.prefix, err)
 		}
 	}
 
-	if len(cfg.LoadBalancers) > 0 {
-		if err := validateLoadBalancers(cfg.LoadBalancers); err != nil {
-			return fmt.Errorf("load balancer validation failed: %w", err)
+	if opts.Strict {
+		for _, finding := range strictFindings(enabledCfg) {
+			result.add(finding.path, "strict validation failed", finding.err)
 		}
 	}
 
-	if cfg.Iam != nil {
-		if err := validateIAM(cfg.Iam); err != nil {
-			return fmt.Errorf("IAM validation failed: %w", err)
+	// Custom validators may rely on the built-in checks passing
+	if result.Valid {
+		if err := validateCustom(enabledCfg); err != nil {
+			result.add("", "policy validation failed", err)
 		}
 	}
 
-	if cfg.Storage != nil {
-		if err := validateStorage(cfg.Storage); err != nil {
-			return fmt.Errorf("storage validation failed: %w", err)
+	return result
+}
+
+// Warnings returns advisory findings for a configuration that is otherwise valid.
+// Warnings describe settings that Terraform will accept but that are likely to
+// fail or misbehave at apply time.
+func Warnings(cfg *config.Config) []string {
+	var warnings []string
+
+	if len(cfg.Projects) > 0 {
+		scoped, err := config.SplitByProject(cfg)
+		if err != nil {
+			// Invalid project assignments are reported by ValidateConfig
+			return nil
+		}
+		for _, projectCfg := range scoped {
+			for _, warning := range Warnings(projectCfg) {
+				warnings = append(warnings, fmt.Sprintf("project %s: %s", projectCfg.Project.Id, warning))
+			}
 		}
+		return warnings
 	}
 
-	// Cross-resource validations
-	if err := validateCrossReferences(cfg); err != nil {
-		return fmt.Errorf("cross-reference validation failed: %w", err)
+	cfg = config.WithoutDisabled(cfg)
+
+	warnings = append(warnings, cmekServiceAgentWarnings(cfg)...)
+	warnings = append(warnings, quotaWarnings(cfg)...)
+	warnings = append(warnings, gvnicWarnings(cfg)...)
+	warnings = append(warnings, machineAvailabilityWarnings(cfg)...)
+	warnings = append(warnings, autoscalingWarnings(cfg)...)
+	warnings = append(warnings, reservedIPWarnings(cfg)...)
+	warnings = append(warnings, healthCheckPortWarnings(cfg)...)
+	warnings = append(warnings, secretValueWarnings(cfg)...)
+	warnings = append(warnings, cloudRunSecretWarnings(cfg)...)
+	warnings = append(warnings, externalServiceAccountWarnings(cfg)...)
+	warnings = append(warnings, customWarnings(cfg)...)
+
+	return warnings
+}
+
+// validateProjects validates each project of a multi-project configuration
+// along with the resources assigned to it
+func validateProjects(cfg *config.Config, opts *ValidateOptions, result *ValidationResult) {
+	scoped, err := config.SplitByProject(cfg)
+	if err != nil {
+		result.add("projects", "", errorf(CodeProjectAssignment, "%w", err))
+		return
 	}
 
-	return nil
+	// Projects sharing a GCS state location would overwrite each other's state
+	stateLocations := make(map[string]string)
+	for _, projectCfg := range scoped {
+		if projectResult := validateConfig(projectCfg, opts); !projectResult.Valid {
+			result.addProject(projectCfg.Project.Id, projectResult)
+		}
+
+		if backend := projectCfg.Project.Backend; backend.GetType() == "gcs" {
+			location := "gs://" + path.Join(backend.Bucket, backend.Prefix)
+			if other, ok := stateLocations[location]; ok {
+				result.add("projects", "", errorf(CodeInvalidValue, "projects %s and %s store their state in the same location: %s (give each project its own prefix)", other, projectCfg.Project.Id, location))
+				continue
+			}
+			stateLocations[location] = projectCfg.Project.Id
+		}
+	}
 }
 
 // validateProject validates project configuration
 func validateProject(project *config.Project) error {
 	if project == nil {
-		return fmt.Errorf("project configuration is required")
+		return errorf(CodeProjectMissing, "project configuration is required")
 	}
 
+	var errs []error
+
 	// Validate project ID format (GCP-specific rules)
 	if !isValidGCPProjectID(project.Id) {
-		return fmt.Errorf("invalid project ID: %s (must be 6-30 characters, lowercase letters, numbers, and hyphens, start with letter, end with letter or number)", project.Id)
+		errs = append(errs, errorf(CodeInvalidProjectID, "invalid project ID: %s (must be 6-30 characters, lowercase letters, numbers, and hyphens, start with letter, end with letter or number)", project.Id))
 	}
 
 	// Validate billing account format
 	if project.BillingAccount != "" && !isValidBillingAccount(project.BillingAccount) {
-		return fmt.Errorf("invalid billing account format: %s", project.BillingAccount)
+		errs = append(errs, errorf(CodeInvalidBillingAccount, "invalid billing account format: %s", project.BillingAccount))
 	}
 
 	// Validate that organization_id and folder_id are mutually exclusive
 	if project.OrganizationId != "" && project.FolderId != "" {
-		return fmt.Errorf("organization_id and folder_id are mutually exclusive")
+		errs = append(errs, errorf(CodeProjectParentConflict, "organization_id and folder_id are mutually exclusive"))
+	}
+
+	validDefaultServiceAccountActions := map[string]bool{
+		"DISABLE":     true,
+		"DELETE":      true,
+		"DEPRIVILEGE": true,
+		"KEEP":        true,
+	}
+
+	if project.DefaultServiceAccountsAction != "" && !validDefaultServiceAccountActions[project.DefaultServiceAccountsAction] {
+		errs = append(errs, errorf(CodeInvalidValue, "invalid default_service_accounts_action: %s (must be DISABLE, DELETE, DEPRIVILEGE, or KEEP)", project.DefaultServiceAccountsAction))
+	}
+
+	if err := validateLabels(project.Labels); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateLabels(project.ProviderDefaultLabels); err != nil {
+		errs = append(errs, fmt.Errorf("provider_default_labels: %w", err))
+	}
+
+	// The provider ignores billing_project unless user_project_override is set
+	if project.BillingProject != "" {
+		if !project.UserProjectOverride {
+			errs = append(errs, errorf(CodeInvalidValue, "billing_project requires user_project_override"))
+		}
+		if !isValidGCPProjectID(project.BillingProject) {
+			errs = append(errs, errorf(CodeInvalidProjectID, "invalid billing_project: %s (must be 6-30 characters, lowercase letters, numbers, and hyphens, start with letter, end with letter or number)", project.BillingProject))
+		}
+	}
+
+	// Sorted so that errors are reported in the same order on every run
+	resourceTypes := make([]string, 0, len(project.QuotaLimits))
+	for resourceType := range project.QuotaLimits {
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+	sort.Strings(resourceTypes)
+
+	for _, resourceType := range resourceTypes {
+		limit := project.QuotaLimits[resourceType]
+		if _, ok := defaultQuotas[resourceType]; !ok {
+			errs = append(errs, errorf(CodeInvalidValue, "unknown quota_limits resource type: %s (must be vpcs, subnets, firewall_rules, service_accounts, or custom_roles)", resourceType))
+			continue
+		}
+		if limit < 0 {
+			errs = append(errs, errorf(CodeInvalidValue, "quota_limits %s cannot be negative", resourceType))
+		}
+	}
+
+	for _, version := range []struct{ field, constraint string }{
+		{"google_provider_version", project.GoogleProviderVersion},
+		{"google_beta_provider_version", project.GoogleBetaProviderVersion},
+	} {
+		if version.constraint != "" && !isValidVersionConstraint(version.constraint) {
+			errs = append(errs, errorf(CodeInvalidValue, "invalid %s: %s (must be a Terraform version constraint, e.g. \"~> 5.0\" or \">= 5.10, < 6.0\")", version.field, version.constraint))
+		}
+	}
+
+	if project.Backend != nil {
+		if err := validateBackend(project.Backend); err != nil {
+			errs = append(errs, fmt.Errorf("invalid backend: %w", err))
+		}
+	}
+
+	if project.ApiPropagationDelay != "" {
+		delay, err := config.ParseDuration(project.ApiPropagationDelay)
+		if err != nil {
+			errs = append(errs, errorf(CodeInvalidDuration, "invalid api_propagation_delay: %w", err))
+		} else if delay == 0 {
+			errs = append(errs, errorf(CodeInvalidDuration, "api_propagation_delay must be positive"))
+		}
+		if len(project.Apis) == 0 {
+			errs = append(errs, errorf(CodeInvalidValue, "api_propagation_delay requires apis to be enabled"))
+		}
 	}
 
+	return errors.Join(errs...)
+}
+
+// validateBackend validates the Terraform backend of a project
+func validateBackend(backend *config.Backend) error {
+	switch backend.Type {
+	case "gcs":
+		if backend.Bucket == "" {
+			return errorf(CodeRequiredField, "gcs backend requires a bucket")
+		}
+		if !isValidBucketName(backend.Bucket) {
+			return errorf(CodeInvalidValue, "invalid bucket name: %s", backend.Bucket)
+		}
+		if backend.Path != "" {
+			return errorf(CodeInvalidValue, "path is only supported by the local backend (use prefix for gcs)")
+		}
+	case "local":
+		if backend.Bucket != "" || backend.Prefix != "" {
+			return errorf(CodeInvalidValue, "bucket and prefix are only supported by the gcs backend")
+		}
+	case "":
+		return errorf(CodeRequiredField, "backend type is required (gcs or local)")
+	default:
+		return errorf(CodeInvalidValue, "unsupported backend type: %s (must be gcs or local)", backend.Type)
+	}
 	return nil
 }
 
 // validateNetworking validates networking configuration
 func validateNetworking(networking *config.Networking) error {
+	var errs []error
 	// Validate reserved IPs
 	for _, ip := range networking.ReservedIps {
 		if err := validateReservedIP(ip); err != nil {
-			return fmt.Errorf("invalid reserved IP %s: %w", ip.Name, err)
+			errs = append(errs, wrapEach(err, "invalid reserved IP %s", ip.Name))
 		}
 	}
 
 	// Validate VPCs
 	for _, vpc := range networking.Vpcs {
 		if err := validateVPC(vpc); err != nil {
-			return fmt.Errorf("invalid VPC %s: %w", vpc.Name, err)
+			errs = append(errs, wrapEach(err, "invalid VPC %s", vpc.Name))
 		}
 	}
 
+	if err := validateNetworkCIDRs(networking.Vpcs); err != nil {
+		errs = append(errs, err)
+	}
+
 	// Validate firewall rules
 	for _, rule := range networking.FirewallRules {
 		if err := validateFirewallRule(rule); err != nil {
-			return fmt.Errorf("invalid firewall rule %s: %w", rule.Name, err)
+			errs = append(errs, wrapEach(err, "invalid firewall rule %s", rule.Name))
 		}
 	}
 
 	// Validate NAT gateways
 	for _, nat := range networking.NatGateways {
 		if err := validateNATGateway(nat); err != nil {
-			return fmt.Errorf("invalid NAT gateway %s: %w", nat.Name, err)
+			errs = append(errs, wrapEach(err, "invalid NAT gateway %s", nat.Name))
+		}
+	}
+
+	if err := validateVpcPeerings(networking); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateVpcPeerings validates VPC peerings against the VPCs of the
+// configuration and the peerings back from peer networks it declares
+func validateVpcPeerings(networking *config.Networking) error {
+	vpcs := make(map[string]bool)
+	for _, vpc := range networking.Vpcs {
+		vpcs[vpc.Name] = true
+	}
+
+	// Peerings by network and peer network, to find the peering back
+	type networkPair struct{ network, peer string }
+	pairs := make(map[networkPair]*config.VpcPeering)
+	names := make(map[string]bool)
+	var errs []error
+	for _, peering := range networking.Peerings {
+		if names[peering.Name] {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate VPC peering name: %s", peering.Name))
+			continue
+		}
+		names[peering.Name] = true
+
+		if err := validateVpcPeering(peering, vpcs); err != nil {
+			errs = append(errs, fmt.Errorf("invalid VPC peering %s: %w", peering.Name, err))
+			continue
+		}
+
+		pair := networkPair{peering.Network, peering.PeerNetwork}
+		if other, ok := pairs[pair]; ok {
+			errs = append(errs, errorf(CodeVpcPeering, "VPC peerings %s and %s both peer network %s with %s", other.Name, peering.Name, peering.Network, peering.PeerNetwork))
+			continue
 		}
+		pairs[pair] = peering
+	}
+
+	// Routes can only be imported from a peer that exports them. The peering
+	// back from a network elsewhere isn't known, so it's assumed to export.
+	for _, peering := range networking.Peerings {
+		back, ok := pairs[networkPair{peering.PeerNetwork, peering.Network}]
+		if !ok {
+			continue
+		}
+		if peering.ImportCustomRoutes && !back.ExportCustomRoutes {
+			errs = append(errs, errorf(CodeVpcPeering, "VPC peering %s imports custom routes from %s, but peering %s back from it does not export them", peering.Name, peering.PeerNetwork, back.Name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateVpcPeering validates a single VPC peering; vpcs holds the VPC names
+// of the configuration
+func validateVpcPeering(peering *config.VpcPeering, vpcs map[string]bool) error {
+	if !peeringNamePattern.MatchString(peering.Name) {
+		return errorf(CodeVpcPeering, "invalid name: %s (must be 1-63 lowercase letters, digits, and hyphens, starting with a letter)", peering.Name)
+	}
+
+	if peering.Network == "" {
+		return errorf(CodeRequiredField, "network is required")
+	}
+	if !vpcs[peering.Network] {
+		return errorf(CodeUnknownReference, "unknown network: %s", peering.Network)
+	}
+
+	// Peer networks are VPCs of this configuration unless given as a path
+	if peering.PeerNetwork == "" {
+		return errorf(CodeRequiredField, "peer_network is required")
+	}
+	if !strings.Contains(peering.PeerNetwork, "/") && !vpcs[peering.PeerNetwork] {
+		return errorf(CodeUnknownReference, "unknown peer network: %s (use a network self link or projects/<project>/global/networks/<network> for networks elsewhere)", peering.PeerNetwork)
+	}
+	if peering.PeerNetwork == peering.Network {
+		return errorf(CodeVpcPeering, "network %s cannot peer with itself", peering.Network)
 	}
 
 	return nil
@@ -124,104 +583,265 @@ func validateNetworking(networking *config.Networking) error {
 
 // validateReservedIP validates a reserved IP configuration
 func validateReservedIP(ip *config.ReservedIp) error {
+	var errs []error
+	if err := validateDescription(ip.Description, maxDescriptionLength); err != nil {
+		errs = append(errs, err)
+	}
+
 	// Regional IPs must have a region specified
 	if ip.Type == config.ReservedIpType_RESERVED_IP_TYPE_REGIONAL && ip.Region == config.Region_REGION_UNSPECIFIED {
-		return fmt.Errorf("regional reserved IP must specify a region")
+		errs = append(errs, errorf(CodeReservedIPRegion, "regional reserved IP must specify a region"))
 	}
 
 	// Global IPs should not have a region
 	if ip.Type == config.ReservedIpType_RESERVED_IP_TYPE_GLOBAL && ip.Region != config.Region_REGION_UNSPECIFIED {
-		return fmt.Errorf("global reserved IP should not specify a region")
+		errs = append(errs, errorf(CodeReservedIPRegion, "global reserved IP should not specify a region"))
 	}
 
-	return nil
+	switch ip.AddressType {
+	case "", "EXTERNAL", "INTERNAL":
+	default:
+		errs = append(errs, errorf(CodeReservedIPAddress, "invalid address_type: %s (must be EXTERNAL or INTERNAL)", ip.AddressType))
+	}
+
+	if ip.Address != "" && net.ParseIP(ip.Address) == nil {
+		errs = append(errs, errorf(CodeReservedIPAddress, "invalid address: %s (must be an IPv4 or IPv6 address)", ip.Address))
+	}
+
+	return errors.Join(errs...)
 }
 
-// validateVPC validates a VPC configuration
+// validateVPC validates a VPC configuration. Overlapping subnet ranges are
+// reported by validateNetworkCIDRs, which compares the ranges of all VPCs.
 func validateVPC(vpc *config.Vpc) error {
+	var errs []error
+	if err := validateDescription(vpc.Description, maxDescriptionLength); err != nil {
+		errs = append(errs, err)
+	}
+
 	// Validate subnets
-	usedCIDRs := make(map[string]bool)
-	
 	for _, subnet := range vpc.Subnets {
 		if err := validateSubnet(subnet); err != nil {
-			return fmt.Errorf("invalid subnet %s: %w", subnet.Name, err)
+			errs = append(errs, wrapEach(err, "invalid subnet %s", subnet.Name))
 		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateNetworkCIDRs checks that no two subnet ranges overlap, within a VPC
+// or across VPCs, including secondary ranges. GCP allows overlapping ranges in separate
+// VPCs, but they can't be peered or connected through a VPN later.
+func validateNetworkCIDRs(vpcs []*config.Vpc) error {
+	type cidrRange struct {
+		cidr  string
+		owner string
+	}
 
-		// Check for CIDR overlaps
-		if usedCIDRs[subnet.Cidr] {
-			return fmt.Errorf("duplicate CIDR range %s in subnet %s", subnet.Cidr, subnet.Name)
+	var ranges []cidrRange
+	for _, vpc := range vpcs {
+		for _, subnet := range vpc.Subnets {
+			ranges = append(ranges, cidrRange{subnet.Cidr, fmt.Sprintf("subnet %s in VPC %s", subnet.Name, vpc.Name)})
+			for _, secondary := range subnet.SecondaryRanges {
+				ranges = append(ranges, cidrRange{secondary.IpCidrRange, fmt.Sprintf("secondary range %s of subnet %s in VPC %s", secondary.RangeName, subnet.Name, vpc.Name)})
+			}
 		}
-		usedCIDRs[subnet.Cidr] = true
+	}
 
-		// Validate CIDR overlaps (basic check)
-		for existingCIDR := range usedCIDRs {
-			if existingCIDR != subnet.Cidr && cidrsOverlap(subnet.Cidr, existingCIDR) {
-				return fmt.Errorf("CIDR range %s in subnet %s overlaps with existing range %s", subnet.Cidr, subnet.Name, existingCIDR)
+	var errs []error
+	for i, r := range ranges {
+		for _, other := range ranges[:i] {
+			if cidrsOverlap(r.cidr, other.cidr) {
+				errs = append(errs, errorf(CodeCIDROverlap, "CIDR range %s (%s) overlaps with %s (%s)", r.cidr, r.owner, other.cidr, other.owner))
 			}
 		}
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
 // validateSubnet validates a subnet configuration
 func validateSubnet(subnet *config.Subnet) error {
+	var errs []error
 	// Validate CIDR format
 	if !isValidCIDR(subnet.Cidr) {
-		return fmt.Errorf("invalid CIDR format: %s", subnet.Cidr)
+		errs = append(errs, errorf(CodeInvalidCIDR, "invalid CIDR format: %s", subnet.Cidr))
+	}
+
+	if err := validateDescription(subnet.Description, maxDescriptionLength); err != nil {
+		errs = append(errs, err)
 	}
 
 	// Validate secondary ranges
 	usedSecondaryRanges := make(map[string]bool)
 	for _, secondary := range subnet.SecondaryRanges {
 		if !isValidCIDR(secondary.IpCidrRange) {
-			return fmt.Errorf("invalid secondary CIDR format: %s", secondary.IpCidrRange)
+			errs = append(errs, errorf(CodeInvalidCIDR, "invalid secondary CIDR format: %s", secondary.IpCidrRange))
 		}
 
 		if usedSecondaryRanges[secondary.RangeName] {
-			return fmt.Errorf("duplicate secondary range name: %s", secondary.RangeName)
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate secondary range name: %s", secondary.RangeName))
 		}
 		usedSecondaryRanges[secondary.RangeName] = true
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
 // validateFirewallRule validates a firewall rule
 func validateFirewallRule(rule *config.FirewallRule) error {
+	var errs []error
+	if err := validateDescription(rule.Description, maxDescriptionLength); err != nil {
+		errs = append(errs, err)
+	}
+
 	// Validate direction-specific fields
 	if rule.Direction == "INGRESS" && len(rule.DestinationRanges) > 0 {
-		return fmt.Errorf("INGRESS rules cannot have destination_ranges")
+		errs = append(errs, errorf(CodeFirewallDirection, "INGRESS rules cannot have destination_ranges"))
 	}
-	
+
 	if rule.Direction == "EGRESS" && len(rule.SourceRanges) > 0 {
-		return fmt.Errorf("EGRESS rules cannot have source_ranges")
+		errs = append(errs, errorf(CodeFirewallDirection, "EGRESS rules cannot have source_ranges"))
 	}
 
 	if rule.Direction == "EGRESS" && len(rule.SourceTags) > 0 {
-		return fmt.Errorf("EGRESS rules cannot have source_tags")
+		errs = append(errs, errorf(CodeFirewallDirection, "EGRESS rules cannot have source_tags"))
 	}
 
 	// Validate that either allow or deny is specified, but not both
 	if len(rule.Allow) > 0 && len(rule.Deny) > 0 {
-		return fmt.Errorf("firewall rule cannot have both allow and deny blocks")
+		errs = append(errs, errorf(CodeFirewallAction, "firewall rule cannot have both allow and deny blocks"))
 	}
 
 	if len(rule.Allow) == 0 && len(rule.Deny) == 0 {
-		return fmt.Errorf("firewall rule must have either allow or deny block")
+		errs = append(errs, errorf(CodeFirewallAction, "firewall rule must have either allow or deny block"))
+	}
+
+	// Validate each entry's protocol and ports, then that protocols within
+	// allow/deny blocks don't overlap
+	var allowProtocols []string
+	for _, allow := range rule.Allow {
+		if err := validateFirewallEntry("allow", allow.Protocol, allow.Ports); err != nil {
+			errs = append(errs, err)
+		}
+		allowProtocols = append(allowProtocols, allow.Protocol)
+	}
+	if err := validateFirewallProtocols("allow", allowProtocols); err != nil {
+		errs = append(errs, err)
+	}
+
+	var denyProtocols []string
+	for _, deny := range rule.Deny {
+		if err := validateFirewallEntry("deny", deny.Protocol, deny.Ports); err != nil {
+			errs = append(errs, err)
+		}
+		denyProtocols = append(denyProtocols, deny.Protocol)
+	}
+	if err := validateFirewallProtocols("deny", denyProtocols); err != nil {
+		errs = append(errs, err)
 	}
 
 	// Validate IP ranges
 	for _, cidr := range rule.SourceRanges {
 		if !isValidCIDR(cidr) {
-			return fmt.Errorf("invalid source range CIDR: %s", cidr)
+			errs = append(errs, errorf(CodeInvalidCIDR, "invalid source range CIDR: %s", cidr))
 		}
 	}
 
 	for _, cidr := range rule.DestinationRanges {
 		if !isValidCIDR(cidr) {
-			return fmt.Errorf("invalid destination range CIDR: %s", cidr)
+			errs = append(errs, errorf(CodeInvalidCIDR, "invalid destination range CIDR: %s", cidr))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// firewallProtocols are the protocols firewall rules accept by name, mapped to
+// whether entries for them may list ports
+var firewallProtocols = map[string]bool{
+	"tcp":  true,
+	"udp":  true,
+	"sctp": true,
+	"icmp": false,
+	"esp":  false,
+	"ah":   false,
+	"ipip": false,
+	"all":  false,
+}
+
+// firewallPortProtocolNumbers are the IP protocol numbers of tcp, udp, and
+// sctp, which may be given instead of their names
+var firewallPortProtocolNumbers = map[int]bool{6: true, 17: true, 132: true}
+
+// validateFirewallEntry checks the protocol and ports of an allow or deny
+// entry. The protocol is a name or an IP protocol number (0-255), and ports,
+// which only tcp, udp, and sctp take, are single ports or start-end ranges.
+func validateFirewallEntry(block, protocol string, ports []string) error {
+	protocol = strings.ToLower(protocol)
+	takesPorts, known := firewallProtocols[protocol]
+	if !known {
+		n, err := strconv.Atoi(protocol)
+		if err != nil || n < 0 || n > 255 {
+			return errorf(CodeFirewallPorts, "invalid protocol %q in %s block (must be tcp, udp, icmp, esp, ah, sctp, ipip, all, or a protocol number from 0 to 255)", protocol, block)
+		}
+		takesPorts = firewallPortProtocolNumbers[n]
+	}
+
+	if len(ports) > 0 && !takesPorts {
+		return errorf(CodeFirewallPorts, "protocol %s in %s block cannot have ports (only tcp, udp, and sctp can)", protocol, block)
+	}
+	for _, port := range ports {
+		if !isValidFirewallPort(port) {
+			return errorf(CodeFirewallPorts, "invalid port %q for protocol %s in %s block (must be a port from 0 to 65535 or a start-end range)", port, protocol, block)
+		}
+	}
+	return nil
+}
+
+// isValidFirewallPort reports whether port is a single port or a start-end
+// range of ports with start no greater than end
+func isValidFirewallPort(port string) bool {
+	start, end, isRange := strings.Cut(port, "-")
+	first, ok := parsePortNumber(start)
+	if !ok {
+		return false
+	}
+	if !isRange {
+		return true
+	}
+	last, ok := parsePortNumber(end)
+	return ok && first <= last
+}
+
+// parsePortNumber parses a port number from 0 to 65535, written in decimal
+// digits only
+func parsePortNumber(s string) (int, bool) {
+	if s == "" || strings.TrimLeft(s, "0123456789") != "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n > 65535 {
+		return 0, false
+	}
+	return n, true
+}
+
+// validateFirewallProtocols checks that the protocols of a rule's allow or deny
+// entries are not redundant: "all" cannot be combined with specific protocols,
+// and the same protocol cannot appear more than once
+func validateFirewallProtocols(block string, protocols []string) error {
+	seen := make(map[string]bool)
+	for _, protocol := range protocols {
+		protocol = strings.ToLower(protocol)
+		if seen[protocol] {
+			return errorf(CodeFirewallProtocol, "protocol %s appears more than once in %s block", protocol, block)
 		}
+		seen[protocol] = true
+	}
+
+	if seen["all"] && len(seen) > 1 {
+		return errorf(CodeFirewallProtocol, "protocol all cannot be combined with specific protocols in %s block", block)
 	}
 
 	return nil
@@ -236,12 +856,12 @@ func validateNATGateway(nat *config.NatGateway) error {
 	}
 
 	if !validOptions[nat.NatIpAllocateOption] {
-		return fmt.Errorf("invalid NAT IP allocate option: %s", nat.NatIpAllocateOption)
+		return errorf(CodeInvalidValue, "invalid NAT IP allocate option: %s", nat.NatIpAllocateOption)
 	}
 
 	// If MANUAL_ONLY, must have NAT IPs specified
 	if nat.NatIpAllocateOption == "MANUAL_ONLY" && len(nat.NatIps) == 0 {
-		return fmt.Errorf("MANUAL_ONLY NAT IP allocation requires nat_ips to be specified")
+		return errorf(CodeNATManualIPs, "MANUAL_ONLY NAT IP allocation requires nat_ips to be specified")
 	}
 
 	return nil
@@ -249,245 +869,3365 @@ func validateNATGateway(nat *config.NatGateway) error {
 
 // validateCompute validates compute configuration
 func validateCompute(compute *config.Compute) error {
+	var errs []error
 	// Validate instance templates
 	templateNames := make(map[string]bool)
 	for _, template := range compute.InstanceTemplates {
 		if templateNames[template.Name] {
-			return fmt.Errorf("duplicate instance template name: %s", template.Name)
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate instance template name: %s", template.Name))
+			continue
 		}
 		templateNames[template.Name] = true
 
 		if err := validateInstanceTemplate(template); err != nil {
-			return fmt.Errorf("invalid instance template %s: %w", template.Name, err)
+			errs = append(errs, wrapEach(err, "invalid instance template %s", template.Name))
 		}
 	}
 
 	// Validate instance groups
 	for _, group := range compute.InstanceGroups {
 		if err := validateInstanceGroup(group); err != nil {
-			return fmt.Errorf("invalid instance group %s: %w", group.Name, err)
+			errs = append(errs, wrapEach(err, "invalid instance group %s", group.Name))
+			continue
 		}
 
-		// Check that referenced template exists
-		if !templateNames[group.Template] {
-			return fmt.Errorf("instance group %s references unknown template: %s", group.Name, group.Template)
+		// Check that referenced templates exist
+		if len(group.Versions) == 0 && !templateNames[group.Template] {
+			errs = append(errs, errorf(CodeUnknownReference, "instance group %s references unknown template: %s", group.Name, group.Template))
+			continue
+		}
+		for _, version := range group.Versions {
+			if !templateNames[version.Template] {
+				errs = append(errs, errorf(CodeUnknownReference, "instance group %s version %s references unknown template: %s", group.Name, versionName(version), version.Template))
+			}
 		}
 	}
 
-	return nil
-}
+	// Validate sole-tenant node templates and groups
+	nodeTemplates := make(map[string]*config.NodeTemplate)
+	affinityLabels := make(map[string]bool)
+	for _, template := range compute.NodeTemplates {
+		if nodeTemplates[template.Name] != nil {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate node template name: %s", template.Name))
+			continue
+		}
+		nodeTemplates[template.Name] = template
 
-// validateInstanceTemplate validates an instance template
-func validateInstanceTemplate(template *config.InstanceTemplate) error {
-	// Validate disk size
-	if template.DiskSizeGb < 10 {
-		return fmt.Errorf("disk size must be at least 10 GB")
+		if err := validateNodeTemplate(template); err != nil {
+			errs = append(errs, wrapEach(err, "invalid node template %s", template.Name))
+			continue
+		}
+		for key := range template.NodeAffinityLabels {
+			affinityLabels[key] = true
+		}
 	}
 
-	// Validate network interfaces
-	for _, iface := range template.NetworkInterfaces {
-		if iface.Network == "" && iface.Subnetwork == "" {
-			return fmt.Errorf("network interface must specify either network or subnetwork")
+	nodeGroups := make(map[string]*config.NodeGroup)
+	for _, group := range compute.NodeGroups {
+		if nodeGroups[group.Name] != nil {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate node group name: %s", group.Name))
+			continue
+		}
+		nodeGroups[group.Name] = group
+
+		template, ok := nodeTemplates[group.NodeTemplate]
+		if !ok {
+			errs = append(errs, errorf(CodeUnknownReference, "node group %s references unknown node template: %s", group.Name, group.NodeTemplate))
+			continue
+		}
+		if group.Zone == config.Zone_ZONE_UNSPECIFIED {
+			errs = append(errs, errorf(CodeRequiredField, "node group %s must specify a zone", group.Name))
+			continue
+		}
+		if zoneRegion(group.Zone) != effectiveRegion(template.Region) {
+			errs = append(errs, errorf(CodeSoleTenancy, "node group %s zone %s is not in node template %s region %s", group.Name, group.Zone, template.Name, effectiveRegion(template.Region)))
+			continue
+		}
+		if group.InitialSize < 0 {
+			errs = append(errs, errorf(CodeInvalidValue, "node group %s initial_size cannot be negative", group.Name))
 		}
 	}
 
-	return nil
-}
+	// Validate node affinities of instance templates
+	for _, template := range compute.InstanceTemplates {
+		for _, affinity := range template.NodeAffinities {
+			if err := validateNodeAffinity(affinity, template.MachineType, nodeGroups, nodeTemplates, affinityLabels); err != nil {
+				errs = append(errs, fmt.Errorf("instance template %s: %w", template.Name, err))
+			}
+		}
+	}
 
-// validateInstanceGroup validates an instance group
-func validateInstanceGroup(group *config.InstanceGroup) error {
-	// Validate auto scaling configuration
-	if group.AutoScaling != nil {
-		if group.AutoScaling.Min > group.AutoScaling.Max {
-			return fmt.Errorf("auto scaling min (%d) cannot be greater than max (%d)", group.AutoScaling.Min, group.AutoScaling.Max)
+	// Validate individual instances
+	for _, instance := range compute.Instances {
+		if err := validateInstance(instance); err != nil {
+			errs = append(errs, wrapEach(err, "invalid instance %s", instance.Name))
+			continue
 		}
 
-		if group.AutoScaling.CpuTarget <= 0 || group.AutoScaling.CpuTarget > 1 {
-			return fmt.Errorf("CPU target must be between 0 and 1, got %f", group.AutoScaling.CpuTarget)
+		for _, affinity := range instance.NodeAffinities {
+			if err := validateNodeAffinity(affinity, instance.MachineType, nodeGroups, nodeTemplates, affinityLabels); err != nil {
+				errs = append(errs, fmt.Errorf("instance %s: %w", instance.Name, err))
+				continue
+			}
+
+			// Instances can only be placed on node groups in their own zone
+			if affinity.Key == nodeGroupAffinityKey && affinity.Operator != "NOT_IN" && instance.Zone != config.Zone_ZONE_UNSPECIFIED {
+				for _, name := range affinity.Values {
+					if nodeGroups[name].Zone != instance.Zone {
+						errs = append(errs, errorf(CodeSoleTenancy, "instance %s in zone %s cannot be placed on node group %s in zone %s", instance.Name, instance.Zone, name, nodeGroups[name].Zone))
+					}
+				}
+			}
 		}
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
-// validateLoadBalancers validates load balancer configurations
-func validateLoadBalancers(lbs []*config.LoadBalancer) error {
-	for _, lb := range lbs {
-		if err := validateLoadBalancer(lb); err != nil {
-			return fmt.Errorf("invalid load balancer %s: %w", lb.Name, err)
-		}
+// nodeGroupAffinityKey is the built-in node affinity key selecting sole-tenant node groups by name
+const nodeGroupAffinityKey = "compute.googleapis.com/node-group-name"
+
+// validateNodeTemplate validates a sole-tenant node template
+func validateNodeTemplate(template *config.NodeTemplate) error {
+	var errs []error
+	if machineTypeFamily(template.NodeType) == "" || !strings.Contains(template.NodeType, "-node-") {
+		errs = append(errs, errorf(CodeInvalidValue, "invalid node type: %s (e.g. n1-node-96-624)", template.NodeType))
 	}
-	return nil
+
+	if err := validateDescription(template.Description, maxDescriptionLength); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
 }
 
-// validateLoadBalancer validates a single load balancer
-func validateLoadBalancer(lb *config.LoadBalancer) error {
-	// Validate health check if present
-	if lb.HealthCheck != nil {
-		if err := validateHealthCheck(lb.HealthCheck); err != nil {
-			return fmt.Errorf("invalid health check: %w", err)
+// validateNodeAffinity validates a node affinity and checks that the machine type
+// of the affected instances can run on the targeted sole-tenant nodes
+func validateNodeAffinity(affinity *config.NodeAffinity, machineType config.MachineType, nodeGroups map[string]*config.NodeGroup, nodeTemplates map[string]*config.NodeTemplate, affinityLabels map[string]bool) error {
+	if affinity.Operator != "" && affinity.Operator != "IN" && affinity.Operator != "NOT_IN" {
+		return errorf(CodeInvalidValue, "invalid node affinity operator: %s (must be IN or NOT_IN)", affinity.Operator)
+	}
+	if len(affinity.Values) == 0 {
+		return errorf(CodeRequiredField, "node affinity %s must specify at least one value", affinity.Key)
+	}
+
+	// Sole-tenant nodes do not support E2 machine types
+	family := instanceMachineFamily(machineType)
+	if family == "e2" {
+		return errorf(CodeSoleTenancy, "machine type %s is not supported on sole-tenant nodes", machineType)
+	}
+
+	switch {
+	case affinity.Key == nodeGroupAffinityKey:
+		for _, name := range affinity.Values {
+			group, ok := nodeGroups[name]
+			if !ok {
+				return errorf(CodeUnknownReference, "node affinity references unknown node group: %s", name)
+			}
+
+			nodeType := nodeTemplates[group.NodeTemplate].NodeType
+			if affinity.Operator != "NOT_IN" && machineTypeFamily(nodeType) != family {
+				return errorf(CodeSoleTenancy, "machine type %s is not compatible with node group %s (node type %s)", machineType, name, nodeType)
+			}
 		}
+	case strings.HasPrefix(affinity.Key, "compute.googleapis.com/"):
+		// Other built-in keys (e.g. node-name) are resolved by GCP
+	case !affinityLabels[affinity.Key]:
+		return errorf(CodeSoleTenancy, "node affinity key %s is not a node affinity label of any declared node template", affinity.Key)
 	}
 
 	return nil
 }
 
-// validateHealthCheck validates a health check configuration
-func validateHealthCheck(hc *config.HealthCheck) error {
-	// Validate port range
-	if hc.Port <= 0 || hc.Port > 65535 {
-		return fmt.Errorf("invalid port: %d", hc.Port)
+// instanceMachineFamily returns the machine family (e.g. "n2") of a machine type enum,
+// treating an unspecified machine type as the e2-medium default
+func instanceMachineFamily(machineType config.MachineType) string {
+	if machineType == config.MachineType_MACHINE_TYPE_UNSPECIFIED {
+		return "e2"
 	}
+	name := strings.TrimPrefix(machineType.String(), "MACHINE_TYPE_")
+	return strings.ToLower(strings.SplitN(name, "_", 2)[0])
+}
 
-	// Validate timeouts
-	if hc.TimeoutSec >= hc.CheckIntervalSec {
-		return fmt.Errorf("timeout_sec (%d) must be less than check_interval_sec (%d)", hc.TimeoutSec, hc.CheckIntervalSec)
+// machineTypeFamily returns the machine family prefix of a machine or node type
+// name (e.g. "n1" for "n1-node-96-624")
+func machineTypeFamily(machineType string) string {
+	family, _, found := strings.Cut(machineType, "-")
+	if !found {
+		return ""
 	}
+	return family
+}
 
-	return nil
+// zoneRegion returns the region containing zone (e.g. REGION_US_CENTRAL1 for ZONE_US_CENTRAL1_A)
+func zoneRegion(zone config.Zone) config.Region {
+	name := strings.TrimPrefix(zone.String(), "ZONE_")
+	if i := strings.LastIndex(name, "_"); i >= 0 {
+		name = name[:i]
+	}
+	return config.Region(config.Region_value["REGION_"+name])
 }
 
-// validateIAM validates IAM configuration
-func validateIAM(iam *config.Iam) error {
+// validGuestOSFeatures are the guest OS features Compute Engine accepts on disks
+var validGuestOSFeatures = map[string]bool{
+	"GVNIC":                     true,
+	"IDPF":                      true,
+	"MULTI_IP_SUBNET":           true,
+	"SECURE_BOOT":               true,
+	"SEV_CAPABLE":               true,
+	"SEV_LIVE_MIGRATABLE":       true,
+	"SEV_LIVE_MIGRATABLE_V2":    true,
+	"SEV_SNP_CAPABLE":           true,
+	"SUSPEND_RESUME_COMPATIBLE": true,
+	"TDX_CAPABLE":               true,
+	"UEFI_COMPATIBLE":           true,
+	"VIRTIO_SCSI_MULTIQUEUE":    true,
+	"WINDOWS":                   true,
+}
+
+// validateInstanceTemplate validates an instance template
+func validateInstanceTemplate(template *config.InstanceTemplate) error {
+	var errs []error
+	// Validate disk size
+	if template.DiskSizeGb < 10 {
+		errs = append(errs, errorf(CodeDiskTooSmall, "disk size must be at least 10 GB"))
+	}
+
+	if err := validateDescription(template.Description, maxDescriptionLength); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Validate network interfaces
+	for _, iface := range template.NetworkInterfaces {
+		if iface.Network == "" && iface.Subnetwork == "" {
+			errs = append(errs, errorf(CodeNetworkInterfaceMissing, "network interface must specify either network or subnetwork"))
+		}
+		if err := validateNicType(iface.NicType); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	seenFeatures := make(map[string]bool)
+	for _, feature := range template.GuestOsFeatures {
+		if !validGuestOSFeatures[feature] {
+			errs = append(errs, errorf(CodeInvalidValue, "invalid guest OS feature: %s (e.g. UEFI_COMPATIBLE, GVNIC, SECURE_BOOT)", feature))
+			continue
+		}
+		if seenFeatures[feature] {
+			errs = append(errs, errorf(CodeInvalidValue, "guest OS feature %s is listed more than once", feature))
+		}
+		seenFeatures[feature] = true
+	}
+
+	if template.ReservationAffinity != nil {
+		if err := validateReservationAffinity(template.ReservationAffinity); err != nil {
+			errs = append(errs, fmt.Errorf("invalid reservation affinity: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateReservationAffinity validates that only SPECIFIC_RESERVATION affinities name reservations
+func validateReservationAffinity(affinity *config.ReservationAffinity) error {
+	switch affinity.Type {
+	case "SPECIFIC_RESERVATION":
+		if affinity.Key == "" || len(affinity.Values) == 0 {
+			return errorf(CodeReservationAffinity, "SPECIFIC_RESERVATION requires key and values")
+		}
+	case "ANY_RESERVATION", "NO_RESERVATION":
+		if affinity.Key != "" || len(affinity.Values) > 0 {
+			return errorf(CodeReservationAffinity, "%s must not specify key or values", affinity.Type)
+		}
+	default:
+		return errorf(CodeInvalidValue, "invalid type: %s (must be ANY_RESERVATION, SPECIFIC_RESERVATION, or NO_RESERVATION)", affinity.Type)
+	}
+
+	return nil
+}
+
+// validateInstanceGroup validates an instance group
+func validateInstanceGroup(group *config.InstanceGroup) error {
+	var errs []error
+	if err := validateDescription(group.Description, maxDescriptionLength); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Validate auto scaling configuration
+	if group.AutoScaling != nil {
+		if group.AutoScaling.Min < 0 {
+			errs = append(errs, errorf(CodeAutoscalingBounds, "auto scaling min cannot be negative, got %d", group.AutoScaling.Min))
+		} else if group.AutoScaling.Min > group.AutoScaling.Max {
+			errs = append(errs, errorf(CodeAutoscalingBounds, "auto scaling min (%d) cannot be greater than max (%d)", group.AutoScaling.Min, group.AutoScaling.Max))
+		}
+
+		if group.AutoScaling.CpuTarget <= 0 || group.AutoScaling.CpuTarget > 1 {
+			errs = append(errs, errorf(CodeAutoscalingBounds, "CPU target must be between 0 and 1, got %f", group.AutoScaling.CpuTarget))
+		}
+
+		if group.AutoScaling.CooldownPeriod < 0 {
+			errs = append(errs, errorf(CodeAutoscalingBounds, "auto scaling cooldown_period cannot be negative, got %d", group.AutoScaling.CooldownPeriod))
+		}
+	}
+
+	if len(group.Versions) > 0 {
+		if err := validateInstanceGroupVersions(group); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := validateNamedPorts(group.NamedPorts); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateNamedPorts validates the named ports of an instance group: each has
+// a unique, RFC 1035 name and a port between 1 and 65535. Several names may
+// share a port.
+func validateNamedPorts(ports []*config.NamedPort) error {
+	names := make(map[string]bool)
+	var errs []error
+	for _, port := range ports {
+		if !namedPortNamePattern.MatchString(port.Name) {
+			errs = append(errs, errorf(CodeNamedPort, "invalid named port name: %q (must be 1-63 lowercase letters, digits, and hyphens, starting with a letter)", port.Name))
+		} else if names[port.Name] {
+			errs = append(errs, errorf(CodeNamedPort, "duplicate named port: %s", port.Name))
+		}
+		names[port.Name] = true
+
+		if port.Port < 1 || port.Port > 65535 {
+			errs = append(errs, errorf(CodeNamedPort, "named port %s: port must be between 1 and 65535, got %d", port.Name, port.Port))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateInstanceGroupVersions validates the instance template versions of a
+// managed instance group. Exactly one version must leave its target size
+// unset; it runs on the instances the other versions don't claim.
+func validateInstanceGroupVersions(group *config.InstanceGroup) error {
+	if group.Template != "" {
+		return errorf(CodeInstanceGroupVersions, "template and versions are mutually exclusive; name the template in a version instead")
+	}
+
+	names := make(map[string]bool)
+	var unsized []string
+	var percent, fixed int32
+	for _, version := range group.Versions {
+		if version.Name != "" {
+			if names[version.Name] {
+				return errorf(CodeDuplicateName, "duplicate version name: %s", version.Name)
+			}
+			names[version.Name] = true
+		}
+		if version.Template == "" {
+			return errorf(CodeRequiredField, "version %s must specify a template", versionName(version))
+		}
+
+		switch {
+		case version.TargetSizeFixed != 0 && version.TargetSizePercent != 0:
+			return errorf(CodeInstanceGroupVersions, "version %s sets both target_size_fixed and target_size_percent", versionName(version))
+		case version.TargetSizeFixed < 0:
+			return errorf(CodeInstanceGroupVersions, "version %s target_size_fixed cannot be negative", versionName(version))
+		case version.TargetSizePercent < 0 || version.TargetSizePercent > 100:
+			return errorf(CodeInstanceGroupVersions, "version %s target_size_percent must be between 1 and 100, got %d", versionName(version), version.TargetSizePercent)
+		case version.TargetSizeFixed == 0 && version.TargetSizePercent == 0:
+			unsized = append(unsized, versionName(version))
+		}
+		fixed += version.TargetSizeFixed
+		percent += version.TargetSizePercent
+	}
+
+	if len(unsized) != 1 {
+		return errorf(CodeInstanceGroupVersions, "exactly one version must leave its target size unset to run on the remaining instances, got %d", len(unsized))
+	}
+	if percent > 100 {
+		return errorf(CodeInstanceGroupVersions, "version target_size_percent values add up to %d%%, more than 100%%", percent)
+	}
+	if group.AutoScaling == nil && group.Size > 0 && fixed > group.Size {
+		return errorf(CodeInstanceGroupVersions, "version target_size_fixed values add up to %d, more than the group size %d", fixed, group.Size)
+	}
+
+	return nil
+}
+
+// versionName returns the name of an instance group version for messages,
+// falling back to its template
+func versionName(version *config.InstanceGroupVersion) string {
+	if version.Name != "" {
+		return version.Name
+	}
+	return version.Template
+}
+
+// validateInstance validates an individual instance
+func validateInstance(instance *config.Instance) error {
+	var errs []error
+	if err := validateDescription(instance.Description, maxDescriptionLength); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, iface := range instance.NetworkInterfaces {
+		if err := validateNicType(iface.NicType); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateNicType validates a network interface NIC type; empty uses the image default
+func validateNicType(nicType string) error {
+	switch nicType {
+	case "", "GVNIC", "VIRTIO_NET":
+		return nil
+	default:
+		return errorf(CodeInvalidValue, "invalid NIC type: %s (must be GVNIC or VIRTIO_NET)", nicType)
+	}
+}
+
+// validateLoadBalancers validates load balancer configurations
+func validateLoadBalancers(lbs []*config.LoadBalancer) error {
+	var errs []error
+	for _, lb := range lbs {
+		if err := validateLoadBalancer(lb); err != nil {
+			errs = append(errs, fmt.Errorf("invalid load balancer %s: %w", lb.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateLoadBalancer validates a single load balancer
+func validateLoadBalancer(lb *config.LoadBalancer) error {
+	if err := validateDescription(lb.Description, maxDescriptionLength); err != nil {
+		return err
+	}
+
+	// Validate health check if present
+	if lb.HealthCheck != nil {
+		if err := validateHealthCheck(lb.HealthCheck); err != nil {
+			return fmt.Errorf("invalid health check: %w", err)
+		}
+	}
+
+	if err := validateLoadBalancerSsl(lb); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// maxManagedSslDomains is the number of domains a managed SSL certificate can have
+const maxManagedSslDomains = 100
+
+// validateLoadBalancerSsl validates the SSL certificate of an HTTPS load balancer
+func validateLoadBalancerSsl(lb *config.LoadBalancer) error {
+	if !lb.SslEnabled {
+		if len(lb.ManagedDomains) > 0 || lb.SslCertificate != "" {
+			return errorf(CodeLoadBalancerSsl, "managed_domains and ssl_certificate require ssl_enabled")
+		}
+		return nil
+	}
+
+	if (len(lb.ManagedDomains) > 0) == (lb.SslCertificate != "") {
+		return errorf(CodeLoadBalancerSsl, "ssl_enabled requires exactly one of managed_domains or ssl_certificate")
+	}
+	if len(lb.ManagedDomains) > maxManagedSslDomains {
+		return errorf(CodeLoadBalancerSsl, "a managed SSL certificate supports at most %d domains, got %d", maxManagedSslDomains, len(lb.ManagedDomains))
+	}
+
+	domains := make(map[string]bool)
+	for _, domain := range lb.ManagedDomains {
+		// The trailing dot is optional, and the pattern rejects wildcards,
+		// which managed certificates don't support
+		name := strings.TrimSuffix(domain, ".")
+		if !dnsNamePattern.MatchString(name + ".") {
+			return errorf(CodeLoadBalancerSsl, "invalid managed domain: %q (must be a domain name such as \"www.example.com\")", domain)
+		}
+		lowerName := strings.ToLower(name)
+		if domains[lowerName] {
+			return errorf(CodeLoadBalancerSsl, "duplicate managed domain: %s", domain)
+		}
+		domains[lowerName] = true
+	}
+
+	return nil
+}
+
+// validateHealthCheck validates a health check configuration
+func validateHealthCheck(hc *config.HealthCheck) error {
+	// Validate port range
+	if hc.Port <= 0 || hc.Port > 65535 {
+		return errorf(CodeHealthCheck, "invalid port: %d", hc.Port)
+	}
+
+	if err := validateDescription(hc.Description, maxDescriptionLength); err != nil {
+		return err
+	}
+
+	// Validate timeouts
+	if hc.TimeoutSec >= hc.CheckIntervalSec {
+		return errorf(CodeHealthCheck, "timeout_sec (%d) must be less than check_interval_sec (%d)", hc.TimeoutSec, hc.CheckIntervalSec)
+	}
+
+	// Validate the fields of the health check type, which defaults to HTTP
+	switch hc.Type {
+	case "", "HTTP", "HTTPS":
+		if hc.RequestPath != "" && !strings.HasPrefix(hc.RequestPath, "/") {
+			return errorf(CodeHealthCheck, "request_path must start with /, got %q", hc.RequestPath)
+		}
+		if hc.ProxyHeader != "" {
+			return errorf(CodeHealthCheck, "proxy_header is only supported for TCP health checks")
+		}
+	case "TCP":
+		if hc.RequestPath != "" || hc.Host != "" {
+			return errorf(CodeHealthCheck, "request_path and host are only supported for HTTP and HTTPS health checks")
+		}
+		if hc.ProxyHeader != "" && hc.ProxyHeader != "NONE" && hc.ProxyHeader != "PROXY_V1" {
+			return errorf(CodeHealthCheck, "proxy_header must be NONE or PROXY_V1, got %q", hc.ProxyHeader)
+		}
+	default:
+		return errorf(CodeHealthCheck, "type must be HTTP, HTTPS, or TCP, got %q", hc.Type)
+	}
+
+	return nil
+}
+
+// validateIAM validates IAM configuration
+func validateIAM(iam *config.Iam) error {
+	var errs []error
 	// Validate service accounts
 	accountIds := make(map[string]bool)
 	for _, sa := range iam.ServiceAccounts {
 		if accountIds[sa.AccountId] {
-			return fmt.Errorf("duplicate service account ID: %s", sa.AccountId)
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate service account ID: %s", sa.AccountId))
+			continue
+		}
+		accountIds[sa.AccountId] = true
+
+		if err := validateServiceAccount(sa); err != nil {
+			errs = append(errs, wrapEach(err, "invalid service account %s", sa.AccountId))
+		}
+	}
+
+	// Validate custom roles
+	roleIds := make(map[string]bool)
+	for _, role := range iam.CustomRoles {
+		if roleIds[role.RoleId] {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate custom role ID: %s", role.RoleId))
+			continue
+		}
+		roleIds[role.RoleId] = true
+
+		if err := validateCustomRole(role); err != nil {
+			errs = append(errs, wrapEach(err, "invalid custom role %s", role.RoleId))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateServiceAccount validates a service account configuration
+func validateServiceAccount(sa *config.ServiceAccount) error {
+	var errs []error
+	// Validate account ID format
+	if !isValidServiceAccountId(sa.AccountId) {
+		errs = append(errs, errorf(CodeInvalidServiceAccountID, "invalid service account ID format: %s", sa.AccountId))
+	}
+
+	if err := validateDescription(sa.Description, maxIAMDescriptionLength); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateCustomRole validates a custom role configuration
+func validateCustomRole(role *config.CustomRole) error {
+	var errs []error
+	// Validate that permissions are not empty
+	if len(role.Permissions) == 0 {
+		errs = append(errs, errorf(CodeCustomRolePermissions, "custom role must have at least one permission"))
+	}
+
+	if err := validateDescription(role.Description, maxIAMDescriptionLength); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Validate stage values
+	validStages := map[string]bool{
+		"ALPHA":      true,
+		"BETA":       true,
+		"GA":         true,
+		"DEPRECATED": true,
+	}
+
+	if role.Stage != "" && !validStages[role.Stage] {
+		errs = append(errs, errorf(CodeInvalidValue, "invalid stage: %s", role.Stage))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateStorage validates storage configuration
+func validateStorage(storage *config.Storage) error {
+	var errs []error
+	bucketNames := make(map[string]bool)
+
+	for _, bucket := range storage.Buckets {
+		if bucketNames[bucket.Name] {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate bucket name: %s", bucket.Name))
+			continue
+		}
+		bucketNames[bucket.Name] = true
+
+		if err := validateStorageBucket(bucket); err != nil {
+			errs = append(errs, wrapEach(err, "invalid storage bucket %s", bucket.Name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateStorageBucket validates a storage bucket configuration
+func validateStorageBucket(bucket *config.StorageBucket) error {
+	var errs []error
+	// Validate bucket name format (GCS-specific rules)
+	if !isValidBucketName(bucket.Name) {
+		errs = append(errs, errorf(CodeInvalidBucketName, "invalid bucket name format: %s", bucket.Name))
+	}
+
+	// Validate storage class
+	if bucket.StorageClass != "" && !storageClasses[bucket.StorageClass] {
+		errs = append(errs, errorf(CodeInvalidValue, "invalid storage class: %s", bucket.StorageClass))
+	}
+
+	if err := validateLifecycleRules(bucket.LifecycleRules); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// storageClasses are the storage classes a bucket or lifecycle rule can set
+var storageClasses = map[string]bool{
+	"STANDARD": true,
+	"NEARLINE": true,
+	"COLDLINE": true,
+	"ARCHIVE":  true,
+}
+
+// legacyStorageClasses are storage classes existing objects can still have,
+// which lifecycle conditions can match but nothing can set
+var legacyStorageClasses = map[string]bool{
+	"MULTI_REGIONAL":               true,
+	"REGIONAL":                     true,
+	"DURABLE_REDUCED_AVAILABILITY": true,
+}
+
+// validateLifecycleRules validates the lifecycle rules of a bucket, each on
+// its own and against each other: objects are transitioned to another storage
+// class before they are deleted, so delete ages must exceed transition ages
+func validateLifecycleRules(rules []*config.LifecycleRule) error {
+	maxTransitionAge, maxTransitionRule := int32(0), -1
+	for i, rule := range rules {
+		action, condition := rule.GetAction(), rule.GetCondition()
+		switch action.GetType() {
+		case "Delete", "AbortIncompleteMultipartUpload":
+			if action.GetStorageClass() != "" {
+				return errorf(CodeLifecycleRule, "lifecycle rule %d: storage_class is only supported for SetStorageClass actions", i)
+			}
+		case "SetStorageClass":
+			if action.GetStorageClass() == "" {
+				return errorf(CodeLifecycleRule, "lifecycle rule %d: SetStorageClass action must specify a storage_class", i)
+			}
+			if !storageClasses[action.GetStorageClass()] {
+				return errorf(CodeLifecycleRule, "lifecycle rule %d: invalid target storage class: %s", i, action.GetStorageClass())
+			}
+		default:
+			return errorf(CodeLifecycleRule, "lifecycle rule %d: invalid action type %q (must be Delete, SetStorageClass, or AbortIncompleteMultipartUpload)", i, action.GetType())
+		}
+
+		if condition.GetAge() < 0 {
+			return errorf(CodeLifecycleRule, "lifecycle rule %d: age must not be negative, got %d", i, condition.GetAge())
+		}
+		if condition.GetCreatedBefore() != "" {
+			if _, err := time.Parse("2006-01-02", condition.GetCreatedBefore()); err != nil {
+				return errorf(CodeLifecycleRule, "lifecycle rule %d: created_before must be a date in the form YYYY-MM-DD, got %q", i, condition.GetCreatedBefore())
+			}
+		}
+		for _, class := range condition.GetMatchesStorageClass() {
+			if !storageClasses[class] && !legacyStorageClasses[class] {
+				return errorf(CodeLifecycleRule, "lifecycle rule %d: invalid matches_storage_class: %s", i, class)
+			}
+		}
+
+		if action.GetType() == "SetStorageClass" && condition.GetAge() > maxTransitionAge {
+			maxTransitionAge, maxTransitionRule = condition.GetAge(), i
+		}
+	}
+
+	for i, rule := range rules {
+		if rule.GetAction().GetType() != "Delete" {
+			continue
+		}
+		if age := rule.GetCondition().GetAge(); age > 0 && age <= maxTransitionAge {
+			return errorf(CodeLifecycleRule, "lifecycle rule %d deletes objects after %d days, before lifecycle rule %d transitions them after %d days", i, age, maxTransitionRule, maxTransitionAge)
+		}
+	}
+
+	return nil
+}
+
+// validateKMS validates Cloud KMS configuration
+func validateKMS(kms *config.Kms) error {
+	var errs []error
+	keyRingNames := make(map[string]bool)
+	cryptoKeyNames := make(map[string]bool)
+	for _, keyRing := range kms.KeyRings {
+		if keyRingNames[keyRing.Name] {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate key ring name: %s", keyRing.Name))
+			continue
+		}
+		keyRingNames[keyRing.Name] = true
+
+		if keyRing.Location == "" {
+			errs = append(errs, errorf(CodeRequiredField, "key ring %s must specify a location", keyRing.Name))
+			continue
+		}
+
+		for _, key := range keyRing.CryptoKeys {
+			// Keys are referenced by name for CMEK, so names must be unique across key rings
+			if cryptoKeyNames[key.Name] {
+				errs = append(errs, errorf(CodeDuplicateName, "duplicate crypto key name: %s", key.Name))
+				continue
+			}
+			cryptoKeyNames[key.Name] = true
+
+			if err := validateCryptoKey(key); err != nil {
+				errs = append(errs, fmt.Errorf("invalid crypto key %s in key ring %s: %w", key.Name, keyRing.Name, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateCryptoKey validates a KMS crypto key configuration
+func validateCryptoKey(key *config.KmsCryptoKey) error {
+	validPurposes := map[string]bool{
+		"ENCRYPT_DECRYPT":    true,
+		"ASYMMETRIC_SIGN":    true,
+		"ASYMMETRIC_DECRYPT": true,
+		"MAC":                true,
+	}
+
+	if key.Purpose != "" && !validPurposes[key.Purpose] {
+		return errorf(CodeInvalidValue, "invalid purpose: %s", key.Purpose)
+	}
+
+	symmetric := key.Purpose == "" || key.Purpose == "ENCRYPT_DECRYPT"
+
+	validProtectionLevels := map[string]bool{
+		"SOFTWARE": true,
+		"HSM":      true,
+	}
+
+	if key.ProtectionLevel != "" && !validProtectionLevels[key.ProtectionLevel] {
+		return errorf(CodeInvalidValue, "invalid protection level: %s", key.ProtectionLevel)
+	}
+
+	if key.ProtectionLevel != "" && !symmetric && key.Algorithm == "" {
+		return errorf(CodeCryptoKeySettings, "algorithm is required for %s keys", key.Purpose)
+	}
+
+	if key.RotationPeriod != "" {
+		// Automatic rotation is only supported for symmetric encryption keys
+		if !symmetric {
+			return errorf(CodeCryptoKeySettings, "rotation_period is only supported for ENCRYPT_DECRYPT keys")
+		}
+
+		period, err := config.ParseDuration(key.RotationPeriod)
+		if err != nil {
+			return errorf(CodeInvalidDuration, "invalid rotation_period: %w", err)
+		}
+		if period < 24*time.Hour {
+			return errorf(CodeCryptoKeySettings, "rotation_period must be at least 86400s (1 day), got %s", key.RotationPeriod)
+		}
+	}
+
+	return nil
+}
+
+// validateDatabases validates Cloud SQL and Cloud Spanner configuration
+func validateDatabases(databases *config.Databases) error {
+	var errs []error
+	for _, instance := range databases.CloudSqlInstances {
+		for _, user := range instance.Users {
+			if user.GeneratePassword == nil {
+				continue
+			}
+			if user.Password != "" {
+				errs = append(errs, errorf(CodeInvalidValue, "Cloud SQL user %s sets both password and generate_password", user.Name))
+				continue
+			}
+			if strings.HasPrefix(user.Type, "CLOUD_IAM_") {
+				errs = append(errs, errorf(CodeInvalidValue, "Cloud SQL user %s is a %s user, which has no password", user.Name, user.Type))
+				continue
+			}
+			if err := validateGeneratedPassword(user.GeneratePassword); err != nil {
+				errs = append(errs, fmt.Errorf("Cloud SQL user %s: %w", user.Name, err))
+			}
+		}
+	}
+	if err := validateSqlReplicas(databases.CloudSqlInstances); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, instance := range databases.CloudSpannerInstances {
+		if err := validateSpannerCapacity(instance); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, database := range instance.Databases {
+			for i, statement := range database.Ddl {
+				if strings.TrimSpace(statement) == "" {
+					errs = append(errs, errorf(CodeInvalidValue, "Spanner database %s ddl statement %d is empty", database.Name, i))
+				}
+			}
+			if database.VersionRetentionPeriod == "" {
+				continue
+			}
+			period, err := config.ParseDuration(database.VersionRetentionPeriod)
+			if err != nil {
+				errs = append(errs, errorf(CodeInvalidDuration, "Spanner database %s has invalid version_retention_period: %w", database.Name, err))
+				continue
+			}
+			if period < time.Hour || period > 7*24*time.Hour {
+				errs = append(errs, errorf(CodeInvalidValue, "Spanner database %s version_retention_period must be between 1h and 7d, got %s", database.Name, database.VersionRetentionPeriod))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateSpannerCapacity validates that a Spanner instance sets either a
+// node count or processing units. Processing units are allocated in multiples
+// of 100 below 1000 and in multiples of 1000 from there.
+func validateSpannerCapacity(instance *config.CloudSpannerInstance) error {
+	switch {
+	case instance.NodeCount != 0 && instance.ProcessingUnits != 0:
+		return errorf(CodeSpannerCapacity, "Spanner instance %s sets both node_count and processing_units", instance.Name)
+	case instance.NodeCount == 0 && instance.ProcessingUnits == 0:
+		return errorf(CodeSpannerCapacity, "Spanner instance %s must set node_count or processing_units", instance.Name)
+	case instance.NodeCount < 0:
+		return errorf(CodeSpannerCapacity, "Spanner instance %s node_count must be at least 1, got %d", instance.Name, instance.NodeCount)
+	case instance.ProcessingUnits < 0,
+		instance.ProcessingUnits < 1000 && instance.ProcessingUnits%100 != 0,
+		instance.ProcessingUnits >= 1000 && instance.ProcessingUnits%1000 != 0:
+		return errorf(CodeSpannerCapacity, "Spanner instance %s processing_units must be a multiple of 100 below 1000 or a multiple of 1000, got %d", instance.Name, instance.ProcessingUnits)
+	}
+	return nil
+}
+
+// validateSqlReplicas validates that each Cloud SQL read replica replicates a
+// primary instance of the configuration with the same database version, in the
+// same geography, and that it doesn't define databases or users of its own
+func validateSqlReplicas(instances []*config.CloudSqlInstance) error {
+	primaries := make(map[string]*config.CloudSqlInstance)
+	for _, instance := range instances {
+		if instance.MasterInstanceName == "" {
+			primaries[instance.Name] = instance
+		}
+	}
+
+	for _, replica := range instances {
+		if replica.MasterInstanceName == "" {
+			continue
+		}
+		master, ok := primaries[replica.MasterInstanceName]
+		if !ok {
+			for _, instance := range instances {
+				if instance.Name == replica.MasterInstanceName {
+					return errorf(CodeSqlReplica, "Cloud SQL instance %s replicates %s, which is itself a replica", replica.Name, replica.MasterInstanceName)
+				}
+			}
+			return errorf(CodeUnknownReference, "Cloud SQL instance %s references unknown master instance: %s", replica.Name, replica.MasterInstanceName)
+		}
+		if len(replica.Databases) > 0 || len(replica.Users) > 0 {
+			return errorf(CodeSqlReplica, "Cloud SQL replica %s cannot define databases or users, which are replicated from %s", replica.Name, master.Name)
+		}
+		if replica.DatabaseVersion != master.DatabaseVersion {
+			return errorf(CodeSqlReplica, "Cloud SQL replica %s database_version %s differs from %s of its master %s", replica.Name, replica.DatabaseVersion, master.DatabaseVersion, master.Name)
+		}
+		// Replicas without a region are created in the region of their master
+		if replica.Region != config.Region_REGION_UNSPECIFIED && regionGeography(replica.Region) != regionGeography(effectiveRegion(master.Region)) {
+			return errorf(CodeSqlReplica, "Cloud SQL replica %s region %s is not in the geography of its master %s region %s", replica.Name, replica.Region, master.Name, effectiveRegion(master.Region))
+		}
+	}
+
+	return nil
+}
+
+// regionGeography returns the geography of a region, such as US for
+// REGION_US_CENTRAL1 or EUROPE for REGION_EUROPE_WEST1
+func regionGeography(region config.Region) string {
+	name := strings.TrimPrefix(region.String(), "REGION_")
+	geography, _, _ := strings.Cut(name, "_")
+	return geography
+}
+
+// validateSecretManager validates Secret Manager configuration
+func validateSecretManager(secretManager *config.SecretManager) error {
+	var errs []error
+	for _, secret := range secretManager.Secrets {
+		if secret.Ttl != "" {
+			if _, err := config.ParseDuration(secret.Ttl); err != nil {
+				errs = append(errs, errorf(CodeInvalidDuration, "secret %s has invalid ttl: %w", secret.Name, err))
+				continue
+			}
+		}
+		if generate := secret.GetGenerate(); generate != nil {
+			if err := validateGeneratedPassword(generate); err != nil {
+				errs = append(errs, fmt.Errorf("secret %s: %w", secret.Name, err))
+				continue
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateGeneratedPassword validates the length and complexity settings of a
+// generated password
+func validateGeneratedPassword(password *config.GeneratedPassword) error {
+	length := password.Length
+	if length == 0 {
+		length = 32
+	}
+	if length < 12 || length > 256 {
+		return errorf(CodeGeneratedPassword, "generated password length must be between 12 and 256, got %d", password.Length)
+	}
+
+	minimums := []struct {
+		name  string
+		value int32
+	}{
+		{"min_lower", password.MinLower},
+		{"min_upper", password.MinUpper},
+		{"min_numeric", password.MinNumeric},
+		{"min_special", password.MinSpecial},
+	}
+	var required int32
+	for _, minimum := range minimums {
+		if minimum.value < 0 {
+			return errorf(CodeGeneratedPassword, "generated password %s cannot be negative", minimum.name)
+		}
+		required += minimum.value
+	}
+	if required > length {
+		return errorf(CodeGeneratedPassword, "generated password minimums require %d characters but length is %d", required, length)
+	}
+
+	if password.Special != nil && !password.GetSpecial() {
+		if password.MinSpecial > 0 || password.OverrideSpecial != "" {
+			return errorf(CodeGeneratedPassword, "generated password sets min_special or override_special but special is false")
+		}
+	}
+	for _, r := range password.OverrideSpecial {
+		if r <= ' ' || r > '~' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return errorf(CodeGeneratedPassword, "generated password override_special must contain only printable ASCII symbols, got %q", r)
+		}
+	}
+
+	return nil
+}
+
+// validateMonitoring validates Cloud Monitoring configuration
+func validateMonitoring(monitoring *config.Monitoring) error {
+	var errs []error
+	channelNames := make(map[string]bool)
+	for _, channel := range monitoring.NotificationChannels {
+		if channelNames[channel.Name] {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate notification channel name: %s", channel.Name))
+			continue
+		}
+		channelNames[channel.Name] = true
+
+		if err := validateNotificationChannel(channel); err != nil {
+			errs = append(errs, fmt.Errorf("invalid notification channel %s: %w", channel.Name, err))
+		}
+	}
+
+	metricNames := make(map[string]bool)
+	for _, metric := range monitoring.LogMetrics {
+		if metricNames[metric.Name] {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate log metric name: %s", metric.Name))
+			continue
+		}
+		metricNames[metric.Name] = true
+
+		if err := validateLogMetric(metric); err != nil {
+			errs = append(errs, fmt.Errorf("invalid log metric %s: %w", metric.Name, err))
+		}
+	}
+
+	policyNames := make(map[string]bool)
+	for _, policy := range monitoring.AlertPolicies {
+		if policyNames[policy.Name] {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate alert policy name: %s", policy.Name))
+			continue
+		}
+		policyNames[policy.Name] = true
+
+		if err := validateAlertPolicy(policy, channelNames, metricNames); err != nil {
+			errs = append(errs, fmt.Errorf("invalid alert policy %s: %w", policy.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateNotificationChannel validates a notification channel configuration
+func validateNotificationChannel(channel *config.NotificationChannel) error {
+	if channel.Name == "" {
+		return errorf(CodeRequiredField, "name is required")
+	}
+
+	// Labels each channel type needs to deliver notifications
+	requiredLabels := map[string][]string{
+		"email":     {"email_address"},
+		"slack":     {"channel_name"},
+		"pagerduty": {},
+	}
+
+	labels, ok := requiredLabels[channel.Type]
+	if !ok {
+		return errorf(CodeInvalidValue, "invalid type: %s (must be email, slack, or pagerduty)", channel.Type)
+	}
+
+	for _, label := range labels {
+		if channel.Labels[label] == "" {
+			return errorf(CodeChannelLabels, "%s channels require the %s label", channel.Type, label)
+		}
+	}
+
+	return validateDescription(channel.Description, maxDescriptionLength)
+}
+
+// validateLogMetric validates a log-based metric configuration
+func validateLogMetric(metric *config.LogMetric) error {
+	if metric.Name == "" {
+		return errorf(CodeRequiredField, "name is required")
+	}
+
+	if strings.TrimSpace(metric.Filter) == "" {
+		return errorf(CodeRequiredField, "filter is required")
+	}
+
+	validMetricKinds := map[string]bool{
+		"DELTA":      true,
+		"GAUGE":      true,
+		"CUMULATIVE": true,
+	}
+
+	if metric.MetricKind != "" && !validMetricKinds[metric.MetricKind] {
+		return errorf(CodeInvalidValue, "invalid metric kind: %s (must be DELTA, GAUGE, or CUMULATIVE)", metric.MetricKind)
+	}
+
+	validValueTypes := map[string]bool{
+		"INT64":        true,
+		"DOUBLE":       true,
+		"DISTRIBUTION": true,
+	}
+
+	if metric.ValueType != "" && !validValueTypes[metric.ValueType] {
+		return errorf(CodeInvalidValue, "invalid value type: %s", metric.ValueType)
+	}
+
+	// Extracted values are recorded as distributions
+	if metric.ValueExtractor != "" && metric.ValueType != "DISTRIBUTION" {
+		return errorf(CodeLogMetricValueType, "value_extractor requires value_type DISTRIBUTION")
+	}
+	if metric.ValueType == "DISTRIBUTION" && metric.ValueExtractor == "" {
+		return errorf(CodeLogMetricValueType, "DISTRIBUTION metrics require a value_extractor")
+	}
+
+	return nil
+}
+
+// validateAlertPolicy validates an alert policy and its references to notification channels
+// and log-based metrics
+func validateAlertPolicy(policy *config.AlertPolicy, channels, logMetrics map[string]bool) error {
+	if policy.Name == "" {
+		return errorf(CodeRequiredField, "name is required")
+	}
+
+	validCombiners := map[string]bool{
+		"AND":                        true,
+		"OR":                         true,
+		"AND_WITH_MATCHING_RESOURCE": true,
+	}
+
+	if policy.Combiner != "" && !validCombiners[policy.Combiner] {
+		return errorf(CodeInvalidValue, "invalid combiner: %s", policy.Combiner)
+	}
+
+	if len(policy.Conditions) == 0 {
+		return errorf(CodeRequiredField, "at least one condition is required")
+	}
+
+	for i, condition := range policy.Conditions {
+		if err := validateAlertCondition(condition); err != nil {
+			return fmt.Errorf("invalid condition %d: %w", i, err)
+		}
+		if condition.LogMetric != "" && !logMetrics[condition.LogMetric] {
+			return errorf(CodeUnknownReference, "condition %d references non-existent log metric: %s", i, condition.LogMetric)
+		}
+	}
+
+	for _, channel := range policy.NotificationChannels {
+		if !channels[channel] {
+			return errorf(CodeUnknownReference, "references non-existent notification channel: %s", channel)
+		}
+	}
+
+	return nil
+}
+
+// validateAlertCondition validates a threshold condition of an alert policy
+func validateAlertCondition(condition *config.AlertCondition) error {
+	if condition.DisplayName == "" {
+		return errorf(CodeRequiredField, "display_name is required")
+	}
+
+	// A log metric condition derives its filter from the metric
+	if condition.Filter == "" && condition.LogMetric == "" {
+		return errorf(CodeRequiredField, "filter or log_metric is required")
+	}
+
+	validComparisons := map[string]bool{
+		"COMPARISON_GT": true,
+		"COMPARISON_GE": true,
+		"COMPARISON_LT": true,
+		"COMPARISON_LE": true,
+		"COMPARISON_EQ": true,
+		"COMPARISON_NE": true,
+	}
+
+	if !validComparisons[condition.Comparison] {
+		return errorf(CodeInvalidValue, "invalid comparison: %s", condition.Comparison)
+	}
+
+	// NaN and infinities parse as doubles but are not valid thresholds
+	if math.IsNaN(condition.ThresholdValue) || math.IsInf(condition.ThresholdValue, 0) {
+		return errorf(CodeInvalidValue, "threshold_value must be a finite number")
+	}
+
+	if condition.Duration != "" {
+		if _, err := config.ParseDuration(condition.Duration); err != nil {
+			return errorf(CodeInvalidDuration, "invalid duration: %w", err)
+		}
+	}
+
+	if condition.AlignmentPeriod != "" {
+		if _, err := config.ParseDuration(condition.AlignmentPeriod); err != nil {
+			return errorf(CodeInvalidDuration, "invalid alignment_period: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateLogSinks validates log sink configurations
+func validateLogSinks(sinks []*config.LogSink) error {
+	var errs []error
+	sinkNames := make(map[string]bool)
+	for _, sink := range sinks {
+		if sinkNames[sink.Name] {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate log sink name: %s", sink.Name))
+			continue
+		}
+		sinkNames[sink.Name] = true
+
+		if err := validateLogSink(sink); err != nil {
+			errs = append(errs, fmt.Errorf("invalid log sink %s: %w", sink.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateLogSink validates a single log sink configuration
+func validateLogSink(sink *config.LogSink) error {
+	if sink.Name == "" {
+		return errorf(CodeRequiredField, "name is required")
+	}
+
+	// An empty filter would export every log entry in the project
+	if strings.TrimSpace(sink.Filter) == "" {
+		return errorf(CodeRequiredField, "filter is required")
+	}
+
+	if sink.Destination == nil || sink.Destination.Target == nil {
+		return errorf(CodeRequiredField, "destination is required")
+	}
+
+	if external := sink.Destination.GetExternal(); external != "" {
+		validPrefixes := []string{"storage.googleapis.com/", "pubsub.googleapis.com/", "bigquery.googleapis.com/", "logging.googleapis.com/"}
+		for _, prefix := range validPrefixes {
+			if strings.HasPrefix(external, prefix) {
+				return validateDescription(sink.Description, maxDescriptionLength)
+			}
+		}
+		return errorf(CodeLogSinkDestination, "invalid external destination: %s (must be a storage, pubsub, bigquery, or logging URI)", external)
+	}
+
+	return validateDescription(sink.Description, maxDescriptionLength)
+}
+
+// validatePubSub validates Pub/Sub topics
+func validatePubSub(pubsub *config.PubSub) error {
+	var errs []error
+	names := make(map[string]bool)
+	for _, topic := range pubsub.Topics {
+		if names[topic.Name] {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate Pub/Sub topic name: %s", topic.Name))
+			continue
+		}
+		names[topic.Name] = true
+
+		if !topicNamePattern.MatchString(topic.Name) || strings.HasPrefix(strings.ToLower(topic.Name), "goog") {
+			errs = append(errs, errorf(CodeInvalidTopic, "invalid topic name: %s (must be 3-255 characters, start with a letter, and not start with \"goog\")", topic.Name))
+			continue
+		}
+
+		if topic.MessageRetentionDuration != "" {
+			retention, err := config.ParseDuration(topic.MessageRetentionDuration)
+			if err != nil {
+				errs = append(errs, errorf(CodeInvalidDuration, "topic %s has invalid message_retention_duration: %w", topic.Name, err))
+				continue
+			}
+			if retention < 10*time.Minute || retention > 31*24*time.Hour {
+				errs = append(errs, errorf(CodeInvalidTopic, "topic %s message_retention_duration must be between 10m and 31d, got %s", topic.Name, topic.MessageRetentionDuration))
+				continue
+			}
+		}
+
+		if err := validateLabels(topic.Labels); err != nil {
+			errs = append(errs, fmt.Errorf("topic %s: %w", topic.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateScheduler validates Cloud Scheduler jobs
+func validateScheduler(scheduler *config.Scheduler) error {
+	var errs []error
+	names := make(map[string]bool)
+	for _, job := range scheduler.Jobs {
+		if names[job.Name] {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate Cloud Scheduler job name: %s", job.Name))
+			continue
+		}
+		names[job.Name] = true
+
+		if err := validateSchedulerJob(job); err != nil {
+			errs = append(errs, fmt.Errorf("invalid Cloud Scheduler job %s: %w", job.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validSchedulerMethods are the HTTP methods Cloud Scheduler targets accept
+var validSchedulerMethods = map[string]bool{
+	"GET": true, "POST": true, "PUT": true, "DELETE": true, "PATCH": true, "HEAD": true, "OPTIONS": true,
+}
+
+// validateSchedulerJob validates a single Cloud Scheduler job
+func validateSchedulerJob(job *config.SchedulerJob) error {
+	if !schedulerJobNamePattern.MatchString(job.Name) {
+		return errorf(CodeInvalidValue, "invalid job name: %s (must be 1-500 letters, digits, hyphens, or underscores)", job.Name)
+	}
+
+	if err := validateDescription(job.Description, 500); err != nil {
+		return err
+	}
+
+	if job.Schedule == "" {
+		return errorf(CodeRequiredField, "schedule is required")
+	}
+	if err := validateCronSchedule(job.Schedule); err != nil {
+		return errorf(CodeInvalidSchedule, "invalid schedule %q: %w", job.Schedule, err)
+	}
+
+	// LoadLocation accepts "Local", which is not an IANA name
+	if job.TimeZone != "" {
+		if _, err := time.LoadLocation(job.TimeZone); err != nil || job.TimeZone == "Local" {
+			return errorf(CodeInvalidTimeZone, "invalid time_zone: %s (must be an IANA time zone such as America/New_York)", job.TimeZone)
+		}
+	}
+
+	switch target := job.Target.(type) {
+	case *config.SchedulerJob_HttpTarget:
+		http := target.HttpTarget
+		if !strings.HasPrefix(http.Uri, "https://") && !strings.HasPrefix(http.Uri, "http://") {
+			return errorf(CodeSchedulerTarget, "http_target uri must be an http:// or https:// URI, got %q", http.Uri)
+		}
+		if http.HttpMethod != "" && !validSchedulerMethods[http.HttpMethod] {
+			return errorf(CodeSchedulerTarget, "invalid http_method: %s", http.HttpMethod)
+		}
+		if http.ServiceAccountEmail != "" && !strings.Contains(http.ServiceAccountEmail, "@") {
+			return errorf(CodeSchedulerTarget, "service_account_email must be an email address, got %s", http.ServiceAccountEmail)
+		}
+		if http.OidcAudience != "" && http.ServiceAccountEmail == "" {
+			return errorf(CodeSchedulerTarget, "oidc_audience requires service_account_email")
+		}
+	case *config.SchedulerJob_PubsubTarget:
+		topic := target.PubsubTarget.Topic
+		if topic == "" {
+			return errorf(CodeSchedulerTarget, "pubsub_target topic is required")
+		}
+		if strings.Contains(topic, "/") && !topicIDPattern.MatchString(topic) {
+			return errorf(CodeSchedulerTarget, "pubsub_target topic must be a declared topic name or projects/<project>/topics/<topic>, got %s", topic)
+		}
+		if target.PubsubTarget.Data == "" && len(target.PubsubTarget.Attributes) == 0 {
+			return errorf(CodeSchedulerTarget, "pubsub_target requires data or attributes")
+		}
+	case *config.SchedulerJob_AppEngineTarget:
+		appEngine := target.AppEngineTarget
+		if !strings.HasPrefix(appEngine.RelativeUri, "/") {
+			return errorf(CodeSchedulerTarget, "app_engine_target relative_uri must start with /, got %q", appEngine.RelativeUri)
+		}
+		if appEngine.HttpMethod != "" && !validSchedulerMethods[appEngine.HttpMethod] {
+			return errorf(CodeSchedulerTarget, "invalid http_method: %s", appEngine.HttpMethod)
+		}
+	default:
+		return errorf(CodeSchedulerTarget, "one of http_target, pubsub_target, or app_engine_target is required")
+	}
+
+	if job.AttemptDeadline != "" {
+		deadline, err := config.ParseDuration(job.AttemptDeadline)
+		if err != nil {
+			return errorf(CodeInvalidDuration, "invalid attempt_deadline: %w", err)
+		}
+		if job.GetHttpTarget() != nil && (deadline < 15*time.Second || deadline > 30*time.Minute) {
+			return errorf(CodeInvalidValue, "attempt_deadline for HTTP targets must be between 15s and 30m, got %s", job.AttemptDeadline)
+		}
+	}
+
+	if retry := job.RetryConfig; retry != nil {
+		if retry.RetryCount < 0 || retry.RetryCount > 5 {
+			return errorf(CodeSchedulerRetry, "retry_count must be between 0 and 5, got %d", retry.RetryCount)
+		}
+		if retry.MaxDoublings < 0 {
+			return errorf(CodeSchedulerRetry, "max_doublings cannot be negative")
+		}
+
+		durations := make(map[string]time.Duration)
+		for _, field := range []struct{ name, value string }{
+			{"max_retry_duration", retry.MaxRetryDuration},
+			{"min_backoff_duration", retry.MinBackoffDuration},
+			{"max_backoff_duration", retry.MaxBackoffDuration},
+		} {
+			if field.value == "" {
+				continue
+			}
+			d, err := config.ParseDuration(field.value)
+			if err != nil {
+				return errorf(CodeInvalidDuration, "invalid %s: %w", field.name, err)
+			}
+			durations[field.name] = d
+		}
+		minBackoff, hasMin := durations["min_backoff_duration"]
+		maxBackoff, hasMax := durations["max_backoff_duration"]
+		if hasMin && hasMax && minBackoff > maxBackoff {
+			return errorf(CodeSchedulerRetry, "min_backoff_duration %s exceeds max_backoff_duration %s", retry.MinBackoffDuration, retry.MaxBackoffDuration)
+		}
+	}
+
+	return nil
+}
+
+// validateTasks validates Cloud Tasks queues
+func validateTasks(tasks *config.Tasks) error {
+	var errs []error
+	names := make(map[string]bool)
+	for _, queue := range tasks.Queues {
+		if names[queue.Name] {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate Cloud Tasks queue name: %s", queue.Name))
+			continue
+		}
+		names[queue.Name] = true
+
+		if err := validateTaskQueue(queue); err != nil {
+			errs = append(errs, fmt.Errorf("invalid Cloud Tasks queue %s: %w", queue.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateTaskQueue validates a single Cloud Tasks queue
+func validateTaskQueue(queue *config.TaskQueue) error {
+	if !taskQueueNamePattern.MatchString(queue.Name) {
+		return errorf(CodeInvalidValue, "invalid queue name: %s (must be 1-100 letters, digits, or hyphens)", queue.Name)
+	}
+
+	if queue.Location == config.Region_REGION_UNSPECIFIED {
+		return errorf(CodeRequiredField, "location is required")
+	}
+	if _, ok := config.Region_name[int32(queue.Location)]; !ok {
+		return errorf(CodeInvalidValue, "invalid location: %d is not a known region", queue.Location)
+	}
+
+	if limits := queue.RateLimits; limits != nil {
+		if limits.MaxDispatchesPerSecond < 0 || limits.MaxDispatchesPerSecond > 500 || math.IsNaN(limits.MaxDispatchesPerSecond) {
+			return errorf(CodeQueueRateLimits, "max_dispatches_per_second must be positive and at most 500, got %v", limits.MaxDispatchesPerSecond)
+		}
+		if limits.MaxConcurrentDispatches < 0 || limits.MaxConcurrentDispatches > 5000 {
+			return errorf(CodeQueueRateLimits, "max_concurrent_dispatches must be positive and at most 5000, got %d", limits.MaxConcurrentDispatches)
+		}
+	}
+
+	if retry := queue.RetryConfig; retry != nil {
+		if retry.MaxAttempts < -1 || retry.MaxAttempts > 100 {
+			return errorf(CodeQueueRetry, "max_attempts must be between 1 and 100, or -1 for unlimited, got %d", retry.MaxAttempts)
+		}
+		if retry.MaxDoublings < 0 {
+			return errorf(CodeQueueRetry, "max_doublings cannot be negative")
+		}
+
+		durations := make(map[string]time.Duration)
+		for _, field := range []struct{ name, value string }{
+			{"max_retry_duration", retry.MaxRetryDuration},
+			{"min_backoff", retry.MinBackoff},
+			{"max_backoff", retry.MaxBackoff},
+		} {
+			if field.value == "" {
+				continue
+			}
+			d, err := config.ParseDuration(field.value)
+			if err != nil {
+				return errorf(CodeInvalidDuration, "invalid %s: %w", field.name, err)
+			}
+			durations[field.name] = d
+		}
+		minBackoff, hasMin := durations["min_backoff"]
+		maxBackoff, hasMax := durations["max_backoff"]
+		if hasMin && hasMax && minBackoff > maxBackoff {
+			return errorf(CodeQueueRetry, "min_backoff %s exceeds max_backoff %s", retry.MinBackoff, retry.MaxBackoff)
+		}
+	}
+
+	return nil
+}
+
+// filestoreTiers maps each Filestore tier to its minimum capacity in GB and
+// whether its instances are regional rather than zonal
+var filestoreTiers = map[string]struct {
+	minCapacityGb int64
+	regional      bool
+}{
+	"BASIC_HDD":      {1024, false},
+	"BASIC_SSD":      {2560, false},
+	"HIGH_SCALE_SSD": {10240, false},
+	"ZONAL":          {1024, false},
+	"REGIONAL":       {1024, true},
+	"ENTERPRISE":     {1024, true},
+}
+
+// validateFilestore validates Filestore instances
+func validateFilestore(filestore *config.Filestore) error {
+	var errs []error
+	names := make(map[string]bool)
+	for _, instance := range filestore.Instances {
+		if names[instance.Name] {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate Filestore instance name: %s", instance.Name))
+			continue
+		}
+		names[instance.Name] = true
+
+		if err := validateFilestoreInstance(instance); err != nil {
+			errs = append(errs, fmt.Errorf("invalid Filestore instance %s: %w", instance.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateFilestoreInstance validates a single Filestore instance
+func validateFilestoreInstance(instance *config.FilestoreInstance) error {
+	if !filestoreNamePattern.MatchString(instance.Name) {
+		return errorf(CodeInvalidValue, "invalid instance name: %s (must be 1-63 lowercase letters, digits, or hyphens, starting with a letter)", instance.Name)
+	}
+
+	if instance.Tier == "" {
+		return errorf(CodeRequiredField, "tier is required")
+	}
+	tier, ok := filestoreTiers[instance.Tier]
+	if !ok {
+		return errorf(CodeInvalidValue, "invalid tier: %s (must be BASIC_HDD, BASIC_SSD, HIGH_SCALE_SSD, ZONAL, REGIONAL, or ENTERPRISE)", instance.Tier)
+	}
+
+	if tier.regional {
+		if instance.Zone != config.Zone_ZONE_UNSPECIFIED {
+			return errorf(CodeFilestoreLocation, "%s instances are regional; set region instead of zone", instance.Tier)
+		}
+		if instance.Region == config.Region_REGION_UNSPECIFIED {
+			return errorf(CodeFilestoreLocation, "region is required for %s instances", instance.Tier)
+		}
+	} else {
+		if instance.Region != config.Region_REGION_UNSPECIFIED {
+			return errorf(CodeFilestoreLocation, "%s instances are zonal; set zone instead of region", instance.Tier)
+		}
+		if instance.Zone == config.Zone_ZONE_UNSPECIFIED {
+			return errorf(CodeFilestoreLocation, "zone is required for %s instances", instance.Tier)
+		}
+	}
+
+	share := instance.FileShare
+	if share == nil {
+		return errorf(CodeRequiredField, "file_share is required")
+	}
+	if !filestoreShareNamePattern.MatchString(share.Name) {
+		return errorf(CodeInvalidValue, "invalid file share name: %s (must be 1-16 letters, digits, or underscores, starting with a letter)", share.Name)
+	}
+	if share.CapacityGb < tier.minCapacityGb {
+		return errorf(CodeFilestoreCapacity, "file share capacity %d GB is below the %s minimum of %d GB", share.CapacityGb, instance.Tier, tier.minCapacityGb)
+	}
+
+	if instance.Network == "" {
+		return errorf(CodeRequiredField, "network is required")
+	}
+
+	switch instance.ConnectMode {
+	case "", "DIRECT_PEERING":
+		if instance.ReservedIpRange != "" && !isValidCIDR(instance.ReservedIpRange) {
+			return errorf(CodeInvalidCIDR, "invalid reserved_ip_range: %s (DIRECT_PEERING needs a CIDR block)", instance.ReservedIpRange)
+		}
+	case "PRIVATE_SERVICE_ACCESS":
+		// reserved_ip_range may name an allocated range instead of a CIDR block
+	default:
+		return errorf(CodeInvalidValue, "invalid connect_mode: %s (must be DIRECT_PEERING or PRIVATE_SERVICE_ACCESS)", instance.ConnectMode)
+	}
+
+	return nil
+}
+
+// gkeReleaseChannels are the accepted GKE release channels
+var gkeReleaseChannels = map[string]bool{
+	"RAPID":    true,
+	"REGULAR":  true,
+	"STABLE":   true,
+	"EXTENDED": true,
+}
+
+// validateGke validates GKE clusters
+func validateGke(gke *config.Gke) error {
+	var errs []error
+	names := make(map[string]bool)
+	for _, cluster := range gke.Clusters {
+		if names[cluster.Name] {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate GKE cluster name: %s", cluster.Name))
+			continue
+		}
+		names[cluster.Name] = true
+
+		if err := validateGkeCluster(cluster); err != nil {
+			errs = append(errs, fmt.Errorf("invalid GKE cluster %s: %w", cluster.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateGkeCluster validates a single GKE cluster and its node pools
+func validateGkeCluster(cluster *config.GkeCluster) error {
+	if !gkeNamePattern.MatchString(cluster.Name) {
+		return errorf(CodeInvalidValue, "invalid cluster name: %s (must be 1-40 lowercase letters, digits, or hyphens, starting with a letter)", cluster.Name)
+	}
+
+	hasRegion := cluster.Region != config.Region_REGION_UNSPECIFIED
+	hasZone := cluster.Zone != config.Zone_ZONE_UNSPECIFIED
+	if hasRegion == hasZone {
+		return errorf(CodeGkeLocation, "exactly one of region and zone must be set")
+	}
+
+	if cluster.Network == "" {
+		return errorf(CodeRequiredField, "network is required")
+	}
+	if cluster.Subnetwork == "" {
+		return errorf(CodeRequiredField, "subnetwork is required")
+	}
+
+	if (cluster.PodsSecondaryRange == "") != (cluster.ServicesSecondaryRange == "") {
+		return errorf(CodeGkeSecondaryRange, "pods_secondary_range and services_secondary_range must be set together")
+	}
+	if cluster.PodsSecondaryRange != "" && cluster.PodsSecondaryRange == cluster.ServicesSecondaryRange {
+		return errorf(CodeGkeSecondaryRange, "pods and services must use different secondary ranges, both use %s", cluster.PodsSecondaryRange)
+	}
+
+	if cluster.ReleaseChannel != "" && !gkeReleaseChannels[cluster.ReleaseChannel] {
+		return errorf(CodeInvalidValue, "invalid release_channel: %s (must be RAPID, REGULAR, STABLE, or EXTENDED)", cluster.ReleaseChannel)
+	}
+
+	if len(cluster.NodePools) == 0 {
+		return errorf(CodeRequiredField, "at least one node pool is required (the default node pool is removed)")
+	}
+	pools := make(map[string]bool)
+	for _, pool := range cluster.NodePools {
+		if pools[pool.Name] {
+			return errorf(CodeDuplicateName, "duplicate node pool name: %s", pool.Name)
+		}
+		pools[pool.Name] = true
+
+		if err := validateGkeNodePool(pool); err != nil {
+			return fmt.Errorf("invalid node pool %s: %w", pool.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// validateGkeNodePool validates a single GKE node pool
+func validateGkeNodePool(pool *config.GkeNodePool) error {
+	if !gkeNamePattern.MatchString(pool.Name) {
+		return errorf(CodeInvalidValue, "invalid node pool name: %s (must be 1-40 lowercase letters, digits, or hyphens, starting with a letter)", pool.Name)
+	}
+
+	if pool.MachineType == config.MachineType_MACHINE_TYPE_UNSPECIFIED {
+		return errorf(CodeRequiredField, "machine_type is required")
+	}
+
+	if pool.NodeCount < 0 || pool.MinNodeCount < 0 || pool.MaxNodeCount < 0 {
+		return errorf(CodeGkeNodePoolSize, "node counts must not be negative")
+	}
+	if pool.MinNodeCount > 0 || pool.MaxNodeCount > 0 {
+		if pool.NodeCount != 0 {
+			return errorf(CodeGkeNodePoolSize, "node_count cannot be combined with min_node_count and max_node_count")
+		}
+		if pool.MaxNodeCount == 0 {
+			return errorf(CodeGkeNodePoolSize, "max_node_count is required for autoscaling")
+		}
+		if pool.MinNodeCount > pool.MaxNodeCount {
+			return errorf(CodeGkeNodePoolSize, "min_node_count (%d) exceeds max_node_count (%d)", pool.MinNodeCount, pool.MaxNodeCount)
+		}
+	}
+
+	if pool.DiskSizeGb != 0 && pool.DiskSizeGb < 10 {
+		return errorf(CodeDiskTooSmall, "disk size %d GB is below the minimum of 10 GB", pool.DiskSizeGb)
+	}
+
+	return nil
+}
+
+// dnsRecordTypes are the record set types Cloud DNS supports
+var dnsRecordTypes = map[string]bool{
+	"A": true, "AAAA": true, "CAA": true, "CNAME": true, "MX": true, "NS": true,
+	"PTR": true, "SOA": true, "SPF": true, "SRV": true, "TXT": true,
+}
+
+// validateDns validates Cloud DNS managed zones and their record sets
+func validateDns(dns *config.Dns) error {
+	var errs []error
+	names := make(map[string]bool)
+	for _, zone := range dns.ManagedZones {
+		if names[zone.Name] {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate managed zone name: %s", zone.Name))
+			continue
+		}
+		names[zone.Name] = true
+
+		if err := validateDnsZone(zone); err != nil {
+			errs = append(errs, fmt.Errorf("invalid managed zone %s: %w", zone.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateDnsZone validates a single managed zone
+func validateDnsZone(zone *config.DnsManagedZone) error {
+	if !dnsZoneNamePattern.MatchString(zone.Name) {
+		return errorf(CodeInvalidValue, "invalid zone name: %s (must be 1-63 lowercase letters, digits, or hyphens, starting with a letter)", zone.Name)
+	}
+
+	if zone.DnsName == "" {
+		return errorf(CodeRequiredField, "dns_name is required")
+	}
+	if !dnsNamePattern.MatchString(zone.DnsName) {
+		return errorf(CodeDnsName, "invalid dns_name: %s (must be a domain name ending with a dot, e.g. \"example.com.\")", zone.DnsName)
+	}
+
+	switch zone.Visibility {
+	case "", "public":
+		if len(zone.Networks) > 0 {
+			return errorf(CodeDnsVisibility, "networks can only be set on private zones")
+		}
+	case "private":
+		if len(zone.Networks) == 0 {
+			return errorf(CodeDnsVisibility, "private zones must list at least one network that can resolve them")
+		}
+	default:
+		return errorf(CodeDnsVisibility, "invalid visibility: %s (must be public or private)", zone.Visibility)
+	}
+
+	if err := validateLabels(zone.Labels); err != nil {
+		return err
+	}
+
+	records := make(map[string]bool)
+	for _, record := range zone.RecordSets {
+		name := config.DnsRecordName(zone.DnsName, record.Name)
+		if err := validateDnsRecordSet(zone, record, name); err != nil {
+			return fmt.Errorf("invalid record set %s %s: %w", name, record.Type, err)
+		}
+
+		key := strings.ToLower(name) + " " + record.Type
+		if records[key] {
+			return errorf(CodeDnsRecordSet, "duplicate record set %s %s (combine its rrdatas into one record set)", name, record.Type)
+		}
+		records[key] = true
+	}
+
+	return nil
+}
+
+// validateDnsRecordSet validates a record set with the fully qualified name
+// name in zone
+func validateDnsRecordSet(zone *config.DnsManagedZone, record *config.DnsRecordSet, name string) error {
+	if !dnsRecordNamePattern.MatchString(name) {
+		return errorf(CodeDnsName, "invalid record name: %s", record.Name)
+	}
+	lowerName, lowerZone := strings.ToLower(name), strings.ToLower(zone.DnsName)
+	if lowerName != lowerZone && !strings.HasSuffix(lowerName, "."+lowerZone) {
+		return errorf(CodeDnsName, "record name %s is outside the zone %s", name, zone.DnsName)
+	}
+
+	if record.Type == "" {
+		return errorf(CodeRequiredField, "type is required")
+	}
+	if !dnsRecordTypes[record.Type] {
+		return errorf(CodeDnsRecordSet, "invalid type: %s (must be A, AAAA, CAA, CNAME, MX, NS, PTR, SOA, SPF, SRV, or TXT)", record.Type)
+	}
+
+	if record.Ttl < 0 {
+		return errorf(CodeDnsRecordSet, "ttl must not be negative")
+	}
+	if len(record.Rrdatas) == 0 {
+		return errorf(CodeDnsRecordSet, "at least one rrdata is required")
+	}
+	if record.Type == "CNAME" {
+		if len(record.Rrdatas) > 1 {
+			return errorf(CodeDnsRecordSet, "CNAME record sets must have exactly one rrdata")
+		}
+		if lowerName == lowerZone {
+			return errorf(CodeDnsRecordSet, "the zone apex cannot be a CNAME")
+		}
+	}
+
+	return nil
+}
+
+// maxBigQueryIdLength is the maximum length of dataset and table IDs
+const maxBigQueryIdLength = 1024
+
+// bigQuerySpecialGroups are the special groups a dataset access entry can grant a role to
+var bigQuerySpecialGroups = map[string]bool{
+	"projectOwners": true, "projectReaders": true, "projectWriters": true, "allAuthenticatedUsers": true,
+}
+
+// bigQueryFieldTypes are the types of BigQuery schema fields
+var bigQueryFieldTypes = map[string]bool{
+	"STRING": true, "BYTES": true, "INTEGER": true, "INT64": true, "FLOAT": true, "FLOAT64": true,
+	"NUMERIC": true, "BIGNUMERIC": true, "BOOLEAN": true, "BOOL": true, "TIMESTAMP": true, "DATE": true,
+	"TIME": true, "DATETIME": true, "GEOGRAPHY": true, "JSON": true, "RECORD": true, "STRUCT": true,
+}
+
+// bigQueryPartitionTypes are the granularities of time partitioning
+var bigQueryPartitionTypes = map[string]bool{"DAY": true, "HOUR": true, "MONTH": true, "YEAR": true}
+
+// validateBigQuery validates BigQuery datasets and tables
+func validateBigQuery(bigquery *config.BigQuery) error {
+	var errs []error
+	datasets := make(map[string]bool)
+	for _, dataset := range bigquery.Datasets {
+		if datasets[dataset.DatasetId] {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate dataset ID: %s", dataset.DatasetId))
+			continue
+		}
+		datasets[dataset.DatasetId] = true
+
+		if err := validateBigQueryDataset(dataset); err != nil {
+			errs = append(errs, fmt.Errorf("invalid dataset %s: %w", dataset.DatasetId, err))
+		}
+	}
+
+	tables := make(map[string]bool)
+	for _, table := range bigquery.Tables {
+		key := table.Dataset + "." + table.TableId
+		if tables[key] {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate table: %s", key))
+			continue
+		}
+		tables[key] = true
+
+		if err := validateBigQueryTable(table); err != nil {
+			errs = append(errs, fmt.Errorf("invalid table %s: %w", key, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateBigQueryDataset validates a single dataset
+func validateBigQueryDataset(dataset *config.BigQueryDataset) error {
+	if dataset.DatasetId == "" {
+		return errorf(CodeRequiredField, "dataset_id is required")
+	}
+	if len(dataset.DatasetId) > maxBigQueryIdLength || !bigQueryDatasetIdPattern.MatchString(dataset.DatasetId) {
+		return errorf(CodeBigQueryName, "invalid dataset_id: %s (must be at most 1024 letters, digits, or underscores)", dataset.DatasetId)
+	}
+
+	if dataset.DefaultTableExpiration != "" {
+		expiration, err := config.ParseDuration(dataset.DefaultTableExpiration)
+		if err != nil {
+			return errorf(CodeInvalidValue, "invalid default_table_expiration: %v", err)
+		}
+		if expiration < time.Hour {
+			return errorf(CodeInvalidValue, "default_table_expiration must be at least 1h, got %s", dataset.DefaultTableExpiration)
+		}
+	}
+
+	for i, access := range dataset.Access {
+		if err := validateBigQueryAccess(access); err != nil {
+			return fmt.Errorf("invalid access entry %d: %w", i, err)
+		}
+	}
+
+	return validateLabels(dataset.Labels)
+}
+
+// validateBigQueryAccess validates a dataset access entry
+func validateBigQueryAccess(access *config.BigQueryAccess) error {
+	if access.Role == "" {
+		return errorf(CodeRequiredField, "role is required")
+	}
+
+	principals := 0
+	for _, principal := range []string{access.UserByEmail, access.GroupByEmail, access.Domain, access.SpecialGroup} {
+		if principal != "" {
+			principals++
+		}
+	}
+	if principals != 1 {
+		return errorf(CodeBigQueryAccess, "exactly one of user_by_email, group_by_email, domain, or special_group must be set, got %d", principals)
+	}
+
+	if access.SpecialGroup != "" && !bigQuerySpecialGroups[access.SpecialGroup] {
+		return errorf(CodeBigQueryAccess, "invalid special_group: %s (must be projectOwners, projectReaders, projectWriters, or allAuthenticatedUsers)", access.SpecialGroup)
+	}
+	return nil
+}
+
+// validateBigQueryTable validates a single table, its schema, partitioning,
+// and clustering
+func validateBigQueryTable(table *config.BigQueryTable) error {
+	if table.TableId == "" {
+		return errorf(CodeRequiredField, "table_id is required")
+	}
+	if len(table.TableId) > maxBigQueryIdLength || !bigQueryTableIdPattern.MatchString(table.TableId) {
+		return errorf(CodeBigQueryName, "invalid table_id: %s (must be at most 1024 letters, digits, underscores, or hyphens)", table.TableId)
+	}
+	if table.Dataset == "" {
+		return errorf(CodeRequiredField, "dataset is required")
+	}
+
+	// Top-level fields by name, when the schema is known, to check the
+	// partitioning and clustering fields against
+	var fields map[string]string
+	switch {
+	case table.SchemaJson != "" && len(table.Fields) > 0:
+		return errorf(CodeBigQuerySchema, "fields and schema_json cannot both be set")
+	case table.SchemaJson != "":
+		var schema []map[string]any
+		if err := json.Unmarshal([]byte(table.SchemaJson), &schema); err != nil {
+			return errorf(CodeBigQuerySchema, "schema_json must be a JSON array of fields: %v", err)
+		}
+	case len(table.Fields) > 0:
+		if err := validateBigQueryFields(table.Fields); err != nil {
+			return err
+		}
+		fields = make(map[string]string)
+		for _, field := range table.Fields {
+			fields[field.Name] = field.Type
+		}
+	}
+
+	if partitioning := table.TimePartitioning; partitioning != nil {
+		if !bigQueryPartitionTypes[partitioning.Type] {
+			return errorf(CodeBigQuerySchema, "invalid time_partitioning type: %s (must be DAY, HOUR, MONTH, or YEAR)", partitioning.Type)
+		}
+		if partitioning.Field != "" && fields != nil {
+			switch fields[partitioning.Field] {
+			case "TIMESTAMP", "DATE", "DATETIME":
+			case "":
+				return errorf(CodeBigQuerySchema, "time_partitioning field %s is not a field of the table", partitioning.Field)
+			default:
+				return errorf(CodeBigQuerySchema, "time_partitioning field %s must be a TIMESTAMP, DATE, or DATETIME field", partitioning.Field)
+			}
+		}
+		if partitioning.Expiration != "" {
+			if _, err := config.ParseDuration(partitioning.Expiration); err != nil {
+				return errorf(CodeInvalidValue, "invalid time_partitioning expiration: %v", err)
+			}
+		}
+	}
+
+	if len(table.Clustering) > 4 {
+		return errorf(CodeBigQuerySchema, "a table can be clustered by at most 4 fields, got %d", len(table.Clustering))
+	}
+	for _, field := range table.Clustering {
+		if fields != nil && fields[field] == "" {
+			return errorf(CodeBigQuerySchema, "clustering field %s is not a field of the table", field)
+		}
+	}
+
+	return validateLabels(table.Labels)
+}
+
+// validateBigQueryFields validates schema fields and their nested fields
+func validateBigQueryFields(fields []*config.BigQueryField) error {
+	names := make(map[string]bool)
+	for _, field := range fields {
+		if !bigQueryFieldNamePattern.MatchString(field.Name) {
+			return errorf(CodeBigQuerySchema, "invalid field name: %q (must start with a letter or underscore and contain at most 300 letters, digits, or underscores)", field.Name)
+		}
+		if names[strings.ToLower(field.Name)] {
+			return errorf(CodeBigQuerySchema, "duplicate field name: %s", field.Name)
+		}
+		names[strings.ToLower(field.Name)] = true
+
+		if !bigQueryFieldTypes[field.Type] {
+			return errorf(CodeBigQuerySchema, "field %s has invalid type: %q", field.Name, field.Type)
+		}
+		switch field.Mode {
+		case "", "NULLABLE", "REQUIRED", "REPEATED":
+		default:
+			return errorf(CodeBigQuerySchema, "field %s has invalid mode: %s (must be NULLABLE, REQUIRED, or REPEATED)", field.Name, field.Mode)
+		}
+
+		record := field.Type == "RECORD" || field.Type == "STRUCT"
+		if record && len(field.Fields) == 0 {
+			return errorf(CodeBigQuerySchema, "RECORD field %s must have nested fields", field.Name)
+		}
+		if !record && len(field.Fields) > 0 {
+			return errorf(CodeBigQuerySchema, "field %s has nested fields but is not a RECORD", field.Name)
+		}
+		if err := validateBigQueryFields(field.Fields); err != nil {
+			return fmt.Errorf("in field %s: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+// validateCloudFunctions validates 2nd gen Cloud Functions
+func validateCloudFunctions(functions *config.CloudFunctions) error {
+	var errs []error
+	names := make(map[string]bool)
+	for _, function := range functions.Functions {
+		if names[function.Name] {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate Cloud Function name: %s", function.Name))
+			continue
+		}
+		names[function.Name] = true
+
+		if err := validateCloudFunction(function); err != nil {
+			errs = append(errs, fmt.Errorf("invalid Cloud Function %s: %w", function.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+const (
+	// Request timeouts of 2nd gen functions, which are shorter for event-driven functions
+	maxFunctionTimeoutSeconds      = 3600
+	maxEventFunctionTimeoutSeconds = 540
+	// pubsubEventType is the event type of functions triggered by a Pub/Sub topic
+	pubsubEventType = "google.cloud.pubsub.topic.v1.messagePublished"
+)
+
+// validateCloudFunction validates a single Cloud Function
+func validateCloudFunction(function *config.CloudFunction) error {
+	if !functionNamePattern.MatchString(function.Name) {
+		return errorf(CodeInvalidValue, "invalid function name: %s (must be 1-63 lowercase letters, digits, or hyphens, starting with a letter)", function.Name)
+	}
+
+	if err := validateDescription(function.Description, maxDescriptionLength); err != nil {
+		return err
+	}
+
+	if function.Runtime == "" || function.EntryPoint == "" {
+		return errorf(CodeFunctionSource, "runtime and entry_point are required")
+	}
+	if !functionRuntimePattern.MatchString(function.Runtime) {
+		return errorf(CodeFunctionSource, "invalid runtime: %s (e.g. python312, nodejs20, go122)", function.Runtime)
+	}
+	if function.SourceBucket == "" || function.SourceObject == "" {
+		return errorf(CodeFunctionSource, "source_bucket and source_object are required")
+	}
+
+	if function.AvailableMemory != "" && !functionMemoryPattern.MatchString(function.AvailableMemory) {
+		return errorf(CodeFunctionScaling, "invalid available_memory: %s (e.g. 256M or 1Gi)", function.AvailableMemory)
+	}
+	if function.MinInstances < 0 || function.MaxInstances < 0 {
+		return errorf(CodeFunctionScaling, "min_instances and max_instances cannot be negative")
+	}
+	if function.MaxInstances > 0 && function.MinInstances > function.MaxInstances {
+		return errorf(CodeFunctionScaling, "min_instances (%d) cannot exceed max_instances (%d)", function.MinInstances, function.MaxInstances)
+	}
+	maxTimeout := int32(maxFunctionTimeoutSeconds)
+	if function.EventTrigger != nil {
+		maxTimeout = maxEventFunctionTimeoutSeconds
+	}
+	if function.TimeoutSeconds < 0 || function.TimeoutSeconds > maxTimeout {
+		return errorf(CodeFunctionScaling, "timeout_seconds must be between 1 and %d, got %d", maxTimeout, function.TimeoutSeconds)
+	}
+
+	if err := validateLabels(function.Labels); err != nil {
+		return err
+	}
+
+	if function.ServiceAccount != "" && !strings.Contains(function.ServiceAccount, "@") {
+		return errorf(CodeInvalidValue, "service_account must be an email address, got %s", function.ServiceAccount)
+	}
+
+	if trigger := function.EventTrigger; trigger != nil {
+		if trigger.EventType == "" && trigger.PubsubTopic == "" {
+			return errorf(CodeFunctionTrigger, "event_trigger requires event_type or pubsub_topic")
+		}
+		if trigger.PubsubTopic != "" {
+			if trigger.EventType != "" && trigger.EventType != pubsubEventType {
+				return errorf(CodeFunctionTrigger, "pubsub_topic requires event_type %s, got %s", pubsubEventType, trigger.EventType)
+			}
+			if strings.Contains(trigger.PubsubTopic, "/") && !topicIDPattern.MatchString(trigger.PubsubTopic) {
+				return errorf(CodeFunctionTrigger, "pubsub_topic must be a declared topic name or projects/<project>/topics/<topic>, got %s", trigger.PubsubTopic)
+			}
+		} else if trigger.EventType == pubsubEventType {
+			return errorf(CodeFunctionTrigger, "event_type %s requires pubsub_topic", pubsubEventType)
+		}
+		if trigger.ServiceAccount != "" && !strings.Contains(trigger.ServiceAccount, "@") {
+			return errorf(CodeFunctionTrigger, "event_trigger service_account must be an email address, got %s", trigger.ServiceAccount)
+		}
+	}
+
+	return nil
+}
+
+// validateArtifactRegistry validates Artifact Registry repositories
+func validateArtifactRegistry(registry *config.ArtifactRegistry) error {
+	var errs []error
+	ids := make(map[string]bool)
+	for _, repository := range registry.Repositories {
+		if ids[repository.RepositoryId] {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate repository_id: %s", repository.RepositoryId))
+			continue
+		}
+		ids[repository.RepositoryId] = true
+
+		if err := validateArtifactRepository(repository); err != nil {
+			errs = append(errs, fmt.Errorf("invalid repository %s: %w", repository.RepositoryId, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// maxCleanupPolicies is the number of cleanup policies a repository can have
+const maxCleanupPolicies = 10
+
+// validateArtifactRepository validates a single Artifact Registry repository
+func validateArtifactRepository(repository *config.ArtifactRepository) error {
+	if !artifactRepositoryIdPattern.MatchString(repository.RepositoryId) {
+		return errorf(CodeArtifactRepositoryId, "invalid repository_id: %q (must be 1-63 lowercase letters, digits, or hyphens, starting with a letter)", repository.RepositoryId)
+	}
+
+	if _, ok := config.ArtifactFormat_name[int32(repository.Format)]; !ok || repository.Format == config.ArtifactFormat_ARTIFACT_FORMAT_UNSPECIFIED {
+		return errorf(CodeArtifactFormat, "format must be ARTIFACT_FORMAT_DOCKER, ARTIFACT_FORMAT_MAVEN, ARTIFACT_FORMAT_NPM, or ARTIFACT_FORMAT_PYTHON, got %s", repository.Format)
+	}
+
+	if err := validateDescription(repository.Description, maxDescriptionLength); err != nil {
+		return err
+	}
+
+	if len(repository.CleanupPolicies) > maxCleanupPolicies {
+		return errorf(CodeArtifactCleanup, "at most %d cleanup policies are allowed, got %d", maxCleanupPolicies, len(repository.CleanupPolicies))
+	}
+	policyIds := make(map[string]bool)
+	for _, policy := range repository.CleanupPolicies {
+		if policy.Id == "" {
+			return errorf(CodeArtifactCleanup, "cleanup policies must specify an id")
+		}
+		if policyIds[policy.Id] {
+			return errorf(CodeArtifactCleanup, "duplicate cleanup policy id: %s", policy.Id)
+		}
+		policyIds[policy.Id] = true
+
+		if err := validateArtifactCleanupPolicy(policy); err != nil {
+			return fmt.Errorf("invalid cleanup policy %s: %w", policy.Id, err)
+		}
+	}
+
+	return validateLabels(repository.Labels)
+}
+
+// validateArtifactCleanupPolicy validates a cleanup policy of a repository
+func validateArtifactCleanupPolicy(policy *config.ArtifactCleanupPolicy) error {
+	if policy.Action != "DELETE" && policy.Action != "KEEP" {
+		return errorf(CodeArtifactCleanup, "action must be DELETE or KEEP, got %q", policy.Action)
+	}
+
+	hasCondition := policy.TagState != "" || len(policy.TagPrefixes) > 0 || policy.OlderThan != "" || policy.NewerThan != ""
+	if policy.KeepCount < 0 {
+		return errorf(CodeArtifactCleanup, "keep_count cannot be negative")
+	}
+	if policy.KeepCount > 0 {
+		if policy.Action != "KEEP" {
+			return errorf(CodeArtifactCleanup, "keep_count is only supported for KEEP policies")
+		}
+		if hasCondition {
+			return errorf(CodeArtifactCleanup, "keep_count cannot be combined with tag_state, tag_prefixes, older_than, or newer_than")
+		}
+		return nil
+	}
+
+	if policy.TagState != "" && policy.TagState != "TAGGED" && policy.TagState != "UNTAGGED" && policy.TagState != "ANY" {
+		return errorf(CodeArtifactCleanup, "tag_state must be TAGGED, UNTAGGED, or ANY, got %q", policy.TagState)
+	}
+	for _, field := range []struct{ name, value string }{
+		{"older_than", policy.OlderThan},
+		{"newer_than", policy.NewerThan},
+	} {
+		if field.value == "" {
+			continue
+		}
+		if _, err := config.ParseDuration(field.value); err != nil {
+			return errorf(CodeInvalidDuration, "invalid %s: %w", field.name, err)
+		}
+	}
+	if policy.Action == "DELETE" && !hasCondition && len(policy.PackageNamePrefixes) == 0 {
+		return errorf(CodeArtifactCleanup, "DELETE policies must set a condition, or they delete every version")
+	}
+
+	return nil
+}
+
+// cronFields are the unix-cron fields in order, with their allowed ranges and
+// the names accepted in place of numbers
+var cronFields = []struct {
+	name     string
+	min, max int
+	names    []string
+}{
+	{"minute", 0, 59, nil},
+	{"hour", 0, 23, nil},
+	{"day of month", 1, 31, nil},
+	{"month", 1, 12, []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+	{"day of week", 0, 7, []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}},
+}
+
+// validateCronSchedule checks that schedule is a unix-cron expression
+func validateCronSchedule(schedule string) error {
+	fields := strings.Fields(schedule)
+	if len(fields) != len(cronFields) {
+		return fmt.Errorf("expected %d fields (minute hour day-of-month month day-of-week), got %d", len(cronFields), len(fields))
+	}
+
+	for i, field := range fields {
+		spec := cronFields[i]
+		value := func(s string) (int, error) {
+			for n, name := range spec.names {
+				if strings.EqualFold(s, name) {
+					return n + spec.min, nil
+				}
+			}
+			v, err := strconv.Atoi(s)
+			if err != nil || v < spec.min || v > spec.max {
+				return 0, fmt.Errorf("%s value %q must be between %d and %d", spec.name, s, spec.min, spec.max)
+			}
+			return v, nil
+		}
+
+		for _, item := range strings.Split(field, ",") {
+			rangePart, step, hasStep := strings.Cut(item, "/")
+			if hasStep {
+				if n, err := strconv.Atoi(step); err != nil || n < 1 {
+					return fmt.Errorf("%s step %q must be a positive number", spec.name, step)
+				}
+			}
+			if rangePart == "*" {
+				continue
+			}
+
+			low, high, isRange := strings.Cut(rangePart, "-")
+			start, err := value(low)
+			if err != nil {
+				return err
+			}
+			if !isRange {
+				continue
+			}
+			end, err := value(high)
+			if err != nil {
+				return err
+			}
+			if start > end {
+				return fmt.Errorf("%s range %q is reversed", spec.name, rangePart)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateResourceTags validates Resource Manager tag keys, values, and bindings
+func validateResourceTags(tags *config.ResourceTags) error {
+	var errs []error
+	// Values declared for each key, used to check bindings
+	declared := make(map[string]map[string]bool)
+	for _, key := range tags.Keys {
+		if !isValidTagShortName(key.ShortName) {
+			errs = append(errs, errorf(CodeInvalidTag, "invalid tag key short name: %s (must be 1-63 characters, start and end with a letter or number, and contain only letters, numbers, hyphens, underscores, and dots)", key.ShortName))
+			continue
+		}
+		if declared[key.ShortName] != nil {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate tag key: %s", key.ShortName))
+			continue
+		}
+		if key.Parent != "" && !strings.HasPrefix(key.Parent, "organizations/") && !strings.HasPrefix(key.Parent, "projects/") {
+			errs = append(errs, errorf(CodeInvalidTag, "tag key %s has invalid parent: %s (must be organizations/<id> or projects/<id>)", key.ShortName, key.Parent))
+			continue
+		}
+		if err := validateDescription(key.Description, 256); err != nil {
+			errs = append(errs, fmt.Errorf("tag key %s: %w", key.ShortName, err))
+			continue
+		}
+
+		values := make(map[string]bool)
+		for _, value := range key.Values {
+			if !isValidTagShortName(value.ShortName) {
+				errs = append(errs, errorf(CodeInvalidTag, "invalid tag value short name %s for key %s", value.ShortName, key.ShortName))
+				continue
+			}
+			if values[value.ShortName] {
+				errs = append(errs, errorf(CodeDuplicateName, "duplicate value %s for tag key %s", value.ShortName, key.ShortName))
+				continue
+			}
+			values[value.ShortName] = true
+		}
+		declared[key.ShortName] = values
+	}
+
+	// A resource can carry at most one value for each key
+	bound := make(map[string]bool)
+	for i, binding := range tags.Bindings {
+		values, ok := declared[binding.Key]
+		if !ok {
+			errs = append(errs, errorf(CodeUnknownReference, "tag binding %d references undeclared tag key: %s", i, binding.Key))
+			continue
+		}
+		if !values[binding.Value] {
+			errs = append(errs, errorf(CodeUnknownReference, "tag binding %d references undeclared value %s for tag key %s", i, binding.Value, binding.Key))
+			continue
+		}
+
+		if binding.Resource != "" && !strings.HasPrefix(binding.Resource, "//") {
+			errs = append(errs, errorf(CodeTagBinding, "tag binding %d has invalid resource: %s (must be a full resource name starting with //)", i, binding.Resource))
+			continue
+		}
+		if binding.Location != "" && binding.Resource == "" {
+			errs = append(errs, errorf(CodeTagBinding, "tag binding %d sets location without a resource (project bindings are not location-scoped)", i))
+			continue
+		}
+
+		target := binding.Resource + "|" + binding.Key
+		if bound[target] {
+			errs = append(errs, errorf(CodeTagBinding, "tag binding %d binds key %s to the same resource more than once", i, binding.Key))
+			continue
+		}
+		bound[target] = true
+	}
+
+	return errors.Join(errs...)
+}
+
+// cmekServiceAgentDomains maps each service that can use CMEK to the domain of
+// the Google-managed service agent that must be able to use the key
+var cmekServiceAgentDomains = map[string]string{
+	"Cloud Storage":  "gs-project-accounts.iam.gserviceaccount.com",
+	"Compute Engine": "compute-system.iam.gserviceaccount.com",
+	"Cloud SQL":      "gcp-sa-cloud-sql.iam.gserviceaccount.com",
+}
+
+// cmekServiceAgentWarnings warns when a resource uses a CMEK key but the service
+// agent for that resource type is not granted roles/cloudkms.cryptoKeyEncrypterDecrypter,
+// either through kms.grant_service_agents or a project-level role binding
+func cmekServiceAgentWarnings(cfg *config.Config) []string {
+	if cfg.Kms != nil && cfg.Kms.GrantServiceAgents {
+		return nil
+	}
+
+	// Find the services whose agents already have a project-level grant
+	granted := make(map[string]bool)
+	if cfg.Iam != nil {
+		for _, binding := range cfg.Iam.RoleBindings {
+			if binding.Role != "roles/cloudkms.cryptoKeyEncrypterDecrypter" {
+				continue
+			}
+			for _, member := range binding.Members {
+				for service, domain := range cmekServiceAgentDomains {
+					if strings.HasSuffix(member, "@"+domain) {
+						granted[service] = true
+					}
+				}
+			}
+		}
+	}
+
+	var warnings []string
+	warn := func(service, resource, key string) {
+		if !granted[service] {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s uses CMEK key %s but the %s service agent (service-PROJECT_NUMBER@%s) is not granted roles/cloudkms.cryptoKeyEncrypterDecrypter; set kms.grant_service_agents or add a role binding",
+				resource, key, service, cmekServiceAgentDomains[service]))
+		}
+	}
+
+	if cfg.Storage != nil {
+		for _, bucket := range cfg.Storage.Buckets {
+			if bucket.KmsKey != "" {
+				warn("Cloud Storage", "storage bucket "+bucket.Name, bucket.KmsKey)
+			}
+		}
+	}
+
+	if cfg.Compute != nil {
+		for _, template := range cfg.Compute.InstanceTemplates {
+			if template.KmsKey != "" {
+				warn("Compute Engine", "instance template "+template.Name, template.KmsKey)
+			}
+		}
+	}
+
+	if cfg.Databases != nil {
+		for _, instance := range cfg.Databases.CloudSqlInstances {
+			if instance.KmsKey != "" {
+				warn("Cloud SQL", "Cloud SQL instance "+instance.Name, instance.KmsKey)
+			}
+		}
+	}
+
+	return warnings
+}
+
+// autoscalingWarnings warns about instance groups whose autoscaler can't
+// scale out, which validation allows as a way of draining a group
+func autoscalingWarnings(cfg *config.Config) []string {
+	var warnings []string
+	for _, group := range cfg.GetCompute().GetInstanceGroups() {
+		if group.AutoScaling != nil && group.AutoScaling.Max == 0 {
+			warnings = append(warnings, fmt.Sprintf(
+				"instance group %s has auto scaling max 0, so the autoscaler never runs any instances; raise max or remove the group",
+				group.Name))
+		}
+	}
+	return warnings
+}
+
+// healthCheckPortWarnings warns about load balancers whose health check probes
+// a port that isn't a named port of their backend instance group. Backend
+// services send traffic to a named port, so a health check on another port
+// checks something other than what serves the traffic, and instances may be
+// marked unhealthy, or healthy while traffic fails. Groups without named ports
+// are reported by ValidateConfig.
+func healthCheckPortWarnings(cfg *config.Config) []string {
+	groups := make(map[string]*config.InstanceGroup)
+	for _, group := range cfg.GetCompute().GetInstanceGroups() {
+		groups[group.Name] = group
+	}
+
+	var warnings []string
+	for _, lb := range cfg.LoadBalancers {
+		group, declared := groups[lb.Backend]
+		if lb.HealthCheck == nil || !declared || len(group.NamedPorts) == 0 {
+			continue
+		}
+
+		matched := false
+		ports := make([]string, 0, len(group.NamedPorts))
+		for _, port := range group.NamedPorts {
+			matched = matched || port.Port == lb.HealthCheck.Port
+			ports = append(ports, fmt.Sprintf("%s:%d", port.Name, port.Port))
+		}
+		if !matched {
+			warnings = append(warnings, fmt.Sprintf(
+				"load balancer %s health check probes port %d, which is not a named port of instance group %s (%s); check the port the instances serve",
+				lb.Name, lb.HealthCheck.Port, group.Name, strings.Join(ports, ", ")))
+		}
+	}
+	return warnings
+}
+
+// reservedIPWarnings warns about internal reserved IPs whose address is outside
+// every subnet of the configuration. The subnet may be managed elsewhere, so
+// this isn't an error, but it's more often a typo.
+func reservedIPWarnings(cfg *config.Config) []string {
+	var subnets []*net.IPNet
+	for _, vpc := range cfg.GetNetworking().GetVpcs() {
+		for _, subnet := range vpc.Subnets {
+			if _, ipNet, err := net.ParseCIDR(subnet.Cidr); err == nil {
+				subnets = append(subnets, ipNet)
+			}
+		}
+	}
+
+	var warnings []string
+	for _, ip := range cfg.GetNetworking().GetReservedIps() {
+		address := net.ParseIP(ip.Address)
+		if ip.AddressType != "INTERNAL" || address == nil {
+			continue
+		}
+		inSubnet := false
+		for _, subnet := range subnets {
+			if subnet.Contains(address) {
+				inSubnet = true
+				break
+			}
+		}
+		if !inSubnet {
+			warnings = append(warnings, fmt.Sprintf(
+				"reserved IP %s has internal address %s, which is not in any subnet of the configuration",
+				ip.Name, ip.Address))
+		}
+	}
+	return warnings
+}
+
+// gvnicWarnings warns about GVNIC network interfaces whose image may not
+// support gVNIC. Instance templates can declare the GVNIC guest OS feature;
+// instances rely on the image having it.
+func gvnicWarnings(cfg *config.Config) []string {
+	if cfg.Compute == nil {
+		return nil
+	}
+
+	usesGvnic := func(ifaces []*config.NetworkInterface) bool {
+		for _, iface := range ifaces {
+			if iface.NicType == "GVNIC" {
+				return true
+			}
+		}
+		return false
+	}
+
+	var warnings []string
+	for _, template := range cfg.Compute.InstanceTemplates {
+		if !usesGvnic(template.NetworkInterfaces) {
+			continue
+		}
+		hasFeature := false
+		for _, feature := range template.GuestOsFeatures {
+			if feature == "GVNIC" {
+				hasFeature = true
+			}
+		}
+		if !hasFeature {
+			warnings = append(warnings, fmt.Sprintf(
+				"instance template %s uses a GVNIC network interface but does not list the GVNIC guest OS feature; add it to guest_os_features unless image %s already supports gVNIC",
+				template.Name, template.Image))
+		}
+	}
+	for _, instance := range cfg.Compute.Instances {
+		if usesGvnic(instance.NetworkInterfaces) {
+			warnings = append(warnings, fmt.Sprintf(
+				"instance %s uses a GVNIC network interface; image %s must support gVNIC",
+				instance.Name, instance.Image))
+		}
+	}
+
+	return warnings
+}
+
+// secretValueWarnings warns about secrets whose value is written into the
+// configuration. The value ends up in the generated code as well, while values
+// from a variable or generated by Terraform only live in the Terraform state.
+func secretValueWarnings(cfg *config.Config) []string {
+	var warnings []string
+	for _, secret := range cfg.GetSecretManager().GetSecrets() {
+		switch secret.ValueSource.(type) {
+		case *config.Secret_PlainText, *config.Secret_Base64Value:
+			warnings = append(warnings, fmt.Sprintf(
+				"secret %s has its value in the configuration and generated code; use from_env_var or generate to keep it out of version control",
+				secret.Name))
+		}
+	}
+	return warnings
+}
+
+// defaultQuotas are GCP's default per-project quotas for resource types that
+// commonly run into them. project.quota_limits overrides them for projects that
+// have been granted increases.
+var defaultQuotas = map[string]int{
+	"vpcs":             15,
+	"subnets":          275,
+	"firewall_rules":   200,
+	"service_accounts": 100,
+	"custom_roles":     300,
+}
+
+// serviceAgentPattern matches the account IDs of Google-managed service agents,
+// which live in Google-owned projects rather than in another project of the user
+var serviceAgentPattern = regexp.MustCompile(`^service-[0-9]+$`)
+
+// externalServiceAccountWarnings warns about IAM binding members that are
+// user-managed service accounts of another project. Accounts of this project
+// are checked against iam.service_accounts by ValidateConfig, but accounts of
+// other projects can't be, so a typo there goes unnoticed until apply.
+func externalServiceAccountWarnings(cfg *config.Config) []string {
+	projectId := cfg.GetProject().GetId()
+	if projectId == "" {
+		return nil
+	}
+
+	var warnings []string
+	checkMembers := func(from string, members []string) {
+		for _, member := range members {
+			accountId, domain, ok := strings.Cut(serviceAccountMember(member), "@")
+			project, managed := strings.CutSuffix(domain, ".iam.gserviceaccount.com")
+			if !ok || !managed || project == projectId || serviceAgentPattern.MatchString(accountId) {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf(
+				"%s member %s is a service account of project %s, which this configuration does not manage; check that it exists there",
+				from, member, project))
+		}
+	}
+	for _, binding := range cfg.GetIam().GetRoleBindings() {
+		checkMembers("role binding "+binding.Role, binding.Members)
+	}
+	for _, service := range cfg.GetCloudRun().GetServices() {
+		for _, binding := range service.IamBindings {
+			checkMembers("Cloud Run service "+service.Name+" binding "+binding.Role, binding.Members)
+		}
+	}
+	return warnings
+}
+
+// quotaWarnings warns when the configuration declares more resources of a type
+// than the project's quota allows, so increases can be requested before applying
+func quotaWarnings(cfg *config.Config) []string {
+	counts := make(map[string]int)
+	if cfg.Networking != nil {
+		counts["vpcs"] = len(cfg.Networking.Vpcs)
+		for _, vpc := range cfg.Networking.Vpcs {
+			counts["subnets"] += len(vpc.Subnets)
+		}
+		counts["firewall_rules"] = len(cfg.Networking.FirewallRules)
+	}
+	if cfg.Iam != nil {
+		counts["service_accounts"] = len(cfg.Iam.ServiceAccounts)
+		counts["custom_roles"] = len(cfg.Iam.CustomRoles)
+	}
+
+	resourceTypes := make([]string, 0, len(defaultQuotas))
+	for resourceType := range defaultQuotas {
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+	sort.Strings(resourceTypes)
+
+	var warnings []string
+	for _, resourceType := range resourceTypes {
+		quota, source := defaultQuotas[resourceType], "default quota"
+		if limit, ok := cfg.GetProject().GetQuotaLimits()[resourceType]; ok {
+			quota, source = int(limit), "quota_limits"
+		}
+		if counts[resourceType] > quota {
+			warnings = append(warnings, fmt.Sprintf(
+				"%d %s declared, exceeding the project's %s of %d; request a quota increase before applying and record it in project.quota_limits",
+				counts[resourceType], strings.ReplaceAll(resourceType, "_", " "), source, quota))
+		}
+	}
+
+	return warnings
+}
+
+// validateCloudRun validates Cloud Run configuration
+func validateCloudRun(cloudRun *config.CloudRun) error {
+	var errs []error
+	connectors := make(map[string]*config.CloudRunVpcConnector)
+	for _, connector := range cloudRun.VpcConnectors {
+		if connectors[connector.Name] != nil {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate VPC connector name: %s", connector.Name))
+			continue
+		}
+		connectors[connector.Name] = connector
+
+		if err := validateVpcConnector(connector); err != nil {
+			errs = append(errs, fmt.Errorf("invalid VPC connector %s: %w", connector.Name, err))
+		}
+	}
+
+	serviceNames := make(map[string]bool)
+	for _, service := range cloudRun.Services {
+		if serviceNames[service.Name] {
+			errs = append(errs, errorf(CodeDuplicateName, "duplicate Cloud Run service name: %s", service.Name))
+			continue
+		}
+		serviceNames[service.Name] = true
+
+		if err := validateCloudRunService(service); err != nil {
+			errs = append(errs, fmt.Errorf("invalid Cloud Run service %s: %w", service.Name, err))
+			continue
+		}
+
+		if err := validateCloudRunVpcAccess(service, connectors); err != nil {
+			errs = append(errs, fmt.Errorf("invalid Cloud Run service %s: %w", service.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateVpcConnector validates a Serverless VPC Access connector configuration
+func validateVpcConnector(connector *config.CloudRunVpcConnector) error {
+	if !isValidVpcConnectorName(connector.Name) {
+		return errorf(CodeVpcConnectorConfig, "invalid connector name format: %s (must be 1-25 lowercase letters, numbers, and hyphens, starting with a letter)", connector.Name)
+	}
+
+	// A connector uses either an existing subnet or its own network + range
+	if connector.Subnet != "" {
+		if connector.IpCidrRange != "" || connector.Network != "" {
+			return errorf(CodeVpcConnectorConfig, "subnet is mutually exclusive with network and ip_cidr_range")
+		}
+	} else {
+		if connector.Network == "" || connector.IpCidrRange == "" {
+			return errorf(CodeVpcConnectorConfig, "either subnet or both network and ip_cidr_range must be specified")
+		}
+
+		_, ipNet, err := net.ParseCIDR(connector.IpCidrRange)
+		if err != nil {
+			return errorf(CodeInvalidCIDR, "invalid ip_cidr_range: %s", connector.IpCidrRange)
+		}
+		if ones, _ := ipNet.Mask.Size(); ones != 28 {
+			return errorf(CodeInvalidCIDR, "ip_cidr_range must be a /28, got %s", connector.IpCidrRange)
+		}
+	}
+
+	validMachineTypes := map[string]bool{
+		"f1-micro":      true,
+		"e2-micro":      true,
+		"e2-standard-4": true,
+	}
+
+	if connector.MachineType != "" && !validMachineTypes[connector.MachineType] {
+		return errorf(CodeInvalidValue, "invalid machine type: %s", connector.MachineType)
+	}
+
+	// Unset instance counts fall back to the GCP defaults (min 2, max 10)
+	minInstances := connector.MinInstances
+	if minInstances == 0 {
+		minInstances = 2
+	}
+	if minInstances < 2 {
+		return errorf(CodeVpcConnectorConfig, "min_instances must be at least 2, got %d", minInstances)
+	}
+
+	if connector.MaxInstances != 0 {
+		if connector.MaxInstances < minInstances {
+			return errorf(CodeVpcConnectorConfig, "max_instances (%d) cannot be less than min_instances (%d)", connector.MaxInstances, minInstances)
+		}
+		if connector.MaxInstances > 10 {
+			return errorf(CodeVpcConnectorConfig, "max_instances must be at most 10, got %d", connector.MaxInstances)
+		}
+	}
+
+	return nil
+}
+
+// validateCloudRunService validates a Cloud Run service configuration
+func validateCloudRunService(service *config.CloudRunService) error {
+	if err := validateCloudRunTraffic(service.Name, service.Traffic); err != nil {
+		return err
+	}
+
+	if err := validateCloudRunEnv(service.Config); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// secretVersionPattern matches a Secret Manager version: "latest" or a version number
+var secretVersionPattern = regexp.MustCompile(`^(latest|[1-9][0-9]*)$`)
+
+// validateCloudRunEnv validates a service's environment variables. Each name
+// may be set once, either to a value or from a secret, and secrets are read
+// at a version number or "latest" (the default).
+func validateCloudRunEnv(cfg *config.CloudRunServiceConfig) error {
+	names := make(map[string]bool)
+	for name := range cfg.GetEnvVars() {
+		names[name] = true
+	}
+
+	for i, env := range cfg.GetEnvFromSecrets() {
+		if env.Name == "" {
+			return errorf(CodeCloudRunSecretEnv, "env_from_secrets entry %d: name is required", i)
+		}
+		if names[env.Name] {
+			return errorf(CodeCloudRunSecretEnv, "environment variable %s is set more than once", env.Name)
+		}
+		names[env.Name] = true
+
+		if env.SecretName == "" {
+			return errorf(CodeCloudRunSecretEnv, "environment variable %s: secret_name is required", env.Name)
+		}
+		if env.Version != "" && !secretVersionPattern.MatchString(env.Version) {
+			return errorf(CodeCloudRunSecretEnv, "environment variable %s: invalid secret version: %s (must be \"latest\" or a version number)", env.Name, env.Version)
+		}
+	}
+
+	return nil
+}
+
+// cloudRunSecretWarnings warns about Cloud Run environment variables read from
+// secrets that aren't declared in secret_manager. Such secrets may exist
+// outside the configuration, but a typo goes unnoticed until the service is
+// deployed.
+func cloudRunSecretWarnings(cfg *config.Config) []string {
+	secrets := collectResourceNames(cfg).secrets
+
+	var warnings []string
+	for _, service := range cfg.GetCloudRun().GetServices() {
+		for _, env := range service.GetConfig().GetEnvFromSecrets() {
+			if env.SecretName != "" && !secrets[env.SecretName] {
+				warnings = append(warnings, fmt.Sprintf(
+					"Cloud Run service %s environment variable %s reads secret %s, which is not declared in secret_manager; make sure it exists and the service account can access it",
+					service.Name, env.Name, env.SecretName))
+			}
+		}
+	}
+	return warnings
+}
+
+// validateCloudRunVpcAccess validates a service's VPC access settings. When the
+// connector is declared in the config, it must live in the service's region.
+func validateCloudRunVpcAccess(service *config.CloudRunService, connectors map[string]*config.CloudRunVpcConnector) error {
+	if service.Config == nil || service.Config.VpcAccess == nil {
+		return nil
+	}
+	vpcAccess := service.Config.VpcAccess
+
+	if vpcAccess.Connector == "" {
+		if vpcAccess.Egress != config.VpcEgress_VPC_EGRESS_UNSPECIFIED {
+			return errorf(CodeCloudRunAccess, "vpc_access egress requires a connector")
+		}
+		return nil
+	}
+
+	if service.Config.VpcConnector != "" {
+		return errorf(CodeCloudRunAccess, "vpc_connector and vpc_access.connector are mutually exclusive")
+	}
+
+	connector, declared := connectors[vpcAccess.Connector]
+	if !declared {
+		// External connector, nothing more to check
+		return nil
+	}
+
+	if effectiveRegion(connector.Region) != effectiveRegion(service.Location) {
+		return errorf(CodeCloudRunAccess, "VPC connector %s is in region %s but service is in %s",
+			connector.Name, effectiveRegion(connector.Region), effectiveRegion(service.Location))
+	}
+
+	return nil
+}
+
+// validateCloudRunTraffic validates a service's traffic split: percentages must
+// sum to 100, each revision (or the latest revision) may only be targeted once,
+// revisions must belong to the service, and tags must be unique
+func validateCloudRunTraffic(serviceName string, traffic []*config.CloudRunTraffic) error {
+	if len(traffic) == 0 {
+		return nil
+	}
+
+	total := int32(0)
+	// A revision can be listed again under another tag, e.g. the latest
+	// revision serving all traffic plus a 0% "canary" entry for its tag URL
+	type target struct{ revision, tag string }
+	targets := make(map[target]bool)
+	tags := make(map[string]bool)
+	for i, entry := range traffic {
+		if entry.Percent < 0 || entry.Percent > 100 {
+			return errorf(CodeTrafficSplit, "traffic entry %d: percent must be between 0 and 100, got %d", i, entry.Percent)
+		}
+		total += entry.Percent
+
+		// An empty revision name targets the latest revision
+		revision := entry.RevisionName
+		if targets[target{revision, entry.Tag}] {
+			if revision == "" {
+				return errorf(CodeTrafficSplit, "traffic entry %d: latest revision is targeted more than once", i)
+			}
+			return errorf(CodeTrafficSplit, "traffic entry %d: revision %s is targeted more than once", i, revision)
+		}
+		targets[target{revision, entry.Tag}] = true
+
+		if revision != "" && !strings.HasPrefix(revision, serviceName+"-") {
+			return errorf(CodeTrafficSplit, "traffic entry %d: revision %s does not belong to service %s", i, revision, serviceName)
+		}
+
+		if entry.Tag != "" {
+			if tags[entry.Tag] {
+				return errorf(CodeTrafficSplit, "traffic entry %d: duplicate tag %s", i, entry.Tag)
+			}
+			tags[entry.Tag] = true
+		}
+	}
+
+	if total != 100 {
+		return errorf(CodeTrafficSplit, "traffic percentages must sum to 100, got %d", total)
+	}
+
+	return nil
+}
+
+// validateCrossReferences validates cross-resource references
+func validateCrossReferences(cfg *config.Config) error {
+	var errs []error
+	// Collect all resource names for validation
+	resources := collectResourceNames(cfg)
+
+	// Validate CMEK key references
+	if cfg.Storage != nil {
+		for _, bucket := range cfg.Storage.Buckets {
+			if bucket.KmsKey != "" && !resources.cryptoKeys[bucket.KmsKey] {
+				errs = append(errs, errorf(CodeUnknownReference, "storage bucket %s references unknown crypto key: %s", bucket.Name, bucket.KmsKey))
+			}
+		}
+	}
+
+	if cfg.Compute != nil {
+		for _, template := range cfg.Compute.InstanceTemplates {
+			if template.KmsKey != "" && !resources.cryptoKeys[template.KmsKey] {
+				errs = append(errs, errorf(CodeUnknownReference, "instance template %s references unknown crypto key: %s", template.Name, template.KmsKey))
+			}
+		}
+	}
+
+	if cfg.Databases != nil {
+		for _, instance := range cfg.Databases.CloudSqlInstances {
+			if instance.KmsKey != "" && !resources.cryptoKeys[instance.KmsKey] {
+				errs = append(errs, errorf(CodeUnknownReference, "Cloud SQL instance %s references unknown crypto key: %s", instance.Name, instance.KmsKey))
+			}
+		}
+	}
+
+	// Validate that interfaces naming both a network and a subnetwork name a
+	// subnetwork of that network
+	if cfg.Compute != nil {
+		subnetNetworks := make(map[string]string)
+		if cfg.Networking != nil {
+			for _, vpc := range cfg.Networking.Vpcs {
+				for _, subnet := range vpc.Subnets {
+					subnetNetworks[subnet.Name] = vpc.Name
+				}
+			}
+		}
+
+		checkInterfaces := func(kind, name string, interfaces []*config.NetworkInterface) {
+			for i, iface := range interfaces {
+				network, declared := subnetNetworks[iface.Subnetwork]
+				if iface.Network == "" || !declared || !resources.networks[iface.Network] || network == iface.Network {
+					continue
+				}
+				errs = append(errs, errorf(CodeSubnetNetworkMismatch, "%s %s network interface %d uses subnetwork %s of VPC %s but names network %s", kind, name, i, iface.Subnetwork, network, iface.Network))
+			}
 		}
-		accountIds[sa.AccountId] = true
 
-		if err := validateServiceAccount(sa); err != nil {
-			return fmt.Errorf("invalid service account %s: %w", sa.AccountId, err)
+		for _, template := range cfg.Compute.InstanceTemplates {
+			checkInterfaces("instance template", template.Name, template.NetworkInterfaces)
+		}
+		for _, instance := range cfg.Compute.Instances {
+			checkInterfaces("instance", instance.Name, instance.NetworkInterfaces)
 		}
 	}
 
-	// Validate custom roles
-	roleIds := make(map[string]bool)
-	for _, role := range iam.CustomRoles {
-		if roleIds[role.RoleId] {
-			return fmt.Errorf("duplicate custom role ID: %s", role.RoleId)
+	// Validate log sink destinations
+	for _, sink := range cfg.LogSinks {
+		if bucket := sink.GetDestination().GetStorageBucket(); bucket != "" && !resources.buckets[bucket] {
+			errs = append(errs, errorf(CodeUnknownReference, "log sink %s references unknown storage bucket: %s (use an external destination for buckets managed elsewhere)", sink.Name, bucket))
 		}
-		roleIds[role.RoleId] = true
+	}
 
-		if err := validateCustomRole(role); err != nil {
-			return fmt.Errorf("invalid custom role %s: %w", role.RoleId, err)
+	// Validate Cloud Scheduler Pub/Sub targets
+	if cfg.Scheduler != nil {
+		for _, job := range cfg.Scheduler.Jobs {
+			if topic := job.GetPubsubTarget().GetTopic(); topic != "" && !strings.Contains(topic, "/") && !resources.topics[topic] {
+				errs = append(errs, errorf(CodeUnknownReference, "Cloud Scheduler job %s references unknown Pub/Sub topic: %s (use projects/<project>/topics/<topic> for topics managed elsewhere)", job.Name, topic))
+			}
 		}
 	}
 
-	return nil
-}
+	// Validate Filestore networks
+	if cfg.Filestore != nil {
+		for _, instance := range cfg.Filestore.Instances {
+			if instance.Network != "" && !resources.networks[instance.Network] {
+				errs = append(errs, errorf(CodeUnknownReference, "Filestore instance %s references unknown network: %s", instance.Name, instance.Network))
+			}
+		}
+	}
 
-// validateServiceAccount validates a service account configuration
-func validateServiceAccount(sa *config.ServiceAccount) error {
-	// Validate account ID format
-	if !isValidServiceAccountId(sa.AccountId) {
-		return fmt.Errorf("invalid service account ID format: %s", sa.AccountId)
+	// Validate the networks of private DNS zones
+	if cfg.Dns != nil {
+		for _, zone := range cfg.Dns.ManagedZones {
+			for _, network := range zone.Networks {
+				if !resources.networks[network] {
+					errs = append(errs, errorf(CodeUnknownReference, "managed zone %s references unknown network: %s", zone.Name, network))
+				}
+			}
+		}
 	}
 
-	return nil
-}
+	// Validate the datasets of BigQuery tables
+	if cfg.Bigquery != nil {
+		for _, table := range cfg.Bigquery.Tables {
+			if table.Dataset != "" && !resources.datasets[table.Dataset] {
+				errs = append(errs, errorf(CodeUnknownReference, "table %s references unknown dataset: %s", table.TableId, table.Dataset))
+			}
+		}
+	}
 
-// validateCustomRole validates a custom role configuration
-func validateCustomRole(role *config.CustomRole) error {
-	// Validate that permissions are not empty
-	if len(role.Permissions) == 0 {
-		return fmt.Errorf("custom role must have at least one permission")
+	// Validate the topics and service accounts of Cloud Functions
+	if cfg.CloudFunctions != nil {
+		for _, function := range cfg.CloudFunctions.Functions {
+			if topic := function.GetEventTrigger().GetPubsubTopic(); topic != "" && !strings.Contains(topic, "/") && !resources.topics[topic] {
+				errs = append(errs, errorf(CodeUnknownReference, "Cloud Function %s references unknown Pub/Sub topic: %s (use projects/<project>/topics/<topic> for topics managed elsewhere)", function.Name, topic))
+				continue
+			}
+			for _, email := range []string{function.ServiceAccount, function.GetEventTrigger().GetServiceAccount()} {
+				if accountId := projectServiceAccountId(email, cfg.GetProject().GetId()); accountId != "" && !resources.serviceAccounts[accountId] {
+					errs = append(errs, errorf(CodeUnknownReference, "Cloud Function %s references unknown service account: %s (declare %s in iam.service_accounts)", function.Name, email, accountId))
+				}
+			}
+		}
 	}
 
-	// Validate stage values
-	validStages := map[string]bool{
-		"ALPHA":      true,
-		"BETA":       true,
-		"GA":         true,
-		"DEPRECATED": true,
+	// Validate GKE networks, subnets, secondary ranges, and node service accounts
+	if cfg.Gke != nil {
+		subnets := make(map[string]*config.Subnet)
+		subnetNetworks := make(map[string]string)
+		if cfg.Networking != nil {
+			for _, vpc := range cfg.Networking.Vpcs {
+				for _, subnet := range vpc.Subnets {
+					subnets[subnet.Name] = subnet
+					subnetNetworks[subnet.Name] = vpc.Name
+				}
+			}
+		}
+
+		for _, cluster := range cfg.Gke.Clusters {
+			if cluster.Network != "" && !resources.networks[cluster.Network] {
+				errs = append(errs, errorf(CodeUnknownReference, "GKE cluster %s references unknown network: %s", cluster.Name, cluster.Network))
+				continue
+			}
+			if cluster.Subnetwork != "" && !resources.subnets[cluster.Subnetwork] {
+				errs = append(errs, errorf(CodeUnknownReference, "GKE cluster %s references unknown subnetwork: %s", cluster.Name, cluster.Subnetwork))
+				continue
+			}
+			if network, declared := subnetNetworks[cluster.Subnetwork]; declared && cluster.Network != "" && network != cluster.Network {
+				errs = append(errs, errorf(CodeSubnetNetworkMismatch, "GKE cluster %s uses subnetwork %s of VPC %s but names network %s", cluster.Name, cluster.Subnetwork, network, cluster.Network))
+				continue
+			}
+
+			if subnet := subnets[cluster.Subnetwork]; subnet != nil {
+				ranges := make(map[string]bool)
+				for _, secondary := range subnet.SecondaryRanges {
+					ranges[secondary.RangeName] = true
+				}
+				for _, name := range []string{cluster.PodsSecondaryRange, cluster.ServicesSecondaryRange} {
+					if name != "" && !ranges[name] {
+						errs = append(errs, errorf(CodeGkeSecondaryRange, "GKE cluster %s references secondary range %s, which is not declared on subnetwork %s", cluster.Name, name, cluster.Subnetwork))
+					}
+				}
+			}
+
+			for _, pool := range cluster.NodePools {
+				if pool.ServiceAccount != "" && !resources.serviceAccounts[pool.ServiceAccount] {
+					errs = append(errs, errorf(CodeUnknownReference, "GKE node pool %s/%s references unknown service account: %s", cluster.Name, pool.Name, pool.ServiceAccount))
+				}
+			}
+		}
 	}
 
-	if role.Stage != "" && !validStages[role.Stage] {
-		return fmt.Errorf("invalid stage: %s", role.Stage)
+	// Validate Cloud Run service accounts of this project, which are given by email
+	if cfg.CloudRun != nil {
+		for _, service := range cfg.CloudRun.Services {
+			email := service.GetConfig().GetServiceAccount()
+			if accountId := projectServiceAccountId(email, cfg.GetProject().GetId()); accountId != "" && !resources.serviceAccounts[accountId] {
+				errs = append(errs, errorf(CodeUnknownReference, "Cloud Run service %s references unknown service account: %s (declare %s in iam.service_accounts)", service.Name, email, accountId))
+			}
+		}
 	}
 
-	return nil
-}
+	// Validate service account members of IAM bindings that belong to this
+	// project; accounts of other projects are reported by Warnings
+	checkMembers := func(from string, members []string) {
+		for _, member := range members {
+			if accountId := projectServiceAccountId(serviceAccountMember(member), cfg.GetProject().GetId()); accountId != "" && !resources.serviceAccounts[accountId] {
+				errs = append(errs, errorf(CodeUnknownReference, "%s references unknown service account: %s (declare %s in iam.service_accounts)", from, member, accountId))
+			}
+		}
+	}
+	for _, binding := range cfg.GetIam().GetRoleBindings() {
+		checkMembers("role binding "+binding.Role, binding.Members)
+	}
+	for _, service := range cfg.GetCloudRun().GetServices() {
+		for _, binding := range service.IamBindings {
+			checkMembers("Cloud Run service "+service.Name+" binding "+binding.Role, binding.Members)
+		}
+	}
 
-// validateStorage validates storage configuration
-func validateStorage(storage *config.Storage) error {
-	bucketNames := make(map[string]bool)
-	
-	for _, bucket := range storage.Buckets {
-		if bucketNames[bucket.Name] {
-			return fmt.Errorf("duplicate bucket name: %s", bucket.Name)
+	// Named ports of each instance group, which backend services route to
+	namedPorts := make(map[string]map[string]bool)
+	for _, group := range cfg.GetCompute().GetInstanceGroups() {
+		namedPorts[group.Name] = make(map[string]bool)
+		for _, port := range group.NamedPorts {
+			namedPorts[group.Name][port.Name] = true
 		}
-		bucketNames[bucket.Name] = true
+	}
 
-		if err := validateStorageBucket(bucket); err != nil {
-			return fmt.Errorf("invalid storage bucket %s: %w", bucket.Name, err)
+	// Validate load balancer references
+	for _, lb := range cfg.LoadBalancers {
+		// Validate IP reference
+		if lb.Ip != "" && !resources.reservedIPs[lb.Ip] {
+			errs = append(errs, errorf(CodeUnknownReference, "load balancer %s references unknown reserved IP: %s", lb.Name, lb.Ip))
+			continue
+		}
+
+		// Validate backend reference
+		if !resources.instanceGroups[lb.Backend] {
+			errs = append(errs, errorf(CodeUnknownReference, "load balancer %s references unknown backend: %s", lb.Name, lb.Backend))
+			continue
+		}
+
+		// Backend services send traffic to a named port of the group
+		portName := lb.PortName
+		if portName == "" {
+			portName = "http"
+		}
+		if ports, ok := namedPorts[lb.Backend]; ok && !ports[portName] {
+			errs = append(errs, errorf(CodeBackendNamedPort, "load balancer %s routes to named port %s, which instance group %s does not declare in named_ports", lb.Name, portName, lb.Backend))
 		}
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
-// validateStorageBucket validates a storage bucket configuration
-func validateStorageBucket(bucket *config.StorageBucket) error {
-	// Validate bucket name format (GCS-specific rules)
-	if !isValidBucketName(bucket.Name) {
-		return fmt.Errorf("invalid bucket name format: %s", bucket.Name)
+// validateDisabledReferences reports references from enabled resources to
+// resources that exist in the configuration but are disabled. cfg must already
+// have disabled resources removed; disabled holds the names of those resources.
+// References among disabled resources are not checked since none of them are generated.
+func validateDisabledReferences(cfg *config.Config, disabled *resourceNames) error {
+	check := func(from, kind, name string, names map[string]bool) error {
+		if name != "" && names[name] {
+			return errorf(CodeDisabledReference, "%s references %s %s, which is disabled", from, kind, name)
+		}
+		return nil
+	}
+	// Report every offending reference
+	var errs []error
+	add := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	checkInterfaces := func(from string, ifaces []*config.NetworkInterface) {
+		for _, iface := range ifaces {
+			add(check(from, "network", iface.Network, disabled.networks))
+			add(check(from, "subnet", iface.Subnetwork, disabled.subnets))
+			for _, access := range iface.AccessConfigs {
+				add(check(from, "reserved IP", access.NatIp, disabled.reservedIPs))
+			}
+		}
 	}
 
-	// Validate storage class
-	validClasses := map[string]bool{
-		"STANDARD": true,
-		"NEARLINE": true,
-		"COLDLINE": true,
-		"ARCHIVE":  true,
+	if cfg.Networking != nil {
+		for _, rule := range cfg.Networking.FirewallRules {
+			add(check("firewall rule "+rule.Name, "network", rule.Network, disabled.networks))
+		}
+		for _, nat := range cfg.Networking.NatGateways {
+			for _, ip := range nat.NatIps {
+				add(check("NAT gateway "+nat.Name, "reserved IP", ip, disabled.reservedIPs))
+			}
+			for _, subnet := range nat.SourceSubnetworkIpRangesToNat {
+				add(check("NAT gateway "+nat.Name, "subnet", subnet.Name, disabled.subnets))
+			}
+		}
+		for _, peering := range cfg.Networking.Peerings {
+			add(check("VPC peering "+peering.Name, "network", peering.Network, disabled.networks))
+			add(check("VPC peering "+peering.Name, "network", peering.PeerNetwork, disabled.networks))
+		}
 	}
 
-	if bucket.StorageClass != "" && !validClasses[bucket.StorageClass] {
-		return fmt.Errorf("invalid storage class: %s", bucket.StorageClass)
+	if cfg.Compute != nil {
+		for _, template := range cfg.Compute.InstanceTemplates {
+			from := "instance template " + template.Name
+			checkInterfaces(from, template.NetworkInterfaces)
+			add(check(from, "crypto key", template.KmsKey, disabled.cryptoKeys))
+		}
+		for _, group := range cfg.Compute.InstanceGroups {
+			add(check("instance group "+group.Name, "instance template", group.Template, disabled.instanceTemplates))
+			for _, version := range group.Versions {
+				add(check("instance group "+group.Name, "instance template", version.Template, disabled.instanceTemplates))
+			}
+		}
+		for _, group := range cfg.Compute.NodeGroups {
+			add(check("node group "+group.Name, "node template", group.NodeTemplate, disabled.nodeTemplates))
+		}
+		for _, instance := range cfg.Compute.Instances {
+			from := "instance " + instance.Name
+			checkInterfaces(from, instance.NetworkInterfaces)
+			for _, affinity := range instance.NodeAffinities {
+				if affinity.Key == nodeGroupAffinityKey {
+					for _, group := range affinity.Values {
+						add(check(from, "node group", group, disabled.nodeGroups))
+					}
+				}
+			}
+		}
+		for _, template := range cfg.Compute.InstanceTemplates {
+			for _, affinity := range template.NodeAffinities {
+				if affinity.Key == nodeGroupAffinityKey {
+					for _, group := range affinity.Values {
+						add(check("instance template "+template.Name, "node group", group, disabled.nodeGroups))
+					}
+				}
+			}
+		}
 	}
 
-	return nil
-}
+	for _, lb := range cfg.LoadBalancers {
+		add(check("load balancer "+lb.Name, "reserved IP", lb.Ip, disabled.reservedIPs))
+		add(check("load balancer "+lb.Name, "instance group", lb.Backend, disabled.instanceGroups))
+	}
 
-// validateCrossReferences validates cross-resource references
-func validateCrossReferences(cfg *config.Config) error {
-	// Collect all resource names for validation
-	resources := collectResourceNames(cfg)
+	if cfg.Iam != nil {
+		for _, binding := range cfg.Iam.RoleBindings {
+			for _, member := range binding.Members {
+				add(check("role binding "+binding.Role, "service account", projectServiceAccountId(serviceAccountMember(member), cfg.GetProject().GetId()), disabled.serviceAccounts))
+			}
+		}
+	}
 
-	// Validate load balancer references
-	for _, lb := range cfg.LoadBalancers {
-		// Validate IP reference
-		if lb.Ip != "" && !resources.reservedIPs[lb.Ip] {
-			return fmt.Errorf("load balancer %s references unknown reserved IP: %s", lb.Name, lb.Ip)
+	if cfg.Storage != nil {
+		for _, bucket := range cfg.Storage.Buckets {
+			add(check("storage bucket "+bucket.Name, "crypto key", bucket.KmsKey, disabled.cryptoKeys))
 		}
+	}
 
-		// Validate backend reference
-		if !resources.instanceGroups[lb.Backend] {
-			return fmt.Errorf("load balancer %s references unknown backend: %s", lb.Name, lb.Backend)
+	if cfg.CloudRun != nil {
+		for _, connector := range cfg.CloudRun.VpcConnectors {
+			add(check("VPC connector "+connector.Name, "network", connector.Network, disabled.networks))
+			add(check("VPC connector "+connector.Name, "subnet", connector.Subnet, disabled.subnets))
+		}
+		for _, service := range cfg.CloudRun.Services {
+			add(check("Cloud Run service "+service.Name, "VPC connector", service.GetConfig().GetVpcAccess().GetConnector(), disabled.vpcConnectors))
+			add(check("Cloud Run service "+service.Name, "service account", projectServiceAccountId(service.GetConfig().GetServiceAccount(), cfg.GetProject().GetId()), disabled.serviceAccounts))
+			for _, env := range service.GetConfig().GetEnvFromSecrets() {
+				add(check("Cloud Run service "+service.Name+" env var "+env.Name, "secret", env.SecretName, disabled.secrets))
+			}
+			for _, binding := range service.IamBindings {
+				for _, member := range binding.Members {
+					add(check("Cloud Run service "+service.Name+" binding "+binding.Role, "service account", projectServiceAccountId(serviceAccountMember(member), cfg.GetProject().GetId()), disabled.serviceAccounts))
+				}
+			}
 		}
 	}
 
-	return nil
+	if cfg.Databases != nil {
+		for _, instance := range cfg.Databases.CloudSqlInstances {
+			add(check("Cloud SQL instance "+instance.Name, "crypto key", instance.KmsKey, disabled.cryptoKeys))
+			add(check("Cloud SQL instance "+instance.Name, "master instance", instance.MasterInstanceName, disabled.sqlInstances))
+			add(check("Cloud SQL instance "+instance.Name, "network", instance.GetNetwork().GetPrivateNetwork().GetPrivateNetwork(), disabled.networks))
+		}
+	}
+
+	if cfg.Monitoring != nil {
+		for _, policy := range cfg.Monitoring.AlertPolicies {
+			for _, channel := range policy.NotificationChannels {
+				add(check("alert policy "+policy.Name, "notification channel", channel, disabled.notificationChannels))
+			}
+			for _, condition := range policy.Conditions {
+				add(check("alert policy "+policy.Name, "log metric", condition.LogMetric, disabled.logMetrics))
+			}
+		}
+	}
+
+	for _, sink := range cfg.LogSinks {
+		add(check("log sink "+sink.Name, "storage bucket", sink.GetDestination().GetStorageBucket(), disabled.buckets))
+	}
+
+	if cfg.Scheduler != nil {
+		for _, job := range cfg.Scheduler.Jobs {
+			add(check("Cloud Scheduler job "+job.Name, "Pub/Sub topic", job.GetPubsubTarget().GetTopic(), disabled.topics))
+		}
+	}
+
+	if cfg.Filestore != nil {
+		for _, instance := range cfg.Filestore.Instances {
+			add(check("Filestore instance "+instance.Name, "network", instance.Network, disabled.networks))
+		}
+	}
+
+	if cfg.Gke != nil {
+		for _, cluster := range cfg.Gke.Clusters {
+			from := "GKE cluster " + cluster.Name
+			add(check(from, "network", cluster.Network, disabled.networks))
+			add(check(from, "subnet", cluster.Subnetwork, disabled.subnets))
+			for _, pool := range cluster.NodePools {
+				add(check("GKE node pool "+cluster.Name+"/"+pool.Name, "service account", pool.ServiceAccount, disabled.serviceAccounts))
+			}
+		}
+	}
+
+	if cfg.Dns != nil {
+		for _, zone := range cfg.Dns.ManagedZones {
+			for _, network := range zone.Networks {
+				add(check("managed zone "+zone.Name, "network", network, disabled.networks))
+			}
+		}
+	}
+
+	if cfg.Bigquery != nil {
+		for _, table := range cfg.Bigquery.Tables {
+			add(check("table "+table.TableId, "dataset", table.Dataset, disabled.datasets))
+		}
+	}
+
+	if cfg.CloudFunctions != nil {
+		for _, function := range cfg.CloudFunctions.Functions {
+			from := "Cloud Function " + function.Name
+			add(check(from, "storage bucket", function.SourceBucket, disabled.buckets))
+			add(check(from, "Pub/Sub topic", function.GetEventTrigger().GetPubsubTopic(), disabled.topics))
+			add(check(from, "service account", projectServiceAccountId(function.ServiceAccount, cfg.GetProject().GetId()), disabled.serviceAccounts))
+			add(check(from, "service account", projectServiceAccountId(function.GetEventTrigger().GetServiceAccount(), cfg.GetProject().GetId()), disabled.serviceAccounts))
+		}
+	}
+
+	if cfg.ResourceTags != nil {
+		for i, binding := range cfg.ResourceTags.Bindings {
+			from := fmt.Sprintf("tag binding %d", i)
+			add(check(from, "tag key", binding.Key, disabled.tagKeys))
+			add(check(from, "tag value", binding.Key+"/"+binding.Value, disabled.tagValues))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// disabledResourceNames returns the names of resources declared in cfg that are
+// absent from enabledCfg, i.e. resources that are disabled or nested in a
+// disabled resource
+func disabledResourceNames(cfg, enabledCfg *config.Config) *resourceNames {
+	all := collectResourceNames(cfg)
+	enabled := collectResourceNames(enabledCfg)
+
+	difference := func(all, enabled map[string]bool) map[string]bool {
+		result := make(map[string]bool)
+		for name := range all {
+			if !enabled[name] {
+				result[name] = true
+			}
+		}
+		return result
+	}
+
+	return &resourceNames{
+		reservedIPs:          difference(all.reservedIPs, enabled.reservedIPs),
+		networks:             difference(all.networks, enabled.networks),
+		subnets:              difference(all.subnets, enabled.subnets),
+		instanceTemplates:    difference(all.instanceTemplates, enabled.instanceTemplates),
+		instanceGroups:       difference(all.instanceGroups, enabled.instanceGroups),
+		nodeTemplates:        difference(all.nodeTemplates, enabled.nodeTemplates),
+		nodeGroups:           difference(all.nodeGroups, enabled.nodeGroups),
+		serviceAccounts:      difference(all.serviceAccounts, enabled.serviceAccounts),
+		cryptoKeys:           difference(all.cryptoKeys, enabled.cryptoKeys),
+		buckets:              difference(all.buckets, enabled.buckets),
+		vpcConnectors:        difference(all.vpcConnectors, enabled.vpcConnectors),
+		notificationChannels: difference(all.notificationChannels, enabled.notificationChannels),
+		logMetrics:           difference(all.logMetrics, enabled.logMetrics),
+		sqlInstances:         difference(all.sqlInstances, enabled.sqlInstances),
+		tagKeys:              difference(all.tagKeys, enabled.tagKeys),
+		tagValues:            difference(all.tagValues, enabled.tagValues),
+		topics:               difference(all.topics, enabled.topics),
+		datasets:             difference(all.datasets, enabled.datasets),
+		secrets:              difference(all.secrets, enabled.secrets),
+	}
 }
 
 // resourceNames holds collections of resource names for cross-reference validation
 type resourceNames struct {
-	reservedIPs     map[string]bool
-	networks        map[string]bool
-	subnets         map[string]bool
-	instanceGroups  map[string]bool
-	serviceAccounts map[string]bool
+	reservedIPs          map[string]bool
+	networks             map[string]bool
+	subnets              map[string]bool
+	instanceTemplates    map[string]bool
+	instanceGroups       map[string]bool
+	nodeTemplates        map[string]bool
+	nodeGroups           map[string]bool
+	serviceAccounts      map[string]bool
+	cryptoKeys           map[string]bool
+	buckets              map[string]bool
+	vpcConnectors        map[string]bool
+	notificationChannels map[string]bool
+	logMetrics           map[string]bool
+	sqlInstances         map[string]bool
+	tagKeys              map[string]bool
+	// tagValues are keyed by "<key>/<value>"
+	tagValues map[string]bool
+	topics    map[string]bool
+	datasets  map[string]bool
+	secrets   map[string]bool
 }
 
 // collectResourceNames collects all resource names from the configuration
 func collectResourceNames(cfg *config.Config) *resourceNames {
 	resources := &resourceNames{
-		reservedIPs:     make(map[string]bool),
-		networks:        make(map[string]bool),
-		subnets:         make(map[string]bool),
-		instanceGroups:  make(map[string]bool),
-		serviceAccounts: make(map[string]bool),
+		reservedIPs:          make(map[string]bool),
+		networks:             make(map[string]bool),
+		subnets:              make(map[string]bool),
+		instanceTemplates:    make(map[string]bool),
+		instanceGroups:       make(map[string]bool),
+		nodeTemplates:        make(map[string]bool),
+		nodeGroups:           make(map[string]bool),
+		serviceAccounts:      make(map[string]bool),
+		cryptoKeys:           make(map[string]bool),
+		buckets:              make(map[string]bool),
+		vpcConnectors:        make(map[string]bool),
+		notificationChannels: make(map[string]bool),
+		logMetrics:           make(map[string]bool),
+		sqlInstances:         make(map[string]bool),
+		tagKeys:              make(map[string]bool),
+		tagValues:            make(map[string]bool),
+		topics:               make(map[string]bool),
+		datasets:             make(map[string]bool),
+		secrets:              make(map[string]bool),
 	}
 
 	// Collect networking resources
@@ -506,9 +4246,18 @@ func collectResourceNames(cfg *config.Config) *resourceNames {
 
 	// Collect compute resources
 	if cfg.Compute != nil {
+		for _, template := range cfg.Compute.InstanceTemplates {
+			resources.instanceTemplates[template.Name] = true
+		}
 		for _, group := range cfg.Compute.InstanceGroups {
 			resources.instanceGroups[group.Name] = true
 		}
+		for _, template := range cfg.Compute.NodeTemplates {
+			resources.nodeTemplates[template.Name] = true
+		}
+		for _, group := range cfg.Compute.NodeGroups {
+			resources.nodeGroups[group.Name] = true
+		}
 	}
 
 	// Collect IAM resources
@@ -518,6 +4267,75 @@ func collectResourceNames(cfg *config.Config) *resourceNames {
 		}
 	}
 
+	// Collect storage resources
+	if cfg.Storage != nil {
+		for _, bucket := range cfg.Storage.Buckets {
+			resources.buckets[bucket.Name] = true
+		}
+	}
+
+	// Collect Cloud Run resources
+	if cfg.CloudRun != nil {
+		for _, connector := range cfg.CloudRun.VpcConnectors {
+			resources.vpcConnectors[connector.Name] = true
+		}
+	}
+
+	// Collect monitoring resources
+	if cfg.Monitoring != nil {
+		for _, channel := range cfg.Monitoring.NotificationChannels {
+			resources.notificationChannels[channel.Name] = true
+		}
+		for _, metric := range cfg.Monitoring.LogMetrics {
+			resources.logMetrics[metric.Name] = true
+		}
+	}
+
+	// Collect resource tags
+	if cfg.ResourceTags != nil {
+		for _, key := range cfg.ResourceTags.Keys {
+			resources.tagKeys[key.ShortName] = true
+			for _, value := range key.Values {
+				resources.tagValues[key.ShortName+"/"+value.ShortName] = true
+			}
+		}
+	}
+
+	// Collect Pub/Sub resources
+	if cfg.Pubsub != nil {
+		for _, topic := range cfg.Pubsub.Topics {
+			resources.topics[topic.Name] = true
+		}
+	}
+
+	// Collect BigQuery resources
+	if cfg.Bigquery != nil {
+		for _, dataset := range cfg.Bigquery.Datasets {
+			resources.datasets[dataset.DatasetId] = true
+		}
+	}
+
+	// Collect Cloud SQL instances
+	if cfg.Databases != nil {
+		for _, instance := range cfg.Databases.CloudSqlInstances {
+			resources.sqlInstances[instance.Name] = true
+		}
+	}
+
+	// Collect Secret Manager secrets
+	for _, secret := range cfg.GetSecretManager().GetSecrets() {
+		resources.secrets[secret.Name] = true
+	}
+
+	// Collect KMS resources
+	if cfg.Kms != nil {
+		for _, keyRing := range cfg.Kms.KeyRings {
+			for _, key := range keyRing.CryptoKeys {
+				resources.cryptoKeys[key.Name] = true
+			}
+		}
+	}
+
 	return resources
 }
 
@@ -542,15 +4360,62 @@ func isValidCIDR(cidr string) bool {
 	return err == nil
 }
 
+// cidrsOverlap reports whether two CIDR ranges share any address, by comparing
+// the first and last address of each. Ranges of different IP versions and
+// invalid ranges never overlap.
 func cidrsOverlap(cidr1, cidr2 string) bool {
-	_, net1, err1 := net.ParseCIDR(cidr1)
-	_, net2, err2 := net.ParseCIDR(cidr2)
-	
-	if err1 != nil || err2 != nil {
+	start1, end1, ok1 := cidrBounds(cidr1)
+	start2, end2, ok2 := cidrBounds(cidr2)
+
+	if !ok1 || !ok2 || len(start1) != len(start2) {
 		return false
 	}
-	
-	return net1.Contains(net2.IP) || net2.Contains(net1.IP)
+
+	return bytes.Compare(start1, end2) <= 0 && bytes.Compare(start2, end1) <= 0
+}
+
+// cidrBounds returns the first and last address of a CIDR range, 4 bytes long
+// for IPv4 and 16 for IPv6. Host bits set in the input are ignored.
+func cidrBounds(cidr string) (net.IP, net.IP, bool) {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	start := ipNet.IP
+	if v4 := start.To4(); v4 != nil {
+		start = v4
+	}
+	if len(start) != len(ipNet.Mask) {
+		return nil, nil, false
+	}
+
+	end := make(net.IP, len(start))
+	for i := range start {
+		end[i] = start[i] | ^ipNet.Mask[i]
+	}
+	return start, end, true
+}
+
+// projectServiceAccountId returns the account ID of a service account email
+// of the given project (<account-id>@<project>.iam.gserviceaccount.com), or ""
+// for other emails, such as accounts of other projects or Google-managed ones
+func projectServiceAccountId(email, projectId string) string {
+	accountId, domain, ok := strings.Cut(email, "@")
+	if !ok || projectId == "" || domain != projectId+".iam.gserviceaccount.com" {
+		return ""
+	}
+	return accountId
+}
+
+// serviceAccountMember returns the email of an IAM member of the form
+// serviceAccount:<email>, or "" for other members
+func serviceAccountMember(member string) string {
+	email, ok := strings.CutPrefix(member, "serviceAccount:")
+	if !ok {
+		return ""
+	}
+	return email
 }
 
 func isValidServiceAccountId(id string) bool {
@@ -561,6 +4426,60 @@ func isValidServiceAccountId(id string) bool {
 	return match
 }
 
+var (
+	topicNamePattern        = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9._~+%-]{2,254}$`)
+	topicIDPattern          = regexp.MustCompile(`^projects/[^/]+/topics/[^/]+$`)
+	schedulerJobNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,500}$`)
+	taskQueueNamePattern    = regexp.MustCompile(`^[a-zA-Z0-9-]{1,100}$`)
+	filestoreNamePattern    = regexp.MustCompile(`^[a-z]([-a-z0-9]{0,61}[a-z0-9])?$`)
+	gkeNamePattern          = regexp.MustCompile(`^[a-z]([-a-z0-9]{0,38}[a-z0-9])?$`)
+	dnsZoneNamePattern      = regexp.MustCompile(`^[a-z]([-a-z0-9]{0,61}[a-z0-9])?$`)
+	dnsNamePattern          = regexp.MustCompile(`^([a-zA-Z0-9]([-a-zA-Z0-9]{0,61}[a-zA-Z0-9])?\.)+$`)
+	functionNamePattern     = regexp.MustCompile(`^[a-z]([-a-z0-9]{0,61}[a-z0-9])?$`)
+	functionRuntimePattern  = regexp.MustCompile(`^[a-z]+[0-9]+$`)
+	functionMemoryPattern   = regexp.MustCompile(`^[1-9][0-9]*(M|Mi|G|Gi)$`)
+	// VPC peering names follow the Compute Engine naming rules
+	peeringNamePattern = regexp.MustCompile(`^[a-z]([-a-z0-9]{0,61}[a-z0-9])?$`)
+	// Named ports of instance groups too
+	namedPortNamePattern = regexp.MustCompile(`^[a-z]([-a-z0-9]{0,61}[a-z0-9])?$`)
+	// Artifact Registry repository IDs follow the same rules as function names
+	artifactRepositoryIdPattern = regexp.MustCompile(`^[a-z]([-a-z0-9]{0,61}[a-z0-9])?$`)
+	// dnsRecordNamePattern also allows a leading wildcard label and
+	// underscores, as in _dmarc.example.com.
+	dnsRecordNamePattern = regexp.MustCompile(`^(\*\.)?([a-zA-Z0-9_]([-a-zA-Z0-9_]{0,61}[a-zA-Z0-9_])?\.)+$`)
+	// BigQuery table IDs also allow hyphens, and field names cannot start
+	// with a digit. IDs are limited to maxBigQueryIdLength separately, since
+	// regexp repeat counts stop at 1000.
+	bigQueryDatasetIdPattern = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+	bigQueryTableIdPattern   = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+	bigQueryFieldNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]{0,299}$`)
+	// filestoreShareNamePattern uses the 16 character limit of the basic tiers
+	filestoreShareNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,15}$`)
+	// versionConstraintPattern matches one comma-separated part of a Terraform version constraint
+	versionConstraintPattern = regexp.MustCompile(`^(=|!=|>|>=|<|<=|~>)?\s*v?\d+(\.\d+){0,2}(-[0-9A-Za-z.-]+)?$`)
+)
+
+// isValidVersionConstraint reports whether s is a Terraform version
+// constraint: comma-separated versions, each with an optional operator
+func isValidVersionConstraint(s string) bool {
+	for _, part := range strings.Split(s, ",") {
+		if !versionConstraintPattern.MatchString(strings.TrimSpace(part)) {
+			return false
+		}
+	}
+	return true
+}
+
+func isValidTagShortName(name string) bool {
+	match, _ := regexp.MatchString(`^[a-zA-Z0-9]([a-zA-Z0-9_.-]{0,61}[a-zA-Z0-9])?$`, name)
+	return match
+}
+
+func isValidVpcConnectorName(name string) bool {
+	match, _ := regexp.MatchString(`^[a-z][-a-z0-9]{0,23}[a-z0-9]$`, name)
+	return match
+}
+
 func isValidBucketName(name string) bool {
 	if len(name) < 3 || len(name) > 63 {
 		return false
@@ -568,4 +4487,44 @@ func isValidBucketName(name string) bool {
 	// Basic validation - GCS has more complex rules
 	match, _ := regexp.MatchString(`^[a-z0-9][a-z0-9\-_.]*[a-z0-9]$`, name)
 	return match
-}
\ No newline at end of file
+}
+
+// effectiveRegion returns the region a resource is created in, treating an
+// unspecified region as the provider default (us-central1)
+func effectiveRegion(region config.Region) config.Region {
+	if region == config.Region_REGION_UNSPECIFIED {
+		return config.Region_REGION_US_CENTRAL1
+	}
+	return region
+}
+
+// validateLabels checks label keys and values against GCP's label constraints
+func validateLabels(labels map[string]string) error {
+	if len(labels) > maxLabels {
+		return errorf(CodeInvalidLabel, "%d labels exceed the maximum of %d", len(labels), maxLabels)
+	}
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !labelKeyPattern.MatchString(key) {
+			return errorf(CodeInvalidLabel, "invalid label key: %q (must be 1-63 lowercase letters, numbers, underscores, and hyphens, starting with a letter)", key)
+		}
+		if !labelValuePattern.MatchString(labels[key]) {
+			return errorf(CodeInvalidLabel, "invalid value for label %s: %q (must be at most 63 lowercase letters, numbers, underscores, and hyphens)", key, labels[key])
+		}
+	}
+	return nil
+}
+
+// validateDescription checks that a resource description fits within GCP's length limit
+func validateDescription(description string, maxLength int) error {
+	if length := utf8.RuneCountInString(description); length > maxLength {
+		return errorf(CodeDescriptionTooLong, "description is %d characters, exceeds maximum of %d", length, maxLength)
+	}
+	return nil
+}
@@ -1,9 +1,15 @@
 package validator
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
 	"testing"
 
 	"custoodian/pkg/config"
+
+	"google.golang.org/protobuf/proto"
 )
 
 func TestValidateConfig(t *testing.T) {
@@ -54,6 +60,30 @@ func TestValidateProject(t *testing.T) {
 	if err != nil {
 		t.Errorf("Expected no error for valid project, got: %v", err)
 	}
+	// Test default service account actions
+	project.DefaultServiceAccountsAction = "DEPRIVILEGE"
+	if err := validateProject(project); err != nil {
+		t.Errorf("Expected no error for DEPRIVILEGE action, got: %v", err)
+	}
+	project.DefaultServiceAccountsAction = "REMOVE"
+	if err := validateProject(project); err == nil {
+		t.Error("Expected error for unknown default service account action, got nil")
+	}
+	project.DefaultServiceAccountsAction = ""
+
+	// Test billing project
+	project.BillingProject = "billing-project-123"
+	if err := validateProject(project); CodeOf(err) != CodeInvalidValue {
+		t.Errorf("Expected %s error for billing_project without user_project_override, got: %v", CodeInvalidValue, err)
+	}
+	project.UserProjectOverride = true
+	if err := validateProject(project); err != nil {
+		t.Errorf("Expected no error for billing_project, got: %v", err)
+	}
+	project.BillingProject = "Billing_Project"
+	if err := validateProject(project); CodeOf(err) != CodeInvalidProjectID {
+		t.Errorf("Expected %s error for invalid billing_project, got: %v", CodeInvalidProjectID, err)
+	}
 }
 
 func TestIsValidGCPProjectID(t *testing.T) {
@@ -78,3 +108,2630 @@ func TestIsValidGCPProjectID(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateFirewallRuleProtocols(t *testing.T) {
+	tests := []struct {
+		name  string
+		allow []*config.FirewallAllow
+		deny  []*config.FirewallDeny
+		valid bool
+	}{
+		{
+			name:  "distinct protocols",
+			allow: []*config.FirewallAllow{{Protocol: "tcp", Ports: []string{"80"}}, {Protocol: "udp"}},
+			valid: true,
+		},
+		{
+			name:  "all alone",
+			deny:  []*config.FirewallDeny{{Protocol: "all"}},
+			valid: true,
+		},
+		{
+			name:  "all combined with tcp",
+			allow: []*config.FirewallAllow{{Protocol: "tcp", Ports: []string{"80"}}, {Protocol: "all"}},
+			valid: false,
+		},
+		{
+			name:  "duplicate protocol",
+			allow: []*config.FirewallAllow{{Protocol: "tcp", Ports: []string{"80"}}, {Protocol: "TCP", Ports: []string{"443"}}},
+			valid: false,
+		},
+		{
+			name:  "duplicate deny protocol",
+			deny:  []*config.FirewallDeny{{Protocol: "icmp"}, {Protocol: "icmp"}},
+			valid: false,
+		},
+	}
+
+	for _, test := range tests {
+		rule := &config.FirewallRule{
+			Name:      "test-rule",
+			Direction: "INGRESS",
+			Allow:     test.allow,
+			Deny:      test.deny,
+		}
+		err := validateFirewallRule(rule)
+		if test.valid && err != nil {
+			t.Errorf("%s: expected no error, got: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected error, got nil", test.name)
+		}
+	}
+}
+
+func TestValidateFirewallRulePorts(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+		ports    []string
+		valid    bool
+	}{
+		{"single port", "tcp", []string{"443"}, true},
+		{"range", "udp", []string{"8080-8090"}, true},
+		{"equal range bounds", "tcp", []string{"22-22"}, true},
+		{"upper bound", "sctp", []string{"65535"}, true},
+		{"protocol without ports", "icmp", nil, true},
+		{"protocol number", "6", []string{"80"}, true},
+		{"uppercase protocol", "TCP", []string{"80"}, true},
+		{"open range", "tcp", []string{"8080-"}, false},
+		{"reversed range", "tcp", []string{"9000-8000"}, false},
+		{"out of range", "tcp", []string{"65536"}, false},
+		{"signed port", "tcp", []string{"+80"}, false},
+		{"named port", "tcp", []string{"http"}, false},
+		{"empty port", "tcp", []string{""}, false},
+		{"ports on icmp", "icmp", []string{"80"}, false},
+		{"ports on all", "all", []string{"80"}, false},
+		{"unknown protocol", "http", nil, false},
+		{"protocol number out of range", "256", nil, false},
+		{"missing protocol", "", nil, false},
+	}
+
+	for _, test := range tests {
+		allow := &config.FirewallRule{
+			Name:      "test-rule",
+			Direction: "INGRESS",
+			Allow:     []*config.FirewallAllow{{Protocol: test.protocol, Ports: test.ports}},
+		}
+		deny := &config.FirewallRule{
+			Name:      "test-rule",
+			Direction: "INGRESS",
+			Deny:      []*config.FirewallDeny{{Protocol: test.protocol, Ports: test.ports}},
+		}
+		for _, rule := range []*config.FirewallRule{allow, deny} {
+			err := validateFirewallRule(rule)
+			if test.valid && err != nil {
+				t.Errorf("%s: expected no error, got: %v", test.name, err)
+			}
+			if !test.valid && CodeOf(err) != CodeFirewallPorts {
+				t.Errorf("%s: expected code %q, got: %v", test.name, CodeFirewallPorts, err)
+			}
+		}
+	}
+
+	// The error names the rule and the offending port
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Networking: &config.Networking{
+			Vpcs: []*config.Vpc{{Name: "main-vpc"}},
+			FirewallRules: []*config.FirewallRule{{
+				Name:      "allow-app",
+				Network:   "main-vpc",
+				Direction: "INGRESS",
+				Allow:     []*config.FirewallAllow{{Protocol: "tcp", Ports: []string{"8080-"}}},
+			}},
+		},
+	}
+	err := ValidateConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "allow-app") || !strings.Contains(err.Error(), `"8080-"`) {
+		t.Errorf("Expected error naming rule allow-app and port 8080-, got: %v", err)
+	}
+}
+
+func TestValidateDescription(t *testing.T) {
+	if err := validateDescription(strings.Repeat("a", maxDescriptionLength), maxDescriptionLength); err != nil {
+		t.Errorf("Expected no error for description at limit, got: %v", err)
+	}
+
+	vpc := &config.Vpc{
+		Name:        "main-vpc",
+		Description: strings.Repeat("a", maxDescriptionLength+1),
+	}
+	if err := validateVPC(vpc); err == nil {
+		t.Error("Expected error for VPC description over limit, got nil")
+	}
+
+	sa := &config.ServiceAccount{
+		AccountId:   "web-server-sa",
+		Description: strings.Repeat("a", maxIAMDescriptionLength+1),
+	}
+	if err := validateServiceAccount(sa); err == nil {
+		t.Error("Expected error for service account description over limit, got nil")
+	}
+}
+
+func TestValidateCloudRunTraffic(t *testing.T) {
+	tests := []struct {
+		name    string
+		traffic []*config.CloudRunTraffic
+		valid   bool
+	}{
+		{"no traffic", nil, true},
+		{"latest only", []*config.CloudRunTraffic{{Percent: 100}}, true},
+		{
+			name: "canary split",
+			traffic: []*config.CloudRunTraffic{
+				{RevisionName: "api-00001", Percent: 90},
+				{Percent: 10, Tag: "canary"},
+			},
+			valid: true,
+		},
+		{"under 100", []*config.CloudRunTraffic{{Percent: 90}}, false},
+		{
+			name: "over 100",
+			traffic: []*config.CloudRunTraffic{
+				{RevisionName: "api-00001", Percent: 60},
+				{Percent: 60},
+			},
+			valid: false,
+		},
+		{
+			name: "latest targeted twice",
+			traffic: []*config.CloudRunTraffic{
+				{Percent: 50},
+				{Percent: 50},
+			},
+			valid: false,
+		},
+		{
+			name: "latest tagged at 0%",
+			traffic: []*config.CloudRunTraffic{
+				{Percent: 100},
+				{Percent: 0, Tag: "canary"},
+			},
+			valid: true,
+		},
+		{
+			name: "revision targeted twice",
+			traffic: []*config.CloudRunTraffic{
+				{RevisionName: "api-00001", Percent: 50},
+				{RevisionName: "api-00001", Percent: 50},
+			},
+			valid: false,
+		},
+		{
+			name: "revision from another service",
+			traffic: []*config.CloudRunTraffic{
+				{RevisionName: "worker-00001", Percent: 100},
+			},
+			valid: false,
+		},
+		{
+			name: "duplicate tag",
+			traffic: []*config.CloudRunTraffic{
+				{RevisionName: "api-00001", Percent: 50, Tag: "blue"},
+				{Percent: 50, Tag: "blue"},
+			},
+			valid: false,
+		},
+	}
+
+	for _, test := range tests {
+		err := validateCloudRunTraffic("api", test.traffic)
+		if test.valid && err != nil {
+			t.Errorf("%s: expected no error, got: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected error, got nil", test.name)
+		}
+	}
+}
+
+func TestValidateCloudRunSecretEnv(t *testing.T) {
+	newConfig := func(envVars map[string]string, envFromSecrets ...*config.CloudRunEnvFromSecret) *config.Config {
+		return &config.Config{
+			Project:       &config.Project{Id: "test-project-123", Name: "Test Project"},
+			SecretManager: &config.SecretManager{Secrets: []*config.Secret{{Name: "db-url"}}},
+			CloudRun: &config.CloudRun{Services: []*config.CloudRunService{{
+				Name:     "api",
+				Location: config.Region_REGION_US_CENTRAL1,
+				Image:    "gcr.io/test-project-123/api:latest",
+				Config:   &config.CloudRunServiceConfig{EnvVars: envVars, EnvFromSecrets: envFromSecrets},
+			}}},
+		}
+	}
+
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		code Code
+	}{
+		{"declared secret", newConfig(nil, &config.CloudRunEnvFromSecret{Name: "DATABASE_URL", SecretName: "db-url"}), ""},
+		{"version number", newConfig(nil, &config.CloudRunEnvFromSecret{Name: "DATABASE_URL", SecretName: "db-url", Version: "3"}), ""},
+		{"external secret", newConfig(nil, &config.CloudRunEnvFromSecret{Name: "API_KEY", SecretName: "api-key", Version: "latest"}), ""},
+		{"missing name", newConfig(nil, &config.CloudRunEnvFromSecret{SecretName: "db-url"}), CodeCloudRunSecretEnv},
+		{"missing secret", newConfig(nil, &config.CloudRunEnvFromSecret{Name: "DATABASE_URL"}), CodeCloudRunSecretEnv},
+		{"invalid version", newConfig(nil, &config.CloudRunEnvFromSecret{Name: "DATABASE_URL", SecretName: "db-url", Version: "v2"}), CodeCloudRunSecretEnv},
+		{"also in env_vars", newConfig(map[string]string{"DATABASE_URL": "postgres://"}, &config.CloudRunEnvFromSecret{Name: "DATABASE_URL", SecretName: "db-url"}), CodeCloudRunSecretEnv},
+		{"set twice", newConfig(nil,
+			&config.CloudRunEnvFromSecret{Name: "DATABASE_URL", SecretName: "db-url"},
+			&config.CloudRunEnvFromSecret{Name: "DATABASE_URL", SecretName: "db-url", Version: "2"},
+		), CodeCloudRunSecretEnv},
+	}
+
+	for _, test := range tests {
+		if err := ValidateConfig(test.cfg); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+
+	// Secrets that aren't declared may exist outside the configuration
+	cfg := newConfig(nil,
+		&config.CloudRunEnvFromSecret{Name: "DATABASE_URL", SecretName: "db-url"},
+		&config.CloudRunEnvFromSecret{Name: "API_KEY", SecretName: "api-key"},
+	)
+	warnings := cloudRunSecretWarnings(cfg)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "reads secret api-key, which is not declared") {
+		t.Errorf("Expected a warning for the undeclared secret, got: %v", warnings)
+	}
+
+	disabled := false
+	cfg.SecretManager.Secrets[0].Enabled = &disabled
+	if err := ValidateConfig(cfg); CodeOf(err) != CodeDisabledReference {
+		t.Errorf("Expected a disabled reference error, got: %v", err)
+	}
+}
+
+func TestValidateNetworkCIDRs(t *testing.T) {
+	subnet := func(name, cidr string, secondary ...string) *config.Subnet {
+		s := &config.Subnet{Name: name, Cidr: cidr, Region: config.Region_REGION_US_CENTRAL1}
+		for i, r := range secondary {
+			s.SecondaryRanges = append(s.SecondaryRanges, &config.SecondaryRange{RangeName: fmt.Sprintf("range-%d", i), IpCidrRange: r})
+		}
+		return s
+	}
+
+	tests := []struct {
+		name    string
+		vpcs    []*config.Vpc
+		wantErr string
+	}{
+		{
+			name: "distinct ranges",
+			vpcs: []*config.Vpc{
+				{Name: "vpc-a", Subnets: []*config.Subnet{subnet("a", "10.0.0.0/24", "10.1.0.0/16")}},
+				{Name: "vpc-b", Subnets: []*config.Subnet{subnet("b", "10.2.0.0/24", "10.3.0.0/16")}},
+			},
+		},
+		{
+			name: "subnets in different VPCs",
+			vpcs: []*config.Vpc{
+				{Name: "vpc-a", Subnets: []*config.Subnet{subnet("a", "10.0.0.0/16")}},
+				{Name: "vpc-b", Subnets: []*config.Subnet{subnet("b", "10.0.5.0/24")}},
+			},
+			wantErr: "CIDR range 10.0.5.0/24 (subnet b in VPC vpc-b) overlaps with 10.0.0.0/16 (subnet a in VPC vpc-a)",
+		},
+		{
+			name: "secondary range and subnet in different VPCs",
+			vpcs: []*config.Vpc{
+				{Name: "vpc-a", Subnets: []*config.Subnet{subnet("a", "10.0.0.0/24", "10.4.0.0/14")}},
+				{Name: "vpc-b", Subnets: []*config.Subnet{subnet("b", "10.5.0.0/24")}},
+			},
+			wantErr: "(subnet b in VPC vpc-b) overlaps with 10.4.0.0/14 (secondary range range-0 of subnet a in VPC vpc-a)",
+		},
+		{
+			name: "secondary range and primary range of one subnet",
+			vpcs: []*config.Vpc{
+				{Name: "vpc-a", Subnets: []*config.Subnet{subnet("a", "10.0.0.0/24", "10.0.0.0/16")}},
+			},
+			wantErr: "overlaps with 10.0.0.0/24 (subnet a in VPC vpc-a)",
+		},
+		{
+			name: "subnets in one VPC",
+			vpcs: []*config.Vpc{
+				{Name: "vpc-a", Subnets: []*config.Subnet{subnet("a", "10.0.0.0/16"), subnet("b", "10.0.1.0/24")}},
+			},
+			wantErr: "CIDR range 10.0.1.0/24 (subnet b in VPC vpc-a) overlaps with 10.0.0.0/16 (subnet a in VPC vpc-a)",
+		},
+	}
+
+	for _, test := range tests {
+		err := validateNetworkCIDRs(test.vpcs)
+		if test.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: expected no error, got: %v", test.name, err)
+			}
+			continue
+		}
+		if CodeOf(err) != CodeCIDROverlap || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("%s: expected %s error containing %q, got: %v", test.name, CodeCIDROverlap, test.wantErr, err)
+		}
+	}
+
+	// An overlap within a VPC is reported once
+	result := Validate(&config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Networking: &config.Networking{Vpcs: []*config.Vpc{
+			{Name: "main-vpc", Subnets: []*config.Subnet{subnet("a", "10.0.0.0/16"), subnet("b", "10.0.1.0/24")}},
+		}},
+	})
+	if len(result.Errors) != 1 || result.Errors[0].Code != CodeCIDROverlap {
+		t.Errorf("Expected one %s error, got: %v", CodeCIDROverlap, result.Errors)
+	}
+}
+
+func TestCIDRsOverlap(t *testing.T) {
+	tests := []struct {
+		name         string
+		cidr1, cidr2 string
+		want         bool
+	}{
+		{"identical", "10.0.0.0/24", "10.0.0.0/24", true},
+		{"first contains second", "10.0.0.0/16", "10.0.1.0/24", true},
+		{"second contains first", "10.0.1.0/24", "10.0.0.0/16", true},
+		{"upper half", "10.0.0.0/24", "10.0.0.128/25", true},
+		{"upper half reversed", "10.0.0.128/25", "10.0.0.0/24", true},
+		{"host bits set", "10.0.0.200/24", "10.0.0.128/25", true},
+		{"adjacent", "10.0.0.0/24", "10.0.1.0/24", false},
+		{"adjacent halves", "10.0.0.0/25", "10.0.0.128/25", false},
+		{"disjoint", "10.0.0.0/24", "192.168.0.0/24", false},
+		{"single addresses", "10.0.0.1/32", "10.0.0.1/32", true},
+		{"everything", "0.0.0.0/0", "172.16.5.0/24", true},
+		{"IPv6 overlap", "2001:db8::/32", "2001:db8:1::/48", true},
+		{"IPv6 adjacent", "2001:db8::/33", "2001:db8:8000::/33", false},
+		{"mixed versions", "0.0.0.0/0", "::/0", false},
+		{"invalid", "10.0.0.0/33", "10.0.0.0/24", false},
+	}
+
+	for _, test := range tests {
+		if got := cidrsOverlap(test.cidr1, test.cidr2); got != test.want {
+			t.Errorf("%s: cidrsOverlap(%s, %s) = %v, want %v", test.name, test.cidr1, test.cidr2, got, test.want)
+		}
+	}
+}
+
+func TestValidateCloudRunServiceAccount(t *testing.T) {
+	newConfig := func(serviceAccount string) *config.Config {
+		return &config.Config{
+			Project: &config.Project{Id: "test-project-123", Name: "Test Project", BillingAccount: "123456-ABCDEF-789012"},
+			Iam:     &config.Iam{ServiceAccounts: []*config.ServiceAccount{{AccountId: "api-service"}}},
+			CloudRun: &config.CloudRun{Services: []*config.CloudRunService{{
+				Name:     "api",
+				Location: config.Region_REGION_US_CENTRAL1,
+				Image:    "gcr.io/test-project-123/api:latest",
+				Config:   &config.CloudRunServiceConfig{ServiceAccount: serviceAccount},
+			}}},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		serviceAccount string
+		code           Code
+	}{
+		{"declared", "api-service@test-project-123.iam.gserviceaccount.com", ""},
+		{"default", "", ""},
+		{"other project", "deployer@other-project.iam.gserviceaccount.com", ""},
+		{"google managed", "123456789-compute@developer.gserviceaccount.com", ""},
+		{"undeclared", "worker@test-project-123.iam.gserviceaccount.com", CodeUnknownReference},
+	}
+
+	for _, test := range tests {
+		err := ValidateConfig(newConfig(test.serviceAccount))
+		if CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+		if test.code != "" && (err == nil || !strings.Contains(err.Error(), "Cloud Run service api references unknown service account")) {
+			t.Errorf("%s: expected the error to name the service, got: %v", test.name, err)
+		}
+	}
+
+	disabled := false
+	cfg := newConfig("api-service@test-project-123.iam.gserviceaccount.com")
+	cfg.Iam.ServiceAccounts[0].Enabled = &disabled
+	if err := ValidateConfig(cfg); CodeOf(err) != CodeDisabledReference {
+		t.Errorf("Expected a disabled reference error, got: %v", err)
+	}
+}
+
+func TestValidateServiceAccountMembers(t *testing.T) {
+	newConfig := func(member string) *config.Config {
+		return &config.Config{
+			Project: &config.Project{Id: "test-project-123", Name: "Test Project", BillingAccount: "123456-ABCDEF-789012"},
+			Iam: &config.Iam{
+				ServiceAccounts: []*config.ServiceAccount{{AccountId: "app-service"}},
+				RoleBindings:    []*config.RoleBinding{{Role: "roles/viewer", Members: []string{"user:alice@example.com", member}}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		member  string
+		code    Code
+		warning bool
+	}{
+		{"declared", "serviceAccount:app-service@test-project-123.iam.gserviceaccount.com", "", false},
+		{"undeclared", "serviceAccount:app-servce@test-project-123.iam.gserviceaccount.com", CodeUnknownReference, false},
+		{"other project", "serviceAccount:deployer@other-project.iam.gserviceaccount.com", "", true},
+		{"service agent", "serviceAccount:service-123456789@gcp-sa-pubsub.iam.gserviceaccount.com", "", false},
+		{"google managed", "serviceAccount:123456789-compute@developer.gserviceaccount.com", "", false},
+		{"group", "group:admins@example.com", "", false},
+	}
+
+	for _, test := range tests {
+		cfg := newConfig(test.member)
+		err := ValidateConfig(cfg)
+		if CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+		if test.code != "" && (err == nil || !strings.Contains(err.Error(), "role binding roles/viewer references unknown service account")) {
+			t.Errorf("%s: expected the error to name the binding, got: %v", test.name, err)
+		}
+		if warnings := externalServiceAccountWarnings(cfg); (len(warnings) > 0) != test.warning {
+			t.Errorf("%s: expected warning %v, got: %v", test.name, test.warning, warnings)
+		}
+	}
+
+	disabled := false
+	cfg := newConfig("serviceAccount:app-service@test-project-123.iam.gserviceaccount.com")
+	cfg.Iam.ServiceAccounts[0].Enabled = &disabled
+	if err := ValidateConfig(cfg); CodeOf(err) != CodeDisabledReference {
+		t.Errorf("Expected a disabled reference error, got: %v", err)
+	}
+
+	cfg = &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project", BillingAccount: "123456-ABCDEF-789012"},
+		CloudRun: &config.CloudRun{Services: []*config.CloudRunService{{
+			Name:        "api",
+			Location:    config.Region_REGION_US_CENTRAL1,
+			Image:       "gcr.io/test-project-123/api:latest",
+			IamBindings: []*config.CloudRunIamBinding{{Role: "roles/run.invoker", Members: []string{"serviceAccount:caller@test-project-123.iam.gserviceaccount.com"}}},
+		}}},
+	}
+	if err := ValidateConfig(cfg); CodeOf(err) != CodeUnknownReference {
+		t.Errorf("Expected an unknown reference error for the Cloud Run binding, got: %v", err)
+	}
+}
+
+func TestValidateVpcConnector(t *testing.T) {
+	tests := []struct {
+		name      string
+		connector *config.CloudRunVpcConnector
+		valid     bool
+	}{
+		{"network and range", &config.CloudRunVpcConnector{Name: "conn", Network: "main-vpc", IpCidrRange: "10.8.0.0/28"}, true},
+		{"subnet", &config.CloudRunVpcConnector{Name: "conn", Subnet: "connector-subnet", MinInstances: 2, MaxInstances: 3}, true},
+		{"wrong prefix length", &config.CloudRunVpcConnector{Name: "conn", Network: "main-vpc", IpCidrRange: "10.8.0.0/24"}, false},
+		{"subnet and range", &config.CloudRunVpcConnector{Name: "conn", Subnet: "connector-subnet", IpCidrRange: "10.8.0.0/28"}, false},
+		{"missing range", &config.CloudRunVpcConnector{Name: "conn", Network: "main-vpc"}, false},
+		{"min below 2", &config.CloudRunVpcConnector{Name: "conn", Subnet: "s", MinInstances: 1}, false},
+		{"max below min", &config.CloudRunVpcConnector{Name: "conn", Subnet: "s", MinInstances: 4, MaxInstances: 3}, false},
+		{"invalid machine type", &config.CloudRunVpcConnector{Name: "conn", Subnet: "s", MachineType: "n2-standard-2"}, false},
+	}
+
+	for _, test := range tests {
+		err := validateVpcConnector(test.connector)
+		if test.valid && err != nil {
+			t.Errorf("%s: expected no error, got: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected error, got nil", test.name)
+		}
+	}
+}
+
+func TestValidateCloudRunVpcAccess(t *testing.T) {
+	connectors := map[string]*config.CloudRunVpcConnector{
+		"conn": {Name: "conn", Region: config.Region_REGION_US_CENTRAL1, Network: "main-vpc", IpCidrRange: "10.8.0.0/28"},
+	}
+	service := &config.CloudRunService{
+		Name:     "api",
+		Location: config.Region_REGION_US_CENTRAL1,
+		Image:    "gcr.io/test-project-123/api:latest",
+		Config: &config.CloudRunServiceConfig{VpcAccess: &config.CloudRunVpcAccess{
+			Connector: "conn",
+			Egress:    config.VpcEgress_VPC_EGRESS_ALL_TRAFFIC,
+		}},
+	}
+
+	tests := []struct {
+		name    string
+		service *config.CloudRunService
+		code    Code
+	}{
+		{"declared connector", service, ""},
+		{"external connector", modified(service, func(s *config.CloudRunService) { s.Config.VpcAccess.Connector = "shared-conn" }), ""},
+		{"no vpc access", modified(service, func(s *config.CloudRunService) { s.Config.VpcAccess = nil }), ""},
+		{"egress without connector", modified(service, func(s *config.CloudRunService) { s.Config.VpcAccess.Connector = "" }), CodeCloudRunAccess},
+		{"both connectors", modified(service, func(s *config.CloudRunService) { s.Config.VpcConnector = "conn" }), CodeCloudRunAccess},
+		{"region mismatch", modified(service, func(s *config.CloudRunService) { s.Location = config.Region_REGION_US_EAST1 }), CodeCloudRunAccess},
+	}
+
+	for _, test := range tests {
+		if err := validateCloudRunVpcAccess(test.service, connectors); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+}
+
+func TestCMEKServiceAgentWarnings(t *testing.T) {
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Kms: &config.Kms{
+			KeyRings: []*config.KmsKeyRing{{
+				Name:       "main",
+				Location:   "us-central1",
+				CryptoKeys: []*config.KmsCryptoKey{{Name: "data-key"}},
+			}},
+		},
+		Storage: &config.Storage{
+			Buckets: []*config.StorageBucket{{Name: "my-data-bucket", Location: "US", KmsKey: "data-key"}},
+		},
+	}
+
+	if warnings := Warnings(cfg); len(warnings) != 1 {
+		t.Errorf("Expected 1 warning for ungranted service agent, got %d: %v", len(warnings), warnings)
+	}
+
+	// A project-level grant to the storage agent silences the warning
+	cfg.Iam = &config.Iam{
+		RoleBindings: []*config.RoleBinding{{
+			Role:    "roles/cloudkms.cryptoKeyEncrypterDecrypter",
+			Members: []string{"serviceAccount:service-123456789@gs-project-accounts.iam.gserviceaccount.com"},
+		}},
+	}
+	if warnings := Warnings(cfg); len(warnings) != 0 {
+		t.Errorf("Expected no warnings with role binding, got: %v", warnings)
+	}
+
+	// Auto-generated grants also silence the warning
+	cfg.Iam = nil
+	cfg.Kms.GrantServiceAgents = true
+	if warnings := Warnings(cfg); len(warnings) != 0 {
+		t.Errorf("Expected no warnings with grant_service_agents, got: %v", warnings)
+	}
+}
+
+func TestValidateKMS(t *testing.T) {
+	keyRing := func(name, location string, keys ...string) *config.KmsKeyRing {
+		keyRing := &config.KmsKeyRing{Name: name, Location: location}
+		for _, key := range keys {
+			keyRing.CryptoKeys = append(keyRing.CryptoKeys, &config.KmsCryptoKey{Name: key})
+		}
+		return keyRing
+	}
+	tests := []struct {
+		name     string
+		keyRings []*config.KmsKeyRing
+		code     Code
+	}{
+		{"valid", []*config.KmsKeyRing{keyRing("app", "us-central1", "data", "logs"), keyRing("backup", "us", "archive")}, ""},
+		{"empty key ring", []*config.KmsKeyRing{keyRing("app", "global")}, ""},
+		{"duplicate key ring", []*config.KmsKeyRing{keyRing("app", "us-central1"), keyRing("app", "us-east1")}, CodeDuplicateName},
+		{"no location", []*config.KmsKeyRing{keyRing("app", "", "data")}, CodeRequiredField},
+		{"duplicate key in key ring", []*config.KmsKeyRing{keyRing("app", "us-central1", "data", "data")}, CodeDuplicateName},
+		{"duplicate key across key rings", []*config.KmsKeyRing{keyRing("app", "us-central1", "data"), keyRing("backup", "us", "data")}, CodeDuplicateName},
+	}
+
+	for _, test := range tests {
+		if err := validateKMS(&config.Kms{KeyRings: test.keyRings}); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+}
+
+func TestValidateCryptoKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  *config.KmsCryptoKey
+		code Code
+	}{
+		{"defaults", &config.KmsCryptoKey{Name: "data"}, ""},
+		{"rotated symmetric key", &config.KmsCryptoKey{Name: "data", Purpose: "ENCRYPT_DECRYPT", ProtectionLevel: "HSM", RotationPeriod: "90d"}, ""},
+		{"signing key", &config.KmsCryptoKey{Name: "sign", Purpose: "ASYMMETRIC_SIGN", ProtectionLevel: "SOFTWARE", Algorithm: "EC_SIGN_P256_SHA256"}, ""},
+		{"invalid purpose", &config.KmsCryptoKey{Name: "data", Purpose: "SIGN"}, CodeInvalidValue},
+		{"invalid protection level", &config.KmsCryptoKey{Name: "data", ProtectionLevel: "EXTERNAL_HARDWARE"}, CodeInvalidValue},
+		{"asymmetric key without algorithm", &config.KmsCryptoKey{Name: "sign", Purpose: "ASYMMETRIC_SIGN", ProtectionLevel: "HSM"}, CodeCryptoKeySettings},
+		{"rotated asymmetric key", &config.KmsCryptoKey{Name: "sign", Purpose: "ASYMMETRIC_DECRYPT", RotationPeriod: "90d"}, CodeCryptoKeySettings},
+		{"bad rotation period", &config.KmsCryptoKey{Name: "data", RotationPeriod: "quarterly"}, CodeInvalidDuration},
+		{"rotation period too short", &config.KmsCryptoKey{Name: "data", RotationPeriod: "12h"}, CodeCryptoKeySettings},
+	}
+
+	for _, test := range tests {
+		if err := validateCryptoKey(test.key); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+}
+
+func TestValidateCMEKReferences(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want string
+	}{
+		{"declared keys", &config.Config{
+			Storage:   &config.Storage{Buckets: []*config.StorageBucket{{Name: "test-project-123-assets", Location: "US", KmsKey: "data"}}},
+			Compute:   &config.Compute{InstanceTemplates: []*config.InstanceTemplate{{Name: "web", MachineType: config.MachineType_MACHINE_TYPE_E2_MEDIUM, Image: "debian-cloud/debian-12", DiskSizeGb: 20, KmsKey: "data"}}},
+			Databases: &config.Databases{CloudSqlInstances: []*config.CloudSqlInstance{{Name: "main-db", DatabaseVersion: "POSTGRES_15", Region: config.Region_REGION_US_CENTRAL1, Tier: "db-f1-micro", KmsKey: "data"}}},
+		}, ""},
+		{"bucket", &config.Config{
+			Storage: &config.Storage{Buckets: []*config.StorageBucket{{Name: "test-project-123-assets", Location: "US", KmsKey: "other"}}},
+		}, "storage bucket test-project-123-assets references unknown crypto key: other"},
+		{"instance template", &config.Config{
+			Compute: &config.Compute{InstanceTemplates: []*config.InstanceTemplate{{Name: "web", MachineType: config.MachineType_MACHINE_TYPE_E2_MEDIUM, Image: "debian-cloud/debian-12", DiskSizeGb: 20, KmsKey: "other"}}},
+		}, "instance template web references unknown crypto key: other"},
+		{"Cloud SQL instance", &config.Config{
+			Databases: &config.Databases{CloudSqlInstances: []*config.CloudSqlInstance{{Name: "main-db", DatabaseVersion: "POSTGRES_15", Region: config.Region_REGION_US_CENTRAL1, Tier: "db-f1-micro", KmsKey: "other"}}},
+		}, "Cloud SQL instance main-db references unknown crypto key: other"},
+	}
+
+	for _, test := range tests {
+		test.cfg.Project = &config.Project{Id: "test-project-123", Name: "Test Project", BillingAccount: "123456-ABCDEF-789012"}
+		test.cfg.Kms = &config.Kms{KeyRings: []*config.KmsKeyRing{{
+			Name:       "app",
+			Location:   "us-central1",
+			CryptoKeys: []*config.KmsCryptoKey{{Name: "data"}},
+		}}}
+		err := ValidateConfig(test.cfg)
+		if test.want == "" {
+			if err != nil {
+				t.Errorf("%s: expected no error, got: %v", test.name, err)
+			}
+			continue
+		}
+		if CodeOf(err) != CodeUnknownReference || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: expected %q, got: %v", test.name, test.want, err)
+		}
+	}
+}
+
+func TestValidateMonitoring(t *testing.T) {
+	condition := func() *config.AlertCondition {
+		return &config.AlertCondition{
+			DisplayName:    "High CPU",
+			Filter:         `metric.type="compute.googleapis.com/instance/cpu/utilization"`,
+			Comparison:     "COMPARISON_GT",
+			ThresholdValue: 0.8,
+			Duration:       "300s",
+		}
+	}
+	channels := []*config.NotificationChannel{
+		{Name: "oncall", Type: "email", Labels: map[string]string{"email_address": "oncall@example.com"}},
+		{Name: "pager", Type: "pagerduty"},
+	}
+
+	tests := []struct {
+		name       string
+		monitoring *config.Monitoring
+		valid      bool
+	}{
+		{"valid", &config.Monitoring{
+			NotificationChannels: channels,
+			AlertPolicies:        []*config.AlertPolicy{{Name: "cpu", Conditions: []*config.AlertCondition{condition()}, NotificationChannels: []string{"oncall", "pager"}}},
+		}, true},
+		{"undeclared channel", &config.Monitoring{
+			NotificationChannels: channels,
+			AlertPolicies:        []*config.AlertPolicy{{Name: "cpu", Conditions: []*config.AlertCondition{condition()}, NotificationChannels: []string{"missing"}}},
+		}, false},
+		{"invalid channel type", &config.Monitoring{
+			NotificationChannels: []*config.NotificationChannel{{Name: "sms", Type: "sms"}},
+		}, false},
+		{"email without address", &config.Monitoring{
+			NotificationChannels: []*config.NotificationChannel{{Name: "oncall", Type: "email"}},
+		}, false},
+		{"non-finite threshold", &config.Monitoring{
+			AlertPolicies: []*config.AlertPolicy{{Name: "cpu", Conditions: []*config.AlertCondition{func() *config.AlertCondition {
+				c := condition()
+				c.ThresholdValue = math.Inf(1)
+				return c
+			}()}}},
+		}, false},
+		{"no conditions", &config.Monitoring{
+			AlertPolicies: []*config.AlertPolicy{{Name: "cpu"}},
+		}, false},
+		{"log metric condition", &config.Monitoring{
+			LogMetrics: []*config.LogMetric{{Name: "errors", Filter: "severity>=ERROR", MetricKind: "DELTA"}},
+			AlertPolicies: []*config.AlertPolicy{{Name: "errors", Conditions: []*config.AlertCondition{
+				{DisplayName: "Errors", LogMetric: "errors", Comparison: "COMPARISON_GT", ThresholdValue: 10},
+			}}},
+		}, true},
+		{"undeclared log metric", &config.Monitoring{
+			AlertPolicies: []*config.AlertPolicy{{Name: "errors", Conditions: []*config.AlertCondition{
+				{DisplayName: "Errors", LogMetric: "errors", Comparison: "COMPARISON_GT", ThresholdValue: 10},
+			}}},
+		}, false},
+		{"log metric without filter", &config.Monitoring{
+			LogMetrics: []*config.LogMetric{{Name: "errors", Filter: " "}},
+		}, false},
+		{"invalid metric kind", &config.Monitoring{
+			LogMetrics: []*config.LogMetric{{Name: "errors", Filter: "severity>=ERROR", MetricKind: "SUM"}},
+		}, false},
+	}
+
+	for _, test := range tests {
+		err := validateMonitoring(test.monitoring)
+		if test.valid && err != nil {
+			t.Errorf("%s: expected no error, got: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected error, got nil", test.name)
+		}
+	}
+}
+
+func TestValidateLogSinks(t *testing.T) {
+	bucketDest := &config.LogSinkDestination{Target: &config.LogSinkDestination_StorageBucket{StorageBucket: "audit-logs-bucket"}}
+
+	tests := []struct {
+		name  string
+		sinks []*config.LogSink
+		valid bool
+	}{
+		{"bucket destination", []*config.LogSink{{Name: "audit", Filter: `logName:"cloudaudit.googleapis.com"`, Destination: bucketDest}}, true},
+		{"external destination", []*config.LogSink{{Name: "audit", Filter: "severity>=ERROR", Destination: &config.LogSinkDestination{
+			Target: &config.LogSinkDestination_External{External: "storage.googleapis.com/central-logs"},
+		}}}, true},
+		{"missing filter", []*config.LogSink{{Name: "audit", Destination: bucketDest}}, false},
+		{"missing destination", []*config.LogSink{{Name: "audit", Filter: "severity>=ERROR"}}, false},
+		{"invalid external destination", []*config.LogSink{{Name: "audit", Filter: "severity>=ERROR", Destination: &config.LogSinkDestination{
+			Target: &config.LogSinkDestination_External{External: "gs://central-logs"},
+		}}}, false},
+		{"duplicate names", []*config.LogSink{
+			{Name: "audit", Filter: "severity>=ERROR", Destination: bucketDest},
+			{Name: "audit", Filter: "severity>=ERROR", Destination: bucketDest},
+		}, false},
+	}
+
+	for _, test := range tests {
+		err := validateLogSinks(test.sinks)
+		if test.valid && err != nil {
+			t.Errorf("%s: expected no error, got: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected error, got nil", test.name)
+		}
+	}
+
+	// Bucket destinations must be declared in the config
+	cfg := &config.Config{
+		Project:  &config.Project{Id: "test-project-123", Name: "Test Project"},
+		LogSinks: []*config.LogSink{{Name: "audit", Filter: "severity>=ERROR", Destination: bucketDest}},
+	}
+	if err := validateCrossReferences(cfg); err == nil {
+		t.Error("Expected error for undeclared sink bucket, got nil")
+	}
+	cfg.Storage = &config.Storage{Buckets: []*config.StorageBucket{{Name: "audit-logs-bucket", Location: "US"}}}
+	if err := validateCrossReferences(cfg); err != nil {
+		t.Errorf("Expected no error for declared sink bucket, got: %v", err)
+	}
+}
+
+func TestValidateResourceTags(t *testing.T) {
+	keys := []*config.TagKey{{
+		ShortName: "environment",
+		Values:    []*config.TagValue{{ShortName: "production"}, {ShortName: "staging"}},
+	}}
+
+	tests := []struct {
+		name  string
+		tags  *config.ResourceTags
+		valid bool
+	}{
+		{"project binding", &config.ResourceTags{Keys: keys, Bindings: []*config.TagBinding{{Key: "environment", Value: "production"}}}, true},
+		{"location binding", &config.ResourceTags{Keys: keys, Bindings: []*config.TagBinding{
+			{Key: "environment", Value: "staging", Resource: "//storage.googleapis.com/projects/_/buckets/data", Location: "us"},
+		}}, true},
+		{"undeclared key", &config.ResourceTags{Keys: keys, Bindings: []*config.TagBinding{{Key: "team", Value: "production"}}}, false},
+		{"undeclared value", &config.ResourceTags{Keys: keys, Bindings: []*config.TagBinding{{Key: "environment", Value: "dev"}}}, false},
+		{"two values for one key", &config.ResourceTags{Keys: keys, Bindings: []*config.TagBinding{
+			{Key: "environment", Value: "production"},
+			{Key: "environment", Value: "staging"},
+		}}, false},
+		{"invalid short name", &config.ResourceTags{Keys: []*config.TagKey{{ShortName: "-env"}}}, false},
+		{"relative resource name", &config.ResourceTags{Keys: keys, Bindings: []*config.TagBinding{
+			{Key: "environment", Value: "staging", Resource: "projects/_/buckets/data"},
+		}}, false},
+	}
+
+	for _, test := range tests {
+		err := validateResourceTags(test.tags)
+		if test.valid && err != nil {
+			t.Errorf("%s: expected no error, got: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected error, got nil", test.name)
+		}
+	}
+}
+
+func TestValidateComputeSoleTenancy(t *testing.T) {
+	newCompute := func(machineType config.MachineType, affinity *config.NodeAffinity) *config.Compute {
+		return &config.Compute{
+			NodeTemplates: []*config.NodeTemplate{{
+				Name:               "n2-template",
+				Region:             config.Region_REGION_US_CENTRAL1,
+				NodeType:           "n2-node-80-640",
+				NodeAffinityLabels: map[string]string{"workload": "regulated"},
+			}},
+			NodeGroups: []*config.NodeGroup{{Name: "regulated-nodes", Zone: config.Zone_ZONE_US_CENTRAL1_A, NodeTemplate: "n2-template"}},
+			Instances: []*config.Instance{{
+				Name:           "vm",
+				Zone:           config.Zone_ZONE_US_CENTRAL1_A,
+				MachineType:    machineType,
+				NodeAffinities: []*config.NodeAffinity{affinity},
+			}},
+		}
+	}
+	groupAffinity := func(group string) *config.NodeAffinity {
+		return &config.NodeAffinity{Key: "compute.googleapis.com/node-group-name", Values: []string{group}}
+	}
+
+	tests := []struct {
+		name    string
+		compute *config.Compute
+		valid   bool
+	}{
+		{"node group affinity", newCompute(config.MachineType_MACHINE_TYPE_N2_STANDARD_4, groupAffinity("regulated-nodes")), true},
+		{"label affinity", newCompute(config.MachineType_MACHINE_TYPE_N2_STANDARD_4, &config.NodeAffinity{Key: "workload", Values: []string{"regulated"}}), true},
+		{"unknown node group", newCompute(config.MachineType_MACHINE_TYPE_N2_STANDARD_4, groupAffinity("other-nodes")), false},
+		{"unknown label", newCompute(config.MachineType_MACHINE_TYPE_N2_STANDARD_4, &config.NodeAffinity{Key: "team", Values: []string{"a"}}), false},
+		{"e2 machine type", newCompute(config.MachineType_MACHINE_TYPE_E2_STANDARD_4, groupAffinity("regulated-nodes")), false},
+		{"mismatched family", newCompute(config.MachineType_MACHINE_TYPE_N1_STANDARD_4, groupAffinity("regulated-nodes")), false},
+	}
+
+	for _, test := range tests {
+		err := validateCompute(test.compute)
+		if test.valid && err != nil {
+			t.Errorf("%s: expected no error, got: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected error, got nil", test.name)
+		}
+	}
+
+	// Node groups must be in their template's region
+	compute := newCompute(config.MachineType_MACHINE_TYPE_N2_STANDARD_4, groupAffinity("regulated-nodes"))
+	compute.NodeGroups[0].Zone = config.Zone_ZONE_US_EAST1_B
+	compute.Instances = nil
+	if err := validateCompute(compute); err == nil {
+		t.Error("Expected error for node group outside template region, got nil")
+	}
+}
+
+func TestValidateConfigDisabledResources(t *testing.T) {
+	disabled := false
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Networking: &config.Networking{
+			ReservedIps: []*config.ReservedIp{{Name: "lb-ip", Enabled: &disabled}},
+		},
+		Compute: &config.Compute{
+			InstanceTemplates: []*config.InstanceTemplate{{Name: "web-template", DiskSizeGb: 20}},
+			InstanceGroups:    []*config.InstanceGroup{{Name: "web-group", Template: "web-template", Size: 1}},
+		},
+		LoadBalancers: []*config.LoadBalancer{{Name: "web-lb", Backend: "web-group", Ip: "lb-ip"}},
+	}
+
+	// The load balancer references a disabled reserved IP, reported as disabled rather than missing
+	err := ValidateConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "reserved IP lb-ip, which is disabled") {
+		t.Errorf("Expected disabled reserved IP error, got: %v", err)
+	}
+
+	// Disabling the load balancer as well skips validation of its references
+	cfg.LoadBalancers[0].Enabled = &disabled
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected no error with disabled load balancer, got: %v", err)
+	}
+}
+
+func TestValidateDisabledReferences(t *testing.T) {
+	disabled := false
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Networking: &config.Networking{
+			Vpcs: []*config.Vpc{{
+				Name:    "main-vpc",
+				Subnets: []*config.Subnet{{Name: "app-subnet", Cidr: "10.0.1.0/24", Enabled: &disabled}},
+			}},
+		},
+		Compute: &config.Compute{
+			Instances: []*config.Instance{{
+				Name:              "app-vm",
+				NetworkInterfaces: []*config.NetworkInterface{{Subnetwork: "app-subnet"}},
+			}},
+		},
+	}
+
+	enabledCfg := config.WithoutDisabled(cfg)
+	err := validateDisabledReferences(enabledCfg, disabledResourceNames(cfg, enabledCfg))
+	if err == nil || !strings.Contains(err.Error(), "instance app-vm references subnet app-subnet, which is disabled") {
+		t.Errorf("Expected disabled subnet error, got: %v", err)
+	}
+
+	// References among disabled resources are allowed
+	cfg.Compute.Instances[0].Enabled = &disabled
+	enabledCfg = config.WithoutDisabled(cfg)
+	if err := validateDisabledReferences(enabledCfg, disabledResourceNames(cfg, enabledCfg)); err != nil {
+		t.Errorf("Expected no error when the referencing instance is also disabled, got: %v", err)
+	}
+}
+
+func TestValidateReservationAffinity(t *testing.T) {
+	tests := []struct {
+		name     string
+		affinity *config.ReservationAffinity
+		valid    bool
+	}{
+		{"any", &config.ReservationAffinity{Type: "ANY_RESERVATION"}, true},
+		{"none", &config.ReservationAffinity{Type: "NO_RESERVATION"}, true},
+		{"specific", &config.ReservationAffinity{Type: "SPECIFIC_RESERVATION", Key: "compute.googleapis.com/reservation-name", Values: []string{"web-reservation"}}, true},
+		{"specific without values", &config.ReservationAffinity{Type: "SPECIFIC_RESERVATION", Key: "compute.googleapis.com/reservation-name"}, false},
+		{"any with key", &config.ReservationAffinity{Type: "ANY_RESERVATION", Key: "compute.googleapis.com/reservation-name"}, false},
+		{"unknown type", &config.ReservationAffinity{Type: "SOME_RESERVATION"}, false},
+	}
+
+	for _, test := range tests {
+		err := validateReservationAffinity(test.affinity)
+		if test.valid && err != nil {
+			t.Errorf("%s: expected no error, got: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected error, got nil", test.name)
+		}
+	}
+}
+
+func TestValidateGuestOSFeatures(t *testing.T) {
+	tests := []struct {
+		name     string
+		features []string
+		valid    bool
+	}{
+		{"none", nil, true},
+		{"known features", []string{"UEFI_COMPATIBLE", "GVNIC"}, true},
+		{"unknown feature", []string{"FAST_NETWORKING"}, false},
+		{"lowercase", []string{"gvnic"}, false},
+		{"duplicate", []string{"GVNIC", "GVNIC"}, false},
+	}
+
+	for _, test := range tests {
+		err := validateInstanceTemplate(&config.InstanceTemplate{
+			Name:            "web-template",
+			MachineType:     config.MachineType_MACHINE_TYPE_E2_MEDIUM,
+			DiskSizeGb:      20,
+			GuestOsFeatures: test.features,
+		})
+		if test.valid && err != nil {
+			t.Errorf("%s: expected no error, got: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected error, got nil", test.name)
+		}
+	}
+}
+
+func TestValidationErrorCodes(t *testing.T) {
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project", BillingAccount: "123456-ABCDEF-789012"},
+		Networking: &config.Networking{
+			Vpcs: []*config.Vpc{{
+				Name: "main-vpc",
+				Subnets: []*config.Subnet{
+					{Name: "subnet-a", Cidr: "10.0.0.0/16", Region: config.Region_REGION_US_CENTRAL1},
+					{Name: "subnet-b", Cidr: "10.0.1.0/24", Region: config.Region_REGION_US_CENTRAL1},
+				},
+			}},
+		},
+	}
+
+	err := ValidateConfig(cfg)
+	if code := CodeOf(err); code != CodeCIDROverlap {
+		t.Fatalf("Expected code %s, got %q (error: %v)", CodeCIDROverlap, code, err)
+	}
+	if !strings.Contains(err.Error(), "[NET002] CIDR range 10.0.1.0/24 (subnet subnet-b in VPC main-vpc) overlaps") {
+		t.Errorf("Expected error message to include the code, got: %v", err)
+	}
+
+	// Wrapped causes remain available
+	err = validateAlertCondition(&config.AlertCondition{DisplayName: "High CPU", Filter: "metric.type=\"x\"", Comparison: "COMPARISON_GT", Duration: "5 minutes"})
+	if CodeOf(err) != CodeInvalidDuration {
+		t.Errorf("Expected code %s, got %q", CodeInvalidDuration, CodeOf(err))
+	}
+	if CodeOf(fmt.Errorf("not a validation error")) != "" {
+		t.Error("Expected no code for a plain error")
+	}
+
+	for _, code := range Codes() {
+		explanation, ok := Explain(code)
+		if !ok || explanation.Title == "" || explanation.Description == "" || explanation.Remediation == "" {
+			t.Errorf("Code %s has an incomplete explanation: %+v", code, explanation)
+		}
+	}
+	if _, ok := Explain("NET999"); ok {
+		t.Error("Expected unknown code to have no explanation")
+	}
+}
+
+func TestValidateResult(t *testing.T) {
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Compute: &config.Compute{
+			InstanceTemplates: []*config.InstanceTemplate{{
+				Name:              "web-template",
+				MachineType:       config.MachineType_MACHINE_TYPE_E2_MEDIUM,
+				DiskSizeGb:        20,
+				NetworkInterfaces: []*config.NetworkInterface{{Network: "default"}},
+			}},
+			InstanceGroups: []*config.InstanceGroup{
+				{Name: "web-group", Template: "web-template", AutoScaling: &config.AutoScaling{Max: 0, CpuTarget: 0.6}},
+			},
+		},
+	}
+
+	// A valid configuration reports its warnings
+	result := Validate(cfg)
+	if !result.Valid || result.Err() != nil {
+		t.Fatalf("Expected a valid result, got: %v", result.Err())
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Severity != SeverityWarning || !strings.Contains(result.Errors[0].Message, "web-group") {
+		t.Errorf("Expected one warning about web-group, got: %+v", result.Errors)
+	}
+
+	// Errors carry the path and code separately from the message, and
+	// collapse to the error ValidateConfig returns
+	cfg.Compute.InstanceGroups[0].AutoScaling.Min = -1
+	result = Validate(cfg)
+	if result.Valid || len(result.Errors) != 1 {
+		t.Fatalf("Expected a single error, got: %+v", result.Errors)
+	}
+	e := result.Errors[0]
+	if e.Path != "compute" || e.Code != CodeAutoscalingBounds || e.Severity != SeverityError {
+		t.Errorf("Expected a %s error at compute, got: %+v", CodeAutoscalingBounds, e)
+	}
+	if strings.Contains(e.Message, "["+string(CodeAutoscalingBounds)+"]") || !strings.HasPrefix(e.Message, "invalid instance group web-group: ") {
+		t.Errorf("Expected the message without code or prefix, got: %s", e.Message)
+	}
+	err := ValidateConfig(cfg)
+	if result.Err().Error() != err.Error() || !strings.HasPrefix(err.Error(), "compute validation failed: ") {
+		t.Errorf("Expected Err to match ValidateConfig, got %q and %q", result.Err(), err)
+	}
+
+	output, jsonErr := json.Marshal(result)
+	if jsonErr != nil {
+		t.Fatalf("Failed to encode result: %v", jsonErr)
+	}
+	if !strings.Contains(string(output), `"valid":false`) || !strings.Contains(string(output), `"path":"compute"`) || !strings.Contains(string(output), `"severity":"error"`) {
+		t.Errorf("Unexpected JSON encoding: %s", output)
+	}
+}
+
+func TestValidateStrict(t *testing.T) {
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Networking: &config.Networking{Vpcs: []*config.Vpc{{
+			Name:    "main-vpc",
+			Subnets: []*config.Subnet{{Name: "main-subnet", Cidr: "10.0.0.0/24"}},
+		}}},
+		Compute: &config.Compute{Instances: []*config.Instance{{
+			Name:              "web-vm",
+			MachineType:       config.MachineType_MACHINE_TYPE_E2_SMALL,
+			Image:             "debian-cloud/debian-12",
+			NetworkInterfaces: []*config.NetworkInterface{{Subnetwork: "main-subnet"}},
+		}}},
+	}
+
+	// Unspecified enums are defaulted unless validation is strict
+	if result := Validate(cfg); !result.Valid {
+		t.Fatalf("Expected a valid result without strict, got: %v", result.Err())
+	}
+
+	result := ValidateWithOptions(cfg, &ValidateOptions{Strict: true})
+	if result.Valid || len(result.Errors) != 1 {
+		t.Fatalf("Expected a single strict error, got: %+v", result.Errors)
+	}
+	if e := result.Errors[0]; e.Path != "networking" || e.Code != CodeUnspecifiedEnum || !strings.Contains(e.Message, "subnet main-subnet: region") {
+		t.Errorf("Expected a %s error for the subnet region, got: %+v", CodeUnspecifiedEnum, e)
+	}
+
+	// The instance zone defaults to the zone variable, so it isn't reported
+	cfg.Networking.Vpcs[0].Subnets[0].Region = config.Region_REGION_US_EAST1
+	cfg.Compute.Instances[0].MachineType = config.MachineType_MACHINE_TYPE_UNSPECIFIED
+	result = ValidateWithOptions(cfg, &ValidateOptions{Strict: true})
+	if result.Valid || len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Message, "instance web-vm: machine_type") {
+		t.Errorf("Expected a single error for the instance machine type, got: %+v", result.Errors)
+	}
+}
+
+func TestValidateOutput(t *testing.T) {
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Output:  &config.Output{Numbered: true, FileMap: map[string]string{"iam.tf": "permissions.tf"}},
+	}
+	if result := Validate(cfg); !result.Valid {
+		t.Fatalf("Expected a valid result, got: %v", result.Err())
+	}
+
+	cfg.Output.FileMap["network.tf"] = "net.tf"
+	result := Validate(cfg)
+	if result.Valid || len(result.Errors) != 1 {
+		t.Fatalf("Expected a single error, got: %+v", result.Errors)
+	}
+	if e := result.Errors[0]; e.Path != "output" || e.Code != CodeOutputFileName || !strings.Contains(e.Message, "network.tf") {
+		t.Errorf("Expected a %s error for network.tf, got: %+v", CodeOutputFileName, e)
+	}
+}
+
+func TestValidateCollectsErrors(t *testing.T) {
+	cfg := &config.Config{
+		Project: &config.Project{Id: "My_Project", Name: "Test Project"},
+		Networking: &config.Networking{Vpcs: []*config.Vpc{
+			{
+				Name:    "main-vpc",
+				Subnets: []*config.Subnet{{Name: "main-subnet", Cidr: "10.0.0.0/33", Region: config.Region_REGION_US_CENTRAL1}},
+			},
+			{
+				Name:    "data-vpc",
+				Subnets: []*config.Subnet{{Name: "data-subnet", Cidr: "10.1.0.0/33", Region: config.Region_REGION_US_CENTRAL1}},
+			},
+		}},
+		Storage: &config.Storage{Buckets: []*config.StorageBucket{
+			{Name: "Assets", Location: "US"},
+			{Name: "logs-bucket", Location: "US"},
+			{Name: "Backups", Location: "US"},
+		}},
+	}
+
+	// Each section reports every invalid resource, not just the first
+	result := Validate(cfg)
+	want := []struct {
+		path string
+		code Code
+	}{
+		{"project", CodeInvalidProjectID},
+		{"networking", CodeInvalidCIDR},
+		{"networking", CodeInvalidCIDR},
+		{"storage", CodeInvalidBucketName},
+		{"storage", CodeInvalidBucketName},
+	}
+	if result.Valid || len(result.Errors) != len(want) {
+		t.Fatalf("Expected %d errors, got: %+v", len(want), result.Errors)
+	}
+	for i, w := range want {
+		if e := result.Errors[i]; e.Path != w.path || e.Code != w.code {
+			t.Errorf("Expected error %d to be %s at %s, got: %+v", i, w.code, w.path, e)
+		}
+	}
+
+	// ValidateConfig reports them together, keeping the check prefixes, with
+	// the code of the first
+	err := ValidateConfig(cfg)
+	for _, prefix := range []string{
+		"project validation failed: ",
+		"networking validation failed: invalid VPC main-vpc",
+		"networking validation failed: invalid VPC data-vpc",
+		"storage validation failed: invalid storage bucket Assets",
+		"storage validation failed: invalid storage bucket Backups",
+	} {
+		if err == nil || !strings.Contains(err.Error(), prefix) {
+			t.Errorf("Expected error containing %q, got: %v", prefix, err)
+		}
+	}
+	if CodeOf(err) != CodeInvalidProjectID {
+		t.Errorf("Expected code %s, got %s", CodeInvalidProjectID, CodeOf(err))
+	}
+
+	// Every project of a multi-project configuration is validated
+	multi := &config.Config{
+		Projects: []*config.Project{
+			{Id: "web-prod-123", Name: "Web Prod", BillingAccount: "invalid"},
+			{Id: "data-prod-123", Name: "Data Prod", BillingAccount: "invalid"},
+		},
+	}
+	err = ValidateConfig(multi)
+	for _, project := range []string{"project web-prod-123: ", "project data-prod-123: "} {
+		if err == nil || !strings.Contains(err.Error(), project) {
+			t.Errorf("Expected error containing %q, got: %v", project, err)
+		}
+	}
+}
+
+func TestValidateCollectsSectionErrors(t *testing.T) {
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Networking: &config.Networking{
+			Vpcs: []*config.Vpc{{
+				Name: "main-vpc",
+				Subnets: []*config.Subnet{
+					{Name: "web-subnet", Cidr: "10.0.0.0/33", Region: config.Region_REGION_US_CENTRAL1},
+					{Name: "db-subnet", Cidr: "10.1.0.0/33", Region: config.Region_REGION_US_CENTRAL1},
+				},
+			}},
+			FirewallRules: []*config.FirewallRule{{
+				Name:              "allow-web",
+				Network:           "main-vpc",
+				Direction:         "INGRESS",
+				DestinationRanges: []string{"10.0.0.0/8"},
+			}},
+		},
+	}
+
+	// Two bad subnets of one VPC and two problems of one firewall rule are
+	// all reported in one run
+	result := Validate(cfg)
+	want := []struct {
+		code    Code
+		message string
+	}{
+		{CodeInvalidCIDR, "invalid VPC main-vpc: invalid subnet web-subnet: invalid CIDR format: 10.0.0.0/33"},
+		{CodeInvalidCIDR, "invalid VPC main-vpc: invalid subnet db-subnet: invalid CIDR format: 10.1.0.0/33"},
+		{CodeFirewallDirection, "invalid firewall rule allow-web: INGRESS rules cannot have destination_ranges"},
+		{CodeFirewallAction, "invalid firewall rule allow-web: firewall rule must have either allow or deny block"},
+	}
+	if len(result.Errors) != len(want) {
+		t.Fatalf("Expected %d errors, got: %v", len(want), result.Errors)
+	}
+	for i, w := range want {
+		if e := result.Errors[i]; e.Path != "networking" || e.Code != w.code || e.Message != w.message {
+			t.Errorf("Error %d: expected networking [%s] %s, got %s [%s] %s", i, w.code, w.message, e.Path, e.Code, e.Message)
+		}
+	}
+
+	// Every reference to a disabled resource is reported
+	disabled := false
+	cfg = &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Networking: &config.Networking{
+			Vpcs: []*config.Vpc{{Name: "main-vpc"}, {Name: "old-vpc", Enabled: &disabled}},
+			FirewallRules: []*config.FirewallRule{
+				{Name: "allow-web", Network: "old-vpc", Direction: "INGRESS", Allow: []*config.FirewallAllow{{Protocol: "tcp", Ports: []string{"80"}}}},
+				{Name: "allow-ssh", Network: "old-vpc", Direction: "INGRESS", Allow: []*config.FirewallAllow{{Protocol: "tcp", Ports: []string{"22"}}}},
+			},
+		},
+	}
+	result = Validate(cfg)
+	var references []string
+	for _, e := range result.Errors {
+		if e.Code == CodeDisabledReference {
+			references = append(references, e.Message)
+		}
+	}
+	if len(references) != 2 {
+		t.Errorf("Expected both firewall rules to be reported, got: %v", result.Errors)
+	}
+}
+
+func TestValidateProjects(t *testing.T) {
+	newConfig := func(bucketProject string) *config.Config {
+		return &config.Config{
+			Projects: []*config.Project{
+				{Id: "web-prod-123", Name: "Web Prod", BillingAccount: "123456-ABCDEF-789012"},
+				{Id: "data-prod-123", Name: "Data Prod", BillingAccount: "123456-ABCDEF-789012"},
+			},
+			Storage: &config.Storage{
+				Buckets: []*config.StorageBucket{{Name: "data-lake-bucket", Location: "US", Project: bucketProject}},
+			},
+		}
+	}
+
+	if err := ValidateConfig(newConfig("data-prod-123")); err != nil {
+		t.Errorf("Expected valid multi-project config, got: %v", err)
+	}
+
+	duplicate := newConfig("web-prod-123")
+	duplicate.Projects[1].Id = "web-prod-123"
+
+	tests := []struct {
+		name   string
+		cfg    *config.Config
+		expect string
+	}{
+		{"unassigned resource", newConfig(""), "must specify a project"},
+		{"undeclared project", newConfig("ml-prod-123"), "references undeclared project: ml-prod-123"},
+		{"duplicate project", duplicate, "duplicate project ID"},
+	}
+
+	for _, test := range tests {
+		err := ValidateConfig(test.cfg)
+		if CodeOf(err) != CodeProjectAssignment || !strings.Contains(err.Error(), test.expect) {
+			t.Errorf("%s: expected %s error containing %q, got: %v", test.name, CodeProjectAssignment, test.expect, err)
+		}
+	}
+
+	// Each project is validated on its own
+	invalid := newConfig("data-prod-123")
+	invalid.Projects[1].BillingAccount = "invalid"
+	if err := ValidateConfig(invalid); err == nil || !strings.Contains(err.Error(), "project data-prod-123: project validation failed") {
+		t.Errorf("Expected per-project validation error, got: %v", err)
+	}
+}
+
+func TestQuotaWarnings(t *testing.T) {
+	cfg := &config.Config{
+		Project:    &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Networking: &config.Networking{},
+	}
+	for i := 0; i < 16; i++ {
+		cfg.Networking.Vpcs = append(cfg.Networking.Vpcs, &config.Vpc{Name: fmt.Sprintf("vpc-%d", i)})
+	}
+
+	warnings := quotaWarnings(cfg)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "16 vpcs declared, exceeding the project's default quota of 15") {
+		t.Errorf("Expected VPC quota warning, got: %v", warnings)
+	}
+
+	// Granted increases are respected
+	cfg.Project.QuotaLimits = map[string]int32{"vpcs": 20}
+	if warnings := quotaWarnings(cfg); len(warnings) != 0 {
+		t.Errorf("Expected no warnings within quota_limits, got: %v", warnings)
+	}
+
+	cfg.Project.QuotaLimits = map[string]int32{"vpc": 20}
+	if err := validateProject(cfg.Project); CodeOf(err) != CodeInvalidValue {
+		t.Errorf("Expected unknown quota_limits key to be rejected, got: %v", err)
+	}
+
+	// Errors are reported in resource type order
+	cfg.Project.QuotaLimits = map[string]int32{"vpcs": -1, "subnets": -1, "firewall_rules": -1}
+	for i := 0; i < 10; i++ {
+		want := "[CFG003] quota_limits firewall_rules cannot be negative\n[CFG003] quota_limits subnets cannot be negative\n[CFG003] quota_limits vpcs cannot be negative"
+		if err := validateProject(cfg.Project); err == nil || err.Error() != want {
+			t.Fatalf("Expected sorted quota_limits errors, got: %v", err)
+		}
+	}
+}
+
+func TestValidateAPIPropagationDelay(t *testing.T) {
+	tests := []struct {
+		name  string
+		delay string
+		apis  []config.GcpApi
+		code  Code
+	}{
+		{"seconds", "60s", []config.GcpApi{config.GcpApi_GCP_API_COMPUTE}, ""},
+		{"minutes", "2m", []config.GcpApi{config.GcpApi_GCP_API_COMPUTE}, ""},
+		{"not a duration", "soon", []config.GcpApi{config.GcpApi_GCP_API_COMPUTE}, CodeInvalidDuration},
+		{"zero", "0s", []config.GcpApi{config.GcpApi_GCP_API_COMPUTE}, CodeInvalidDuration},
+		{"no apis", "60s", nil, CodeInvalidValue},
+	}
+
+	for _, test := range tests {
+		err := validateProject(&config.Project{Id: "test-project-123", Apis: test.apis, ApiPropagationDelay: test.delay})
+		if CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+}
+
+func TestValidateProviderVersions(t *testing.T) {
+	tests := []struct {
+		name   string
+		google string
+		beta   string
+		code   Code
+	}{
+		{"unset", "", "", ""},
+		{"pessimistic", "~> 5.40", "~> 5.0", ""},
+		{"range", ">= 5.10, < 6.0", "", ""},
+		{"exact", "5.40.0", "= 5.40.0", ""},
+		{"prerelease", "6.0.0-beta1", "", ""},
+		{"latest", "latest", "", CodeInvalidValue},
+		{"bad operator", "^5.0", "", CodeInvalidValue},
+		{"empty part", "~> 5.0,", "", CodeInvalidValue},
+		{"bad beta", "", "5.x", CodeInvalidValue},
+	}
+
+	for _, test := range tests {
+		err := validateProject(&config.Project{Id: "test-project-123", GoogleProviderVersion: test.google, GoogleBetaProviderVersion: test.beta})
+		if CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+
+	// Both invalid constraints are reported, always in field order
+	for i := 0; i < 10; i++ {
+		err := validateProject(&config.Project{Id: "test-project-123", GoogleProviderVersion: "latest", GoogleBetaProviderVersion: "5.x"})
+		if err == nil || !strings.HasPrefix(err.Error(), "[CFG003] invalid google_provider_version: latest") || !strings.Contains(err.Error(), "\n[CFG003] invalid google_beta_provider_version: 5.x") {
+			t.Fatalf("Expected both constraints to be reported in order, got: %v", err)
+		}
+	}
+}
+
+func TestValidateBackend(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend *config.Backend
+		code    Code
+	}{
+		{"gcs", &config.Backend{Type: "gcs", Bucket: "tf-state-bucket", Prefix: "web/prod"}, ""},
+		{"gcs without prefix", &config.Backend{Type: "gcs", Bucket: "tf-state-bucket"}, ""},
+		{"local", &config.Backend{Type: "local"}, ""},
+		{"local with path", &config.Backend{Type: "local", Path: "state/terraform.tfstate"}, ""},
+		{"missing type", &config.Backend{Bucket: "tf-state-bucket"}, CodeRequiredField},
+		{"unsupported type", &config.Backend{Type: "s3", Bucket: "tf-state-bucket"}, CodeInvalidValue},
+		{"gcs without bucket", &config.Backend{Type: "gcs", Prefix: "web"}, CodeRequiredField},
+		{"invalid bucket", &config.Backend{Type: "gcs", Bucket: "TF_State"}, CodeInvalidValue},
+		{"gcs with path", &config.Backend{Type: "gcs", Bucket: "tf-state-bucket", Path: "x.tfstate"}, CodeInvalidValue},
+		{"local with bucket", &config.Backend{Type: "local", Bucket: "tf-state-bucket"}, CodeInvalidValue},
+	}
+
+	for _, test := range tests {
+		err := validateProject(&config.Project{Id: "test-project-123", Backend: test.backend})
+		if CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+
+	// Projects of a multi-project configuration need separate state
+	cfg := &config.Config{
+		Projects: []*config.Project{
+			{Id: "web-prod-123", Name: "Web Prod", Backend: &config.Backend{Type: "gcs", Bucket: "tf-state-bucket", Prefix: "web"}},
+			{Id: "data-prod-123", Name: "Data Prod", Backend: &config.Backend{Type: "gcs", Bucket: "tf-state-bucket", Prefix: "data"}},
+		},
+	}
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected separate prefixes to be valid, got: %v", err)
+	}
+	cfg.Projects[1].Backend.Prefix = "web/"
+	if err := ValidateConfig(cfg); CodeOf(err) != CodeInvalidValue || !strings.Contains(err.Error(), "gs://tf-state-bucket/web") {
+		t.Errorf("Expected shared state location error, got: %v", err)
+	}
+}
+
+func TestSecretValueWarnings(t *testing.T) {
+	cfg := &config.Config{
+		SecretManager: &config.SecretManager{Secrets: []*config.Secret{
+			{Name: "plain", ValueSource: &config.Secret_PlainText{PlainText: "hunter2"}},
+			{Name: "encoded", ValueSource: &config.Secret_Base64Value{Base64Value: "aHVudGVyMg=="}},
+			{Name: "from-env", ValueSource: &config.Secret_FromEnvVar{FromEnvVar: "API_KEY"}},
+			{Name: "generated", ValueSource: &config.Secret_Generate{Generate: &config.GeneratedPassword{}}},
+			{Name: "unset"},
+		}},
+	}
+
+	warnings := secretValueWarnings(cfg)
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "secret plain ") || !strings.Contains(warnings[1], "secret encoded ") {
+		t.Errorf("Expected warnings for the plain and encoded secrets, got: %v", warnings)
+	}
+}
+
+func TestGvnicWarnings(t *testing.T) {
+	template := &config.InstanceTemplate{
+		Name:              "web-template",
+		MachineType:       config.MachineType_MACHINE_TYPE_E2_MEDIUM,
+		DiskSizeGb:        20,
+		NetworkInterfaces: []*config.NetworkInterface{{Subnetwork: "web-subnet", NicType: "GVNIC"}},
+	}
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Compute: &config.Compute{InstanceTemplates: []*config.InstanceTemplate{template}},
+	}
+
+	warnings := gvnicWarnings(cfg)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "does not list the GVNIC guest OS feature") {
+		t.Errorf("Expected GVNIC guest OS feature warning, got: %v", warnings)
+	}
+
+	template.GuestOsFeatures = []string{"GVNIC"}
+	if warnings := gvnicWarnings(cfg); len(warnings) != 0 {
+		t.Errorf("Expected no warnings with the GVNIC guest OS feature, got: %v", warnings)
+	}
+
+	template.NetworkInterfaces[0].NicType = "E1000"
+	if err := validateInstanceTemplate(template); CodeOf(err) != CodeInvalidValue {
+		t.Errorf("Expected invalid NIC type to be rejected, got: %v", err)
+	}
+}
+
+func TestValidateReservedIPAddress(t *testing.T) {
+	tests := []struct {
+		name string
+		ip   *config.ReservedIp
+		code Code
+	}{
+		{"no address", &config.ReservedIp{Name: "lb-ip"}, ""},
+		{"ipv4", &config.ReservedIp{Name: "lb-ip", Address: "34.120.0.10"}, ""},
+		{"ipv6", &config.ReservedIp{Name: "lb-ip", Address: "2600:1901::1"}, ""},
+		{"internal", &config.ReservedIp{Name: "db-ip", Address: "10.0.0.5", AddressType: "INTERNAL"}, ""},
+		{"octet out of range", &config.ReservedIp{Name: "lb-ip", Address: "10.0.0.300"}, CodeReservedIPAddress},
+		{"cidr", &config.ReservedIp{Name: "lb-ip", Address: "10.0.0.0/24"}, CodeReservedIPAddress},
+		{"unknown type", &config.ReservedIp{Name: "lb-ip", AddressType: "PRIVATE"}, CodeReservedIPAddress},
+	}
+
+	for _, test := range tests {
+		err := validateNetworking(&config.Networking{ReservedIps: []*config.ReservedIp{test.ip}})
+		if CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+		if err != nil && !strings.Contains(err.Error(), test.ip.Name) {
+			t.Errorf("%s: expected error to name reserved IP %s, got: %v", test.name, test.ip.Name, err)
+		}
+	}
+}
+
+func TestReservedIPWarnings(t *testing.T) {
+	ip := &config.ReservedIp{Name: "db-ip", Address: "10.0.0.5", AddressType: "INTERNAL"}
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Networking: &config.Networking{
+			Vpcs:        []*config.Vpc{{Name: "main-vpc", Subnets: []*config.Subnet{{Name: "main-subnet", Cidr: "10.0.0.0/24"}}}},
+			ReservedIps: []*config.ReservedIp{ip},
+		},
+	}
+
+	if warnings := reservedIPWarnings(cfg); len(warnings) != 0 {
+		t.Errorf("Expected no warnings for an address in a subnet, got: %v", warnings)
+	}
+
+	ip.Address = "10.1.0.5"
+	warnings := reservedIPWarnings(cfg)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "reserved IP db-ip") {
+		t.Errorf("Expected a warning for an address outside every subnet, got: %v", warnings)
+	}
+
+	ip.AddressType = "EXTERNAL"
+	if warnings := reservedIPWarnings(cfg); len(warnings) != 0 {
+		t.Errorf("Expected no warnings for an external address, got: %v", warnings)
+	}
+}
+
+func TestMachineAvailabilityWarnings(t *testing.T) {
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Compute: &config.Compute{
+			InstanceTemplates: []*config.InstanceTemplate{{Name: "compute-template", MachineType: config.MachineType_MACHINE_TYPE_C2_STANDARD_8}},
+			InstanceGroups: []*config.InstanceGroup{{
+				Name:     "compute-group",
+				Template: "compute-template",
+				Zones:    []config.Zone{config.Zone_ZONE_US_CENTRAL1_A, config.Zone_ZONE_US_WEST2_A},
+			}},
+			Instances: []*config.Instance{
+				{Name: "web-vm", MachineType: config.MachineType_MACHINE_TYPE_E2_MEDIUM, Zone: config.Zone_ZONE_US_WEST2_B},
+				{Name: "batch-vm", MachineType: config.MachineType_MACHINE_TYPE_C2_STANDARD_4},
+			},
+		},
+	}
+
+	// e2 is offered everywhere, and the zone of batch-vm is the zone variable
+	warnings := machineAvailabilityWarnings(cfg)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "instance template compute-template of instance group compute-group uses machine family c2") || !strings.Contains(warnings[0], "ZONE_US_WEST2_A") {
+		t.Errorf("Expected a single warning for the template in us-west2, got: %v", warnings)
+	}
+
+	cfg.Compute.Instances[1].Zone = config.Zone_ZONE_US_WEST2_C
+	if warnings := machineAvailabilityWarnings(cfg); len(warnings) != 2 || !strings.Contains(warnings[1], "instance batch-vm") {
+		t.Errorf("Expected a warning for batch-vm, got: %v", warnings)
+	}
+
+	// Every family the table lists must be a family of the MachineType enum
+	families := make(map[string]bool)
+	for value := range config.MachineType_name {
+		families[instanceMachineFamily(config.MachineType(value))] = true
+	}
+	for family := range machineFamilyRegions {
+		if !families[family] {
+			t.Errorf("machineFamilyRegions lists unknown family %s", family)
+		}
+	}
+}
+
+func TestHealthCheckPortWarnings(t *testing.T) {
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Compute: &config.Compute{InstanceGroups: []*config.InstanceGroup{
+			{Name: "web-group", NamedPorts: []*config.NamedPort{{Name: "http", Port: 80}, {Name: "admin", Port: 8081}}},
+			{Name: "batch-group"},
+		}},
+		LoadBalancers: []*config.LoadBalancer{
+			{Name: "web-lb", Backend: "web-group", HealthCheck: &config.HealthCheck{Name: "web-hc", Port: 80}},
+			{Name: "batch-lb", Backend: "batch-group", HealthCheck: &config.HealthCheck{Name: "batch-hc", Port: 8080}},
+		},
+	}
+
+	// A group without named ports is a validation error rather than a warning
+	if warnings := healthCheckPortWarnings(cfg); len(warnings) != 0 {
+		t.Errorf("Expected no warnings for a health check on a named port, got: %v", warnings)
+	}
+
+	cfg.LoadBalancers[0].HealthCheck.Port = 8080
+	warnings := healthCheckPortWarnings(cfg)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "load balancer web-lb health check probes port 8080") || !strings.Contains(warnings[0], "(http:80, admin:8081)") {
+		t.Errorf("Expected a warning for the health check port, got: %v", warnings)
+	}
+}
+
+func TestValidateVpcPeerings(t *testing.T) {
+	newNetworking := func(peerings ...*config.VpcPeering) *config.Networking {
+		return &config.Networking{Vpcs: []*config.Vpc{{Name: "main-vpc"}, {Name: "data-vpc"}}, Peerings: peerings}
+	}
+	shared := "projects/shared-project/global/networks/shared-vpc"
+
+	tests := []struct {
+		name       string
+		networking *config.Networking
+		code       Code
+	}{
+		{"local peers", newNetworking(
+			&config.VpcPeering{Name: "main-to-data", Network: "main-vpc", PeerNetwork: "data-vpc", ImportCustomRoutes: true},
+			&config.VpcPeering{Name: "data-to-main", Network: "data-vpc", PeerNetwork: "main-vpc", ExportCustomRoutes: true},
+		), ""},
+		{"external peer", newNetworking(&config.VpcPeering{Name: "main-to-shared", Network: "main-vpc", PeerNetwork: shared, ImportCustomRoutes: true}), ""},
+		{"unknown network", newNetworking(&config.VpcPeering{Name: "main-to-shared", Network: "other-vpc", PeerNetwork: shared}), CodeUnknownReference},
+		{"unknown peer", newNetworking(&config.VpcPeering{Name: "main-to-other", Network: "main-vpc", PeerNetwork: "other-vpc"}), CodeUnknownReference},
+		{"self peering", newNetworking(&config.VpcPeering{Name: "main-to-main", Network: "main-vpc", PeerNetwork: "main-vpc"}), CodeVpcPeering},
+		{"invalid name", newNetworking(&config.VpcPeering{Name: "main-to-shared_", Network: "main-vpc", PeerNetwork: shared}), CodeVpcPeering},
+		{"duplicate name", newNetworking(
+			&config.VpcPeering{Name: "main-to-shared", Network: "main-vpc", PeerNetwork: shared},
+			&config.VpcPeering{Name: "main-to-shared", Network: "data-vpc", PeerNetwork: shared},
+		), CodeDuplicateName},
+		{"duplicate pair", newNetworking(
+			&config.VpcPeering{Name: "main-to-shared", Network: "main-vpc", PeerNetwork: shared},
+			&config.VpcPeering{Name: "main-to-shared-2", Network: "main-vpc", PeerNetwork: shared},
+		), CodeVpcPeering},
+		{"import without export", newNetworking(
+			&config.VpcPeering{Name: "main-to-data", Network: "main-vpc", PeerNetwork: "data-vpc", ImportCustomRoutes: true},
+			&config.VpcPeering{Name: "data-to-main", Network: "data-vpc", PeerNetwork: "main-vpc"},
+		), CodeVpcPeering},
+	}
+
+	for _, test := range tests {
+		err := validateNetworking(test.networking)
+		if CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+		if err != nil && !strings.Contains(err.Error(), "main-to-") {
+			t.Errorf("%s: expected the error to name the peering, got: %v", test.name, err)
+		}
+	}
+}
+
+func TestValidateAutoScaling(t *testing.T) {
+	tests := []struct {
+		name        string
+		autoScaling *config.AutoScaling
+		code        Code
+	}{
+		{"valid", &config.AutoScaling{Min: 1, Max: 5, CpuTarget: 0.6, CooldownPeriod: 90}, ""},
+		{"default cooldown", &config.AutoScaling{Min: 0, Max: 5, CpuTarget: 0.6}, ""},
+		{"drained", &config.AutoScaling{Min: 0, Max: 0, CpuTarget: 0.6}, ""},
+		{"negative min", &config.AutoScaling{Min: -1, Max: 5, CpuTarget: 0.6}, CodeAutoscalingBounds},
+		{"min above max", &config.AutoScaling{Min: 6, Max: 5, CpuTarget: 0.6}, CodeAutoscalingBounds},
+		{"zero CPU target", &config.AutoScaling{Min: 1, Max: 5}, CodeAutoscalingBounds},
+		{"negative cooldown", &config.AutoScaling{Min: 1, Max: 5, CpuTarget: 0.6, CooldownPeriod: -30}, CodeAutoscalingBounds},
+	}
+
+	for _, test := range tests {
+		group := &config.InstanceGroup{Name: "web-group", Template: "web-template", AutoScaling: test.autoScaling}
+		if err := validateInstanceGroup(group); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+
+	// Errors name the instance group
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Compute: &config.Compute{InstanceGroups: []*config.InstanceGroup{
+			{Name: "web-group", Template: "web-template", AutoScaling: &config.AutoScaling{Min: -1, Max: 5, CpuTarget: 0.6}},
+		}},
+	}
+	if err := ValidateConfig(cfg); err == nil || !strings.Contains(err.Error(), "web-group") {
+		t.Errorf("Expected error naming the instance group, got: %v", err)
+	}
+
+	cfg.Compute.InstanceGroups[0].AutoScaling = &config.AutoScaling{Max: 0, CpuTarget: 0.6}
+	if warnings := autoscalingWarnings(cfg); len(warnings) != 1 || !strings.Contains(warnings[0], "web-group") {
+		t.Errorf("Expected a warning naming the instance group with max 0, got: %v", warnings)
+	}
+}
+
+func TestCustomValidators(t *testing.T) {
+	saved := customValidators
+	defer func() { customValidators = saved }()
+
+	Register(func(cfg *config.Config) []Diagnostic {
+		var diags []Diagnostic
+		if cfg.Project.Labels["cost-center"] == "" {
+			diags = append(diags, Diagnostic{Severity: SeverityWarning, Message: "project has no cost-center label"})
+		}
+		if !strings.HasPrefix(cfg.Project.Id, "acme-") {
+			diags = append(diags, Diagnostic{Severity: SeverityError, Message: "project ID must start with acme-"})
+		}
+		return diags
+	})
+
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project", BillingAccount: "123456-ABCDEF-789012"},
+	}
+	err := ValidateConfig(cfg)
+	if CodeOf(err) != CodePolicyViolation || !strings.Contains(err.Error(), "project ID must start with acme-") {
+		t.Errorf("Expected custom policy error, got: %v", err)
+	}
+
+	cfg.Project.Id = "acme-project-123"
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	warnings := Warnings(cfg)
+	if len(warnings) != 1 || warnings[0] != "project has no cost-center label" {
+		t.Errorf("Expected custom warning, got: %v", warnings)
+	}
+}
+
+func TestValidateCronSchedule(t *testing.T) {
+	tests := []struct {
+		schedule string
+		valid    bool
+	}{
+		{"0 */6 * * *", true},
+		{"30 2 * * MON-FRI", true},
+		{"0 9 1,15 jan-jun 0", true},
+		{"5/10 0-23/2 * * 7", true},
+		{"* * * *", false},
+		{"60 * * * *", false},
+		{"0 24 * * *", false},
+		{"0 0 0 * *", false},
+		{"0 0 * 13 *", false},
+		{"0 0 * * FRI-MON", false},
+		{"*/0 * * * *", false},
+		{"every 5 minutes", false},
+	}
+
+	for _, test := range tests {
+		err := validateCronSchedule(test.schedule)
+		if test.valid && err != nil {
+			t.Errorf("%q: expected no error, got: %v", test.schedule, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%q: expected error, got nil", test.schedule)
+		}
+	}
+}
+
+func TestValidateScheduler(t *testing.T) {
+	pubsubTarget := func(topic string) *config.SchedulerJob_PubsubTarget {
+		return &config.SchedulerJob_PubsubTarget{PubsubTarget: &config.SchedulerPubSubTarget{Topic: topic, Data: "run"}}
+	}
+	tests := []struct {
+		name string
+		job  *config.SchedulerJob
+		code Code
+	}{
+		{"valid", &config.SchedulerJob{Name: "nightly", Schedule: "0 2 * * *", TimeZone: "Europe/Berlin", Target: pubsubTarget("jobs")}, ""},
+		{"external topic", &config.SchedulerJob{Name: "nightly", Schedule: "0 2 * * *", Target: pubsubTarget("projects/shared/topics/events")}, ""},
+		{"bad cron", &config.SchedulerJob{Name: "nightly", Schedule: "0 25 * * *", Target: pubsubTarget("jobs")}, CodeInvalidSchedule},
+		{"bad time zone", &config.SchedulerJob{Name: "nightly", Schedule: "0 2 * * *", TimeZone: "Mars/Olympus", Target: pubsubTarget("jobs")}, CodeInvalidTimeZone},
+		{"local time zone", &config.SchedulerJob{Name: "nightly", Schedule: "0 2 * * *", TimeZone: "Local", Target: pubsubTarget("jobs")}, CodeInvalidTimeZone},
+		{"undeclared topic", &config.SchedulerJob{Name: "nightly", Schedule: "0 2 * * *", Target: pubsubTarget("events")}, CodeUnknownReference},
+		{"no target", &config.SchedulerJob{Name: "nightly", Schedule: "0 2 * * *"}, CodeSchedulerTarget},
+		{"relative HTTP URI", &config.SchedulerJob{Name: "nightly", Schedule: "0 2 * * *", Target: &config.SchedulerJob_HttpTarget{HttpTarget: &config.SchedulerHttpTarget{Uri: "/refresh"}}}, CodeSchedulerTarget},
+		{"too many retries", &config.SchedulerJob{Name: "nightly", Schedule: "0 2 * * *", Target: pubsubTarget("jobs"), RetryConfig: &config.SchedulerRetryConfig{RetryCount: 6}}, CodeSchedulerRetry},
+	}
+
+	for _, test := range tests {
+		cfg := &config.Config{
+			Project:   &config.Project{Id: "test-project-123", Name: "Test Project", BillingAccount: "123456-ABCDEF-789012"},
+			Pubsub:    &config.PubSub{Topics: []*config.PubSubTopic{{Name: "jobs"}}},
+			Scheduler: &config.Scheduler{Jobs: []*config.SchedulerJob{test.job}},
+		}
+		if err := ValidateConfig(cfg); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+}
+
+func TestValidateTaskQueue(t *testing.T) {
+	tests := []struct {
+		name  string
+		queue *config.TaskQueue
+		code  Code
+	}{
+		{"valid", &config.TaskQueue{
+			Name:        "image-resize",
+			Location:    config.Region_REGION_US_CENTRAL1,
+			RateLimits:  &config.TaskQueueRateLimits{MaxDispatchesPerSecond: 50, MaxConcurrentDispatches: 100},
+			RetryConfig: &config.TaskQueueRetryConfig{MaxAttempts: 5, MinBackoff: "1s", MaxBackoff: "10m"},
+		}, ""},
+		{"unlimited attempts", &config.TaskQueue{Name: "q", Location: config.Region_REGION_US_EAST1, RetryConfig: &config.TaskQueueRetryConfig{MaxAttempts: -1}}, ""},
+		{"no location", &config.TaskQueue{Name: "q"}, CodeRequiredField},
+		{"unknown location", &config.TaskQueue{Name: "q", Location: config.Region(999)}, CodeInvalidValue},
+		{"invalid name", &config.TaskQueue{Name: "image_resize", Location: config.Region_REGION_US_CENTRAL1}, CodeInvalidValue},
+		{"negative rate", &config.TaskQueue{Name: "q", Location: config.Region_REGION_US_CENTRAL1, RateLimits: &config.TaskQueueRateLimits{MaxDispatchesPerSecond: -1}}, CodeQueueRateLimits},
+		{"rate too high", &config.TaskQueue{Name: "q", Location: config.Region_REGION_US_CENTRAL1, RateLimits: &config.TaskQueueRateLimits{MaxDispatchesPerSecond: 1000}}, CodeQueueRateLimits},
+		{"too many attempts", &config.TaskQueue{Name: "q", Location: config.Region_REGION_US_CENTRAL1, RetryConfig: &config.TaskQueueRetryConfig{MaxAttempts: 1000}}, CodeQueueRetry},
+		{"backoff reversed", &config.TaskQueue{Name: "q", Location: config.Region_REGION_US_CENTRAL1, RetryConfig: &config.TaskQueueRetryConfig{MinBackoff: "1h", MaxBackoff: "1m"}}, CodeQueueRetry},
+		{"bad duration", &config.TaskQueue{Name: "q", Location: config.Region_REGION_US_CENTRAL1, RetryConfig: &config.TaskQueueRetryConfig{MaxRetryDuration: "forever"}}, CodeInvalidDuration},
+	}
+
+	for _, test := range tests {
+		if err := validateTaskQueue(test.queue); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+}
+
+func TestValidateFilestore(t *testing.T) {
+	share := func(capacity int64) *config.FilestoreFileShare {
+		return &config.FilestoreFileShare{Name: "share1", CapacityGb: capacity}
+	}
+	zone := config.Zone_ZONE_US_CENTRAL1_A
+	tests := []struct {
+		name     string
+		instance *config.FilestoreInstance
+		code     Code
+	}{
+		{"valid", &config.FilestoreInstance{Name: "nfs", Tier: "BASIC_HDD", Zone: zone, FileShare: share(1024), Network: "main-vpc", ReservedIpRange: "10.200.0.0/29"}, ""},
+		{"regional", &config.FilestoreInstance{Name: "nfs", Tier: "ENTERPRISE", Region: config.Region_REGION_US_CENTRAL1, FileShare: share(1024), Network: "main-vpc"}, ""},
+		{"private service access", &config.FilestoreInstance{Name: "nfs", Tier: "ZONAL", Zone: zone, FileShare: share(1024), Network: "main-vpc", ConnectMode: "PRIVATE_SERVICE_ACCESS", ReservedIpRange: "filestore-range"}, ""},
+		{"no tier", &config.FilestoreInstance{Name: "nfs", Zone: zone, FileShare: share(1024), Network: "main-vpc"}, CodeRequiredField},
+		{"unknown tier", &config.FilestoreInstance{Name: "nfs", Tier: "PREMIUM", Zone: zone, FileShare: share(2560), Network: "main-vpc"}, CodeInvalidValue},
+		{"basic hdd too small", &config.FilestoreInstance{Name: "nfs", Tier: "BASIC_HDD", Zone: zone, FileShare: share(1000), Network: "main-vpc"}, CodeFilestoreCapacity},
+		{"basic ssd too small", &config.FilestoreInstance{Name: "nfs", Tier: "BASIC_SSD", Zone: zone, FileShare: share(1024), Network: "main-vpc"}, CodeFilestoreCapacity},
+		{"zonal tier with region", &config.FilestoreInstance{Name: "nfs", Tier: "BASIC_HDD", Region: config.Region_REGION_US_CENTRAL1, FileShare: share(1024), Network: "main-vpc"}, CodeFilestoreLocation},
+		{"regional tier without region", &config.FilestoreInstance{Name: "nfs", Tier: "REGIONAL", Zone: zone, FileShare: share(1024), Network: "main-vpc"}, CodeFilestoreLocation},
+		{"no file share", &config.FilestoreInstance{Name: "nfs", Tier: "BASIC_HDD", Zone: zone, Network: "main-vpc"}, CodeRequiredField},
+		{"bad share name", &config.FilestoreInstance{Name: "nfs", Tier: "BASIC_HDD", Zone: zone, FileShare: &config.FilestoreFileShare{Name: "my-share", CapacityGb: 1024}, Network: "main-vpc"}, CodeInvalidValue},
+		{"bad reserved range", &config.FilestoreInstance{Name: "nfs", Tier: "BASIC_HDD", Zone: zone, FileShare: share(1024), Network: "main-vpc", ReservedIpRange: "filestore-range"}, CodeInvalidCIDR},
+		{"no network", &config.FilestoreInstance{Name: "nfs", Tier: "BASIC_HDD", Zone: zone, FileShare: share(1024)}, CodeRequiredField},
+		{"undeclared network", &config.FilestoreInstance{Name: "nfs", Tier: "BASIC_HDD", Zone: zone, FileShare: share(1024), Network: "other-vpc"}, CodeUnknownReference},
+	}
+
+	for _, test := range tests {
+		cfg := &config.Config{
+			Project: &config.Project{Id: "test-project-123", Name: "Test Project", BillingAccount: "123456-ABCDEF-789012"},
+			Networking: &config.Networking{
+				Vpcs: []*config.Vpc{{Name: "main-vpc", Subnets: []*config.Subnet{{Name: "main-subnet", Cidr: "10.0.1.0/24"}}}},
+			},
+			Filestore: &config.Filestore{Instances: []*config.FilestoreInstance{test.instance}},
+		}
+		if err := ValidateConfig(cfg); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+}
+
+// modified returns a copy of a valid resource changed by modify, so that test
+// cases only spell out how they differ from it
+func modified[T proto.Message](valid T, modify func(T)) T {
+	resource := proto.Clone(valid).(T)
+	modify(resource)
+	return resource
+}
+
+func TestValidateGke(t *testing.T) {
+	region := config.Region_REGION_US_CENTRAL1
+	machine := config.MachineType_MACHINE_TYPE_E2_MEDIUM
+	cluster := &config.GkeCluster{
+		Name:       "main",
+		Region:     region,
+		Network:    "gke-vpc",
+		Subnetwork: "gke-subnet",
+		NodePools:  []*config.GkeNodePool{{Name: "default", MachineType: machine, NodeCount: 1, ServiceAccount: "gke-nodes"}},
+	}
+	tests := []struct {
+		name    string
+		cluster *config.GkeCluster
+		code    Code
+	}{
+		{"valid", cluster, ""},
+		{"vpc native", modified(cluster, func(c *config.GkeCluster) { c.PodsSecondaryRange, c.ServicesSecondaryRange = "pods", "services" }), ""},
+		{"autoscaling", modified(cluster, func(c *config.GkeCluster) {
+			c.NodePools[0] = &config.GkeNodePool{Name: "default", MachineType: machine, MinNodeCount: 1, MaxNodeCount: 5}
+		}), ""},
+		{"bad name", modified(cluster, func(c *config.GkeCluster) { c.Name = "Main_Cluster" }), CodeInvalidValue},
+		{"no location", modified(cluster, func(c *config.GkeCluster) { c.Region = config.Region_REGION_UNSPECIFIED }), CodeGkeLocation},
+		{"region and zone", modified(cluster, func(c *config.GkeCluster) { c.Zone = config.Zone_ZONE_US_CENTRAL1_A }), CodeGkeLocation},
+		{"no subnetwork", modified(cluster, func(c *config.GkeCluster) { c.Subnetwork = "" }), CodeRequiredField},
+		{"bad release channel", modified(cluster, func(c *config.GkeCluster) { c.ReleaseChannel = "BETA" }), CodeInvalidValue},
+		{"only pods range", modified(cluster, func(c *config.GkeCluster) { c.PodsSecondaryRange = "pods" }), CodeGkeSecondaryRange},
+		{"same ranges", modified(cluster, func(c *config.GkeCluster) { c.PodsSecondaryRange, c.ServicesSecondaryRange = "pods", "pods" }), CodeGkeSecondaryRange},
+		{"undeclared range", modified(cluster, func(c *config.GkeCluster) { c.PodsSecondaryRange, c.ServicesSecondaryRange = "pods", "svc" }), CodeGkeSecondaryRange},
+		{"no node pools", modified(cluster, func(c *config.GkeCluster) { c.NodePools = nil }), CodeRequiredField},
+		{"duplicate node pools", modified(cluster, func(c *config.GkeCluster) { c.NodePools = append(c.NodePools, c.NodePools[0]) }), CodeDuplicateName},
+		{"no machine type", modified(cluster, func(c *config.GkeCluster) { c.NodePools[0].MachineType = config.MachineType_MACHINE_TYPE_UNSPECIFIED }), CodeRequiredField},
+		{"node count with autoscaling", modified(cluster, func(c *config.GkeCluster) { c.NodePools[0].MaxNodeCount = 3 }), CodeGkeNodePoolSize},
+		{"min above max", modified(cluster, func(c *config.GkeCluster) {
+			c.NodePools[0] = &config.GkeNodePool{Name: "default", MachineType: machine, MinNodeCount: 5, MaxNodeCount: 3}
+		}), CodeGkeNodePoolSize},
+		{"min without max", modified(cluster, func(c *config.GkeCluster) {
+			c.NodePools[0] = &config.GkeNodePool{Name: "default", MachineType: machine, MinNodeCount: 1}
+		}), CodeGkeNodePoolSize},
+		{"small disk", modified(cluster, func(c *config.GkeCluster) { c.NodePools[0].DiskSizeGb = 5 }), CodeDiskTooSmall},
+		{"undeclared network", modified(cluster, func(c *config.GkeCluster) { c.Network = "other-vpc" }), CodeUnknownReference},
+		{"undeclared subnetwork", modified(cluster, func(c *config.GkeCluster) { c.Subnetwork = "other-subnet" }), CodeUnknownReference},
+		{"undeclared service account", modified(cluster, func(c *config.GkeCluster) { c.NodePools[0].ServiceAccount = "other-sa" }), CodeUnknownReference},
+	}
+
+	for _, test := range tests {
+		cfg := &config.Config{
+			Project: &config.Project{Id: "test-project-123", Name: "Test Project", BillingAccount: "123456-ABCDEF-789012"},
+			Networking: &config.Networking{
+				Vpcs: []*config.Vpc{
+					{Name: "gke-vpc", Subnets: []*config.Subnet{{
+						Name:   "gke-subnet",
+						Cidr:   "10.0.0.0/20",
+						Region: region,
+						SecondaryRanges: []*config.SecondaryRange{
+							{RangeName: "pods", IpCidrRange: "10.4.0.0/14"},
+							{RangeName: "services", IpCidrRange: "10.8.0.0/20"},
+						},
+					}}},
+				},
+			},
+			Iam: &config.Iam{ServiceAccounts: []*config.ServiceAccount{{AccountId: "gke-nodes"}}},
+			Gke: &config.Gke{Clusters: []*config.GkeCluster{test.cluster}},
+		}
+		if err := ValidateConfig(cfg); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+
+	// A subnetwork of another VPC
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project", BillingAccount: "123456-ABCDEF-789012"},
+		Networking: &config.Networking{
+			Vpcs: []*config.Vpc{
+				{Name: "gke-vpc", Subnets: []*config.Subnet{{Name: "gke-subnet", Cidr: "10.0.0.0/20", Region: region}}},
+				{Name: "other-vpc", Subnets: []*config.Subnet{{Name: "other-subnet", Cidr: "10.1.0.0/20", Region: region}}},
+			},
+		},
+		Gke: &config.Gke{Clusters: []*config.GkeCluster{{
+			Name:       "main",
+			Region:     region,
+			Network:    "gke-vpc",
+			Subnetwork: "other-subnet",
+			NodePools:  []*config.GkeNodePool{{Name: "default", MachineType: machine, NodeCount: 1}},
+		}}},
+	}
+	if err := ValidateConfig(cfg); CodeOf(err) != CodeSubnetNetworkMismatch {
+		t.Errorf("Expected code %q for a subnetwork of another VPC, got: %v", CodeSubnetNetworkMismatch, err)
+	}
+}
+
+func TestValidateDns(t *testing.T) {
+	record := func(name, recordType string, rrdatas ...string) *config.DnsRecordSet {
+		return &config.DnsRecordSet{Name: name, Type: recordType, Rrdatas: rrdatas}
+	}
+	www := record("www", "A", "203.0.113.10")
+	tests := []struct {
+		name string
+		zone *config.DnsManagedZone
+		code Code
+	}{
+		{"valid", &config.DnsManagedZone{Name: "example", DnsName: "example.com.", RecordSets: []*config.DnsRecordSet{www}}, ""},
+		{"record kinds", &config.DnsManagedZone{Name: "example", DnsName: "example.com.", RecordSets: []*config.DnsRecordSet{
+			record("", "MX", "10 mail.example.com."),
+			record("@", "TXT", `"v=spf1 -all"`),
+			record("_dmarc", "TXT", `"v=DMARC1; p=none"`),
+			record("*.api", "CNAME", "api.example.com."),
+			record("mail.example.com.", "A", "203.0.113.20"),
+		}}, ""},
+		{"private", &config.DnsManagedZone{Name: "example", DnsName: "example.com.", Visibility: "private", Networks: []string{"main-vpc"}}, ""},
+		{"bad zone name", &config.DnsManagedZone{Name: "Example_Zone", DnsName: "example.com."}, CodeInvalidValue},
+		{"no dns name", &config.DnsManagedZone{Name: "example"}, CodeRequiredField},
+		{"no trailing dot", &config.DnsManagedZone{Name: "example", DnsName: "example.com"}, CodeDnsName},
+		{"record outside zone", &config.DnsManagedZone{Name: "example", DnsName: "example.com.", RecordSets: []*config.DnsRecordSet{record("www.example.org.", "A", "203.0.113.10")}}, CodeDnsName},
+		{"bad record name", &config.DnsManagedZone{Name: "example", DnsName: "example.com.", RecordSets: []*config.DnsRecordSet{record("bad name", "A", "203.0.113.10")}}, CodeDnsName},
+		{"no type", &config.DnsManagedZone{Name: "example", DnsName: "example.com.", RecordSets: []*config.DnsRecordSet{record("api", "", "203.0.113.10")}}, CodeRequiredField},
+		{"unknown type", &config.DnsManagedZone{Name: "example", DnsName: "example.com.", RecordSets: []*config.DnsRecordSet{record("api", "ALIAS", "example.net.")}}, CodeDnsRecordSet},
+		{"no rrdatas", &config.DnsManagedZone{Name: "example", DnsName: "example.com.", RecordSets: []*config.DnsRecordSet{record("api", "A")}}, CodeDnsRecordSet},
+		{"negative ttl", &config.DnsManagedZone{Name: "example", DnsName: "example.com.", RecordSets: []*config.DnsRecordSet{{Name: "api", Type: "A", Ttl: -1, Rrdatas: []string{"203.0.113.10"}}}}, CodeDnsRecordSet},
+		{"duplicate record set", &config.DnsManagedZone{Name: "example", DnsName: "example.com.", RecordSets: []*config.DnsRecordSet{www, record("www.example.com.", "A", "203.0.113.11")}}, CodeDnsRecordSet},
+		{"cname with two values", &config.DnsManagedZone{Name: "example", DnsName: "example.com.", RecordSets: []*config.DnsRecordSet{record("api", "CNAME", "a.example.net.", "b.example.net.")}}, CodeDnsRecordSet},
+		{"cname at apex", &config.DnsManagedZone{Name: "example", DnsName: "example.com.", RecordSets: []*config.DnsRecordSet{record("@", "CNAME", "example.net.")}}, CodeDnsRecordSet},
+		{"bad visibility", &config.DnsManagedZone{Name: "example", DnsName: "example.com.", Visibility: "internal"}, CodeDnsVisibility},
+		{"private without networks", &config.DnsManagedZone{Name: "example", DnsName: "example.com.", Visibility: "private"}, CodeDnsVisibility},
+		{"public with networks", &config.DnsManagedZone{Name: "example", DnsName: "example.com.", Networks: []string{"main-vpc"}}, CodeDnsVisibility},
+		{"undeclared network", &config.DnsManagedZone{Name: "example", DnsName: "example.com.", Visibility: "private", Networks: []string{"other-vpc"}}, CodeUnknownReference},
+	}
+
+	for _, test := range tests {
+		cfg := &config.Config{
+			Project: &config.Project{Id: "test-project-123", Name: "Test Project", BillingAccount: "123456-ABCDEF-789012"},
+			Networking: &config.Networking{
+				Vpcs: []*config.Vpc{{Name: "main-vpc", Subnets: []*config.Subnet{{Name: "main-subnet", Cidr: "10.0.1.0/24"}}}},
+			},
+			Dns: &config.Dns{ManagedZones: []*config.DnsManagedZone{test.zone}},
+		}
+		if err := ValidateConfig(cfg); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+
+	// A private zone can't resolve from a disabled VPC
+	disabled := false
+	cfg := &config.Config{
+		Project:    &config.Project{Id: "test-project-123", Name: "Test Project", BillingAccount: "123456-ABCDEF-789012"},
+		Networking: &config.Networking{Vpcs: []*config.Vpc{{Name: "main-vpc", Enabled: &disabled}}},
+		Dns:        &config.Dns{ManagedZones: []*config.DnsManagedZone{{Name: "example", DnsName: "example.com.", Visibility: "private", Networks: []string{"main-vpc"}}}},
+	}
+	if err := ValidateConfig(cfg); CodeOf(err) != CodeDisabledReference {
+		t.Errorf("Expected a disabled reference error, got: %v", err)
+	}
+}
+
+func TestValidateBigQuery(t *testing.T) {
+	field := func(name, fieldType string, nested ...*config.BigQueryField) *config.BigQueryField {
+		return &config.BigQueryField{Name: name, Type: fieldType, Fields: nested}
+	}
+	dataset := &config.BigQueryDataset{
+		DatasetId: "analytics",
+		Location:  "US",
+		Access:    []*config.BigQueryAccess{{Role: "OWNER", SpecialGroup: "projectOwners"}},
+	}
+	table := &config.BigQueryTable{
+		TableId:          "events",
+		Dataset:          "analytics",
+		Fields:           []*config.BigQueryField{field("id", "STRING"), field("created_at", "TIMESTAMP")},
+		TimePartitioning: &config.BigQueryTimePartitioning{Type: "DAY", Field: "created_at"},
+		Clustering:       []string{"id"},
+	}
+	tests := []struct {
+		name    string
+		dataset *config.BigQueryDataset
+		table   *config.BigQueryTable
+		code    Code
+	}{
+		{"valid", dataset, table, ""},
+		{"schema json", dataset, modified(table, func(tbl *config.BigQueryTable) {
+			tbl.Fields, tbl.SchemaJson = nil, `[{"name": "id", "type": "STRING"}]`
+		}), ""},
+		{"record field", dataset, modified(table, func(tbl *config.BigQueryTable) {
+			tbl.Fields = append(tbl.Fields, field("payload", "RECORD", field("key", "STRING")))
+		}), ""},
+		{"no dataset id", modified(dataset, func(d *config.BigQueryDataset) { d.DatasetId = "" }), nil, CodeRequiredField},
+		{"hyphenated dataset id", modified(dataset, func(d *config.BigQueryDataset) { d.DatasetId = "web-analytics" }), nil, CodeBigQueryName},
+		{"long dataset id", modified(dataset, func(d *config.BigQueryDataset) { d.DatasetId = strings.Repeat("a", 1025) }), nil, CodeBigQueryName},
+		{"short table expiration", modified(dataset, func(d *config.BigQueryDataset) { d.DefaultTableExpiration = "30m" }), nil, CodeInvalidValue},
+		{"access without role", modified(dataset, func(d *config.BigQueryDataset) { d.Access[0].Role = "" }), nil, CodeRequiredField},
+		{"access with two principals", modified(dataset, func(d *config.BigQueryDataset) { d.Access[0].Domain = "example.com" }), nil, CodeBigQueryAccess},
+		{"unknown special group", modified(dataset, func(d *config.BigQueryDataset) { d.Access[0].SpecialGroup = "everyone" }), nil, CodeBigQueryAccess},
+		{"bad table id", dataset, modified(table, func(tbl *config.BigQueryTable) { tbl.TableId = "daily events" }), CodeBigQueryName},
+		{"table without dataset", dataset, modified(table, func(tbl *config.BigQueryTable) { tbl.Dataset = "" }), CodeRequiredField},
+		{"undeclared dataset", dataset, modified(table, func(tbl *config.BigQueryTable) { tbl.Dataset = "warehouse" }), CodeUnknownReference},
+		{"fields and schema json", dataset, modified(table, func(tbl *config.BigQueryTable) { tbl.SchemaJson = "[]" }), CodeBigQuerySchema},
+		{"malformed schema json", dataset, modified(table, func(tbl *config.BigQueryTable) { tbl.Fields, tbl.SchemaJson = nil, `{"name": "id"}` }), CodeBigQuerySchema},
+		{"unknown field type", dataset, modified(table, func(tbl *config.BigQueryTable) { tbl.Fields[0].Type = "VARCHAR" }), CodeBigQuerySchema},
+		{"record without fields", dataset, modified(table, func(tbl *config.BigQueryTable) { tbl.Fields[0].Type = "RECORD" }), CodeBigQuerySchema},
+		{"bad partitioning type", dataset, modified(table, func(tbl *config.BigQueryTable) { tbl.TimePartitioning.Type = "WEEK" }), CodeBigQuerySchema},
+		{"partitioning on string", dataset, modified(table, func(tbl *config.BigQueryTable) { tbl.TimePartitioning.Field = "id" }), CodeBigQuerySchema},
+		{"unknown clustering field", dataset, modified(table, func(tbl *config.BigQueryTable) { tbl.Clustering = []string{"user"} }), CodeBigQuerySchema},
+		{"too many clustering fields", dataset, modified(table, func(tbl *config.BigQueryTable) { tbl.Clustering = []string{"a", "b", "c", "d", "e"} }), CodeBigQuerySchema},
+	}
+
+	for _, test := range tests {
+		bigquery := &config.BigQuery{Datasets: []*config.BigQueryDataset{test.dataset}}
+		if test.table != nil {
+			bigquery.Tables = []*config.BigQueryTable{test.table}
+		}
+		cfg := &config.Config{
+			Project:  &config.Project{Id: "test-project-123", Name: "Test Project", BillingAccount: "123456-ABCDEF-789012"},
+			Bigquery: bigquery,
+		}
+		if err := ValidateConfig(cfg); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+
+	// A table can't live in a disabled dataset
+	disabled := false
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project", BillingAccount: "123456-ABCDEF-789012"},
+		Bigquery: &config.BigQuery{
+			Datasets: []*config.BigQueryDataset{modified(dataset, func(d *config.BigQueryDataset) { d.Enabled = &disabled })},
+			Tables:   []*config.BigQueryTable{table},
+		},
+	}
+	if err := ValidateConfig(cfg); CodeOf(err) != CodeDisabledReference {
+		t.Errorf("Expected a disabled reference error, got: %v", err)
+	}
+}
+
+func TestValidateLifecycleRules(t *testing.T) {
+	rule := func(action, class string, age int32) *config.LifecycleRule {
+		return &config.LifecycleRule{
+			Action:    &config.LifecycleAction{Type: action, StorageClass: class},
+			Condition: &config.LifecycleCondition{Age: age},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		rules []*config.LifecycleRule
+		code  Code
+	}{
+		{"none", nil, ""},
+		{"transition then delete", []*config.LifecycleRule{rule("SetStorageClass", "NEARLINE", 30), rule("SetStorageClass", "ARCHIVE", 90), rule("Delete", "", 365)}, ""},
+		{"delete by date", []*config.LifecycleRule{rule("SetStorageClass", "NEARLINE", 30), {
+			Action:    &config.LifecycleAction{Type: "Delete"},
+			Condition: &config.LifecycleCondition{CreatedBefore: "2024-01-01", MatchesStorageClass: []string{"REGIONAL"}},
+		}}, ""},
+		{"delete before transition", []*config.LifecycleRule{rule("SetStorageClass", "NEARLINE", 10), rule("Delete", "", 5)}, CodeLifecycleRule},
+		{"delete with transition", []*config.LifecycleRule{rule("SetStorageClass", "NEARLINE", 10), rule("Delete", "", 10)}, CodeLifecycleRule},
+		{"bad action", []*config.LifecycleRule{rule("Archive", "", 10)}, CodeLifecycleRule},
+		{"missing target class", []*config.LifecycleRule{rule("SetStorageClass", "", 10)}, CodeLifecycleRule},
+		{"bad target class", []*config.LifecycleRule{rule("SetStorageClass", "REGIONAL", 10)}, CodeLifecycleRule},
+		{"delete with class", []*config.LifecycleRule{rule("Delete", "NEARLINE", 10)}, CodeLifecycleRule},
+		{"negative age", []*config.LifecycleRule{rule("Delete", "", -1)}, CodeLifecycleRule},
+		{"bad created_before", []*config.LifecycleRule{{
+			Action:    &config.LifecycleAction{Type: "Delete"},
+			Condition: &config.LifecycleCondition{CreatedBefore: "01/01/2024"},
+		}}, CodeLifecycleRule},
+	}
+
+	for _, test := range tests {
+		err := validateLifecycleRules(test.rules)
+		if test.code == "" && err != nil {
+			t.Errorf("%s: expected no error, got: %v", test.name, err)
+		}
+		if test.code != "" && CodeOf(err) != test.code {
+			t.Errorf("%s: expected %s error, got: %v", test.name, test.code, err)
+		}
+	}
+
+	// Errors name the bucket and the rule
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Storage: &config.Storage{Buckets: []*config.StorageBucket{{
+			Name:           "test-project-123-logs",
+			Location:       "US",
+			LifecycleRules: []*config.LifecycleRule{rule("SetStorageClass", "NEARLINE", 10), rule("Delete", "", 5)},
+		}}},
+	}
+	err := ValidateConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "test-project-123-logs") || !strings.Contains(err.Error(), "lifecycle rule 1") {
+		t.Errorf("Expected error naming the bucket and rule, got: %v", err)
+	}
+}
+
+func TestValidateCloudFunctions(t *testing.T) {
+	function := &config.CloudFunction{
+		Name:           "on-event",
+		Runtime:        "python312",
+		EntryPoint:     "handler",
+		SourceBucket:   "test-project-123-source",
+		SourceObject:   "on-event.zip",
+		ServiceAccount: "fn-runner@test-project-123.iam.gserviceaccount.com",
+		EventTrigger:   &config.CloudFunctionEventTrigger{PubsubTopic: "events"},
+	}
+	newConfig := func(function *config.CloudFunction) *config.Config {
+		return &config.Config{
+			Project:        &config.Project{Id: "test-project-123", Name: "Test Project", BillingAccount: "123456-ABCDEF-789012"},
+			Iam:            &config.Iam{ServiceAccounts: []*config.ServiceAccount{{AccountId: "fn-runner"}}},
+			Storage:        &config.Storage{Buckets: []*config.StorageBucket{{Name: "test-project-123-source", Location: "US"}}},
+			Pubsub:         &config.PubSub{Topics: []*config.PubSubTopic{{Name: "events"}}},
+			CloudFunctions: &config.CloudFunctions{Functions: []*config.CloudFunction{function}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		function *config.CloudFunction
+		code     Code
+	}{
+		{"valid", function, ""},
+		{"http", modified(function, func(f *config.CloudFunction) { f.EventTrigger, f.TimeoutSeconds = nil, 3600 }), ""},
+		{"external topic", modified(function, func(f *config.CloudFunction) { f.EventTrigger.PubsubTopic = "projects/other-project/topics/events" }), ""},
+		{"storage event", modified(function, func(f *config.CloudFunction) {
+			f.EventTrigger = &config.CloudFunctionEventTrigger{EventType: "google.cloud.storage.object.v1.finalized", EventFilters: map[string]string{"bucket": "uploads"}}
+		}), ""},
+		{"bad name", modified(function, func(f *config.CloudFunction) { f.Name = "On_Event" }), CodeInvalidValue},
+		{"no runtime", modified(function, func(f *config.CloudFunction) { f.Runtime = "" }), CodeFunctionSource},
+		{"bad runtime", modified(function, func(f *config.CloudFunction) { f.Runtime = "python-3.12" }), CodeFunctionSource},
+		{"no source object", modified(function, func(f *config.CloudFunction) { f.SourceObject = "" }), CodeFunctionSource},
+		{"bad memory", modified(function, func(f *config.CloudFunction) { f.AvailableMemory = "256MB" }), CodeFunctionScaling},
+		{"min above max", modified(function, func(f *config.CloudFunction) { f.MinInstances, f.MaxInstances = 3, 2 }), CodeFunctionScaling},
+		{"event timeout", modified(function, func(f *config.CloudFunction) { f.TimeoutSeconds = 600 }), CodeFunctionScaling},
+		{"empty trigger", modified(function, func(f *config.CloudFunction) { f.EventTrigger.PubsubTopic = "" }), CodeFunctionTrigger},
+		{"topic with other event type", modified(function, func(f *config.CloudFunction) { f.EventTrigger.EventType = "google.cloud.storage.object.v1.finalized" }), CodeFunctionTrigger},
+		{"pubsub event without topic", modified(function, func(f *config.CloudFunction) {
+			f.EventTrigger = &config.CloudFunctionEventTrigger{EventType: "google.cloud.pubsub.topic.v1.messagePublished"}
+		}), CodeFunctionTrigger},
+		{"undeclared topic", modified(function, func(f *config.CloudFunction) { f.EventTrigger.PubsubTopic = "orders" }), CodeUnknownReference},
+		{"undeclared service account", modified(function, func(f *config.CloudFunction) { f.ServiceAccount = "worker@test-project-123.iam.gserviceaccount.com" }), CodeUnknownReference},
+		{"undeclared trigger service account", modified(function, func(f *config.CloudFunction) {
+			f.EventTrigger.ServiceAccount = "invoker@test-project-123.iam.gserviceaccount.com"
+		}), CodeUnknownReference},
+	}
+
+	for _, test := range tests {
+		if err := ValidateConfig(newConfig(test.function)); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+
+	// A function can't be triggered by a disabled topic
+	disabled := false
+	cfg := newConfig(function)
+	cfg.Pubsub.Topics[0].Enabled = &disabled
+	if err := ValidateConfig(cfg); CodeOf(err) != CodeDisabledReference {
+		t.Errorf("Expected a disabled reference error, got: %v", err)
+	}
+}
+
+func TestValidateArtifactRegistry(t *testing.T) {
+	repository := &config.ArtifactRepository{
+		RepositoryId: "containers",
+		Format:       config.ArtifactFormat_ARTIFACT_FORMAT_DOCKER,
+		CleanupPolicies: []*config.ArtifactCleanupPolicy{
+			{Id: "delete-untagged", Action: "DELETE", TagState: "UNTAGGED", OlderThan: "30d"},
+			{Id: "keep-recent", Action: "KEEP", KeepCount: 10},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		repository *config.ArtifactRepository
+		code       Code
+	}{
+		{"valid", repository, ""},
+		{"maven", modified(repository, func(r *config.ArtifactRepository) {
+			r.Format, r.CleanupPolicies = config.ArtifactFormat_ARTIFACT_FORMAT_MAVEN, nil
+		}), ""},
+		{"package prefix delete", modified(repository, func(r *config.ArtifactRepository) {
+			r.CleanupPolicies = []*config.ArtifactCleanupPolicy{{Id: "drop-test", Action: "DELETE", PackageNamePrefixes: []string{"test-"}}}
+		}), ""},
+		{"uppercase id", modified(repository, func(r *config.ArtifactRepository) { r.RepositoryId = "Containers" }), CodeArtifactRepositoryId},
+		{"trailing hyphen", modified(repository, func(r *config.ArtifactRepository) { r.RepositoryId = "containers-" }), CodeArtifactRepositoryId},
+		{"no format", modified(repository, func(r *config.ArtifactRepository) { r.Format = config.ArtifactFormat_ARTIFACT_FORMAT_UNSPECIFIED }), CodeArtifactFormat},
+		{"unknown format", modified(repository, func(r *config.ArtifactRepository) { r.Format = config.ArtifactFormat(42) }), CodeArtifactFormat},
+		{"bad action", modified(repository, func(r *config.ArtifactRepository) { r.CleanupPolicies[0].Action = "ARCHIVE" }), CodeArtifactCleanup},
+		{"duplicate policy", modified(repository, func(r *config.ArtifactRepository) { r.CleanupPolicies[1].Id = "delete-untagged" }), CodeArtifactCleanup},
+		{"bad tag state", modified(repository, func(r *config.ArtifactRepository) { r.CleanupPolicies[0].TagState = "SOME" }), CodeArtifactCleanup},
+		{"bad duration", modified(repository, func(r *config.ArtifactRepository) { r.CleanupPolicies[0].OlderThan = "a month" }), CodeInvalidDuration},
+		{"unconditional delete", modified(repository, func(r *config.ArtifactRepository) {
+			r.CleanupPolicies[0] = &config.ArtifactCleanupPolicy{Id: "delete-all", Action: "DELETE"}
+		}), CodeArtifactCleanup},
+		{"delete keep count", modified(repository, func(r *config.ArtifactRepository) { r.CleanupPolicies[1].Action = "DELETE" }), CodeArtifactCleanup},
+		{"keep count with condition", modified(repository, func(r *config.ArtifactRepository) { r.CleanupPolicies[1].TagState = "TAGGED" }), CodeArtifactCleanup},
+	}
+
+	for _, test := range tests {
+		cfg := &config.Config{
+			Project:          &config.Project{Id: "test-project-123", Name: "Test Project"},
+			ArtifactRegistry: &config.ArtifactRegistry{Repositories: []*config.ArtifactRepository{test.repository}},
+		}
+		if err := ValidateConfig(cfg); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+
+	cfg := &config.Config{
+		Project:          &config.Project{Id: "test-project-123", Name: "Test Project"},
+		ArtifactRegistry: &config.ArtifactRegistry{Repositories: []*config.ArtifactRepository{repository, repository}},
+	}
+	if err := ValidateConfig(cfg); CodeOf(err) != CodeDuplicateName {
+		t.Errorf("Expected a duplicate name error, got: %v", err)
+	}
+}
+
+func TestValidateGeneratedPassword(t *testing.T) {
+	noSpecial := false
+	tests := []struct {
+		name     string
+		password *config.GeneratedPassword
+		code     Code
+	}{
+		{"defaults", &config.GeneratedPassword{}, ""},
+		{"complexity", &config.GeneratedPassword{Length: 24, MinLower: 4, MinUpper: 4, MinNumeric: 4, MinSpecial: 2, OverrideSpecial: "!#%-_"}, ""},
+		{"no special", &config.GeneratedPassword{Length: 16, Special: &noSpecial}, ""},
+		{"too short", &config.GeneratedPassword{Length: 8}, CodeGeneratedPassword},
+		{"too long", &config.GeneratedPassword{Length: 1024}, CodeGeneratedPassword},
+		{"negative minimum", &config.GeneratedPassword{MinUpper: -1}, CodeGeneratedPassword},
+		{"minimums exceed length", &config.GeneratedPassword{Length: 12, MinLower: 6, MinUpper: 6, MinNumeric: 1}, CodeGeneratedPassword},
+		{"minimums exceed default length", &config.GeneratedPassword{MinNumeric: 33}, CodeGeneratedPassword},
+		{"min special without special", &config.GeneratedPassword{Special: &noSpecial, MinSpecial: 1}, CodeGeneratedPassword},
+		{"override special without special", &config.GeneratedPassword{Special: &noSpecial, OverrideSpecial: "!"}, CodeGeneratedPassword},
+		{"letters in override special", &config.GeneratedPassword{OverrideSpecial: "!a"}, CodeGeneratedPassword},
+		{"space in override special", &config.GeneratedPassword{OverrideSpecial: "! "}, CodeGeneratedPassword},
+	}
+
+	for _, test := range tests {
+		if err := validateGeneratedPassword(test.password); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+}
+
+func TestValidateGeneratedPasswordUsers(t *testing.T) {
+	tests := []struct {
+		name string
+		user *config.CloudSqlUser
+		code Code
+	}{
+		{"generated", &config.CloudSqlUser{Name: "root", GeneratePassword: &config.GeneratedPassword{}}, ""},
+		{"password and generated", &config.CloudSqlUser{Name: "root", Password: "hunter2", GeneratePassword: &config.GeneratedPassword{}}, CodeInvalidValue},
+		{"IAM user", &config.CloudSqlUser{Name: "app@example.com", Type: "CLOUD_IAM_USER", GeneratePassword: &config.GeneratedPassword{}}, CodeInvalidValue},
+		{"invalid settings", &config.CloudSqlUser{Name: "root", GeneratePassword: &config.GeneratedPassword{Length: 4}}, CodeGeneratedPassword},
+	}
+
+	for _, test := range tests {
+		databases := &config.Databases{
+			CloudSqlInstances: []*config.CloudSqlInstance{{Name: "main-db", Users: []*config.CloudSqlUser{test.user}}},
+		}
+		if err := validateDatabases(databases); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+}
+
+func TestValidateHealthCheck(t *testing.T) {
+	check := &config.HealthCheck{Name: "web-health-check", Port: 80, CheckIntervalSec: 10, TimeoutSec: 5}
+
+	tests := []struct {
+		name string
+		hc   *config.HealthCheck
+		code Code
+	}{
+		{"default type", modified(check, func(hc *config.HealthCheck) { hc.RequestPath = "/health" }), ""},
+		{"https", modified(check, func(hc *config.HealthCheck) { hc.Type, hc.RequestPath, hc.Host = "HTTPS", "/health", "app.example.com" }), ""},
+		{"tcp", modified(check, func(hc *config.HealthCheck) { hc.Type, hc.ProxyHeader = "TCP", "PROXY_V1" }), ""},
+		{"unknown type", modified(check, func(hc *config.HealthCheck) { hc.Type = "GRPC" }), CodeHealthCheck},
+		{"relative path", modified(check, func(hc *config.HealthCheck) { hc.RequestPath = "health" }), CodeHealthCheck},
+		{"http proxy header", modified(check, func(hc *config.HealthCheck) { hc.ProxyHeader = "PROXY_V1" }), CodeHealthCheck},
+		{"tcp request path", modified(check, func(hc *config.HealthCheck) { hc.Type, hc.RequestPath = "TCP", "/health" }), CodeHealthCheck},
+		{"tcp bad proxy header", modified(check, func(hc *config.HealthCheck) { hc.Type, hc.ProxyHeader = "TCP", "PROXY_V2" }), CodeHealthCheck},
+	}
+
+	for _, test := range tests {
+		if err := validateHealthCheck(test.hc); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+}
+
+func TestValidateLoadBalancerSsl(t *testing.T) {
+	tests := []struct {
+		name string
+		lb   *config.LoadBalancer
+		code Code
+	}{
+		{"http only", &config.LoadBalancer{Name: "web-lb"}, ""},
+		{"managed domains", &config.LoadBalancer{Name: "web-lb", SslEnabled: true, ManagedDomains: []string{"example.com", "www.example.com."}}, ""},
+		{"existing certificate", &config.LoadBalancer{Name: "web-lb", SslEnabled: true, SslCertificate: "web-cert"}, ""},
+		{"no certificate", &config.LoadBalancer{Name: "web-lb", SslEnabled: true}, CodeLoadBalancerSsl},
+		{"both certificates", &config.LoadBalancer{Name: "web-lb", SslEnabled: true, ManagedDomains: []string{"example.com"}, SslCertificate: "web-cert"}, CodeLoadBalancerSsl},
+		{"domains without ssl", &config.LoadBalancer{Name: "web-lb", ManagedDomains: []string{"example.com"}}, CodeLoadBalancerSsl},
+		{"wildcard domain", &config.LoadBalancer{Name: "web-lb", SslEnabled: true, ManagedDomains: []string{"*.example.com"}}, CodeLoadBalancerSsl},
+		{"malformed domain", &config.LoadBalancer{Name: "web-lb", SslEnabled: true, ManagedDomains: []string{"example..com"}}, CodeLoadBalancerSsl},
+		{"duplicate domain", &config.LoadBalancer{Name: "web-lb", SslEnabled: true, ManagedDomains: []string{"example.com", "Example.com."}}, CodeLoadBalancerSsl},
+	}
+
+	for _, test := range tests {
+		if err := validateLoadBalancer(test.lb); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+}
+
+func TestValidateSqlReplicas(t *testing.T) {
+	primary := &config.CloudSqlInstance{Name: "main-db", DatabaseVersion: "POSTGRES_15", Region: config.Region_REGION_US_EAST1}
+	replica := &config.CloudSqlInstance{Name: "main-db-replica", DatabaseVersion: "POSTGRES_15", MasterInstanceName: "main-db"}
+
+	tests := []struct {
+		name    string
+		replica *config.CloudSqlInstance
+		code    Code
+	}{
+		{"valid", replica, ""},
+		{"same geography", modified(replica, func(r *config.CloudSqlInstance) { r.Region = config.Region_REGION_US_WEST1 }), ""},
+		{"other geography", modified(replica, func(r *config.CloudSqlInstance) { r.Region = config.Region_REGION_EUROPE_WEST1 }), CodeSqlReplica},
+		{"unknown master", modified(replica, func(r *config.CloudSqlInstance) { r.MasterInstanceName = "other-db" }), CodeUnknownReference},
+		{"replica of replica", modified(replica, func(r *config.CloudSqlInstance) { r.MasterInstanceName = r.Name }), CodeSqlReplica},
+		{"databases", modified(replica, func(r *config.CloudSqlInstance) { r.Databases = []*config.CloudSqlDatabase{{Name: "app"}} }), CodeSqlReplica},
+		{"users", modified(replica, func(r *config.CloudSqlInstance) { r.Users = []*config.CloudSqlUser{{Name: "app"}} }), CodeSqlReplica},
+		{"database version", modified(replica, func(r *config.CloudSqlInstance) { r.DatabaseVersion = "POSTGRES_14" }), CodeSqlReplica},
+	}
+
+	for _, test := range tests {
+		if err := validateDatabases(&config.Databases{CloudSqlInstances: []*config.CloudSqlInstance{primary, test.replica}}); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+
+	disabled := false
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Databases: &config.Databases{CloudSqlInstances: []*config.CloudSqlInstance{
+			modified(primary, func(p *config.CloudSqlInstance) { p.Enabled = &disabled }),
+			replica,
+		}},
+	}
+	if err := ValidateConfig(cfg); err == nil || !strings.Contains(err.Error(), "references master instance main-db, which is disabled") {
+		t.Errorf("Expected disabled master error, got: %v", err)
+	}
+}
+
+func TestValidateSpanner(t *testing.T) {
+	tests := []struct {
+		name     string
+		instance *config.CloudSpannerInstance
+		code     Code
+	}{
+		{"nodes", &config.CloudSpannerInstance{Name: "spanner", NodeCount: 1}, ""},
+		{"small processing units", &config.CloudSpannerInstance{Name: "spanner", ProcessingUnits: 300}, ""},
+		{"large processing units", &config.CloudSpannerInstance{Name: "spanner", ProcessingUnits: 2000}, ""},
+		{"no capacity", &config.CloudSpannerInstance{Name: "spanner"}, CodeSpannerCapacity},
+		{"both", &config.CloudSpannerInstance{Name: "spanner", NodeCount: 1, ProcessingUnits: 1000}, CodeSpannerCapacity},
+		{"negative nodes", &config.CloudSpannerInstance{Name: "spanner", NodeCount: -1}, CodeSpannerCapacity},
+		{"uneven small processing units", &config.CloudSpannerInstance{Name: "spanner", ProcessingUnits: 150}, CodeSpannerCapacity},
+		{"uneven large processing units", &config.CloudSpannerInstance{Name: "spanner", ProcessingUnits: 1500}, CodeSpannerCapacity},
+		{"empty ddl", &config.CloudSpannerInstance{Name: "spanner", NodeCount: 1, Databases: []*config.CloudSpannerDatabase{
+			{Name: "app", Ddl: []string{"CREATE TABLE Users (UserId STRING(36)) PRIMARY KEY (UserId)", " "}},
+		}}, CodeInvalidValue},
+	}
+
+	for _, test := range tests {
+		databases := &config.Databases{CloudSpannerInstances: []*config.CloudSpannerInstance{test.instance}}
+		if err := validateDatabases(databases); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+}
+
+func TestValidateInstanceGroupVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		size     int32
+		versions []*config.InstanceGroupVersion
+		code     Code
+	}{
+		{"canary percent", "", 10, []*config.InstanceGroupVersion{
+			{Name: "stable", Template: "web-v1"},
+			{Name: "canary", Template: "web-v2", TargetSizePercent: 10},
+		}, ""},
+		{"canary fixed", "", 10, []*config.InstanceGroupVersion{
+			{Template: "web-v1"},
+			{Template: "web-v2", TargetSizeFixed: 2},
+		}, ""},
+		{"single version", "", 3, []*config.InstanceGroupVersion{{Template: "web-v1"}}, ""},
+		{"template and versions", "web-v1", 3, []*config.InstanceGroupVersion{{Template: "web-v2"}}, CodeInstanceGroupVersions},
+		{"unknown template", "", 3, []*config.InstanceGroupVersion{{Template: "web-v3"}}, CodeUnknownReference},
+		{"no template", "", 3, []*config.InstanceGroupVersion{{Name: "stable"}}, CodeRequiredField},
+		{"duplicate name", "", 3, []*config.InstanceGroupVersion{
+			{Name: "v", Template: "web-v1"},
+			{Name: "v", Template: "web-v2", TargetSizeFixed: 1},
+		}, CodeDuplicateName},
+		{"no unsized version", "", 10, []*config.InstanceGroupVersion{
+			{Template: "web-v1", TargetSizePercent: 90},
+			{Template: "web-v2", TargetSizePercent: 10},
+		}, CodeInstanceGroupVersions},
+		{"two unsized versions", "", 10, []*config.InstanceGroupVersion{
+			{Template: "web-v1"},
+			{Template: "web-v2"},
+		}, CodeInstanceGroupVersions},
+		{"fixed and percent", "", 10, []*config.InstanceGroupVersion{
+			{Template: "web-v1"},
+			{Template: "web-v2", TargetSizeFixed: 1, TargetSizePercent: 10},
+		}, CodeInstanceGroupVersions},
+		{"percent over 100", "", 10, []*config.InstanceGroupVersion{
+			{Template: "web-v1"},
+			{Template: "web-v2", TargetSizePercent: 101},
+		}, CodeInstanceGroupVersions},
+		{"percents add up over 100", "", 10, []*config.InstanceGroupVersion{
+			{Name: "stable", Template: "web-v1"},
+			{Name: "canary-a", Template: "web-v2", TargetSizePercent: 60},
+			{Name: "canary-b", Template: "web-v2", TargetSizePercent: 50},
+		}, CodeInstanceGroupVersions},
+		{"fixed over group size", "", 3, []*config.InstanceGroupVersion{
+			{Template: "web-v1"},
+			{Template: "web-v2", TargetSizeFixed: 4},
+		}, CodeInstanceGroupVersions},
+	}
+
+	for _, test := range tests {
+		compute := &config.Compute{
+			InstanceTemplates: []*config.InstanceTemplate{{Name: "web-v1", DiskSizeGb: 20}, {Name: "web-v2", DiskSizeGb: 20}},
+			InstanceGroups: []*config.InstanceGroup{{
+				Name:     "web-group",
+				Template: test.template,
+				Size:     test.size,
+				Versions: test.versions,
+			}},
+		}
+		if err := validateCompute(compute); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+}
+
+func TestValidateBackendNamedPorts(t *testing.T) {
+	tests := []struct {
+		name       string
+		namedPorts []*config.NamedPort
+		portName   string
+		code       Code
+	}{
+		{"default http port", []*config.NamedPort{{Name: "http", Port: 80}}, "", ""},
+		{"custom port name", []*config.NamedPort{{Name: "app-http", Port: 8080}}, "app-http", ""},
+		{"no named ports", nil, "", CodeBackendNamedPort},
+		{"missing default port", []*config.NamedPort{{Name: "app-http", Port: 8080}}, "", CodeBackendNamedPort},
+		{"missing custom port", []*config.NamedPort{{Name: "http", Port: 80}}, "app-http", CodeBackendNamedPort},
+		{"shared port", []*config.NamedPort{{Name: "http", Port: 80}, {Name: "web", Port: 80}}, "", ""},
+		{"duplicate name", []*config.NamedPort{{Name: "http", Port: 80}, {Name: "http", Port: 8080}}, "", CodeNamedPort},
+		{"invalid name", []*config.NamedPort{{Name: "HTTP", Port: 80}}, "HTTP", CodeNamedPort},
+		{"port out of range", []*config.NamedPort{{Name: "http", Port: 65536}}, "", CodeNamedPort},
+		{"missing port", []*config.NamedPort{{Name: "http"}}, "", CodeNamedPort},
+	}
+
+	for _, test := range tests {
+		cfg := &config.Config{
+			Project: &config.Project{Id: "test-project-123", Name: "Test Project", BillingAccount: "123456-ABCDEF-789012"},
+			Compute: &config.Compute{
+				InstanceTemplates: []*config.InstanceTemplate{{Name: "web-template", DiskSizeGb: 20}},
+				InstanceGroups:    []*config.InstanceGroup{{Name: "web-group", Template: "web-template", Size: 2, NamedPorts: test.namedPorts}},
+			},
+			LoadBalancers: []*config.LoadBalancer{{Name: "web-lb", Backend: "web-group", PortName: test.portName}},
+		}
+		if err := ValidateConfig(cfg); CodeOf(err) != test.code {
+			t.Errorf("%s: expected code %q, got: %v", test.name, test.code, err)
+		}
+	}
+}
+
+func TestValidateLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		valid  bool
+	}{
+		{"valid", map[string]string{"environment": "prod", "cost-center": "eng_42"}, true},
+		{"empty value", map[string]string{"managed": ""}, true},
+		{"international", map[string]string{"équipe": "données"}, true},
+		{"uppercase key", map[string]string{"Environment": "prod"}, false},
+		{"key starting with digit", map[string]string{"1env": "prod"}, false},
+		{"uppercase value", map[string]string{"environment": "Prod"}, false},
+		{"dotted value", map[string]string{"owner": "team.web"}, false},
+		{"long value", map[string]string{"owner": strings.Repeat("a", 64)}, false},
+	}
+
+	for _, test := range tests {
+		err := validateLabels(test.labels)
+		if test.valid && err != nil {
+			t.Errorf("%s: expected no error, got: %v", test.name, err)
+		}
+		if !test.valid && CodeOf(err) != CodeInvalidLabel {
+			t.Errorf("%s: expected %s error, got: %v", test.name, CodeInvalidLabel, err)
+		}
+	}
+
+	project := &config.Project{Id: "test-project-123", ProviderDefaultLabels: map[string]string{"Team": "web"}}
+	if err := validateProject(project); err == nil || !strings.Contains(err.Error(), "provider_default_labels") {
+		t.Errorf("Expected provider_default_labels error, got: %v", err)
+	}
+
+	cfg := &config.Config{Project: &config.Project{Id: "test-project-123"}, CommonLabels: map[string]string{"Team": "web"}}
+	if err := ValidateConfig(cfg); err == nil || !strings.Contains(err.Error(), "common_labels") || CodeOf(err) != CodeInvalidLabel {
+		t.Errorf("Expected common_labels error, got: %v", err)
+	}
+}
+
+func TestValidateInterfaceSubnetworkNetwork(t *testing.T) {
+	cfg := &config.Config{
+		Project: &config.Project{Id: "test-project-123", Name: "Test Project"},
+		Networking: &config.Networking{
+			Vpcs: []*config.Vpc{
+				{Name: "web-vpc", Subnets: []*config.Subnet{{Name: "web-subnet", Cidr: "10.0.1.0/24"}}},
+				{Name: "data-vpc", Subnets: []*config.Subnet{{Name: "data-subnet", Cidr: "10.1.1.0/24"}}},
+			},
+		},
+		Compute: &config.Compute{
+			Instances: []*config.Instance{{
+				Name:              "app-vm",
+				NetworkInterfaces: []*config.NetworkInterface{{Network: "web-vpc", Subnetwork: "web-subnet"}},
+			}},
+		},
+	}
+
+	if err := validateCrossReferences(cfg); err != nil {
+		t.Errorf("Expected matching network and subnetwork to be valid, got: %v", err)
+	}
+
+	cfg.Compute.Instances[0].NetworkInterfaces[0].Subnetwork = "data-subnet"
+	err := validateCrossReferences(cfg)
+	if CodeOf(err) != CodeSubnetNetworkMismatch || !strings.Contains(err.Error(), "uses subnetwork data-subnet of VPC data-vpc but names network web-vpc") {
+		t.Errorf("Expected subnetwork mismatch error, got: %v", err)
+	}
+}
@@ -0,0 +1,18 @@
+package config
+
+import "strings"
+
+// DnsRecordName returns the fully qualified name of a record set in the zone
+// with the given DNS name. A name ending with a dot is already fully
+// qualified; "" and "@" are the zone apex, and other names are relative to
+// the zone (e.g. "www" in "example.com." is "www.example.com.").
+func DnsRecordName(dnsName, name string) string {
+	switch {
+	case name == "" || name == "@":
+		return dnsName
+	case strings.HasSuffix(name, "."):
+		return name
+	default:
+		return name + "." + dnsName
+	}
+}
@@ -0,0 +1,21 @@
+package config
+
+import "testing"
+
+func TestDnsRecordName(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"", "example.com."},
+		{"@", "example.com."},
+		{"www", "www.example.com."},
+		{"*.api", "*.api.example.com."},
+		{"mail.example.com.", "mail.example.com."},
+	}
+
+	for _, test := range tests {
+		if got := DnsRecordName("example.com.", test.name); got != test.want {
+			t.Errorf("%q: expected %s, got %s", test.name, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var (
+	// durationDaysPattern matches whole days (e.g. "30d"), which Go durations lack
+	durationDaysPattern = regexp.MustCompile(`^([0-9]+)d$`)
+	// durationSecondsPattern matches a bare number of seconds (e.g. "3600")
+	durationSecondsPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]{1,9})?$`)
+)
+
+// ParseDuration parses a duration field. It accepts Go durations (e.g. "90m",
+// "1h30m", "86400s"), whole days (e.g. "7d"), and bare seconds (e.g. "3600").
+// Negative durations are rejected.
+func ParseDuration(s string) (time.Duration, error) {
+	if match := durationDaysPattern.FindStringSubmatch(s); match != nil {
+		days, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || days > int64(time.Duration(1<<63-1)/(24*time.Hour)) {
+			return 0, fmt.Errorf("%s is out of range", s)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	value := s
+	if durationSecondsPattern.MatchString(s) {
+		value += "s"
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a duration (e.g. \"3600s\", \"90m\", \"7d\")", s)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("%s is negative", s)
+	}
+	return d, nil
+}
+
+// FormatSeconds formats a duration the way GCP APIs expect it: seconds with
+// an "s" suffix and up to nine fractional digits (e.g. "86400s", "1.5s")
+func FormatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		valid    bool
+	}{
+		{"300s", 5 * time.Minute, true},
+		{"1h30m", 90 * time.Minute, true},
+		{"7d", 7 * 24 * time.Hour, true},
+		{"3600", time.Hour, true},
+		{"0.5", 500 * time.Millisecond, true},
+		{"", 0, false},
+		{"1.5d", 0, false},
+		{"-1h", 0, false},
+		{"five minutes", 0, false},
+		{"99999999999d", 0, false},
+	}
+
+	for _, test := range tests {
+		d, err := ParseDuration(test.input)
+		if test.valid && (err != nil || d != test.expected) {
+			t.Errorf("ParseDuration(%q): expected %v, got %v (error: %v)", test.input, test.expected, d, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("ParseDuration(%q): expected error, got %v", test.input, d)
+		}
+	}
+}
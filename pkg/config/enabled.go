@@ -0,0 +1,57 @@
+package config
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// WithoutDisabled returns a copy of cfg with every resource whose enabled field
+// is explicitly set to false removed, along with the resources nested inside it
+// (e.g. the subnets of a disabled VPC). The original configuration is not modified.
+//
+// Generation and validation operate on the pruned configuration, so disabled
+// resources are not generated and references to them are treated as references
+// to undeclared resources.
+func WithoutDisabled(cfg *Config) *Config {
+	pruned := proto.Clone(cfg).(*Config)
+	pruneDisabled(pruned.ProtoReflect())
+	return pruned
+}
+
+// pruneDisabled removes disabled elements from every repeated message field of m, recursively
+func pruneDisabled(m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Kind() != protoreflect.MessageKind || fd.IsMap() {
+			return true
+		}
+
+		if !fd.IsList() {
+			pruneDisabled(v.Message())
+			return true
+		}
+
+		list := v.List()
+		kept := 0
+		for i := 0; i < list.Len(); i++ {
+			elem := list.Get(i)
+			if !isEnabled(elem.Message()) {
+				continue
+			}
+			pruneDisabled(elem.Message())
+			list.Set(kept, elem)
+			kept++
+		}
+		list.Truncate(kept)
+		return true
+	})
+}
+
+// isEnabled reports whether a resource is enabled. Resources without an
+// optional enabled field, or that leave it unset, are enabled.
+func isEnabled(m protoreflect.Message) bool {
+	fd := m.Descriptor().Fields().ByName("enabled")
+	if fd == nil || fd.Kind() != protoreflect.BoolKind || !fd.HasPresence() || !m.Has(fd) {
+		return true
+	}
+	return m.Get(fd).Bool()
+}
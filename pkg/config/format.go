@@ -0,0 +1,79 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+)
+
+// extraFieldSpace matches the separator after a field name at the start of a
+// line. The protobuf encoders randomly emit one or two spaces there (per
+// binary) to discourage byte-for-byte comparisons, which is exactly what
+// fmt --check does, so Format normalizes it to one.
+var extraFieldSpace = regexp.MustCompile(`(?m)^( *(?:[\w.\[\]/]+|"[^"]*"):) +`)
+
+// Format returns the canonical form of cfg in the given format: fields in
+// schema order, one per line, indented by two spaces. Only textproto and JSON
+// are supported; YAML has no canonical Protocol Buffer encoding.
+func Format(cfg *Config, format InputFormat) ([]byte, error) {
+	var out []byte
+	var err error
+	switch format {
+	case InputTextproto:
+		out, err = prototext.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(cfg)
+	case InputJSON:
+		out, err = protojson.MarshalOptions{Multiline: true, Indent: "  ", UseProtoNames: true}.Marshal(cfg)
+	default:
+		return nil, fmt.Errorf("formatting is not supported for %s files", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to format configuration: %w", err)
+	}
+
+	out = extraFieldSpace.ReplaceAll(out, []byte("$1 "))
+	if len(out) > 0 && out[len(out)-1] != '\n' {
+		out = append(out, '\n')
+	}
+	return out, nil
+}
+
+// StripComments removes # comments from textproto content, dropping lines
+// that only held a comment, and reports whether there were any. Quoted
+// strings are left alone, so "a#b" is not a comment.
+func StripComments(content []byte) ([]byte, bool) {
+	var out bytes.Buffer
+	found := false
+	for _, line := range bytes.SplitAfter(content, []byte("\n")) {
+		code := line
+		var quote byte
+		for i := 0; i < len(line); i++ {
+			c := line[i]
+			switch {
+			case quote != 0 && c == '\\':
+				i++
+			case quote != 0 && c == quote:
+				quote = 0
+			case quote == 0 && (c == '"' || c == '\''):
+				quote = c
+			case quote == 0 && c == '#':
+				code = line[:i]
+				i = len(line)
+			}
+		}
+
+		if len(code) == len(line) {
+			out.Write(line)
+			continue
+		}
+		found = true
+		code = bytes.TrimRight(code, " \t")
+		if len(bytes.TrimSpace(code)) > 0 {
+			out.Write(code)
+			out.WriteByte('\n')
+		}
+	}
+	return out.Bytes(), found
+}
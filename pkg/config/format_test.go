@@ -0,0 +1,100 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestFormat(t *testing.T) {
+	cfg := &Config{
+		Networking: &Networking{Vpcs: []*Vpc{{Name: "main-vpc"}}},
+		Project:    &Project{Id: "my-project-123", Labels: map[string]string{"team": "web", "env": "prod"}},
+	}
+
+	tests := []struct {
+		format InputFormat
+		want   string
+	}{
+		{InputTextproto, `project: {
+  id: "my-project-123"
+  labels: {
+    key: "env"
+    value: "prod"
+  }
+  labels: {
+    key: "team"
+    value: "web"
+  }
+}
+networking: {
+  vpcs: {
+    name: "main-vpc"
+  }
+}
+`},
+		{InputJSON, `{
+  "project": {
+    "id": "my-project-123",
+    "labels": {
+      "env": "prod",
+      "team": "web"
+    }
+  },
+  "networking": {
+    "vpcs": [
+      {
+        "name": "main-vpc"
+      }
+    ]
+  }
+}
+`},
+	}
+
+	for _, test := range tests {
+		got, err := Format(cfg, test.format)
+		if err != nil {
+			t.Errorf("%s: expected no error, got: %v", test.format, err)
+			continue
+		}
+		if string(got) != test.want {
+			t.Errorf("%s: expected:\n%s\ngot:\n%s", test.format, test.want, got)
+		}
+
+		parsed := &Config{}
+		if err := Unmarshal(got, test.format, parsed); err != nil || !proto.Equal(parsed, cfg) {
+			t.Errorf("%s: expected formatted output to round-trip, got %v (%v)", test.format, parsed, err)
+		}
+	}
+
+	if _, err := Format(cfg, InputYAML); err == nil {
+		t.Error("Expected an error formatting YAML")
+	}
+}
+
+func TestStripComments(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+		found             bool
+	}{
+		{"no comments", "project: {\n  id: \"p\"\n}\n", "project: {\n  id: \"p\"\n}\n", false},
+		{"comment lines", "# header\nproject: {\n  # id\n  id: \"p\"\n}\n", "project: {\n  id: \"p\"\n}\n", true},
+		{"trailing comment", "project: {\n  id: \"p\"  # the id\n}\n", "project: {\n  id: \"p\"\n}\n", true},
+		{"hash in strings", "name: \"a#b\"\ndesc: 'c#d'\nesc: \"e\\\"#f\"\n", "name: \"a#b\"\ndesc: 'c#d'\nesc: \"e\\\"#f\"\n", false},
+		{"no trailing newline", "id: \"p\" # x", "id: \"p\"\n", true},
+	}
+
+	for _, test := range tests {
+		got, found := StripComments([]byte(test.input))
+		if string(got) != test.want || found != test.found {
+			t.Errorf("%s: expected (%q, %v), got (%q, %v)", test.name, test.want, test.found, got, found)
+		}
+	}
+
+	// Blank lines are layout, not comments, and still make a file unformatted
+	if got, _ := StripComments([]byte("a: 1\n\nb: 2\n")); !strings.Contains(string(got), "\n\n") {
+		t.Errorf("Expected blank lines to be kept, got %q", got)
+	}
+}
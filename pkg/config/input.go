@@ -0,0 +1,195 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gopkg.in/yaml.v3"
+)
+
+// InputFormat is the file format of a configuration file
+type InputFormat string
+
+const (
+	// InputTextproto is Protocol Buffer text format (default)
+	InputTextproto InputFormat = "textproto"
+	// InputJSON is the Protocol Buffer JSON mapping
+	InputJSON InputFormat = "json"
+	// InputYAML is the Protocol Buffer JSON mapping written as YAML
+	InputYAML InputFormat = "yaml"
+)
+
+// InputFormatForFile returns the input format of a configuration file based on
+// its extension: .json for JSON, .yaml or .yml for YAML, and textproto for
+// anything else (e.g. .textproto, .txtpb, .pbtxt)
+func InputFormatForFile(filename string) InputFormat {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return InputJSON
+	case ".yaml", ".yml":
+		return InputYAML
+	default:
+		return InputTextproto
+	}
+}
+
+// Unmarshal parses content in the given format into cfg.
+//
+// JSON and YAML use the Protocol Buffer JSON mapping: fields may be written in
+// snake_case or lowerCamelCase, enums as their value names (e.g.
+// "REGION_US_CENTRAL1"), and maps as objects. Unknown fields are rejected in
+// every format, so a typo is reported instead of being ignored.
+func Unmarshal(content []byte, format InputFormat, cfg *Config) error {
+	switch format {
+	case InputTextproto:
+		if err := prototext.Unmarshal(content, cfg); err != nil {
+			return fmt.Errorf("failed to parse Protocol Buffer text format: %w", err)
+		}
+	case InputJSON:
+		if err := protojson.Unmarshal(content, cfg); err != nil {
+			return fmt.Errorf("failed to parse JSON: %w", err)
+		}
+	case InputYAML:
+		var doc yaml.Node
+		if err := yaml.Unmarshal(content, &doc); err != nil {
+			return fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		value := interface{}(map[string]interface{}{})
+		if len(doc.Content) > 0 {
+			var err error
+			if value, err = yamlMessage(doc.Content[0], cfg.ProtoReflect().Descriptor()); err != nil {
+				return fmt.Errorf("failed to parse YAML: %w", err)
+			}
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		if err := protojson.Unmarshal(data, cfg); err != nil {
+			return fmt.Errorf("failed to parse YAML: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown input format: %s", format)
+	}
+	return nil
+}
+
+// yamlMessage converts a YAML mapping to the JSON value of a message of type
+// desc. Scalars for string fields keep their text, so that unquoted values
+// such as port_range: 80 or version: 1.10 mean "80" and "1.10" as they would
+// in textproto. Unknown fields are reported with their line number.
+func yamlMessage(node *yaml.Node, desc protoreflect.MessageDescriptor) (interface{}, error) {
+	node = resolveAlias(node)
+	if node.Kind != yaml.MappingNode {
+		return yamlScalar(node)
+	}
+
+	m := make(map[string]interface{}, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+		fd := desc.Fields().ByName(protoreflect.Name(key))
+		if fd == nil {
+			fd = desc.Fields().ByJSONName(key)
+		}
+
+		if fd == nil {
+			return nil, fmt.Errorf("line %d: unknown field %q in %s", node.Content[i].Line, key, desc.Name())
+		}
+
+		var err error
+		if m[key], err = yamlField(value, fd); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return m, nil
+}
+
+// yamlField converts the YAML value of a field to its JSON value
+func yamlField(node *yaml.Node, fd protoreflect.FieldDescriptor) (interface{}, error) {
+	node = resolveAlias(node)
+	switch {
+	case fd.IsMap() && node.Kind == yaml.MappingNode:
+		m := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value, err := yamlSingular(node.Content[i+1], fd.MapValue())
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", node.Content[i].Value, err)
+			}
+			m[node.Content[i].Value] = value
+		}
+		return m, nil
+	case fd.IsList() && node.Kind == yaml.SequenceNode:
+		list := make([]interface{}, len(node.Content))
+		for i, elem := range node.Content {
+			value, err := yamlSingular(elem, fd)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			list[i] = value
+		}
+		return list, nil
+	default:
+		return yamlSingular(node, fd)
+	}
+}
+
+// yamlSingular converts a single YAML value of a field's type
+func yamlSingular(node *yaml.Node, fd protoreflect.FieldDescriptor) (interface{}, error) {
+	node = resolveAlias(node)
+	switch {
+	case fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind:
+		return yamlMessage(node, fd.Message())
+	case fd.Kind() == protoreflect.StringKind && node.Kind == yaml.ScalarNode && node.Tag != "!!null":
+		return node.Value, nil
+	default:
+		return yamlScalar(node)
+	}
+}
+
+// yamlScalar decodes a YAML value without schema information
+func yamlScalar(node *yaml.Node) (interface{}, error) {
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return nil, err
+	}
+	return jsonCompatible(value), nil
+}
+
+// resolveAlias returns the node an alias (*name) refers to
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+// jsonCompatible converts a decoded YAML value to values encoding/json can
+// marshal: YAML allows non-string map keys (e.g. {1: 2}), which JSON objects
+// don't
+func jsonCompatible(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = jsonCompatible(value)
+		}
+		return v
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = jsonCompatible(value)
+		}
+		return m
+	case []interface{}:
+		for i, value := range v {
+			v[i] = jsonCompatible(value)
+		}
+		return v
+	default:
+		return v
+	}
+}
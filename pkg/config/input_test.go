@@ -0,0 +1,114 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestInputFormatForFile(t *testing.T) {
+	tests := map[string]InputFormat{
+		"config.textproto":    InputTextproto,
+		"config.txtpb":        InputTextproto,
+		"config":              InputTextproto,
+		"config.json":         InputJSON,
+		"infra/config.yaml":   InputYAML,
+		"infra/config.YML":    InputYAML,
+		"config.yaml.example": InputTextproto,
+	}
+	for filename, want := range tests {
+		if got := InputFormatForFile(filename); got != want {
+			t.Errorf("%s: expected %s, got %s", filename, want, got)
+		}
+	}
+}
+
+func TestUnmarshalInputFormats(t *testing.T) {
+	load := func(filename string) *Config {
+		t.Helper()
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", filename, err)
+		}
+		cfg := &Config{}
+		if err := Unmarshal(content, InputFormatForFile(filename), cfg); err != nil {
+			t.Fatalf("Failed to parse %s: %v", filename, err)
+		}
+		return cfg
+	}
+
+	textproto := load("../../examples/simple.textproto")
+	if yaml := load("../../examples/simple.yaml"); !proto.Equal(textproto, yaml) {
+		t.Errorf("Expected simple.yaml to match simple.textproto, got:\n%v\n\nwant:\n%v", yaml, textproto)
+	}
+
+	data, err := protojson.Marshal(textproto)
+	if err != nil {
+		t.Fatalf("Failed to marshal JSON: %v", err)
+	}
+	fromJSON := &Config{}
+	if err := Unmarshal(data, InputJSON, fromJSON); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	if !proto.Equal(textproto, fromJSON) {
+		t.Errorf("Expected JSON to round-trip, got:\n%v", fromJSON)
+	}
+}
+
+func TestUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    *Config
+		wantErr bool
+	}{
+		{
+			name:  "empty",
+			input: "",
+			want:  &Config{},
+		},
+		{
+			name:  "camel case fields and enums",
+			input: "project:\n  id: my-project-123\n  billingAccount: 123456-ABCDEF-789012\n  apis: [GCP_API_COMPUTE]\n",
+			want:  &Config{Project: &Project{Id: "my-project-123", BillingAccount: "123456-ABCDEF-789012", Apis: []GcpApi{GcpApi_GCP_API_COMPUTE}}},
+		},
+		{
+			name:  "non-string map keys and values",
+			input: "project:\n  id: my-project-123\n  labels:\n    1: 2\n    enabled: true\n  quota_limits:\n    vpcs: 20\n",
+			want:  &Config{Project: &Project{Id: "my-project-123", Labels: map[string]string{"1": "2", "enabled": "true"}, QuotaLimits: map[string]int32{"vpcs": 20}}},
+		},
+		{
+			name:  "unquoted scalars for string fields",
+			input: "project:\n  id: 123\n  labels: {version: 1.10}\nload_balancers:\n  - name: web-lb\n    port_range: 80\n",
+			want:  &Config{Project: &Project{Id: "123", Labels: map[string]string{"version": "1.10"}}, LoadBalancers: []*LoadBalancer{{Name: "web-lb", PortRange: "80"}}},
+		},
+		{
+			name:  "anchors and aliases",
+			input: "project:\n  id: my-project-123\n  labels: &labels {team: web}\n  provider_default_labels: *labels\n",
+			want:  &Config{Project: &Project{Id: "my-project-123", Labels: map[string]string{"team": "web"}, ProviderDefaultLabels: map[string]string{"team": "web"}}},
+		},
+		{name: "unknown field", input: "project:\n  identifier: my-project-123\n", wantErr: true},
+		{name: "wrong type", input: "project: [a, b]\n", wantErr: true},
+		{name: "invalid YAML", input: "project:\n  id: [\n", wantErr: true},
+	}
+
+	for _, test := range tests {
+		cfg := &Config{}
+		err := Unmarshal([]byte(test.input), InputYAML, cfg)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error, got: %v", test.name, cfg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: expected no error, got: %v", test.name, err)
+			continue
+		}
+		if !proto.Equal(cfg, test.want) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.want, cfg)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// JSONSchemaDraft is the JSON Schema dialect JSONSchema describes Config in.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns a JSON Schema document describing Config, built from its
+// protobuf descriptors. Messages and enums are emitted once under $defs and
+// referenced by full name (e.g. custoodian.Project); properties use the proto
+// field names that fmt writes, and unknown fields are rejected. The schema
+// applies to both JSON and YAML configuration files.
+func JSONSchema() ([]byte, error) {
+	desc := (&Config{}).ProtoReflect().Descriptor()
+
+	b := &jsonSchemaBuilder{defs: map[string]any{}}
+	b.message(desc)
+
+	root := map[string]any{
+		"$schema": JSONSchemaDraft,
+		"title":   string(desc.FullName()),
+		"$defs":   b.defs,
+	}
+	for key, value := range b.defs[string(desc.FullName())].(map[string]any) {
+		root[key] = value
+	}
+
+	out, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JSON schema: %w", err)
+	}
+	return append(out, '\n'), nil
+}
+
+type jsonSchemaBuilder struct {
+	defs map[string]any
+}
+
+// ref returns a reference to the $defs entry of a message or enum.
+func (b *jsonSchemaBuilder) ref(name protoreflect.FullName) map[string]any {
+	return map[string]any{"$ref": "#/$defs/" + string(name)}
+}
+
+// message adds md and every message and enum it uses to $defs.
+func (b *jsonSchemaBuilder) message(md protoreflect.MessageDescriptor) {
+	name := string(md.FullName())
+	if _, ok := b.defs[name]; ok {
+		return
+	}
+	schema := map[string]any{
+		"type":                 "object",
+		"additionalProperties": false,
+	}
+	// Register before recursing so self-referencing messages terminate
+	b.defs[name] = schema
+
+	properties := map[string]any{}
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		properties[string(fd.Name())] = b.field(fd)
+	}
+	schema["properties"] = properties
+
+	if constraints := oneofConstraints(md); len(constraints) > 0 {
+		schema["allOf"] = constraints
+	}
+}
+
+// field returns the schema of a field, wrapping repeated fields in an array
+// and maps in an object keyed by the map key.
+func (b *jsonSchemaBuilder) field(fd protoreflect.FieldDescriptor) map[string]any {
+	switch {
+	case fd.IsMap():
+		schema := map[string]any{
+			"type":                 "object",
+			"additionalProperties": b.value(fd.MapValue()),
+		}
+		if pattern := mapKeyPattern(fd.MapKey().Kind()); pattern != "" {
+			schema["propertyNames"] = map[string]any{"pattern": pattern}
+		}
+		return schema
+	case fd.IsList():
+		return map[string]any{
+			"type":  "array",
+			"items": b.value(fd),
+		}
+	default:
+		return b.value(fd)
+	}
+}
+
+// value returns the schema of a single value of a field, following the
+// protojson encoding of its kind.
+func (b *jsonSchemaBuilder) value(fd protoreflect.FieldDescriptor) map[string]any {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		b.message(fd.Message())
+		return b.ref(fd.Message().FullName())
+	case protoreflect.EnumKind:
+		b.enum(fd.Enum())
+		return b.ref(fd.Enum().FullName())
+	case protoreflect.BoolKind:
+		return map[string]any{"type": "boolean"}
+	case protoreflect.StringKind:
+		return map[string]any{"type": "string"}
+	case protoreflect.BytesKind:
+		return map[string]any{"type": "string", "contentEncoding": "base64"}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return map[string]any{"type": "number"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return map[string]any{"type": "integer", "minimum": math.MinInt32, "maximum": math.MaxInt32}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]any{"type": "integer", "minimum": 0, "maximum": math.MaxUint32}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// protojson writes 64-bit integers as strings and accepts either
+		return map[string]any{"type": []string{"integer", "string"}, "minimum": 0, "pattern": `^[0-9]+$`}
+	default:
+		return map[string]any{"type": []string{"integer", "string"}, "pattern": `^-?[0-9]+$`}
+	}
+}
+
+// enum adds ed to $defs as the list of its value names.
+func (b *jsonSchemaBuilder) enum(ed protoreflect.EnumDescriptor) {
+	name := string(ed.FullName())
+	if _, ok := b.defs[name]; ok {
+		return
+	}
+	values := ed.Values()
+	names := make([]string, 0, values.Len())
+	for i := 0; i < values.Len(); i++ {
+		names = append(names, string(values.Get(i).Name()))
+	}
+	b.defs[name] = map[string]any{
+		"type": "string",
+		"enum": names,
+	}
+}
+
+// oneofConstraints returns a constraint for each oneof of md that allows at
+// most one of its fields to be set. Synthetic oneofs of proto3 optional
+// fields have a single field and need none.
+func oneofConstraints(md protoreflect.MessageDescriptor) []any {
+	var constraints []any
+	oneofs := md.Oneofs()
+	for i := 0; i < oneofs.Len(); i++ {
+		od := oneofs.Get(i)
+		if od.IsSynthetic() {
+			continue
+		}
+		var pairs []any
+		fields := od.Fields()
+		for a := 0; a < fields.Len(); a++ {
+			for c := a + 1; c < fields.Len(); c++ {
+				pairs = append(pairs, map[string]any{
+					"required": []string{string(fields.Get(a).Name()), string(fields.Get(c).Name())},
+				})
+			}
+		}
+		if len(pairs) > 0 {
+			constraints = append(constraints, map[string]any{"not": map[string]any{"anyOf": pairs}})
+		}
+	}
+	return constraints
+}
+
+// mapKeyPattern returns the pattern map keys of the given kind must match in
+// JSON, where every object key is a string.
+func mapKeyPattern(kind protoreflect.Kind) string {
+	switch kind {
+	case protoreflect.StringKind:
+		return ""
+	case protoreflect.BoolKind:
+		return `^(true|false)$`
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return `^[0-9]+$`
+	default:
+		return `^-?[0-9]+$`
+	}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	content, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	var schema struct {
+		Schema     string                     `json:"$schema"`
+		Type       string                     `json:"type"`
+		Properties map[string]json.RawMessage `json:"properties"`
+		Defs       map[string]struct {
+			Type       string                     `json:"type"`
+			Enum       []string                   `json:"enum"`
+			Properties map[string]json.RawMessage `json:"properties"`
+			AllOf      []json.RawMessage          `json:"allOf"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(content, &schema); err != nil {
+		t.Fatalf("Expected valid JSON, got: %v", err)
+	}
+
+	if schema.Schema != JSONSchemaDraft || schema.Type != "object" {
+		t.Errorf("Expected an object schema in %s, got %q in %q", JSONSchemaDraft, schema.Type, schema.Schema)
+	}
+
+	for _, key := range []string{"project", "networking", "compute", "load_balancers", "vars"} {
+		if _, ok := schema.Properties[key]; !ok {
+			t.Errorf("Expected top-level property %s", key)
+		}
+	}
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"project", `{"$ref":"#/$defs/custoodian.Project"}`},
+		{"load_balancers", `{"items":{"$ref":"#/$defs/custoodian.LoadBalancer"},"type":"array"}`},
+		{"vars", `{"additionalProperties":{"type":"string"},"type":"object"}`},
+	}
+	for _, test := range tests {
+		if got := string(compactJSON(t, schema.Properties[test.field])); got != test.want {
+			t.Errorf("%s: expected %s, got %s", test.field, test.want, got)
+		}
+	}
+
+	project, ok := schema.Defs["custoodian.Project"]
+	if !ok || project.Type != "object" {
+		t.Fatalf("Expected an object definition for custoodian.Project, got %+v", project)
+	}
+	if _, ok := project.Properties["billing_account"]; !ok {
+		t.Error("Expected custoodian.Project to have a billing_account property")
+	}
+
+	region := schema.Defs["custoodian.Region"]
+	if region.Type != "string" || len(region.Enum) == 0 || region.Enum[1] != "REGION_US_CENTRAL1" {
+		t.Errorf("Expected custoodian.Region to list its value names, got %+v", region)
+	}
+
+	// Only one of a secret's value sources may be set
+	if len(schema.Defs["custoodian.Secret"].AllOf) != 1 {
+		t.Errorf("Expected custoodian.Secret to constrain its oneof, got %+v", schema.Defs["custoodian.Secret"].AllOf)
+	}
+}
+
+func compactJSON(t *testing.T, raw json.RawMessage) []byte {
+	t.Helper()
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		t.Fatalf("Expected valid JSON, got: %v", err)
+	}
+	out, _ := json.Marshal(value)
+	return out
+}
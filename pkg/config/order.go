@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ResourceOrder is the order in which the resources of each collection (e.g.
+// the VPCs, or the subnets of a VPC) appear in the generated files
+type ResourceOrder string
+
+const (
+	// OrderByName sorts resources by name (default)
+	OrderByName ResourceOrder = "by-name"
+	// OrderByType groups resources that have a type field (e.g. load
+	// balancers, notification channels) by type, then sorts them by name
+	OrderByType ResourceOrder = "by-type"
+	// OrderAsDeclared keeps resources in configuration order
+	OrderAsDeclared ResourceOrder = "as-declared"
+)
+
+// ParseResourceOrder parses a resource order name. The empty string selects
+// OrderByName.
+func ParseResourceOrder(s string) (ResourceOrder, error) {
+	switch order := ResourceOrder(s); order {
+	case "":
+		return OrderByName, nil
+	case OrderByName, OrderByType, OrderAsDeclared:
+		return order, nil
+	default:
+		return "", fmt.Errorf("unknown resource order: %s (must be %s, %s, or %s)", s, OrderByName, OrderByType, OrderAsDeclared)
+	}
+}
+
+// Sorted returns a copy of cfg with the resources of every collection in the
+// given order. The original configuration is not modified.
+//
+// Only collections of resources, i.e. messages with both an enabled field and
+// a name (or, for service accounts and custom roles, an account_id or role_id),
+// are reordered. Lists whose order is significant, such as network interfaces
+// or traffic splits, keep their declared order.
+func Sorted(cfg *Config, order ResourceOrder) *Config {
+	sorted := proto.Clone(cfg).(*Config)
+	if order != OrderAsDeclared {
+		sortResources(sorted.ProtoReflect(), order == OrderByType)
+	}
+	return sorted
+}
+
+// sortResources stably sorts every repeated resource field of m, recursively
+func sortResources(m protoreflect.Message, byType bool) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Kind() != protoreflect.MessageKind || fd.IsMap() {
+			return true
+		}
+
+		if !fd.IsList() {
+			sortResources(v.Message(), byType)
+			return true
+		}
+
+		list := v.List()
+		elems := make([]protoreflect.Value, list.Len())
+		for i := range elems {
+			elems[i] = list.Get(i)
+			sortResources(elems[i].Message(), byType)
+		}
+
+		desc := fd.Message()
+		name := resourceNameField(desc)
+		if name == nil || desc.Fields().ByName("enabled") == nil {
+			return true
+		}
+		typ := desc.Fields().ByName("type")
+
+		sort.SliceStable(elems, func(i, j int) bool {
+			a, b := elems[i].Message(), elems[j].Message()
+			if byType && typ != nil {
+				if ta, tb := typeKey(a, typ), typeKey(b, typ); ta != tb {
+					return ta < tb
+				}
+			}
+			return a.Get(name).String() < b.Get(name).String()
+		})
+		for i, elem := range elems {
+			list.Set(i, elem)
+		}
+		return true
+	})
+}
+
+// resourceNameField returns the field that names a resource, or nil
+func resourceNameField(desc protoreflect.MessageDescriptor) protoreflect.FieldDescriptor {
+	for _, field := range []protoreflect.Name{"name", "account_id", "role_id", "dataset_id", "table_id", "repository_id"} {
+		if fd := desc.Fields().ByName(field); fd != nil && fd.Kind() == protoreflect.StringKind {
+			return fd
+		}
+	}
+	return nil
+}
+
+// typeKey returns the value of a resource's type field as a string, using the
+// value name for enums
+func typeKey(m protoreflect.Message, fd protoreflect.FieldDescriptor) string {
+	v := m.Get(fd)
+	if fd.Kind() == protoreflect.EnumKind {
+		if value := fd.Enum().Values().ByNumber(v.Enum()); value != nil {
+			return string(value.Name())
+		}
+		return fmt.Sprint(v.Enum())
+	}
+	return v.String()
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSorted(t *testing.T) {
+	cfg := &Config{
+		Networking: &Networking{
+			Vpcs: []*Vpc{
+				{Name: "web", Subnets: []*Subnet{{Name: "web-b"}, {Name: "web-a"}}},
+				{Name: "data"},
+			},
+		},
+		LoadBalancers: []*LoadBalancer{
+			{Name: "b-http", Type: LoadBalancerType_LOAD_BALANCER_TYPE_HTTP},
+			{Name: "c-tcp", Type: LoadBalancerType_LOAD_BALANCER_TYPE_TCP},
+			{Name: "a-https", Type: LoadBalancerType_LOAD_BALANCER_TYPE_HTTPS},
+		},
+		Iam: &Iam{
+			ServiceAccounts: []*ServiceAccount{{AccountId: "web-sa"}, {AccountId: "batch-sa"}},
+		},
+		Compute: &Compute{
+			Instances: []*Instance{{
+				Name:              "vm",
+				NetworkInterfaces: []*NetworkInterface{{Network: "web"}, {Network: "data"}},
+			}},
+		},
+	}
+
+	names := func(cfg *Config) []string {
+		var result []string
+		for _, vpc := range cfg.Networking.Vpcs {
+			result = append(result, vpc.Name)
+			for _, subnet := range vpc.Subnets {
+				result = append(result, subnet.Name)
+			}
+		}
+		for _, lb := range cfg.LoadBalancers {
+			result = append(result, lb.Name)
+		}
+		for _, account := range cfg.Iam.ServiceAccounts {
+			result = append(result, account.AccountId)
+		}
+		for _, iface := range cfg.Compute.Instances[0].NetworkInterfaces {
+			result = append(result, iface.Network)
+		}
+		return result
+	}
+
+	tests := []struct {
+		order ResourceOrder
+		want  []string
+	}{
+		{OrderByName, []string{"data", "web", "web-a", "web-b", "a-https", "b-http", "c-tcp", "batch-sa", "web-sa", "web", "data"}},
+		{OrderByType, []string{"data", "web", "web-a", "web-b", "b-http", "a-https", "c-tcp", "batch-sa", "web-sa", "web", "data"}},
+		{OrderAsDeclared, []string{"web", "web-b", "web-a", "data", "b-http", "c-tcp", "a-https", "web-sa", "batch-sa", "web", "data"}},
+	}
+
+	for _, test := range tests {
+		if got := names(Sorted(cfg, test.order)); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: expected %v, got %v", test.order, test.want, got)
+		}
+	}
+
+	if got := names(cfg); !reflect.DeepEqual(got, tests[2].want) {
+		t.Errorf("Expected the original configuration to be unchanged, got %v", got)
+	}
+
+	if _, err := ParseResourceOrder("random"); err == nil {
+		t.Error("Expected an error for an unknown resource order")
+	}
+	if order, err := ParseResourceOrder(""); err != nil || order != OrderByName {
+		t.Errorf("Expected the empty order to default to %s, got %s (%v)", OrderByName, order, err)
+	}
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultFileNames are the default names of the .tf files generated for a
+// project, in the order their resources are applied. Output.numbered
+// prefixes each with its position (e.g. "02-networking.tf").
+var DefaultFileNames = []string{
+	"project.tf",
+	"backend.tf",
+	"networking.tf",
+	"compute.tf",
+	"load_balancers.tf",
+	"iam.tf",
+	"storage.tf",
+	"cloud_run.tf",
+	"databases.tf",
+	"secret_manager.tf",
+	"kms.tf",
+	"monitoring.tf",
+	"logging.tf",
+	"tags.tf",
+	"pubsub.tf",
+	"scheduler.tf",
+	"tasks.tf",
+	"filestore.tf",
+	"gke.tf",
+	"dns.tf",
+	"bigquery.tf",
+	"functions.tf",
+	"artifacts.tf",
+	"variables.tf",
+	"outputs.tf",
+	"metadata.tf",
+}
+
+// OutputFileNames returns the name each generated .tf file is written under,
+// keyed by its default name: its file_map entry, its numbered name, or the
+// default name. A nil output keeps every default name.
+//
+// It returns an error if file_map renames a file that isn't generated, a
+// name isn't a plain file name ending in .tf (so files can't escape the
+// output directory), or two files get the same name.
+func OutputFileNames(output *Output) (map[string]string, error) {
+	known := make(map[string]bool, len(DefaultFileNames))
+	for _, name := range DefaultFileNames {
+		known[name] = true
+	}
+
+	// Sorted so the reported error doesn't depend on map order
+	renamed := make([]string, 0, len(output.GetFileMap()))
+	for name := range output.GetFileMap() {
+		renamed = append(renamed, name)
+	}
+	sort.Strings(renamed)
+	for _, name := range renamed {
+		if !known[name] {
+			return nil, fmt.Errorf("file_map renames unknown file: %s (must be a generated .tf file such as networking.tf)", name)
+		}
+		if custom := output.GetFileMap()[name]; !isPlainTfFileName(custom) {
+			return nil, fmt.Errorf("invalid file name for %s: %q (must be a file name ending in .tf, without directories)", name, custom)
+		}
+	}
+
+	names := make(map[string]string, len(DefaultFileNames))
+	owners := make(map[string]string, len(DefaultFileNames))
+	for i, name := range DefaultFileNames {
+		final := name
+		if custom, ok := output.GetFileMap()[name]; ok {
+			final = custom
+		} else if output.GetNumbered() {
+			final = fmt.Sprintf("%02d-%s", i, name)
+		}
+		if owner, ok := owners[final]; ok {
+			return nil, fmt.Errorf("%s and %s would both be written to %s", owner, name, final)
+		}
+		owners[final] = name
+		names[name] = final
+	}
+	return names, nil
+}
+
+// isPlainTfFileName reports whether name is a .tf file name without
+// directories, which stays inside the directory it's written to. Terraform
+// ignores hidden files, so the name can't start with a dot.
+func isPlainTfFileName(name string) bool {
+	return strings.HasSuffix(name, ".tf") && !strings.HasPrefix(name, ".") && !strings.ContainsAny(name, `/\`)
+}
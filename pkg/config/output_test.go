@@ -0,0 +1,57 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOutputFileNames(t *testing.T) {
+	names, err := OutputFileNames(nil)
+	if err != nil {
+		t.Fatalf("OutputFileNames(nil) failed: %v", err)
+	}
+	for _, name := range DefaultFileNames {
+		if names[name] != name {
+			t.Errorf("Expected %s to keep its name, got %s", name, names[name])
+		}
+	}
+
+	names, err = OutputFileNames(&Output{
+		Numbered: true,
+		FileMap:  map[string]string{"iam.tf": "permissions.tf"},
+	})
+	if err != nil {
+		t.Fatalf("OutputFileNames failed: %v", err)
+	}
+	for name, want := range map[string]string{
+		"project.tf":    "00-project.tf",
+		"networking.tf": "02-networking.tf",
+		"iam.tf":        "permissions.tf",
+		"metadata.tf":   "25-metadata.tf",
+	} {
+		if names[name] != want {
+			t.Errorf("Expected %s to be renamed to %s, got %s", name, want, names[name])
+		}
+	}
+
+	tests := []struct {
+		name    string
+		fileMap map[string]string
+		want    string
+	}{
+		{"unknown file", map[string]string{"network.tf": "net.tf"}, "unknown file: network.tf"},
+		{"not a .tf file", map[string]string{"iam.tf": "iam.txt"}, "invalid file name for iam.tf"},
+		{"directory", map[string]string{"iam.tf": "../iam.tf"}, "invalid file name for iam.tf"},
+		{"hidden file", map[string]string{"iam.tf": ".iam.tf"}, "invalid file name for iam.tf"},
+		{"collision with default", map[string]string{"iam.tf": "storage.tf"}, "iam.tf and storage.tf would both be written to storage.tf"},
+		{"collision between renames", map[string]string{"iam.tf": "main.tf", "dns.tf": "main.tf"}, "iam.tf and dns.tf would both be written to main.tf"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := OutputFileNames(&Output{FileMap: test.fileMap})
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("Expected error containing %q, got: %v", test.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// SplitByProject returns one configuration per entry in cfg.Projects, in
+// declaration order. Each has project set to that entry, projects cleared, and
+// only the resources whose project field names it. Resources nested in another
+// resource (e.g. subnets of a VPC) follow their parent. When a single project
+// is declared, resources may leave their project field empty.
+//
+// A configuration without projects is returned unchanged as the only element.
+// It returns an error if project and projects are both set, a project ID is
+// declared twice, or a resource names an undeclared project or, with several
+// projects declared, none at all. The original configuration is not modified.
+func SplitByProject(cfg *Config) ([]*Config, error) {
+	if len(cfg.GetProjects()) == 0 {
+		return []*Config{cfg}, nil
+	}
+	if cfg.GetProject() != nil {
+		return nil, fmt.Errorf("project and projects are mutually exclusive")
+	}
+
+	declared := make(map[string]bool)
+	for _, project := range cfg.GetProjects() {
+		if declared[project.GetId()] {
+			return nil, fmt.Errorf("duplicate project ID in projects: %s", project.GetId())
+		}
+		declared[project.GetId()] = true
+	}
+	if err := checkProjectAssignments(cfg.ProtoReflect(), declared); err != nil {
+		return nil, err
+	}
+
+	scoped := make([]*Config, 0, len(cfg.GetProjects()))
+	for _, project := range cfg.GetProjects() {
+		projectCfg := proto.Clone(cfg).(*Config)
+		projectCfg.Project = proto.Clone(project).(*Project)
+		projectCfg.Projects = nil
+		pruneOtherProjects(projectCfg.ProtoReflect(), project.GetId())
+		scoped = append(scoped, projectCfg)
+	}
+	return scoped, nil
+}
+
+// checkProjectAssignments checks the project field of every resource in m
+// against the declared project IDs
+func checkProjectAssignments(m protoreflect.Message, declared map[string]bool) error {
+	var err error
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Kind() != protoreflect.MessageKind || fd.IsMap() {
+			return true
+		}
+		if !fd.IsList() {
+			err = checkProjectAssignments(v.Message(), declared)
+			return err == nil
+		}
+
+		list := v.List()
+		for i := 0; i < list.Len() && err == nil; i++ {
+			elem := list.Get(i).Message()
+			project, ok := resourceProject(elem)
+			switch {
+			case !ok:
+				err = checkProjectAssignments(elem, declared)
+			case project == "" && len(declared) > 1:
+				err = fmt.Errorf("%s must specify a project when multiple projects are declared", describeResource(elem))
+			case project != "" && !declared[project]:
+				err = fmt.Errorf("%s references undeclared project: %s", describeResource(elem), project)
+			}
+		}
+		return err == nil
+	})
+	return err
+}
+
+// pruneOtherProjects removes resources assigned to a project other than
+// projectID from every repeated message field of m, recursively
+func pruneOtherProjects(m protoreflect.Message, projectID string) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Kind() != protoreflect.MessageKind || fd.IsMap() {
+			return true
+		}
+
+		if !fd.IsList() {
+			// Drop sections left empty by pruning (e.g. networking when every VPC
+			// belongs to another project) so no empty files are generated
+			wasEmpty := proto.Size(v.Message().Interface()) == 0
+			pruneOtherProjects(v.Message(), projectID)
+			if !wasEmpty && proto.Size(v.Message().Interface()) == 0 {
+				m.Clear(fd)
+			}
+			return true
+		}
+
+		list := v.List()
+		kept := 0
+		for i := 0; i < list.Len(); i++ {
+			elem := list.Get(i)
+			if project, ok := resourceProject(elem.Message()); ok && project != "" && project != projectID {
+				continue
+			}
+			pruneOtherProjects(elem.Message(), projectID)
+			list.Set(kept, elem)
+			kept++
+		}
+		list.Truncate(kept)
+		return true
+	})
+}
+
+// resourceProject returns the project field of a resource, and whether the
+// resource has one
+func resourceProject(m protoreflect.Message) (string, bool) {
+	fd := m.Descriptor().Fields().ByName("project")
+	if fd == nil || fd.Kind() != protoreflect.StringKind || fd.IsList() {
+		return "", false
+	}
+	return m.Get(fd).String(), true
+}
+
+// describeResource returns the message type and name of a resource for errors
+// (e.g. "StorageBucket assets-bucket")
+func describeResource(m protoreflect.Message) string {
+	for _, field := range []protoreflect.Name{"name", "account_id", "role_id", "dataset_id", "table_id", "repository_id", "short_name", "role"} {
+		if fd := m.Descriptor().Fields().ByName(field); fd != nil && m.Get(fd).String() != "" {
+			return fmt.Sprintf("%s %s", m.Descriptor().Name(), m.Get(fd).String())
+		}
+	}
+	return string(m.Descriptor().Name())
+}
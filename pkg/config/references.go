@@ -0,0 +1,196 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// referencePattern matches a reference path such as "env" or "project.id"
+var referencePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)*$`)
+
+// ResolveReferences replaces string values of the form "@<path>" in cfg with the
+// value they reference. A path is either the name of an entry in vars
+// (e.g. "@env") or a dotted path of singular fields from the configuration root
+// (e.g. "@project.id"); vars take precedence. References are resolved in plain
+// string fields, repeated string fields, and map values. A value starting with
+// "@@" is kept literally with the first "@" removed, and a bare "@" (such as the
+// apex name of a DNS record set) is kept as is.
+//
+// References must make up the whole value, so "user@example.com" is not a
+// reference. It returns an error naming the field of the first reference that
+// cannot be resolved. Resolution should run once, before validation.
+func ResolveReferences(cfg *Config) error {
+	// Look references up in an unmodified copy so results do not depend on the
+	// order fields are resolved in
+	original := proto.Clone(cfg).(*Config)
+	r := &referenceResolver{root: original.ProtoReflect(), vars: original.GetVars()}
+	return r.resolveMessage(cfg.ProtoReflect(), "")
+}
+
+// referenceResolver resolves "@" references against a configuration
+type referenceResolver struct {
+	root protoreflect.Message
+	vars map[string]string
+}
+
+// resolveMessage resolves the references in every string value of m and its
+// nested messages. prefix is the field path of m, used in errors.
+func (r *referenceResolver) resolveMessage(m protoreflect.Message, prefix string) error {
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if !m.Has(fd) {
+			continue
+		}
+		path := prefix + string(fd.Name())
+		v := m.Get(fd)
+
+		switch {
+		case fd.IsMap():
+			// Collect keys first since the map must not be modified while ranging over it
+			var keys []protoreflect.MapKey
+			v.Map().Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+				keys = append(keys, k)
+				return true
+			})
+			for _, k := range keys {
+				entryPath := fmt.Sprintf("%s[%q]", path, k.String())
+				switch fd.MapValue().Kind() {
+				case protoreflect.StringKind:
+					resolved, err := r.resolveString(v.Map().Get(k).String(), entryPath)
+					if err != nil {
+						return err
+					}
+					v.Map().Set(k, protoreflect.ValueOfString(resolved))
+				case protoreflect.MessageKind:
+					if err := r.resolveMessage(v.Map().Get(k).Message(), entryPath+"."); err != nil {
+						return err
+					}
+				}
+			}
+		case fd.IsList():
+			list := v.List()
+			for j := 0; j < list.Len(); j++ {
+				elemPath := fmt.Sprintf("%s[%d]", path, j)
+				switch fd.Kind() {
+				case protoreflect.StringKind:
+					resolved, err := r.resolveString(list.Get(j).String(), elemPath)
+					if err != nil {
+						return err
+					}
+					list.Set(j, protoreflect.ValueOfString(resolved))
+				case protoreflect.MessageKind:
+					if err := r.resolveMessage(list.Get(j).Message(), elemPath+"."); err != nil {
+						return err
+					}
+				}
+			}
+		case fd.Kind() == protoreflect.StringKind:
+			resolved, err := r.resolveString(v.String(), path)
+			if err != nil {
+				return err
+			}
+			m.Set(fd, protoreflect.ValueOfString(resolved))
+		case fd.Kind() == protoreflect.MessageKind:
+			if err := r.resolveMessage(v.Message(), path+"."); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolveString returns s with a reference replaced by its value, or s
+// unchanged if it is not a reference. path is the field holding s.
+func (r *referenceResolver) resolveString(s, path string) (string, error) {
+	if strings.HasPrefix(s, "@@") {
+		return s[1:], nil
+	}
+	if !isReference(s) {
+		return s, nil
+	}
+
+	value, err := r.lookup(s[1:], map[string]bool{})
+	if err != nil {
+		return "", fmt.Errorf("%s: cannot resolve reference %q: %w", path, s, err)
+	}
+	return value, nil
+}
+
+// lookup returns the value of the reference path ref. seen holds the
+// references being resolved, to detect cycles.
+func (r *referenceResolver) lookup(ref string, seen map[string]bool) (string, error) {
+	if !referencePattern.MatchString(ref) {
+		return "", fmt.Errorf("references must be a var name or a dotted field path such as @project.id (use @@ for a literal @)")
+	}
+	if seen[ref] {
+		return "", fmt.Errorf("reference cycle through @%s", ref)
+	}
+	seen[ref] = true
+
+	value, ok := r.vars[ref]
+	if !ok {
+		var err error
+		if value, err = r.lookupField(ref); err != nil {
+			return "", err
+		}
+	}
+
+	// The referenced value may itself be a reference (e.g. a var set to "@project.id")
+	if isReference(value) {
+		return r.lookup(value[1:], seen)
+	}
+	if strings.HasPrefix(value, "@@") {
+		return value[1:], nil
+	}
+	return value, nil
+}
+
+// isReference reports whether s is a reference: an "@" followed by a path,
+// rather than a bare "@" or an escaped "@@"
+func isReference(s string) bool {
+	return len(s) > 1 && s[0] == '@' && s[1] != '@'
+}
+
+// lookupField returns the value of the singular field at the dotted path ref
+// from the configuration root
+func (r *referenceResolver) lookupField(ref string) (string, error) {
+	m := r.root
+	segments := strings.Split(ref, ".")
+	for i, segment := range segments {
+		fd := m.Descriptor().Fields().ByName(protoreflect.Name(segment))
+		if fd == nil {
+			if i == 0 {
+				return "", fmt.Errorf("no var or configuration field named %q", segment)
+			}
+			return "", fmt.Errorf("%s has no field named %q", strings.Join(segments[:i], "."), segment)
+		}
+		if fd.IsList() || fd.IsMap() {
+			return "", fmt.Errorf("%s is a repeated field and cannot be referenced", strings.Join(segments[:i+1], "."))
+		}
+
+		last := i == len(segments)-1
+		switch {
+		case fd.Kind() == protoreflect.MessageKind && last:
+			return "", fmt.Errorf("%s is a message and cannot be referenced; reference one of its fields", ref)
+		case fd.Kind() == protoreflect.MessageKind:
+			m = m.Get(fd).Message()
+		case !last:
+			return "", fmt.Errorf("%s is not a message", strings.Join(segments[:i+1], "."))
+		case !m.Has(fd):
+			return "", fmt.Errorf("%s is not set", ref)
+		case fd.Kind() == protoreflect.EnumKind:
+			if ev := fd.Enum().Values().ByNumber(m.Get(fd).Enum()); ev != nil {
+				return string(ev.Name()), nil
+			}
+			return fmt.Sprintf("%d", m.Get(fd).Enum()), nil
+		default:
+			return m.Get(fd).String(), nil
+		}
+	}
+	return "", fmt.Errorf("empty reference")
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveReferences(t *testing.T) {
+	newConfig := func(description string) *Config {
+		return &Config{
+			Vars: map[string]string{"env": "prod", "owner": "@project.id", "apex": "@"},
+			Project: &Project{
+				Id:     "test-project-123",
+				Labels: map[string]string{"environment": "@env", "owner": "@owner", "handle": "@@team"},
+			},
+			Dns: &Dns{
+				ManagedZones: []*DnsManagedZone{{
+					Name:       "public",
+					RecordSets: []*DnsRecordSet{{Name: "@", Type: "TXT"}, {Name: "@apex", Type: "MX"}},
+				}},
+			},
+			Networking: &Networking{
+				ReservedIps: []*ReservedIp{{Name: "@env", Region: Region_REGION_US_CENTRAL1, Description: description}},
+			},
+			Storage: &Storage{
+				Buckets: []*StorageBucket{{Name: "@project.id", Labels: map[string]string{"contact": "ops@example.com"}}},
+			},
+		}
+	}
+
+	err := ResolveReferences(newConfig("@networking.reserved_ips"))
+	if err == nil || !strings.Contains(err.Error(), `networking.reserved_ips[0].description: cannot resolve reference "@networking.reserved_ips"`) {
+		t.Fatalf("Expected error naming the unresolvable field, got: %v", err)
+	}
+
+	cfg := newConfig("Region @project")
+	if err := ResolveReferences(cfg); err != nil {
+		t.Fatalf("Expected references to resolve, got: %v", err)
+	}
+
+	labels := cfg.GetProject().GetLabels()
+	if labels["environment"] != "prod" || labels["owner"] != "test-project-123" || labels["handle"] != "@team" {
+		t.Errorf("Unexpected project labels: %v", labels)
+	}
+	if cfg.GetNetworking().GetReservedIps()[0].GetName() != "prod" {
+		t.Errorf("Expected reserved IP name prod, got %q", cfg.GetNetworking().GetReservedIps()[0].GetName())
+	}
+	if cfg.GetNetworking().GetReservedIps()[0].GetDescription() != "Region @project" {
+		t.Errorf("Expected embedded @ to be left alone, got %q", cfg.GetNetworking().GetReservedIps()[0].GetDescription())
+	}
+	for _, record := range cfg.GetDns().GetManagedZones()[0].GetRecordSets() {
+		if record.GetName() != "@" {
+			t.Errorf("Expected the apex record name @ to be kept, got %q", record.GetName())
+		}
+	}
+	bucket := cfg.GetStorage().GetBuckets()[0]
+	if bucket.GetName() != "test-project-123" || bucket.GetLabels()["contact"] != "ops@example.com" {
+		t.Errorf("Unexpected bucket: name %q labels %v", bucket.GetName(), bucket.GetLabels())
+	}
+
+	for _, tt := range []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"unknown name", "@stage", `no var or configuration field named "stage"`},
+		{"unknown nested field", "@project.owner", `project has no field named "owner"`},
+		{"message", "@project", "is a message"},
+		{"unset field", "@project.folder_id", "project.folder_id is not set"},
+		{"invalid syntax", "@Project-ID", "dotted field path"},
+		{"cycle", "@loop", "reference cycle"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Vars:    map[string]string{"loop": "@loop"},
+				Project: &Project{Id: "test-project-123", Name: tt.value},
+			}
+			err := ResolveReferences(cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got: %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+// Package proto embeds the Protocol Buffer schema definitions, whose comments
+// the generated code in pkg/config doesn't keep.
+package proto
+
+import "embed"
+
+// Files holds custoodian/config.proto and custoodian/enums.proto.
+//
+//go:embed custoodian/*.proto
+var Files embed.FS
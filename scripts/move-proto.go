@@ -48,4 +48,4 @@ func main() {
 	if len(files) == 0 {
 		fmt.Println("No .pb.go files found to move")
 	}
-}
\ No newline at end of file
+}
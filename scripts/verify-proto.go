@@ -20,6 +20,6 @@ func main() {
 		}
 		fmt.Printf("✓ %s exists\n", file)
 	}
-	
+
 	fmt.Println("✓ All protobuf files generated successfully")
-}
\ No newline at end of file
+}